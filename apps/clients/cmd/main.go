@@ -1,7 +1,12 @@
 package main
 
-import "github.com/owjoel/client-factpack/apps/clients/pkg/web"
-import _ "github.com/owjoel/client-factpack/apps/clients/docs"
+import (
+	"os"
+
+	_ "github.com/owjoel/client-factpack/apps/clients/docs"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/cli"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web"
+)
 
 // 	Swagger
 //	@title			client-factpack/clients
@@ -11,5 +16,9 @@ import _ "github.com/owjoel/client-factpack/apps/clients/docs"
 //	@BasePath		/api/v1
 
 func main() {
+	// maintenance subcommands, e.g. `clients import -mode name drop.ndjson`
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
 	web.Run()
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -18,11 +19,62 @@ var (
 	PrefectAPIKey       = clean(os.Getenv("PREFECT_API_KEY"))
 	PrefectScrapeFlowID = clean(os.Getenv("PREFECT_SCRAPE_FLOW_ID"))
 	PrefectMatchFlowID  = clean(os.Getenv("PREFECT_MATCH_FLOW_ID"))
+	PrefectSyncFlowID   = clean(os.Getenv("PREFECT_SYNC_FLOW_ID"))
+
+	// WorkflowBackend is "prefect", or "local" to run jobs with stub handlers in the service for development
+	WorkflowBackend = withDefault(clean(os.Getenv("WORKFLOW_BACKEND")), "prefect")
+
+	// JobCallbackSecret signs the job status callbacks sent by the Prefect flows
+	JobCallbackSecret = clean(os.Getenv("JOB_CALLBACK_SECRET"))
+	RabbitMQURL       = clean(os.Getenv("RABBITMQ_URL"))
+
+	// AuditExportSecret signs the manifests of audit log exports, exports are refused without it
+	AuditExportSecret = clean(os.Getenv("AUDIT_EXPORT_SECRET"))
+
+	// AuditAnchorInterval is how often the head of the log chain is anchored. AuditAnchorStore is where anchors are
+	// kept, away from the logs: "file" appends them to AuditAnchorFile, "mongo" keeps them in the separate deployment
+	// at AuditAnchorMongoURI.
+	AuditAnchorInterval = durationWithDefault(os.Getenv("AUDIT_ANCHOR_INTERVAL"), time.Hour)
+	AuditAnchorStore    = withDefault(clean(os.Getenv("AUDIT_ANCHOR_STORE")), "file")
+	AuditAnchorFile     = withDefault(clean(os.Getenv("AUDIT_ANCHOR_FILE")), "audit-anchors/anchors.ndjson")
+	AuditAnchorMongoURI = clean(os.Getenv("AUDIT_ANCHOR_MONGO_URI"))
+
+	// AuditRetentionDays is how many days the logs of each operation are kept before they are archived and purged,
+	// e.g. "view=90,update=2555". Other operations are kept for AuditRetentionDefaultDays, 0 keeps logs forever.
+	AuditRetentionDays        = limitsByName(withDefault(clean(os.Getenv("AUDIT_RETENTION_DAYS")), "view=90,update=2555,delete=2555"))
+	AuditRetentionDefaultDays = limitWithDefault(os.Getenv("AUDIT_RETENTION_DEFAULT_DAYS"), 0)
+	AuditRetentionInterval    = durationWithDefault(os.Getenv("AUDIT_RETENTION_INTERVAL"), 24*time.Hour)
+	// AuditArchiveDir is where purged logs are archived, as gzipped NDJSON files
+	AuditArchiveDir = withDefault(clean(os.Getenv("AUDIT_ARCHIVE_DIR")), "audit-archive")
+
+	// JobReaperInterval is how often stale jobs are looked for, and JobTimeout* how long each job type may go without an update
+	JobReaperInterval     = durationWithDefault(os.Getenv("JOB_REAPER_INTERVAL"), time.Minute)
+	JobTimeoutScrape      = durationWithDefault(os.Getenv("JOB_TIMEOUT_SCRAPE"), 30*time.Minute)
+	JobTimeoutMatch       = durationWithDefault(os.Getenv("JOB_TIMEOUT_MATCH"), 30*time.Minute)
+	JobTimeoutSync        = durationWithDefault(os.Getenv("JOB_TIMEOUT_SYNC"), 10*time.Minute)
+	JobReaperCheckPrefect = boolWithDefault(os.Getenv("JOB_REAPER_CHECK_PREFECT"), true)
+
+	// OutboxDispatchInterval is how often queued flow runs are triggered, and OutboxMaxAttempts how many tries each gets
+	OutboxDispatchInterval = durationWithDefault(os.Getenv("OUTBOX_DISPATCH_INTERVAL"), 2*time.Second)
+	OutboxMaxAttempts      = intWithDefault(os.Getenv("OUTBOX_MAX_ATTEMPTS"), 5)
+
+	// IdempotencyKeyTTL is how long a request's Idempotency-Key and stored response are kept for replays
+	IdempotencyKeyTTL = durationWithDefault(os.Getenv("IDEMPOTENCY_KEY_TTL"), 24*time.Hour)
+
+	// Quotas on scrape and match jobs, 0 turns a limit off. Active jobs are pending or processing, daily counts reset at
+	// midnight UTC. QuotaDailyJobsByGroup raises or lowers the daily limit for Cognito groups, e.g. "admin=500,analyst=100".
+	QuotaMaxActivePerUser = limitWithDefault(os.Getenv("QUOTA_MAX_ACTIVE_PER_USER"), 5)
+	QuotaMaxActiveGlobal  = limitWithDefault(os.Getenv("QUOTA_MAX_ACTIVE_GLOBAL"), 50)
+	QuotaDailyJobs        = limitWithDefault(os.Getenv("QUOTA_DAILY_JOBS"), 50)
+	QuotaDailyJobsByGroup = limitsByName(os.Getenv("QUOTA_DAILY_JOBS_BY_GROUP"))
+	QuotaRetryAfter       = durationWithDefault(os.Getenv("QUOTA_RETRY_AFTER"), 30*time.Second)
 
 	ClientID     = os.Getenv("COGNITO_USERPOOL_CLIENT_ID")
 	ClientSecret = os.Getenv("COGNITO_USERPOOL_CLIENT_SECRET")
 	UserPoolID   = os.Getenv("COGNITO_USERPOOL_ID")
 	AwsRegion    = os.Getenv("AWS_REGION")
+
+	AdminGroup = withDefault(clean(os.Getenv("ADMIN_GROUP")), "admin")
 )
 
 func GetPort(defaultPort int) int {
@@ -48,3 +100,60 @@ func GetVersion() string {
 func clean(s string) string {
 	return strings.Trim(s, "\r\n\t ")
 }
+
+func withDefault(s string, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func durationWithDefault(s string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(clean(s))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+func intWithDefault(s string, fallback int) int {
+	n, err := strconv.Atoi(clean(s))
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+func boolWithDefault(s string, fallback bool) bool {
+	b, err := strconv.ParseBool(clean(s))
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// limitWithDefault is like intWithDefault but keeps 0, which turns a limit off
+func limitWithDefault(s string, fallback int) int {
+	n, err := strconv.Atoi(clean(s))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+// limitsByName parses "name=limit" pairs separated by commas, skipping malformed pairs
+func limitsByName(s string) map[string]int {
+	limits := map[string]int{}
+	for _, pair := range strings.Split(clean(s), ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(clean(value))
+		if err != nil || n < 0 || clean(name) == "" {
+			continue
+		}
+		limits[clean(name)] = n
+	}
+	return limits
+}
@@ -13,6 +13,7 @@ var (
 	ErrForbidden        = errors.New("forbidden")                // 403
 	ErrNotFound         = errors.New("not found")                // 404
 	ErrConflict         = errors.New("conflict")                 // 409
+	ErrTooManyRequests  = errors.New("too many requests")        // 429
 
 	// 500 errors
 	ErrInternal         = errors.New("internal server error")    // 500
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ActivityType string
+
+const (
+	ActivityLog     ActivityType = "log"
+	ActivityJob     ActivityType = "job"
+	ActivityArticle ActivityType = "article"
+	ActivityNote    ActivityType = "note"
+	ActivityChange  ActivityType = "change"
+)
+
+var ActivityTypes = []ActivityType{ActivityLog, ActivityJob, ActivityArticle, ActivityNote, ActivityChange}
+
+// ActivityItem is one entry of a client's activity feed, projected from a log, job, article, note or timeline point
+type ActivityItem struct {
+	Type      ActivityType `bson:"type" json:"type"`
+	RefID     string       `bson:"refId" json:"refId"` // id of the underlying document
+	Timestamp time.Time    `bson:"timestamp" json:"timestamp"`
+	Actor     string       `bson:"actor,omitempty" json:"actor,omitempty"`
+	Action    string       `bson:"action" json:"action"` // log operation, job type, article source, note or changed field
+	Summary   string       `bson:"summary,omitempty" json:"summary,omitempty"`
+	Value     any          `bson:"value,omitempty" json:"value,omitempty"` // new value of a changed field
+}
+
+type GetActivityQuery struct {
+	Types    []ActivityType `form:"type"`
+	From     time.Time      `form:"from"`
+	To       time.Time      `form:"to"`
+	Page     int            `form:"page"`
+	PageSize int            `form:"pageSize"`
+	// ArticleIDs are the articles linked to the client, resolved by the service
+	ArticleIDs []bson.ObjectID `form:"-" json:"-"`
+	// ArticleLinks are the link times known for them
+	ArticleLinks []ArticleLink `form:"-" json:"-"`
+}
+
+type GetActivityResponse struct {
+	Total    int            `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"pageSize"`
+	Items    []ActivityItem `json:"items"`
+}
+
+type Note struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id" swaggerignore:"true"`
+	ClientID  string        `bson:"clientId" json:"clientId"`
+	Author    string        `bson:"author" json:"author"`
+	Text      string        `bson:"text" json:"text"`
+	CreatedAt time.Time     `bson:"createdAt" json:"createdAt"`
+}
+
+type CreateNoteReq struct {
+	Text string `json:"text"`
+}
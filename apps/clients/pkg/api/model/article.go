@@ -1,6 +1,10 @@
 package model
 
-import "go.mongodb.org/mongo-driver/v2/bson"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
 
 type GetArticlesReq struct {
 	ID []string `json:"id"`
@@ -11,14 +15,52 @@ type GetArticlesRes struct {
 }
 
 type Article struct {
-	ID      bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Source  string        `bson:"source" json:"source"`
-	Title   string        `bson:"title" json:"title"`
-	URL     string        `bson:"url" json:"url"`
-	Summary string        `bson:"summary" json:"summary"`
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Source       string        `bson:"source" json:"source"`
+	Title        string        `bson:"title" json:"title"`
+	URL          string        `bson:"url" json:"url"`
+	CanonicalURL string        `bson:"canonicalUrl,omitempty" json:"canonicalUrl,omitempty"`
+	Summary      string        `bson:"summary" json:"summary"`
+	PublishedAt  time.Time     `bson:"publishedAt" json:"publishedAt"`
+	Sentiment    *Sentiment    `bson:"sentiment,omitempty" json:"sentiment,omitempty"`
 }
 
+// Sentiment is the FinBERT classification of an article summary. Score is the confidence of Label.
 type Sentiment struct {
-	Label string  `json:"label"`
-	Score float64 `json:"score"`
+	Label string  `bson:"label" json:"label"`
+	Score float64 `bson:"score" json:"score"`
+}
+
+type GetClientArticlesQuery struct {
+	Source   string `form:"source"`
+	Keyword  string `form:"keyword"`
+	Sort     string `form:"sort"` // "asc" or "desc" by published date, defaults to newest first
+	Page     int    `form:"page"`
+	PageSize int    `form:"pageSize"`
+}
+
+type GetClientArticlesResponse struct {
+	Total    int            `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"pageSize"`
+	Sources  map[string]int `json:"sources"`
+	Articles []Article      `json:"articles"`
+}
+
+// CreateArticleReq is an article submitted for ingestion, linked to every client in ClientIDs
+type CreateArticleReq struct {
+	Source      string     `json:"source"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Summary     string     `json:"summary"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	ClientIDs   []string   `json:"clientIds"`
+	Sentiment   *Sentiment `json:"sentiment"`
+}
+
+type CreateArticleRes struct {
+	ID        string   `json:"id"`
+	Duplicate bool     `json:"duplicate"` // an article with the same canonical URL already existed
+	ClientIDs []string `json:"clientIds"`
+	Excluded  []string `json:"excluded,omitempty"` // clients the article was marked irrelevant for, left unlinked
 }
@@ -11,6 +11,30 @@ type Client struct {
 	Data     bson.D          `bson:"data" json:"data"`
 	Metadata ClientMetadata  `bson:"metadata" json:"metadata"`
 	Articles []bson.ObjectID `json:"articles"`
+	Documents []ClientDocument `bson:"documents,omitempty" json:"documents,omitempty"`
+	ArticleLinks []ArticleLink `bson:"articleLinks,omitempty" json:"articleLinks,omitempty"` // when each article in Articles was linked, if known
+	LegalHold *LegalHold       `bson:"legalHold,omitempty" json:"legalHold,omitempty"`
+}
+
+// LegalHold suspends the purging of the client's logs until it is lifted
+type LegalHold struct {
+	Reason string    `bson:"reason" json:"reason"`
+	SetBy  string    `bson:"setBy" json:"setBy"`
+	SetAt  time.Time `bson:"setAt" json:"setAt"`
+}
+
+// ClientDocument is an uploaded document a reviewer confirmed is about the client
+type ClientDocument struct {
+	JobID    string    `bson:"jobId" json:"jobId"` // the match job the document was uploaded with
+	FileName string    `bson:"fileName" json:"fileName"`
+	LinkedBy string    `bson:"linkedBy" json:"linkedBy"`
+	LinkedAt time.Time `bson:"linkedAt" json:"linkedAt"`
+}
+
+// ArticleLink records when an article was linked to the client
+type ArticleLink struct {
+	ArticleID bson.ObjectID `bson:"articleId" json:"articleId"`
+	LinkedAt  time.Time     `bson:"linkedAt" json:"linkedAt"`
 }
 
 type ClientMetadata struct {
@@ -27,6 +51,11 @@ type GetClientsQuery struct {
 	Page     int    `form:"page" binding:"required"`
 	PageSize int    `form:"pageSize" binding:"required"`
 	Sort     bool   `form:"sort"`
+
+	// NegativePressDays keeps only clients with a negative article published in the last N days
+	NegativePressDays int `form:"negativePressDays"`
+	// NegativePressSince is resolved by the service from NegativePressDays, it is not bound from the request
+	NegativePressSince *time.Time `form:"-" json:"-"`
 }
 
 type GetClientsResponse struct {
@@ -52,6 +81,16 @@ type CreateClientByNameReq struct {
 	Name string `json:"name"`
 }
 
+// SetLegalHoldReq places a legal hold on a client, or lifts it when Hold is false
+type SetLegalHoldReq struct {
+	Hold   *bool  `json:"hold" binding:"required"`
+	Reason string `json:"reason"` // required to place a hold
+}
+
+type RescrapeClientsReq struct {
+	ClientIDs []string `json:"clientIds"`
+}
+
 type UpdateClientReq struct {
 	Changes []SimpleChanges `json:"changes"`
 }
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ArticleFeedback is a reviewer's verdict on whether an article linked to a client is really about them.
+// There is at most one per client and article, the latest verdict wins.
+type ArticleFeedback struct {
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"id" swaggerignore:"true"`
+	ClientID     string        `bson:"clientId" json:"clientId"`
+	ArticleID    string        `bson:"articleId" json:"articleId"`
+	URL          string        `bson:"url" json:"url"`                   // as stored on the article
+	CanonicalURL string        `bson:"canonicalUrl" json:"canonicalUrl"` // exclusions are matched on it, whichever link the article came from
+	Source       string        `bson:"source" json:"source"`
+	Relevant     bool          `bson:"relevant" json:"relevant"`
+	Reason       string        `bson:"reason,omitempty" json:"reason,omitempty"`
+	Actor        string        `bson:"actor" json:"actor"`
+	Timestamp    time.Time     `bson:"timestamp" json:"timestamp"`
+}
+
+type ArticleFeedbackReq struct {
+	Relevant *bool  `json:"relevant"`
+	Reason   string `json:"reason"`
+}
+
+type GetFeedbackStatsQuery struct {
+	Source string    `form:"source"`
+	From   time.Time `form:"from"`
+	To     time.Time `form:"to"`
+}
+
+// FeedbackStats counts reviewed articles, the false positive rate is the share marked irrelevant
+type FeedbackStats struct {
+	Source            string  `bson:"_id" json:"source,omitempty"`
+	Reviewed          int     `bson:"reviewed" json:"reviewed"`
+	Irrelevant        int     `bson:"irrelevant" json:"irrelevant"`
+	FalsePositiveRate float64 `bson:"-" json:"falsePositiveRate"`
+}
+
+type GetFeedbackStatsResponse struct {
+	FeedbackStats
+	Sources []FeedbackStats `json:"sources"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type IdempotencyStatus string
+
+const (
+	IdempotencyInProgress IdempotencyStatus = "in_progress"
+	IdempotencyCompleted  IdempotencyStatus = "completed"
+)
+
+// IdempotencyRecord remembers a request sent with an Idempotency-Key and the response it got, so repeats can be replayed.
+// Keys are scoped to the user and route, ID is the hash of that scope.
+type IdempotencyRecord struct {
+	ID          string            `bson:"_id" json:"id"`
+	Key         string            `bson:"key" json:"key"`
+	Actor       string            `bson:"actor" json:"actor"`
+	Method      string            `bson:"method" json:"method"`
+	Path        string            `bson:"path" json:"path"`
+	RequestHash string            `bson:"requestHash" json:"requestHash"`
+	Token       string            `bson:"token" json:"-"` // identifies the request holding the key
+	Status      IdempotencyStatus `bson:"status" json:"status"`
+	StatusCode  int               `bson:"statusCode,omitempty" json:"statusCode,omitempty"`
+	ContentType string            `bson:"contentType,omitempty" json:"contentType,omitempty"`
+	Body        []byte            `bson:"body,omitempty" json:"body,omitempty"`
+	LockedUntil time.Time         `bson:"lockedUntil" json:"lockedUntil"` // an unfinished request past this is assumed to have crashed
+	CreatedAt   time.Time         `bson:"createdAt" json:"createdAt"`
+	ExpiresAt   time.Time         `bson:"expiresAt" json:"expiresAt"`
+}
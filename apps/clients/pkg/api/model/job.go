@@ -9,31 +9,99 @@ import (
 type JobStatus string
 
 const (
-	JobStatusPending   JobStatus = "pending"
-	JobStatusCompleted JobStatus = "completed"
-	JobStatusFailed    JobStatus = "failed"
+	JobStatusPending    JobStatus = "pending"
+	JobStatusProcessing JobStatus = "processing"
+	JobStatusCompleted  JobStatus = "completed"
+	JobStatusFailed     JobStatus = "failed"
+	JobStatusCancelled  JobStatus = "cancelled"
+	// JobStatusPartial is a batch whose children have all finished, some of them unsuccessfully
+	JobStatusPartial JobStatus = "partial"
 )
 
+// jobTransitions lists the statuses a job may move to from each status. Terminal statuses have none.
+var jobTransitions = map[JobStatus][]JobStatus{
+	JobStatusPending:    {JobStatusProcessing, JobStatusCompleted, JobStatusFailed, JobStatusCancelled},
+	JobStatusProcessing: {JobStatusCompleted, JobStatusFailed, JobStatusCancelled},
+}
+
+// Valid reports whether s is a known job status
+func (s JobStatus) Valid() bool {
+	switch s {
+	case JobStatusPending, JobStatusProcessing, JobStatusCompleted, JobStatusFailed, JobStatusCancelled, JobStatusPartial:
+		return true
+	}
+	return false
+}
+
+// Terminal reports whether a job in status s is finished and can no longer change
+func (s JobStatus) Terminal() bool {
+	return s.Valid() && len(jobTransitions[s]) == 0
+}
+
+// CanTransitionTo reports whether a job may move from s to next
+func (s JobStatus) CanTransitionTo(next JobStatus) bool {
+	for _, allowed := range jobTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type JobType string
 
 const (
 	Scrape JobType = "scrape"
 	Match  JobType = "match"
+	// Batch groups child jobs started together, it runs no flow of its own
+	Batch JobType = "batch"
+	// Sync refreshes a client's profile in the vector index that match jobs search, after a match changed it
+	Sync JobType = "sync"
 )
 
+// Valid reports whether t is a known job type
+func (t JobType) Valid() bool {
+	return t == Scrape || t == Match || t == Batch || t == Sync
+}
+
 type Job struct {
 	ID            bson.ObjectID `bson:"_id,omitempty" json:"id" swaggerignore:"true"`
 	PrefectFlowID string        `bson:"prefectFlowID" json:"prefectFlowID"`
 	Type          JobType       `bson:"type" json:"type"`
+	ClientID      string        `bson:"clientId,omitempty" json:"clientId,omitempty"`
+	CreatedBy     string        `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	Deployment    string        `bson:"deployment,omitempty" json:"deployment,omitempty"`
+	ParentID      string        `bson:"parentId,omitempty" json:"parentId,omitempty"`
+	RootID        string        `bson:"rootId,omitempty" json:"rootId,omitempty"`
+	BatchID       string        `bson:"batchId,omitempty" json:"batchId,omitempty"`
+	Attempt       int           `bson:"attempt,omitempty" json:"attempt,omitempty"`
+	Progress      int           `bson:"progress,omitempty" json:"progress,omitempty"`
 	Status        JobStatus     `bson:"status" json:"status"`
 	CreatedAt     time.Time     `bson:"createdAt" json:"createdAt"`
 	UpdatedAt     time.Time     `bson:"updatedAt" json:"updatedAt"`
 	Input         bson.M        `bson:"input" json:"input"`
 	ScrapeResult  bson.ObjectID `bson:"scrapeResult" json:"scrapeResult"`
 	MatchResults  []MatchResult `bson:"matchResults" json:"matchResults"`
+	// ExtractedProfile is the profile a match flow extracted from the uploaded document
+	ExtractedProfile bson.M `bson:"extractedProfile,omitempty" json:"extractedProfile,omitempty"`
+	// FileBytes is the base64 document a match flow reads. It can be large, so it is sent to the flow as the
+	// file_bytes parameter but kept out of Input and out of responses.
+	FileBytes string `bson:"fileBytes,omitempty" json:"-"`
+	// Children counts a batch's children by status, as of when the batch was last read
+	Children *BatchCounts `bson:"children,omitempty" json:"children,omitempty"`
 	Logs          []JobLog      `bson:"logs" json:"logs"`
 }
 
+// BatchCounts counts the children of a batch by status. A retried child only counts as its latest attempt.
+type BatchCounts struct {
+	Total      int `bson:"total" json:"total"`
+	Pending    int `bson:"pending" json:"pending"`
+	Processing int `bson:"processing" json:"processing"`
+	Completed  int `bson:"completed" json:"completed"`
+	Failed     int `bson:"failed" json:"failed"`
+	Cancelled  int `bson:"cancelled" json:"cancelled"`
+}
+
 type JobLog struct {
 	Message   string        `bson:"message" json:"message"`
 	Timestamp time.Time     `bson:"timestamp" json:"timestamp"`
@@ -42,15 +110,205 @@ type JobLog struct {
 type MatchResult struct {
 	ID         bson.ObjectID `bson:"_id,omitempty" json:"id" swaggerignore:"true"`
 	ConfidenceScore      float64       `bson:"confidenceScore" json:"confidenceScore"`
+	Review          *MatchReview  `bson:"review,omitempty" json:"review,omitempty"`
+}
+
+type MatchDecision string
+
+const (
+	MatchAccepted MatchDecision = "accepted"
+	MatchRejected MatchDecision = "rejected"
+)
+
+// MatchReview is a reviewer's decision on one match candidate, decisions are final
+type MatchReview struct {
+	Decision   MatchDecision `bson:"decision" json:"decision"`
+	Fields     []string      `bson:"fields,omitempty" json:"fields,omitempty"` // extracted fields applied to the candidate
+	Reviewer   string        `bson:"reviewer" json:"reviewer"`
+	ReviewedAt time.Time     `bson:"reviewedAt" json:"reviewedAt"`
+}
+
+type ReviewMatchReq struct {
+	Decision MatchDecision `json:"decision"`
+	// Fields are paths in the extracted profile, e.g. "profile.age", to copy onto the candidate when accepting
+	Fields []string `json:"fields"`
+}
+
+type GetPendingMatchesQuery struct {
+	Page     int `form:"page"`
+	PageSize int `form:"pageSize"`
+}
+
+// MatchCandidate puts a candidate's current profile next to its match result
+type MatchCandidate struct {
+	MatchResult
+	Data bson.D `json:"data"`
+}
+
+// PendingMatch is a completed match job with at least one candidate still awaiting review
+type PendingMatch struct {
+	JobID            string           `json:"jobId"`
+	ClientID         string           `json:"clientId"`
+	FileName         string           `json:"fileName"`
+	CreatedBy        string           `json:"createdBy,omitempty"`
+	CreatedAt        time.Time        `json:"createdAt"`
+	ExtractedProfile bson.M           `json:"extractedProfile"`
+	Candidates       []MatchCandidate `json:"candidates"`
+}
+
+type GetPendingMatchesResponse struct {
+	Total   int            `json:"total"`
+	Matches []PendingMatch `json:"matches"`
+}
+
+type GetMatchStatsQuery struct {
+	Reviewer string    `form:"reviewer"`
+	From     time.Time `form:"from"`
+	To       time.Time `form:"to"`
+}
+
+// MatchStats counts reviewed candidates, accuracy is the share reviewers accepted
+type MatchStats struct {
+	Reviewer      string  `bson:"_id" json:"reviewer,omitempty"`
+	Reviewed      int     `bson:"reviewed" json:"reviewed"`
+	Accepted      int     `bson:"accepted" json:"accepted"`
+	Accuracy      float64 `bson:"-" json:"accuracy"`
+	AcceptedScore float64 `bson:"acceptedScore" json:"acceptedScore"` // mean confidence of accepted candidates
+	RejectedScore float64 `bson:"rejectedScore" json:"rejectedScore"` // mean confidence of rejected candidates
+}
+
+type GetMatchStatsResponse struct {
+	MatchStats
+	Reviewers []MatchStats `json:"reviewers"`
 }
 
 type GetJobsQuery struct {
-	Status   JobStatus `bson:"status" json:"status" form:"status"`
-	Page     int       `bson:"page" json:"page" form:"page"`
-	PageSize int       `bson:"pageSize" json:"pageSize" form:"pageSize"`
+	Status      JobStatus `bson:"status" json:"status" form:"status"`
+	Type        JobType   `bson:"type" json:"type" form:"type"`
+	ClientID    string    `bson:"clientId" json:"clientId" form:"clientId"`
+	CreatedBy   string    `bson:"createdBy" json:"createdBy" form:"createdBy"` // "me" for the current user
+	BatchID     string    `bson:"batchId" json:"batchId" form:"batchId"`
+	CreatedFrom time.Time `bson:"createdFrom" json:"createdFrom" form:"createdFrom"`
+	CreatedTo   time.Time `bson:"createdTo" json:"createdTo" form:"createdTo"`
+	UpdatedFrom time.Time `bson:"updatedFrom" json:"updatedFrom" form:"updatedFrom"`
+	UpdatedTo   time.Time `bson:"updatedTo" json:"updatedTo" form:"updatedTo"`
+	SortBy      string    `bson:"sortBy" json:"sortBy" form:"sortBy"` // one of JobSortFields, defaults to updatedAt
+	Sort        string    `bson:"sort" json:"sort" form:"sort"`       // "asc" or "desc", defaults to newest first
+	Page        int       `bson:"page" json:"page" form:"page"`
+	PageSize    int       `bson:"pageSize" json:"pageSize" form:"pageSize"`
 }
 
+// JobSortFields are the fields jobs can be sorted by
+var JobSortFields = []string{"createdAt", "updatedAt", "status", "type"}
+
 type GetJobsResponse struct {
 	Total int   `bson:"total" json:"total"`
 	Jobs  []Job `bson:"jobs" json:"jobs"`
 }
+
+// GetJobStatsQuery selects the jobs created in a window, defaulting to the last 30 days
+type GetJobStatsQuery struct {
+	Type JobType   `form:"type"`
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+}
+
+// JobStats summarises the jobs created in a window. Durations run from creation to completion and only count
+// completed jobs, leaving out batches, whose last update can come long after their children finished. SuccessRate is the share of finished jobs, completed or failed, that completed.
+type JobStats struct {
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Total       int               `json:"total"`
+	ByStatus    map[JobStatus]int `json:"byStatus"`
+	ByType      map[JobType]int   `json:"byType"`
+	SuccessRate float64           `json:"successRate"`
+	Duration    JobDurationStats  `json:"duration"`
+	Failures    []JobFailureCount `json:"failures"`
+	Throughput  []JobThroughput   `json:"throughput"`
+}
+
+type JobDurationStats struct {
+	Count      int     `bson:"count" json:"count"`
+	P50Seconds float64 `bson:"p50" json:"p50Seconds"`
+	P95Seconds float64 `bson:"p95" json:"p95Seconds"`
+}
+
+// JobFailureCount counts failed jobs by the last message in their logs
+type JobFailureCount struct {
+	Message string `bson:"_id" json:"message"`
+	Count   int    `bson:"count" json:"count"`
+}
+
+// JobThroughput counts the jobs created on one UTC day and how many of them have completed or failed
+type JobThroughput struct {
+	Date      string `bson:"_id" json:"date"` // YYYY-MM-DD
+	Created   int    `bson:"created" json:"created"`
+	Completed int    `bson:"completed" json:"completed"`
+	Failed    int    `bson:"failed" json:"failed"`
+}
+
+// BatchChildError is a child of a batch that a batch-wide action could not be applied to
+type BatchChildError struct {
+	JobID   string `json:"jobId"`
+	Message string `json:"message"`
+}
+
+// BatchActionResponse is the batch after cancelling or retrying its children, with the jobs cancelled or started
+type BatchActionResponse struct {
+	Batch  *Job              `json:"batch"`
+	Jobs   []Job             `json:"jobs"`
+	Errors []BatchChildError `json:"errors"`
+}
+
+// JobAttempt summarises one job in a retry chain
+type JobAttempt struct {
+	ID        string    `json:"id"`
+	Attempt   int       `json:"attempt"`
+	Status    JobStatus `json:"status"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type GetJobResponse struct {
+	Job
+	RetryChain []JobAttempt `json:"retryChain"`
+}
+
+// JobCallbackReq is sent by the Prefect flows to report on a job they are running
+type JobCallbackReq struct {
+	Status       JobStatus     `json:"status,omitempty"`
+	Progress     *int          `json:"progress,omitempty"`
+	Message      string        `json:"message,omitempty"`
+	Logs         []string      `json:"logs,omitempty"`
+	ScrapeResult string        `json:"scrapeResult,omitempty"`
+	MatchResults []MatchResult `json:"matchResults,omitempty"`
+	ExtractedProfile bson.M    `json:"extractedProfile,omitempty"`
+}
+
+// JobUpdate is a validated set of changes to apply to a job
+type JobUpdate struct {
+	Status       JobStatus
+	Progress     *int
+	ScrapeResult *bson.ObjectID
+	MatchResults []MatchResult
+	ExtractedProfile bson.M
+	Logs         []JobLog
+	UpdatedAt    time.Time
+}
+
+type JobEventType string
+
+const (
+	JobEventStatus JobEventType = "status"
+	JobEventLog    JobEventType = "log"
+)
+
+// JobEvent is one server-sent event on a job's event stream.
+// ID is "<logs delivered>-<status delivered>", so a client reconnecting with Last-Event-ID resumes where it stopped.
+type JobEvent struct {
+	ID       string       `json:"-"`
+	Type     JobEventType `json:"-"`
+	Status   JobStatus    `json:"status,omitempty"`
+	Progress int          `json:"progress,omitempty"`
+	Log      *JobLog      `json:"log,omitempty"`
+}
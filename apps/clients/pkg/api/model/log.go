@@ -13,6 +13,16 @@ type Log struct {
 	Operation Operation     `bson:"operation" json:"operation"`
 	Details   string        `bson:"details" json:"details,omitempty"`
 	Timestamp time.Time     `bson:"timestamp" json:"timestamp"`
+	Changes   []Change      `bson:"changes,omitempty" json:"changes,omitempty"`
+
+	// Seq, PrevHash and Hash chain the log to the one before it, see ChainHash. Logs written before the chain have none.
+	Seq      int64  `bson:"seq,omitempty" json:"seq,omitempty"`
+	PrevHash string `bson:"prevHash,omitempty" json:"prevHash,omitempty"`
+	Hash     string `bson:"hash,omitempty" json:"hash,omitempty"`
+
+	// ArchivedIn names the archive holding the log once it has been purged. Only the sequence number, hashes,
+	// operation and timestamp of a purged log are kept, so the chain can still be verified.
+	ArchivedIn string `bson:"archivedIn,omitempty" json:"archivedIn,omitempty"`
 }
 
 type Operation string
@@ -25,12 +35,40 @@ const (
 	OperationScrape          Operation = "scrape"
 	OperationMatch           Operation = "match"
 	OperationCreateAndScrape Operation = "create & scrape"
+	OperationImport          Operation = "import"
+	OperationExport          Operation = "export"
+	OperationAddArticle      Operation = "add article"
+	OperationLinkArticle     Operation = "link article"
+	OperationUnlinkArticle   Operation = "unlink article"
+	OperationReviewArticle   Operation = "review article"
+	OperationJobStatus       Operation = "job status"
+	OperationExportLogs      Operation = "export logs"
+	OperationArchiveLogs     Operation = "archive logs"
+	OperationLegalHold       Operation = "legal hold"
+)
+
+// ChangeSource is what changed a client profile, recorded on both audit log changes and timeline points
+type ChangeSource string
+
+const (
+	ChangeSourceManual ChangeSource = "manual"
+	ChangeSourceScrape ChangeSource = "scrape"
+	ChangeSourceMatch  ChangeSource = "match" // fields applied from a reviewed match
 )
 
+// Change is one field of a client profile changed by the logged operation, Path is relative to Client.Data
+type Change struct {
+	Path   string       `bson:"path" json:"path"`
+	Old    any          `bson:"old" json:"old"`
+	New    any          `bson:"new" json:"new"`
+	Source ChangeSource `bson:"source" json:"source"`
+}
+
 type GetLogsQuery struct {
 	ClientID  string    `bson:"clientId" json:"clientId" form:"clientId"`
 	Operation Operation `bson:"operation" json:"operation" form:"operation"`
 	Actor     string    `bson:"actor" json:"actor" form:"actor"` // username of the actor
+	Path      string    `bson:"path" json:"path" form:"path"`    // changed path, matching the path and any path under it
 	From      time.Time `bson:"from" json:"from" form:"from"`
 	To        time.Time `bson:"to" json:"to" form:"to"`
 	Page      int       `bson:"page" json:"page" form:"page"`
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// ChainHash is the hex SHA-256 of the log's content, its sequence number and the hash of the log before it,
+// so altering, removing or reordering a log breaks the hashes of every log after it
+func (l *Log) ChainHash() string {
+	// the timestamp is hashed as Mongo stores it, to the millisecond
+	content, _ := json.Marshal(struct {
+		Seq       int64          `json:"seq"`
+		PrevHash  string         `json:"prevHash"`
+		ClientID  string         `json:"clientId"`
+		Actor     string         `json:"actor"`
+		Operation Operation      `json:"operation"`
+		Details   string         `json:"details"`
+		Timestamp string         `json:"timestamp"`
+		Changes   []hashedChange `json:"changes,omitempty"`
+	}{l.Seq, l.PrevHash, l.ClientID, l.Actor, l.Operation, l.Details, l.Timestamp.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano), hashedChanges(l.Changes)})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashedChange is a change with its values in canonical JSON, since values read back from Mongo have
+// their own types and key order
+type hashedChange struct {
+	Path   string          `json:"path"`
+	Old    json.RawMessage `json:"old"`
+	New    json.RawMessage `json:"new"`
+	Source ChangeSource    `json:"source"`
+}
+
+func hashedChanges(changes []Change) []hashedChange {
+	if len(changes) == 0 {
+		return nil
+	}
+	hashed := make([]hashedChange, len(changes))
+	for i, c := range changes {
+		hashed[i] = hashedChange{Path: c.Path, Old: canonicalJSON(c.Old), New: canonicalJSON(c.New), Source: c.Source}
+	}
+	return hashed
+}
+
+// canonicalJSON encodes v with object keys sorted, values that can't be encoded hash as null
+func canonicalJSON(v any) json.RawMessage {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return json.RawMessage("null")
+	}
+	out, _ := json.Marshal(generic)
+	return out
+}
+
+// LogAnchor is the head of the log chain at a point in time, kept in a separate store so rewriting
+// the chain up to it can't go unnoticed
+type LogAnchor struct {
+	Seq        int64     `bson:"_id" json:"seq"`
+	Hash       string    `bson:"hash" json:"hash"`
+	AnchoredAt time.Time `bson:"anchoredAt" json:"anchoredAt"`
+}
+
+// LogCheckpoint is the last log purged from the start of the log chain. The logs up to it only remain in archives,
+// so verification picks the chain up from it.
+type LogCheckpoint struct {
+	Seq      int64     `bson:"_id" json:"seq"`
+	Hash     string    `bson:"hash" json:"hash"`
+	PurgedAt time.Time `bson:"purgedAt" json:"purgedAt"`
+}
+
+type ChainBreakReason string
+
+const (
+	ChainBreakGap            ChainBreakReason = "missing entry"      // no log with the expected sequence number
+	ChainBreakHash           ChainBreakReason = "hash mismatch"      // the log's content no longer matches its hash
+	ChainBreakPrevHash       ChainBreakReason = "prev hash mismatch" // the log doesn't point at the log before it
+	ChainBreakAnchorMismatch ChainBreakReason = "anchor mismatch"    // the log differs from the anchored head
+	ChainBreakTruncated      ChainBreakReason = "truncated"          // logs up to the anchored head are missing
+	ChainBreakArchive        ChainBreakReason = "archive mismatch"   // the archive the log was purged to doesn't hold it
+)
+
+// ChainBreak is the first place the log chain fails to verify
+type ChainBreak struct {
+	Seq    int64            `json:"seq"`
+	LogID  string           `json:"logId,omitempty"`
+	Reason ChainBreakReason `json:"reason"`
+}
+
+// ChainVerification reports on a walk of the log chain from the checkpoint, or the first log if nothing was purged
+// yet. Checked counts the logs verified before any break, of which Archived had been purged to an archive.
+type ChainVerification struct {
+	Valid      bool           `json:"valid"`
+	Checked    int64          `json:"checked"`
+	Archived   int64          `json:"archived"`
+	HeadSeq    int64          `json:"headSeq"`
+	HeadHash   string         `json:"headHash,omitempty"`
+	Anchor     *LogAnchor     `json:"anchor,omitempty"`
+	Checkpoint *LogCheckpoint `json:"checkpoint,omitempty"`
+	Break      *ChainBreak    `json:"break,omitempty"`
+	VerifiedAt time.Time      `json:"verifiedAt"`
+}
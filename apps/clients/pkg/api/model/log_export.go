@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+func (f ExportFormat) Valid() bool {
+	return f == ExportFormatCSV || f == ExportFormatNDJSON
+}
+
+// ExportLogsQuery takes the same filters as GetLogsQuery, paging is ignored since every matching log is exported
+type ExportLogsQuery struct {
+	GetLogsQuery
+	Format ExportFormat `form:"format"`
+}
+
+// LogExportFilters records the filters an export was made with
+type LogExportFilters struct {
+	ClientID  string     `bson:"clientId,omitempty" json:"clientId,omitempty"`
+	Operation Operation  `bson:"operation,omitempty" json:"operation,omitempty"`
+	Actor     string     `bson:"actor,omitempty" json:"actor,omitempty"`
+	Path      string     `bson:"path,omitempty" json:"path,omitempty"`
+	From      *time.Time `bson:"from,omitempty" json:"from,omitempty"`
+	To        *time.Time `bson:"to,omitempty" json:"to,omitempty"`
+}
+
+// LogExport is the manifest of an audit log export. SHA256 is the hex digest of the exported file and Rows the number
+// of logs in it. Signature is the hex HMAC-SHA256 of the manifest's JSON encoding without the signature.
+type LogExport struct {
+	ID        bson.ObjectID    `bson:"_id" json:"id"`
+	Format    ExportFormat     `bson:"format" json:"format"`
+	Filters   LogExportFilters `bson:"filters" json:"filters"`
+	Rows      int              `bson:"rows" json:"rows"`
+	SHA256    string           `bson:"sha256" json:"sha256"`
+	CreatedBy string           `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time        `bson:"createdAt" json:"createdAt"`
+	Signature string           `bson:"signature" json:"signature,omitempty"`
+}
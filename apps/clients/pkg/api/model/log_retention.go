@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// ExpiredLogsFilter selects the logs older than Before of the given operations, or with OtherOperations, of every
+// operation but those given. Logs of the held clients are never selected.
+type ExpiredLogsFilter struct {
+	Operations      []Operation
+	OtherOperations bool
+	Before          time.Time
+	HeldClientIDs   []string
+}
+
+// LogArchive is a file of archived logs, SHA256 is the hex digest of the compressed file
+type LogArchive struct {
+	Name   string `json:"name"`
+	Logs   int    `json:"logs"`
+	SHA256 string `json:"sha256"`
+}
+
+// LogPurgeResult is what a retention run archived and purged
+type LogPurgeResult struct {
+	Archived   int            `json:"archived"`
+	Archives   []LogArchive   `json:"archives"`
+	Checkpoint *LogCheckpoint `json:"checkpoint,omitempty"` // where the log chain now starts, if archived logs were deleted
+}
@@ -0,0 +1,27 @@
+package model
+
+type NotificationType string
+type Priority string
+
+const (
+	NotificationTypeJob    NotificationType = "job"
+	NotificationTypeClient NotificationType = "client"
+
+	PriorityHigh   Priority = "high"
+	PriorityMedium Priority = "medium"
+	PriorityLow    Priority = "low"
+)
+
+// Notification is the message consumed by the notification service from the "notifications" queue
+type Notification struct {
+	NotificationType NotificationType `json:"notificationType"`
+	Title            string           `json:"title,omitempty"`
+	Source           string           `json:"source,omitempty"`
+	Username         string           `json:"username,omitempty"`
+	JobID            string           `json:"jobId,omitempty"`
+	Status           JobStatus        `json:"status,omitempty"`
+	Type             JobType          `json:"type,omitempty"`
+	ClientID         string           `json:"clientId,omitempty"`
+	ClientName       []string         `json:"clientName,omitempty"`
+	Priority         Priority         `json:"priority,omitempty"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// OutboxEntry is a workflow run waiting to be triggered for a job. It is written in the same transaction
+// as the job, so a job is never left without a trigger and a trigger never fires for a job that wasn't saved.
+type OutboxEntry struct {
+	ID            bson.ObjectID `bson:"_id,omitempty"`
+	JobID         string        `bson:"jobId"`
+	JobType       JobType       `bson:"jobType"`
+	Deployment    string        `bson:"deployment"`
+	Params        bson.M        `bson:"params"`
+	Status        OutboxStatus  `bson:"status"`
+	Attempts      int           `bson:"attempts"`
+	NextAttemptAt time.Time     `bson:"nextAttemptAt"`
+	LastError     string        `bson:"lastError,omitempty"`
+	FlowRunID     string        `bson:"flowRunId,omitempty"`
+	CreatedAt     time.Time     `bson:"createdAt"`
+	UpdatedAt     time.Time     `bson:"updatedAt"`
+}
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	OutboxStatusFailed    OutboxStatus = "failed"
+	OutboxStatusCancelled OutboxStatus = "cancelled" // the job ended before its flow run was started
+)
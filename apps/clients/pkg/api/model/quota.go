@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// QuotaUsage is how much of one quota is used, a Limit of 0 means there is none
+type QuotaUsage struct {
+	Used     int        `json:"used"`
+	Limit    int        `json:"limit"`
+	ResetsAt *time.Time `json:"resetsAt,omitempty"`
+}
+
+// UsageResponse shows the caller where they stand against the job quotas
+type UsageResponse struct {
+	Username     string     `json:"username"`
+	Active       QuotaUsage `json:"active"`       // the caller's pending and processing jobs
+	Daily        QuotaUsage `json:"daily"`        // jobs the caller created today (UTC)
+	GlobalActive QuotaUsage `json:"globalActive"` // pending and processing jobs of all users
+}
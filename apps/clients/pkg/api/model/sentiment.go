@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+type SentimentTrend string
+
+const (
+	SentimentTrendImproving    SentimentTrend = "improving"
+	SentimentTrendStable       SentimentTrend = "stable"
+	SentimentTrendDeclining    SentimentTrend = "declining"
+	SentimentTrendInsufficient SentimentTrend = "insufficient data"
+)
+
+// SentimentBucket holds the articles of one label published on one day.
+// ScoreSum is signed: positive scores count up, negative scores count down and neutral is zero.
+type SentimentBucket struct {
+	Day      time.Time `bson:"day" json:"day"`
+	Label    string    `bson:"label" json:"label"`
+	Count    int       `bson:"count" json:"count"`
+	ScoreSum float64   `bson:"scoreSum" json:"scoreSum"`
+}
+
+type GetSentimentQuery struct {
+	Days int `form:"days"`
+}
+
+type SentimentDay struct {
+	Day          time.Time      `json:"day"`
+	Counts       map[string]int `json:"counts"`
+	AverageScore float64        `json:"averageScore"`
+}
+
+type ClientSentimentResponse struct {
+	ClientID     string         `json:"clientId"`
+	Days         int            `json:"days"`
+	From         time.Time      `json:"from"`
+	To           time.Time      `json:"to"`
+	Total        int            `json:"total"`
+	Counts       map[string]int `json:"counts"`
+	AverageScore float64        `json:"averageScore"`
+	Trend        SentimentTrend `json:"trend"`
+	TrendDelta   float64        `json:"trendDelta"`
+	Daily        []SentimentDay `json:"daily"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TimelineFields maps the tracked timeline fields to their path inside Client.Data
+var TimelineFields = map[string]string{
+	"netWorth":    "profile.netWorth",
+	"residence":   "profile.currentResidence",
+	"occupations": "profile.occupations",
+}
+
+type TimelinePoint struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id" swaggerignore:"true"`
+	ClientID  string        `bson:"clientId" json:"clientId"`
+	Field     string        `bson:"field" json:"field"`
+	Value     any           `bson:"value" json:"value"`
+	Source    ChangeSource  `bson:"source" json:"source"`
+	Actor     string        `bson:"actor" json:"actor"`
+	Timestamp time.Time     `bson:"timestamp" json:"timestamp"`
+}
+
+type GetTimelineQuery struct {
+	Source   ChangeSource `form:"source"`
+	From     time.Time    `form:"from"`
+	To       time.Time    `form:"to"`
+	Page     int          `form:"page"`
+	PageSize int          `form:"pageSize"`
+}
+
+type GetTimelineResponse struct {
+	ClientID string          `json:"clientId"`
+	Field    string          `json:"field"`
+	Total    int             `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"pageSize"`
+	Points   []TimelinePoint `json:"points"`
+}
@@ -0,0 +1,29 @@
+package model
+
+type ImportMode string
+
+const (
+	ImportModeID   ImportMode = "id"
+	ImportModeName ImportMode = "name"
+)
+
+type ImportClientsQuery struct {
+	Mode   ImportMode `form:"mode"`
+	DryRun bool       `form:"dryRun"`
+}
+
+// ImportResult summarises a bulk import. In a dry run, Valid counts the lines that would have been written.
+type ImportResult struct {
+	DryRun   bool          `json:"dryRun"`
+	Total    int           `json:"total"`
+	Valid    int           `json:"valid"`
+	Inserted int           `json:"inserted"`
+	Updated  int           `json:"updated"`
+	Failed   int           `json:"failed"`
+	Errors   []ImportError `json:"errors,omitempty"`
+}
+
+type ImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
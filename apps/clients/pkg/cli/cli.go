@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+
+	"github.com/owjoel/client-factpack/apps/clients/config"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+const importUsage = "import [-mode id|name] [-dry-run] <file.ndjson|->"
+
+var commands = map[string]command{
+	"import":      {usage: importUsage, run: runImport},
+	"export":      {usage: "export [-o file.ndjson]", run: runExport},
+	"verify-logs": {usage: "verify-logs [-anchor]", run: runVerifyLogs},
+}
+
+// Run executes a maintenance subcommand and returns the process exit code
+func Run(args []string) int {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		printUsage()
+		return 2
+	}
+
+	if err := cmd.run(cliContext(), args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func runImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := fs.String("mode", string(model.ImportModeID), "upsert by 'id' or 'name'")
+	dryRun := fs.Bool("dry-run", false, "validate the file without writing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: clients %s", importUsage)
+	}
+
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	transferService := newTransferService()
+	result, err := transferService.ImportClients(ctx, in, &model.ImportClientsQuery{Mode: model.ImportMode(*mode), DryRun: *dryRun})
+	if result != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(result)
+	}
+	return err
+}
+
+func runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file, '-' for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	count, err := newTransferService().ExportClients(ctx, out)
+	fmt.Fprintf(os.Stderr, "exported %d clients\n", count)
+	return err
+}
+
+// runVerifyLogs walks the audit log chain and fails if it is broken, optionally anchoring the head once it verifies
+func runVerifyLogs(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("verify-logs", flag.ContinueOnError)
+	anchor := fs.Bool("anchor", false, "anchor the head of the chain if it verifies")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mongoDb := repository.InitMongo()
+	chainService := service.NewLogChainService(repository.NewMongoLogRepository(mongoDb), repository.InitLogAnchorStore(), repository.NewFileArchiveStore(config.AuditArchiveDir))
+	result, err := chainService.VerifyChain(ctx)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(result)
+	if !result.Valid {
+		return fmt.Errorf("log chain broken at seq %d: %s", result.Break.Seq, result.Break.Reason)
+	}
+
+	if *anchor {
+		anchored, err := chainService.AnchorHead(ctx)
+		if err != nil {
+			return err
+		}
+		if anchored != nil {
+			fmt.Fprintf(os.Stderr, "anchored head at seq %d\n", anchored.Seq)
+		}
+	}
+	return nil
+}
+
+func newTransferService() *service.TransferService {
+	mongoDb := repository.InitMongo()
+	logService := service.NewLogService(repository.NewMongoLogRepository(mongoDb))
+	return service.NewTransferService(repository.NewMongoClientRepository(mongoDb), logService)
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// cliContext attributes audit logs written by the CLI to the local OS user
+func cliContext() context.Context {
+	username := "cli"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		username = "cli:" + u.Username
+	}
+	return context.WithValue(context.Background(), "username", username)
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: clients <command> [flags]")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  clients %s\n", cmd.usage)
+	}
+}
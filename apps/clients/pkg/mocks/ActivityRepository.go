@@ -0,0 +1,94 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ActivityRepository is an autogenerated mock type for the ActivityRepository type
+type ActivityRepository struct {
+	mock.Mock
+}
+
+// CreateNote provides a mock function with given fields: ctx, note
+func (_m *ActivityRepository) CreateNote(ctx context.Context, note *model.Note) (string, error) {
+	ret := _m.Called(ctx, note)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateNote")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Note) (string, error)); ok {
+		return rf(ctx, note)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Note) string); ok {
+		r0 = rf(ctx, note)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Note) error); ok {
+		r1 = rf(ctx, note)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFeed provides a mock function with given fields: ctx, clientID, query
+func (_m *ActivityRepository) GetFeed(ctx context.Context, clientID string, query *model.GetActivityQuery) ([]model.ActivityItem, int, error) {
+	ret := _m.Called(ctx, clientID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFeed")
+	}
+
+	var r0 []model.ActivityItem
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetActivityQuery) ([]model.ActivityItem, int, error)); ok {
+		return rf(ctx, clientID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetActivityQuery) []model.ActivityItem); ok {
+		r0 = rf(ctx, clientID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ActivityItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.GetActivityQuery) int); ok {
+		r1 = rf(ctx, clientID, query)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *model.GetActivityQuery) error); ok {
+		r2 = rf(ctx, clientID, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewActivityRepository creates a new instance of ActivityRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewActivityRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ActivityRepository {
+	mock := &ActivityRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ActivityServiceInterface is an autogenerated mock type for the ActivityServiceInterface type
+type ActivityServiceInterface struct {
+	mock.Mock
+}
+
+// AddNote provides a mock function with given fields: ctx, clientID, req
+func (_m *ActivityServiceInterface) AddNote(ctx context.Context, clientID string, req *model.CreateNoteReq) (*model.Note, error) {
+	ret := _m.Called(ctx, clientID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddNote")
+	}
+
+	var r0 *model.Note
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.CreateNoteReq) (*model.Note, error)); ok {
+		return rf(ctx, clientID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.CreateNoteReq) *model.Note); ok {
+		r0 = rf(ctx, clientID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Note)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.CreateNoteReq) error); ok {
+		r1 = rf(ctx, clientID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetActivity provides a mock function with given fields: ctx, clientID, query
+func (_m *ActivityServiceInterface) GetActivity(ctx context.Context, clientID string, query *model.GetActivityQuery) (*model.GetActivityResponse, error) {
+	ret := _m.Called(ctx, clientID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActivity")
+	}
+
+	var r0 *model.GetActivityResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetActivityQuery) (*model.GetActivityResponse, error)); ok {
+		return rf(ctx, clientID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetActivityQuery) *model.GetActivityResponse); ok {
+		r0 = rf(ctx, clientID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GetActivityResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.GetActivityQuery) error); ok {
+		r1 = rf(ctx, clientID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewActivityServiceInterface creates a new instance of ActivityServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewActivityServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ActivityServiceInterface {
+	mock := &ActivityServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,8 +5,13 @@ package mocks
 import (
 	context "context"
 
-	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	bson "go.mongodb.org/mongo-driver/v2/bson"
+
 	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+
+	time "time"
 )
 
 // ArticleRepository is an autogenerated mock type for the ArticleRepository type
@@ -14,6 +19,64 @@ type ArticleRepository struct {
 	mock.Mock
 }
 
+// CountByIDs provides a mock function with given fields: ctx, ids, query
+func (_m *ArticleRepository) CountByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) (int, error) {
+	ret := _m.Called(ctx, ids, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByIDs")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) (int, error)); ok {
+		return rf(ctx, ids, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) int); ok {
+		r0 = rf(ctx, ids, query)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) error); ok {
+		r1 = rf(ctx, ids, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountSourcesByIDs provides a mock function with given fields: ctx, ids, query
+func (_m *ArticleRepository) CountSourcesByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) (map[string]int, error) {
+	ret := _m.Called(ctx, ids, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountSourcesByIDs")
+	}
+
+	var r0 map[string]int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) (map[string]int, error)); ok {
+		return rf(ctx, ids, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) map[string]int); ok {
+		r0 = rf(ctx, ids, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) error); ok {
+		r1 = rf(ctx, ids, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAll provides a mock function with given fields: ctx, query
 func (_m *ArticleRepository) GetAll(ctx context.Context, query *model.GetArticlesReq) ([]model.Article, error) {
 	ret := _m.Called(ctx, query)
@@ -44,6 +107,133 @@ func (_m *ArticleRepository) GetAll(ctx context.Context, query *model.GetArticle
 	return r0, r1
 }
 
+// GetByIDs provides a mock function with given fields: ctx, ids, query
+func (_m *ArticleRepository) GetByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) ([]model.Article, error) {
+	ret := _m.Called(ctx, ids, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []model.Article
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) ([]model.Article, error)); ok {
+		return rf(ctx, ids, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) []model.Article); ok {
+		r0 = rf(ctx, ids, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Article)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []bson.ObjectID, *model.GetClientArticlesQuery) error); ok {
+		r1 = rf(ctx, ids, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOne provides a mock function with given fields: ctx, articleID
+func (_m *ArticleRepository) GetOne(ctx context.Context, articleID string) (*model.Article, error) {
+	ret := _m.Called(ctx, articleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOne")
+	}
+
+	var r0 *model.Article
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Article, error)); ok {
+		return rf(ctx, articleID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Article); ok {
+		r0 = rf(ctx, articleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Article)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, articleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSentimentBuckets provides a mock function with given fields: ctx, ids, since
+func (_m *ArticleRepository) GetSentimentBuckets(ctx context.Context, ids []bson.ObjectID, since time.Time) ([]model.SentimentBucket, error) {
+	ret := _m.Called(ctx, ids, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSentimentBuckets")
+	}
+
+	var r0 []model.SentimentBucket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, time.Time) ([]model.SentimentBucket, error)); ok {
+		return rf(ctx, ids, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, time.Time) []model.SentimentBucket); ok {
+		r0 = rf(ctx, ids, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SentimentBucket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []bson.ObjectID, time.Time) error); ok {
+		r1 = rf(ctx, ids, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpsertByURL provides a mock function with given fields: ctx, a
+func (_m *ArticleRepository) UpsertByURL(ctx context.Context, a *model.Article) (bson.ObjectID, bool, error) {
+	ret := _m.Called(ctx, a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertByURL")
+	}
+
+	var r0 bson.ObjectID
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Article) (bson.ObjectID, bool, error)); ok {
+		return rf(ctx, a)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Article) bson.ObjectID); ok {
+		r0 = rf(ctx, a)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(bson.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Article) bool); ok {
+		r1 = rf(ctx, a)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *model.Article) error); ok {
+		r2 = rf(ctx, a)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // NewArticleRepository creates a new instance of ArticleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewArticleRepository(t interface {
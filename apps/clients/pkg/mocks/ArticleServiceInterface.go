@@ -14,6 +14,36 @@ type ArticleServiceInterface struct {
 	mock.Mock
 }
 
+// CreateArticle provides a mock function with given fields: ctx, req
+func (_m *ArticleServiceInterface) CreateArticle(ctx context.Context, req *model.CreateArticleReq) (*model.CreateArticleRes, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateArticle")
+	}
+
+	var r0 *model.CreateArticleRes
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CreateArticleReq) (*model.CreateArticleRes, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CreateArticleReq) *model.CreateArticleRes); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CreateArticleRes)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.CreateArticleReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllArticles provides a mock function with given fields: ctx, query
 func (_m *ArticleServiceInterface) GetAllArticles(ctx context.Context, query *model.GetArticlesReq) ([]model.Article, error) {
 	ret := _m.Called(ctx, query)
@@ -44,6 +74,162 @@ func (_m *ArticleServiceInterface) GetAllArticles(ctx context.Context, query *mo
 	return r0, r1
 }
 
+// GetClientArticles provides a mock function with given fields: ctx, clientID, query
+func (_m *ArticleServiceInterface) GetClientArticles(ctx context.Context, clientID string, query *model.GetClientArticlesQuery) (*model.GetClientArticlesResponse, error) {
+	ret := _m.Called(ctx, clientID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClientArticles")
+	}
+
+	var r0 *model.GetClientArticlesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetClientArticlesQuery) (*model.GetClientArticlesResponse, error)); ok {
+		return rf(ctx, clientID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetClientArticlesQuery) *model.GetClientArticlesResponse); ok {
+		r0 = rf(ctx, clientID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GetClientArticlesResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.GetClientArticlesQuery) error); ok {
+		r1 = rf(ctx, clientID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetClientSentiment provides a mock function with given fields: ctx, clientID, query
+func (_m *ArticleServiceInterface) GetClientSentiment(ctx context.Context, clientID string, query *model.GetSentimentQuery) (*model.ClientSentimentResponse, error) {
+	ret := _m.Called(ctx, clientID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClientSentiment")
+	}
+
+	var r0 *model.ClientSentimentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetSentimentQuery) (*model.ClientSentimentResponse, error)); ok {
+		return rf(ctx, clientID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetSentimentQuery) *model.ClientSentimentResponse); ok {
+		r0 = rf(ctx, clientID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ClientSentimentResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.GetSentimentQuery) error); ok {
+		r1 = rf(ctx, clientID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFeedbackStats provides a mock function with given fields: ctx, query
+func (_m *ArticleServiceInterface) GetFeedbackStats(ctx context.Context, query *model.GetFeedbackStatsQuery) (*model.GetFeedbackStatsResponse, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFeedbackStats")
+	}
+
+	var r0 *model.GetFeedbackStatsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetFeedbackStatsQuery) (*model.GetFeedbackStatsResponse, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetFeedbackStatsQuery) *model.GetFeedbackStatsResponse); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GetFeedbackStatsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GetFeedbackStatsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LinkArticle provides a mock function with given fields: ctx, clientID, articleID
+func (_m *ArticleServiceInterface) LinkArticle(ctx context.Context, clientID string, articleID string) error {
+	ret := _m.Called(ctx, clientID, articleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkArticle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, articleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReviewArticle provides a mock function with given fields: ctx, clientID, articleID, req
+func (_m *ArticleServiceInterface) ReviewArticle(ctx context.Context, clientID string, articleID string, req *model.ArticleFeedbackReq) (*model.ArticleFeedback, error) {
+	ret := _m.Called(ctx, clientID, articleID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReviewArticle")
+	}
+
+	var r0 *model.ArticleFeedback
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.ArticleFeedbackReq) (*model.ArticleFeedback, error)); ok {
+		return rf(ctx, clientID, articleID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.ArticleFeedbackReq) *model.ArticleFeedback); ok {
+		r0 = rf(ctx, clientID, articleID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ArticleFeedback)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *model.ArticleFeedbackReq) error); ok {
+		r1 = rf(ctx, clientID, articleID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnlinkArticle provides a mock function with given fields: ctx, clientID, articleID
+func (_m *ArticleServiceInterface) UnlinkArticle(ctx context.Context, clientID string, articleID string) error {
+	ret := _m.Called(ctx, clientID, articleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlinkArticle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, articleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewArticleServiceInterface creates a new instance of ArticleServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewArticleServiceInterface(t interface {
@@ -17,6 +17,42 @@ type ClientRepository struct {
 	mock.Mock
 }
 
+// AddArticle provides a mock function with given fields: ctx, clientID, articleID
+func (_m *ClientRepository) AddArticle(ctx context.Context, clientID string, articleID bson.ObjectID) error {
+	ret := _m.Called(ctx, clientID, articleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddArticle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bson.ObjectID) error); ok {
+		r0 = rf(ctx, clientID, articleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddDocument provides a mock function with given fields: ctx, clientID, doc
+func (_m *ClientRepository) AddDocument(ctx context.Context, clientID string, doc *model.ClientDocument) error {
+	ret := _m.Called(ctx, clientID, doc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddDocument")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.ClientDocument) error); ok {
+		r0 = rf(ctx, clientID, doc)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Count provides a mock function with given fields: ctx, query
 func (_m *ClientRepository) Count(ctx context.Context, query *model.GetClientsQuery) (int, error) {
 	ret := _m.Called(ctx, query)
@@ -73,6 +109,24 @@ func (_m *ClientRepository) Create(ctx context.Context, c *model.Client) (string
 	return r0, r1
 }
 
+// ForEach provides a mock function with given fields: ctx, fn
+func (_m *ClientRepository) ForEach(ctx context.Context, fn func(*model.Client) error) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForEach")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(*model.Client) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAll provides a mock function with given fields: ctx, query
 func (_m *ClientRepository) GetAll(ctx context.Context, query *model.GetClientsQuery) ([]model.Client, error) {
 	ret := _m.Called(ctx, query)
@@ -131,6 +185,36 @@ func (_m *ClientRepository) GetClientNameByID(ctx context.Context, clientID stri
 	return r0, r1
 }
 
+// GetHeldClientIDs provides a mock function with given fields: ctx
+func (_m *ClientRepository) GetHeldClientIDs(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHeldClientIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOne provides a mock function with given fields: ctx, clientID
 func (_m *ClientRepository) GetOne(ctx context.Context, clientID string) (*model.Client, error) {
 	ret := _m.Called(ctx, clientID)
@@ -161,6 +245,42 @@ func (_m *ClientRepository) GetOne(ctx context.Context, clientID string) (*model
 	return r0, r1
 }
 
+// RemoveArticle provides a mock function with given fields: ctx, clientID, articleID
+func (_m *ClientRepository) RemoveArticle(ctx context.Context, clientID string, articleID bson.ObjectID) error {
+	ret := _m.Called(ctx, clientID, articleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveArticle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bson.ObjectID) error); ok {
+		r0 = rf(ctx, clientID, articleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetLegalHold provides a mock function with given fields: ctx, clientID, hold
+func (_m *ClientRepository) SetLegalHold(ctx context.Context, clientID string, hold *model.LegalHold) error {
+	ret := _m.Called(ctx, clientID, hold)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLegalHold")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.LegalHold) error); ok {
+		r0 = rf(ctx, clientID, hold)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, clientID, update
 func (_m *ClientRepository) Update(ctx context.Context, clientID string, update bson.D) error {
 	ret := _m.Called(ctx, clientID, update)
@@ -179,6 +299,62 @@ func (_m *ClientRepository) Update(ctx context.Context, clientID string, update
 	return r0
 }
 
+// UpsertByID provides a mock function with given fields: ctx, c
+func (_m *ClientRepository) UpsertByID(ctx context.Context, c *model.Client) (bool, error) {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertByID")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Client) (bool, error)); ok {
+		return rf(ctx, c)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Client) bool); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Client) error); ok {
+		r1 = rf(ctx, c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpsertByName provides a mock function with given fields: ctx, c
+func (_m *ClientRepository) UpsertByName(ctx context.Context, c *model.Client) (bool, error) {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertByName")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Client) (bool, error)); ok {
+		return rf(ctx, c)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Client) bool); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Client) error); ok {
+		r1 = rf(ctx, c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewClientRepository creates a new instance of ClientRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewClientRepository(t interface {
@@ -155,6 +155,54 @@ func (_m *ClientServiceInterface) RescrapeClient(ctx context.Context, clientID s
 	return r0
 }
 
+// RescrapeClients provides a mock function with given fields: ctx, req
+func (_m *ClientServiceInterface) RescrapeClients(ctx context.Context, req *model.RescrapeClientsReq) (*model.Job, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RescrapeClients")
+	}
+
+	var r0 *model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.RescrapeClientsReq) (*model.Job, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.RescrapeClientsReq) *model.Job); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.RescrapeClientsReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetLegalHold provides a mock function with given fields: ctx, clientID, req
+func (_m *ClientServiceInterface) SetLegalHold(ctx context.Context, clientID string, req *model.SetLegalHoldReq) error {
+	ret := _m.Called(ctx, clientID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLegalHold")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.SetLegalHoldReq) error); ok {
+		r0 = rf(ctx, clientID, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateClient provides a mock function with given fields: ctx, clientID, changes
 func (_m *ClientServiceInterface) UpdateClient(ctx context.Context, clientID string, changes []model.SimpleChanges) error {
 	ret := _m.Called(ctx, clientID, changes)
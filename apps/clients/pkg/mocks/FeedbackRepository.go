@@ -0,0 +1,105 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// FeedbackRepository is an autogenerated mock type for the FeedbackRepository type
+type FeedbackRepository struct {
+	mock.Mock
+}
+
+// GetStats provides a mock function with given fields: ctx, query
+func (_m *FeedbackRepository) GetStats(ctx context.Context, query *model.GetFeedbackStatsQuery) ([]model.FeedbackStats, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStats")
+	}
+
+	var r0 []model.FeedbackStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetFeedbackStatsQuery) ([]model.FeedbackStats, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetFeedbackStatsQuery) []model.FeedbackStats); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.FeedbackStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GetFeedbackStatsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsExcluded provides a mock function with given fields: ctx, clientID, canonicalURL
+func (_m *FeedbackRepository) IsExcluded(ctx context.Context, clientID string, canonicalURL string) (bool, error) {
+	ret := _m.Called(ctx, clientID, canonicalURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsExcluded")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, clientID, canonicalURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, clientID, canonicalURL)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, canonicalURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Upsert provides a mock function with given fields: ctx, feedback
+func (_m *FeedbackRepository) Upsert(ctx context.Context, feedback *model.ArticleFeedback) error {
+	ret := _m.Called(ctx, feedback)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArticleFeedback) error); ok {
+		r0 = rf(ctx, feedback)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewFeedbackRepository creates a new instance of FeedbackRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFeedbackRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *FeedbackRepository {
+	mock := &FeedbackRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,102 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IdempotencyServiceInterface is an autogenerated mock type for the IdempotencyServiceInterface type
+type IdempotencyServiceInterface struct {
+	mock.Mock
+}
+
+// Begin provides a mock function with given fields: ctx, key, method, path, requestHash
+func (_m *IdempotencyServiceInterface) Begin(ctx context.Context, key string, method string, path string, requestHash string) (*model.IdempotencyRecord, bool, error) {
+	ret := _m.Called(ctx, key, method, path, requestHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Begin")
+	}
+
+	var r0 *model.IdempotencyRecord
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) (*model.IdempotencyRecord, bool, error)); ok {
+		return rf(ctx, key, method, path, requestHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) *model.IdempotencyRecord); ok {
+		r0 = rf(ctx, key, method, path, requestHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.IdempotencyRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) bool); ok {
+		r1 = rf(ctx, key, method, path, requestHash)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, string) error); ok {
+		r2 = rf(ctx, key, method, path, requestHash)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Complete provides a mock function with given fields: ctx, record, statusCode, contentType, body
+func (_m *IdempotencyServiceInterface) Complete(ctx context.Context, record *model.IdempotencyRecord, statusCode int, contentType string, body []byte) error {
+	ret := _m.Called(ctx, record, statusCode, contentType, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.IdempotencyRecord, int, string, []byte) error); ok {
+		r0 = rf(ctx, record, statusCode, contentType, body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Release provides a mock function with given fields: ctx, record
+func (_m *IdempotencyServiceInterface) Release(ctx context.Context, record *model.IdempotencyRecord) error {
+	ret := _m.Called(ctx, record)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.IdempotencyRecord) error); ok {
+		r0 = rf(ctx, record)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIdempotencyServiceInterface creates a new instance of IdempotencyServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotencyServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IdempotencyServiceInterface {
+	mock := &IdempotencyServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// JobCallbackHandler is an autogenerated mock type for the JobCallbackHandler type
+type JobCallbackHandler struct {
+	mock.Mock
+}
+
+// HandleCallback provides a mock function with given fields: ctx, jobID, req
+func (_m *JobCallbackHandler) HandleCallback(ctx context.Context, jobID string, req *model.JobCallbackReq) (*model.Job, error) {
+	ret := _m.Called(ctx, jobID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleCallback")
+	}
+
+	var r0 *model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.JobCallbackReq) (*model.Job, error)); ok {
+		return rf(ctx, jobID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.JobCallbackReq) *model.Job); ok {
+		r0 = rf(ctx, jobID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.JobCallbackReq) error); ok {
+		r1 = rf(ctx, jobID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewJobCallbackHandler creates a new instance of JobCallbackHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewJobCallbackHandler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *JobCallbackHandler {
+	mock := &JobCallbackHandler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
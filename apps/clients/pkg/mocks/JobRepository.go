@@ -5,8 +5,13 @@ package mocks
 import (
 	context "context"
 
-	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	bson "go.mongodb.org/mongo-driver/v2/bson"
+
 	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+
+	time "time"
 )
 
 // JobRepository is an autogenerated mock type for the JobRepository type
@@ -14,6 +19,24 @@ type JobRepository struct {
 	mock.Mock
 }
 
+// Apply provides a mock function with given fields: ctx, jobID, from, update
+func (_m *JobRepository) Apply(ctx context.Context, jobID string, from model.JobStatus, update *model.JobUpdate) error {
+	ret := _m.Called(ctx, jobID, from, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Apply")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.JobStatus, *model.JobUpdate) error); ok {
+		r0 = rf(ctx, jobID, from, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Count provides a mock function with given fields: ctx, query
 func (_m *JobRepository) Count(ctx context.Context, query *model.GetJobsQuery) (int, error) {
 	ret := _m.Called(ctx, query)
@@ -42,6 +65,90 @@ func (_m *JobRepository) Count(ctx context.Context, query *model.GetJobsQuery) (
 	return r0, r1
 }
 
+// CountActive provides a mock function with given fields: ctx, createdBy
+func (_m *JobRepository) CountActive(ctx context.Context, createdBy string) (int, error) {
+	ret := _m.Called(ctx, createdBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountActive")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, createdBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, createdBy)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, createdBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountCreated provides a mock function with given fields: ctx, createdBy, since
+func (_m *JobRepository) CountCreated(ctx context.Context, createdBy string, since time.Time) (int, error) {
+	ret := _m.Called(ctx, createdBy, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountCreated")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (int, error)); ok {
+		return rf(ctx, createdBy, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) int); ok {
+		r0 = rf(ctx, createdBy, since)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, createdBy, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountPendingMatches provides a mock function with given fields: ctx
+func (_m *JobRepository) CountPendingMatches(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountPendingMatches")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, job
 func (_m *JobRepository) Create(ctx context.Context, job *model.Job) (string, error) {
 	ret := _m.Called(ctx, job)
@@ -70,6 +177,66 @@ func (_m *JobRepository) Create(ctx context.Context, job *model.Job) (string, er
 	return r0, r1
 }
 
+// FindBatchChildren provides a mock function with given fields: ctx, batchID, statuses
+func (_m *JobRepository) FindBatchChildren(ctx context.Context, batchID string, statuses []model.JobStatus) ([]model.Job, error) {
+	ret := _m.Called(ctx, batchID, statuses)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindBatchChildren")
+	}
+
+	var r0 []model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []model.JobStatus) ([]model.Job, error)); ok {
+		return rf(ctx, batchID, statuses)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []model.JobStatus) []model.Job); ok {
+		r0 = rf(ctx, batchID, statuses)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []model.JobStatus) error); ok {
+		r1 = rf(ctx, batchID, statuses)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindStale provides a mock function with given fields: ctx, jobType, statuses, before
+func (_m *JobRepository) FindStale(ctx context.Context, jobType model.JobType, statuses []model.JobStatus, before time.Time) ([]model.Job, error) {
+	ret := _m.Called(ctx, jobType, statuses, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindStale")
+	}
+
+	var r0 []model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.JobType, []model.JobStatus, time.Time) ([]model.Job, error)); ok {
+		return rf(ctx, jobType, statuses, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.JobType, []model.JobStatus, time.Time) []model.Job); ok {
+		r0 = rf(ctx, jobType, statuses, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.JobType, []model.JobStatus, time.Time) error); ok {
+		r1 = rf(ctx, jobType, statuses, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAll provides a mock function with given fields: ctx, query
 func (_m *JobRepository) GetAll(ctx context.Context, query *model.GetJobsQuery) ([]model.Job, error) {
 	ret := _m.Called(ctx, query)
@@ -100,6 +267,103 @@ func (_m *JobRepository) GetAll(ctx context.Context, query *model.GetJobsQuery)
 	return r0, r1
 }
 
+// GetBatchCounts provides a mock function with given fields: ctx, batchID
+func (_m *JobRepository) GetBatchCounts(ctx context.Context, batchID string) (*model.BatchCounts, int, error) {
+	ret := _m.Called(ctx, batchID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBatchCounts")
+	}
+
+	var r0 *model.BatchCounts
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.BatchCounts, int, error)); ok {
+		return rf(ctx, batchID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.BatchCounts); ok {
+		r0 = rf(ctx, batchID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.BatchCounts)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) int); ok {
+		r1 = rf(ctx, batchID)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, batchID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetChain provides a mock function with given fields: ctx, rootID
+func (_m *JobRepository) GetChain(ctx context.Context, rootID string) ([]model.Job, error) {
+	ret := _m.Called(ctx, rootID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChain")
+	}
+
+	var r0 []model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]model.Job, error)); ok {
+		return rf(ctx, rootID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.Job); ok {
+		r0 = rf(ctx, rootID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, rootID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMatchStats provides a mock function with given fields: ctx, query
+func (_m *JobRepository) GetMatchStats(ctx context.Context, query *model.GetMatchStatsQuery) ([]model.MatchStats, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMatchStats")
+	}
+
+	var r0 []model.MatchStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetMatchStatsQuery) ([]model.MatchStats, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetMatchStatsQuery) []model.MatchStats); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MatchStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GetMatchStatsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOne provides a mock function with given fields: ctx, jobID
 func (_m *JobRepository) GetOne(ctx context.Context, jobID string) (*model.Job, error) {
 	ret := _m.Called(ctx, jobID)
@@ -130,6 +394,178 @@ func (_m *JobRepository) GetOne(ctx context.Context, jobID string) (*model.Job,
 	return r0, r1
 }
 
+// GetPendingMatches provides a mock function with given fields: ctx, query
+func (_m *JobRepository) GetPendingMatches(ctx context.Context, query *model.GetPendingMatchesQuery) ([]model.Job, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingMatches")
+	}
+
+	var r0 []model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetPendingMatchesQuery) ([]model.Job, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetPendingMatchesQuery) []model.Job); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GetPendingMatchesQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStats provides a mock function with given fields: ctx, query
+func (_m *JobRepository) GetStats(ctx context.Context, query *model.GetJobStatsQuery) (*model.JobStats, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStats")
+	}
+
+	var r0 *model.JobStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetJobStatsQuery) (*model.JobStats, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetJobStatsQuery) *model.JobStats); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.JobStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GetJobStatsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetFlowRunID provides a mock function with given fields: ctx, jobID, flowRunID
+func (_m *JobRepository) SetFlowRunID(ctx context.Context, jobID string, flowRunID string) error {
+	ret := _m.Called(ctx, jobID, flowRunID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFlowRunID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, jobID, flowRunID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetMatchReview provides a mock function with given fields: ctx, jobID, candidateID, review
+func (_m *JobRepository) SetMatchReview(ctx context.Context, jobID string, candidateID bson.ObjectID, review *model.MatchReview) error {
+	ret := _m.Called(ctx, jobID, candidateID, review)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMatchReview")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bson.ObjectID, *model.MatchReview) error); ok {
+		r0 = rf(ctx, jobID, candidateID, review)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateBatch provides a mock function with given fields: ctx, batchID, from, status, progress, counts
+func (_m *JobRepository) UpdateBatch(ctx context.Context, batchID string, from time.Time, status model.JobStatus, progress int, counts *model.BatchCounts) (time.Time, error) {
+	ret := _m.Called(ctx, batchID, from, status, progress, counts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateBatch")
+	}
+
+	var r0 time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, model.JobStatus, int, *model.BatchCounts) (time.Time, error)); ok {
+		return rf(ctx, batchID, from, status, progress, counts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, model.JobStatus, int, *model.BatchCounts) time.Time); ok {
+		r0 = rf(ctx, batchID, from, status, progress, counts)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, model.JobStatus, int, *model.BatchCounts) error); ok {
+		r1 = rf(ctx, batchID, from, status, progress, counts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, jobID, from, to, entry
+func (_m *JobRepository) UpdateStatus(ctx context.Context, jobID string, from []model.JobStatus, to model.JobStatus, entry model.JobLog) error {
+	ret := _m.Called(ctx, jobID, from, to, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []model.JobStatus, model.JobStatus, model.JobLog) error); ok {
+		r0 = rf(ctx, jobID, from, to, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Watch provides a mock function with given fields: ctx, jobID
+func (_m *JobRepository) Watch(ctx context.Context, jobID string) (<-chan *model.Job, error) {
+	ret := _m.Called(ctx, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Watch")
+	}
+
+	var r0 <-chan *model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (<-chan *model.Job, error)); ok {
+		return rf(ctx, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) <-chan *model.Job); ok {
+		r0 = rf(ctx, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewJobRepository creates a new instance of JobRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewJobRepository(t interface {
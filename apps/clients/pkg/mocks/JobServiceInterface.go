@@ -7,6 +7,8 @@ import (
 
 	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // JobServiceInterface is an autogenerated mock type for the JobServiceInterface type
@@ -14,6 +16,66 @@ type JobServiceInterface struct {
 	mock.Mock
 }
 
+// CancelBatch provides a mock function with given fields: ctx, batchID
+func (_m *JobServiceInterface) CancelBatch(ctx context.Context, batchID string) (*model.BatchActionResponse, error) {
+	ret := _m.Called(ctx, batchID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelBatch")
+	}
+
+	var r0 *model.BatchActionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.BatchActionResponse, error)); ok {
+		return rf(ctx, batchID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.BatchActionResponse); ok {
+		r0 = rf(ctx, batchID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.BatchActionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, batchID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CancelJob provides a mock function with given fields: ctx, jobID
+func (_m *JobServiceInterface) CancelJob(ctx context.Context, jobID string) (*model.Job, error) {
+	ret := _m.Called(ctx, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelJob")
+	}
+
+	var r0 *model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Job, error)); ok {
+		return rf(ctx, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Job); ok {
+		r0 = rf(ctx, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateJob provides a mock function with given fields: ctx, job
 func (_m *JobServiceInterface) CreateJob(ctx context.Context, job *model.Job) (string, error) {
 	ret := _m.Called(ctx, job)
@@ -42,6 +104,34 @@ func (_m *JobServiceInterface) CreateJob(ctx context.Context, job *model.Job) (s
 	return r0, r1
 }
 
+// DispatchOutbox provides a mock function with given fields: ctx, maxAttempts
+func (_m *JobServiceInterface) DispatchOutbox(ctx context.Context, maxAttempts int) (int, error) {
+	ret := _m.Called(ctx, maxAttempts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DispatchOutbox")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, maxAttempts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, maxAttempts)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, maxAttempts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllJobs provides a mock function with given fields: ctx, query
 func (_m *JobServiceInterface) GetAllJobs(ctx context.Context, query *model.GetJobsQuery) (int, []model.Job, error) {
 	ret := _m.Called(ctx, query)
@@ -79,6 +169,43 @@ func (_m *JobServiceInterface) GetAllJobs(ctx context.Context, query *model.GetJ
 	return r0, r1, r2
 }
 
+// GetBatchChildren provides a mock function with given fields: ctx, batchID, query
+func (_m *JobServiceInterface) GetBatchChildren(ctx context.Context, batchID string, query *model.GetJobsQuery) (int, []model.Job, error) {
+	ret := _m.Called(ctx, batchID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBatchChildren")
+	}
+
+	var r0 int
+	var r1 []model.Job
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetJobsQuery) (int, []model.Job, error)); ok {
+		return rf(ctx, batchID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GetJobsQuery) int); ok {
+		r0 = rf(ctx, batchID, query)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.GetJobsQuery) []model.Job); ok {
+		r1 = rf(ctx, batchID, query)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *model.GetJobsQuery) error); ok {
+		r2 = rf(ctx, batchID, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetJob provides a mock function with given fields: ctx, jobID
 func (_m *JobServiceInterface) GetJob(ctx context.Context, jobID string) (*model.Job, error) {
 	ret := _m.Called(ctx, jobID)
@@ -109,6 +236,276 @@ func (_m *JobServiceInterface) GetJob(ctx context.Context, jobID string) (*model
 	return r0, r1
 }
 
+// GetRetryChain provides a mock function with given fields: ctx, job
+func (_m *JobServiceInterface) GetRetryChain(ctx context.Context, job *model.Job) ([]model.JobAttempt, error) {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRetryChain")
+	}
+
+	var r0 []model.JobAttempt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Job) ([]model.JobAttempt, error)); ok {
+		return rf(ctx, job)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Job) []model.JobAttempt); ok {
+		r0 = rf(ctx, job)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.JobAttempt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Job) error); ok {
+		r1 = rf(ctx, job)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStats provides a mock function with given fields: ctx, query
+func (_m *JobServiceInterface) GetStats(ctx context.Context, query *model.GetJobStatsQuery) (*model.JobStats, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStats")
+	}
+
+	var r0 *model.JobStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetJobStatsQuery) (*model.JobStats, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetJobStatsQuery) *model.JobStats); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.JobStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GetJobStatsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HandleCallback provides a mock function with given fields: ctx, jobID, req
+func (_m *JobServiceInterface) HandleCallback(ctx context.Context, jobID string, req *model.JobCallbackReq) (*model.Job, error) {
+	ret := _m.Called(ctx, jobID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleCallback")
+	}
+
+	var r0 *model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.JobCallbackReq) (*model.Job, error)); ok {
+		return rf(ctx, jobID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.JobCallbackReq) *model.Job); ok {
+		r0 = rf(ctx, jobID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.JobCallbackReq) error); ok {
+		r1 = rf(ctx, jobID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReapStaleJobs provides a mock function with given fields: ctx, timeouts, checkPrefect
+func (_m *JobServiceInterface) ReapStaleJobs(ctx context.Context, timeouts map[model.JobType]time.Duration, checkPrefect bool) (int, error) {
+	ret := _m.Called(ctx, timeouts, checkPrefect)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReapStaleJobs")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, map[model.JobType]time.Duration, bool) (int, error)); ok {
+		return rf(ctx, timeouts, checkPrefect)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, map[model.JobType]time.Duration, bool) int); ok {
+		r0 = rf(ctx, timeouts, checkPrefect)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, map[model.JobType]time.Duration, bool) error); ok {
+		r1 = rf(ctx, timeouts, checkPrefect)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetryBatch provides a mock function with given fields: ctx, batchID
+func (_m *JobServiceInterface) RetryBatch(ctx context.Context, batchID string) (*model.BatchActionResponse, error) {
+	ret := _m.Called(ctx, batchID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetryBatch")
+	}
+
+	var r0 *model.BatchActionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.BatchActionResponse, error)); ok {
+		return rf(ctx, batchID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.BatchActionResponse); ok {
+		r0 = rf(ctx, batchID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.BatchActionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, batchID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetryJob provides a mock function with given fields: ctx, jobID
+func (_m *JobServiceInterface) RetryJob(ctx context.Context, jobID string) (*model.Job, error) {
+	ret := _m.Called(ctx, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetryJob")
+	}
+
+	var r0 *model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Job, error)); ok {
+		return rf(ctx, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Job); ok {
+		r0 = rf(ctx, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetFlowRunID provides a mock function with given fields: ctx, jobID, flowRunID
+func (_m *JobServiceInterface) SetFlowRunID(ctx context.Context, jobID string, flowRunID string) error {
+	ret := _m.Called(ctx, jobID, flowRunID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFlowRunID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, jobID, flowRunID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StreamJob provides a mock function with given fields: ctx, jobID, lastEventID, send
+func (_m *JobServiceInterface) StreamJob(ctx context.Context, jobID string, lastEventID string, send func(model.JobEvent) error) error {
+	ret := _m.Called(ctx, jobID, lastEventID, send)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, func(model.JobEvent) error) error); ok {
+		r0 = rf(ctx, jobID, lastEventID, send)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SubmitBatch provides a mock function with given fields: ctx, batch, children
+func (_m *JobServiceInterface) SubmitBatch(ctx context.Context, batch *model.Job, children []*model.Job) (string, error) {
+	ret := _m.Called(ctx, batch, children)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubmitBatch")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Job, []*model.Job) (string, error)); ok {
+		return rf(ctx, batch, children)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Job, []*model.Job) string); ok {
+		r0 = rf(ctx, batch, children)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Job, []*model.Job) error); ok {
+		r1 = rf(ctx, batch, children)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubmitJob provides a mock function with given fields: ctx, job
+func (_m *JobServiceInterface) SubmitJob(ctx context.Context, job *model.Job) (string, error) {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubmitJob")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Job) (string, error)); ok {
+		return rf(ctx, job)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Job) string); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Job) error); ok {
+		r1 = rf(ctx, job)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewJobServiceInterface creates a new instance of JobServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewJobServiceInterface(t interface {
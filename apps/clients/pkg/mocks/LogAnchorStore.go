@@ -0,0 +1,125 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogAnchorStore is an autogenerated mock type for the LogAnchorStore type
+type LogAnchorStore struct {
+	mock.Mock
+}
+
+// Append provides a mock function with given fields: ctx, anchor
+func (_m *LogAnchorStore) Append(ctx context.Context, anchor *model.LogAnchor) error {
+	ret := _m.Called(ctx, anchor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Append")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.LogAnchor) error); ok {
+		r0 = rf(ctx, anchor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AppendCheckpoint provides a mock function with given fields: ctx, checkpoint
+func (_m *LogAnchorStore) AppendCheckpoint(ctx context.Context, checkpoint *model.LogCheckpoint) error {
+	ret := _m.Called(ctx, checkpoint)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppendCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.LogCheckpoint) error); ok {
+		r0 = rf(ctx, checkpoint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Latest provides a mock function with given fields: ctx
+func (_m *LogAnchorStore) Latest(ctx context.Context) (*model.LogAnchor, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Latest")
+	}
+
+	var r0 *model.LogAnchor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.LogAnchor, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.LogAnchor); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LogAnchor)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LatestCheckpoint provides a mock function with given fields: ctx
+func (_m *LogAnchorStore) LatestCheckpoint(ctx context.Context) (*model.LogCheckpoint, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LatestCheckpoint")
+	}
+
+	var r0 *model.LogCheckpoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.LogCheckpoint, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.LogCheckpoint); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LogCheckpoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLogAnchorStore creates a new instance of LogAnchorStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogAnchorStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogAnchorStore {
+	mock := &LogAnchorStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogChainServiceInterface is an autogenerated mock type for the LogChainServiceInterface type
+type LogChainServiceInterface struct {
+	mock.Mock
+}
+
+// AnchorHead provides a mock function with given fields: ctx
+func (_m *LogChainServiceInterface) AnchorHead(ctx context.Context) (*model.LogAnchor, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AnchorHead")
+	}
+
+	var r0 *model.LogAnchor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.LogAnchor, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.LogAnchor); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LogAnchor)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyChain provides a mock function with given fields: ctx
+func (_m *LogChainServiceInterface) VerifyChain(ctx context.Context) (*model.ChainVerification, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyChain")
+	}
+
+	var r0 *model.ChainVerification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.ChainVerification, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.ChainVerification); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ChainVerification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLogChainServiceInterface creates a new instance of LogChainServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogChainServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogChainServiceInterface {
+	mock := &LogChainServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogExportRepository is an autogenerated mock type for the LogExportRepository type
+type LogExportRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, export
+func (_m *LogExportRepository) Create(ctx context.Context, export *model.LogExport) error {
+	ret := _m.Called(ctx, export)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.LogExport) error); ok {
+		r0 = rf(ctx, export)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetOne provides a mock function with given fields: ctx, exportID
+func (_m *LogExportRepository) GetOne(ctx context.Context, exportID string) (*model.LogExport, error) {
+	ret := _m.Called(ctx, exportID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOne")
+	}
+
+	var r0 *model.LogExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.LogExport, error)); ok {
+		return rf(ctx, exportID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.LogExport); ok {
+		r0 = rf(ctx, exportID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LogExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, exportID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLogExportRepository creates a new instance of LogExportRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogExportRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogExportRepository {
+	mock := &LogExportRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
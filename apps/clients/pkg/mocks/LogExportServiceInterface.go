@@ -0,0 +1,109 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+)
+
+// LogExportServiceInterface is an autogenerated mock type for the LogExportServiceInterface type
+type LogExportServiceInterface struct {
+	mock.Mock
+}
+
+// GetExport provides a mock function with given fields: ctx, exportID
+func (_m *LogExportServiceInterface) GetExport(ctx context.Context, exportID string) (*model.LogExport, error) {
+	ret := _m.Called(ctx, exportID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExport")
+	}
+
+	var r0 *model.LogExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.LogExport, error)); ok {
+		return rf(ctx, exportID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.LogExport); ok {
+		r0 = rf(ctx, exportID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LogExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, exportID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartExport provides a mock function with given fields: ctx, query
+func (_m *LogExportServiceInterface) StartExport(ctx context.Context, query *model.ExportLogsQuery) (*model.LogExport, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartExport")
+	}
+
+	var r0 *model.LogExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ExportLogsQuery) (*model.LogExport, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ExportLogsQuery) *model.LogExport); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LogExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ExportLogsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WriteExport provides a mock function with given fields: ctx, export, w
+func (_m *LogExportServiceInterface) WriteExport(ctx context.Context, export *model.LogExport, w io.Writer) error {
+	ret := _m.Called(ctx, export, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteExport")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.LogExport, io.Writer) error); ok {
+		r0 = rf(ctx, export, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLogExportServiceInterface creates a new instance of LogExportServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogExportServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogExportServiceInterface {
+	mock := &LogExportServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
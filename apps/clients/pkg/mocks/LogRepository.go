@@ -5,8 +5,11 @@ package mocks
 import (
 	context "context"
 
-	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	bson "go.mongodb.org/mongo-driver/v2/bson"
+
 	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 )
 
 // LogRepository is an autogenerated mock type for the LogRepository type
@@ -14,6 +17,54 @@ type LogRepository struct {
 	mock.Mock
 }
 
+// Archive provides a mock function with given fields: ctx, logIDs, archive
+func (_m *LogRepository) Archive(ctx context.Context, logIDs []bson.ObjectID, archive string) error {
+	ret := _m.Called(ctx, logIDs, archive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Archive")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []bson.ObjectID, string) error); ok {
+		r0 = rf(ctx, logIDs, archive)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ArchivedRunEnd provides a mock function with given fields: ctx, after
+func (_m *LogRepository) ArchivedRunEnd(ctx context.Context, after int64) (*model.Log, error) {
+	ret := _m.Called(ctx, after)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchivedRunEnd")
+	}
+
+	var r0 *model.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*model.Log, error)); ok {
+		return rf(ctx, after)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.Log); ok {
+		r0 = rf(ctx, after)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Log)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, after)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Count provides a mock function with given fields: ctx
 func (_m *LogRepository) Count(ctx context.Context) (int, error) {
 	ret := _m.Called(ctx)
@@ -70,6 +121,90 @@ func (_m *LogRepository) Create(ctx context.Context, log *model.Log) (string, er
 	return r0, r1
 }
 
+// DeleteArchived provides a mock function with given fields: ctx, through
+func (_m *LogRepository) DeleteArchived(ctx context.Context, through int64) error {
+	ret := _m.Called(ctx, through)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteArchived")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, through)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindExpired provides a mock function with given fields: ctx, filter, limit
+func (_m *LogRepository) FindExpired(ctx context.Context, filter *model.ExpiredLogsFilter, limit int) ([]model.Log, error) {
+	ret := _m.Called(ctx, filter, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindExpired")
+	}
+
+	var r0 []model.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ExpiredLogsFilter, int) ([]model.Log, error)); ok {
+		return rf(ctx, filter, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ExpiredLogsFilter, int) []model.Log); ok {
+		r0 = rf(ctx, filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Log)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ExpiredLogsFilter, int) error); ok {
+		r1 = rf(ctx, filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ForEach provides a mock function with given fields: ctx, query, fn
+func (_m *LogRepository) ForEach(ctx context.Context, query *model.GetLogsQuery, fn func(*model.Log) error) error {
+	ret := _m.Called(ctx, query, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForEach")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetLogsQuery, func(*model.Log) error) error); ok {
+		r0 = rf(ctx, query, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ForEachChained provides a mock function with given fields: ctx, after, fn
+func (_m *LogRepository) ForEachChained(ctx context.Context, after int64, fn func(*model.Log) error) error {
+	ret := _m.Called(ctx, after, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForEachChained")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, func(*model.Log) error) error); ok {
+		r0 = rf(ctx, after, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAll provides a mock function with given fields: ctx, query
 func (_m *LogRepository) GetAll(ctx context.Context, query *model.GetLogsQuery) ([]model.Log, error) {
 	ret := _m.Called(ctx, query)
@@ -130,6 +265,36 @@ func (_m *LogRepository) GetOne(ctx context.Context, logID string) (*model.Log,
 	return r0, r1
 }
 
+// Head provides a mock function with given fields: ctx
+func (_m *LogRepository) Head(ctx context.Context) (*model.Log, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Head")
+	}
+
+	var r0 *model.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.Log, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.Log); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Log)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewLogRepository creates a new instance of LogRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewLogRepository(t interface {
@@ -0,0 +1,61 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LogRetentionServiceInterface is an autogenerated mock type for the LogRetentionServiceInterface type
+type LogRetentionServiceInterface struct {
+	mock.Mock
+}
+
+// Purge provides a mock function with given fields: ctx, now
+func (_m *LogRetentionServiceInterface) Purge(ctx context.Context, now time.Time) (*model.LogPurgeResult, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 *model.LogPurgeResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (*model.LogPurgeResult, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) *model.LogPurgeResult); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LogPurgeResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLogRetentionServiceInterface creates a new instance of LogRetentionServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogRetentionServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogRetentionServiceInterface {
+	mock := &LogRetentionServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
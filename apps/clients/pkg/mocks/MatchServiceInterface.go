@@ -0,0 +1,119 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MatchServiceInterface is an autogenerated mock type for the MatchServiceInterface type
+type MatchServiceInterface struct {
+	mock.Mock
+}
+
+// GetMatchStats provides a mock function with given fields: ctx, query
+func (_m *MatchServiceInterface) GetMatchStats(ctx context.Context, query *model.GetMatchStatsQuery) (*model.GetMatchStatsResponse, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMatchStats")
+	}
+
+	var r0 *model.GetMatchStatsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetMatchStatsQuery) (*model.GetMatchStatsResponse, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetMatchStatsQuery) *model.GetMatchStatsResponse); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GetMatchStatsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GetMatchStatsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingMatches provides a mock function with given fields: ctx, query
+func (_m *MatchServiceInterface) GetPendingMatches(ctx context.Context, query *model.GetPendingMatchesQuery) (*model.GetPendingMatchesResponse, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingMatches")
+	}
+
+	var r0 *model.GetPendingMatchesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetPendingMatchesQuery) (*model.GetPendingMatchesResponse, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GetPendingMatchesQuery) *model.GetPendingMatchesResponse); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GetPendingMatchesResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GetPendingMatchesQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewMatch provides a mock function with given fields: ctx, jobID, candidateID, req
+func (_m *MatchServiceInterface) ReviewMatch(ctx context.Context, jobID string, candidateID string, req *model.ReviewMatchReq) (*model.MatchResult, error) {
+	ret := _m.Called(ctx, jobID, candidateID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReviewMatch")
+	}
+
+	var r0 *model.MatchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.ReviewMatchReq) (*model.MatchResult, error)); ok {
+		return rf(ctx, jobID, candidateID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.ReviewMatchReq) *model.MatchResult); ok {
+		r0 = rf(ctx, jobID, candidateID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.MatchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *model.ReviewMatchReq) error); ok {
+		r1 = rf(ctx, jobID, candidateID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMatchServiceInterface creates a new instance of MatchServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMatchServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MatchServiceInterface {
+	mock := &MatchServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,45 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotifierInterface is an autogenerated mock type for the NotifierInterface type
+type NotifierInterface struct {
+	mock.Mock
+}
+
+// Publish provides a mock function with given fields: notification
+func (_m *NotifierInterface) Publish(notification *model.Notification) error {
+	ret := _m.Called(notification)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Publish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Notification) error); ok {
+		r0 = rf(notification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewNotifierInterface creates a new instance of NotifierInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewNotifierInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *NotifierInterface {
+	mock := &NotifierInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
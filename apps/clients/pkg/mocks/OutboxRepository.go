@@ -0,0 +1,164 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	bson "go.mongodb.org/mongo-driver/v2/bson"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+
+	time "time"
+)
+
+// OutboxRepository is an autogenerated mock type for the OutboxRepository type
+type OutboxRepository struct {
+	mock.Mock
+}
+
+// CancelForJob provides a mock function with given fields: ctx, jobID, reason
+func (_m *OutboxRepository) CancelForJob(ctx context.Context, jobID string, reason string) error {
+	ret := _m.Called(ctx, jobID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelForJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, jobID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Claim provides a mock function with given fields: ctx, lockFor
+func (_m *OutboxRepository) Claim(ctx context.Context, lockFor time.Duration) (*model.OutboxEntry, error) {
+	ret := _m.Called(ctx, lockFor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Claim")
+	}
+
+	var r0 *model.OutboxEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) (*model.OutboxEntry, error)); ok {
+		return rf(ctx, lockFor)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) *model.OutboxEntry); ok {
+		r0 = rf(ctx, lockFor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OutboxEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, lockFor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, entry
+func (_m *OutboxRepository) Create(ctx context.Context, entry *model.OutboxEntry) (string, error) {
+	ret := _m.Called(ctx, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.OutboxEntry) (string, error)); ok {
+		return rf(ctx, entry)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.OutboxEntry) string); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.OutboxEntry) error); ok {
+		r1 = rf(ctx, entry)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkDelivered provides a mock function with given fields: ctx, entryID, flowRunID
+func (_m *OutboxRepository) MarkDelivered(ctx context.Context, entryID bson.ObjectID, flowRunID string) error {
+	ret := _m.Called(ctx, entryID, flowRunID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkDelivered")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, bson.ObjectID, string) error); ok {
+		r0 = rf(ctx, entryID, flowRunID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkFailed provides a mock function with given fields: ctx, entryID, lastError
+func (_m *OutboxRepository) MarkFailed(ctx context.Context, entryID bson.ObjectID, lastError string) error {
+	ret := _m.Called(ctx, entryID, lastError)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkFailed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, bson.ObjectID, string) error); ok {
+		r0 = rf(ctx, entryID, lastError)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reschedule provides a mock function with given fields: ctx, entryID, next, lastError
+func (_m *OutboxRepository) Reschedule(ctx context.Context, entryID bson.ObjectID, next time.Time, lastError string) error {
+	ret := _m.Called(ctx, entryID, next, lastError)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reschedule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, bson.ObjectID, time.Time, string) error); ok {
+		r0 = rf(ctx, entryID, next, lastError)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepository {
+	mock := &OutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TimelineRepository is an autogenerated mock type for the TimelineRepository type
+type TimelineRepository struct {
+	mock.Mock
+}
+
+// Count provides a mock function with given fields: ctx, clientID, field, query
+func (_m *TimelineRepository) Count(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) (int, error) {
+	ret := _m.Called(ctx, clientID, field, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.GetTimelineQuery) (int, error)); ok {
+		return rf(ctx, clientID, field, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.GetTimelineQuery) int); ok {
+		r0 = rf(ctx, clientID, field, query)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *model.GetTimelineQuery) error); ok {
+		r1 = rf(ctx, clientID, field, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, point
+func (_m *TimelineRepository) Create(ctx context.Context, point *model.TimelinePoint) (string, error) {
+	ret := _m.Called(ctx, point)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.TimelinePoint) (string, error)); ok {
+		return rf(ctx, point)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.TimelinePoint) string); ok {
+		r0 = rf(ctx, point)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.TimelinePoint) error); ok {
+		r1 = rf(ctx, point)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: ctx, clientID, field, query
+func (_m *TimelineRepository) GetAll(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) ([]model.TimelinePoint, error) {
+	ret := _m.Called(ctx, clientID, field, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []model.TimelinePoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.GetTimelineQuery) ([]model.TimelinePoint, error)); ok {
+		return rf(ctx, clientID, field, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.GetTimelineQuery) []model.TimelinePoint); ok {
+		r0 = rf(ctx, clientID, field, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.TimelinePoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *model.GetTimelineQuery) error); ok {
+		r1 = rf(ctx, clientID, field, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLatest provides a mock function with given fields: ctx, clientID, field
+func (_m *TimelineRepository) GetLatest(ctx context.Context, clientID string, field string) (*model.TimelinePoint, error) {
+	ret := _m.Called(ctx, clientID, field)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatest")
+	}
+
+	var r0 *model.TimelinePoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.TimelinePoint, error)); ok {
+		return rf(ctx, clientID, field)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.TimelinePoint); ok {
+		r0 = rf(ctx, clientID, field)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TimelinePoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, field)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTimelineRepository creates a new instance of TimelineRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTimelineRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TimelineRepository {
+	mock := &TimelineRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
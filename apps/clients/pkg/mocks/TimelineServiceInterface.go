@@ -0,0 +1,112 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	bson "go.mongodb.org/mongo-driver/v2/bson"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+
+	time "time"
+)
+
+// TimelineServiceInterface is an autogenerated mock type for the TimelineServiceInterface type
+type TimelineServiceInterface struct {
+	mock.Mock
+}
+
+// Capture provides a mock function with given fields: ctx, clientID, data, source, actor, at
+func (_m *TimelineServiceInterface) Capture(ctx context.Context, clientID string, data bson.D, source model.ChangeSource, actor string, at time.Time) error {
+	ret := _m.Called(ctx, clientID, data, source, actor, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Capture")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bson.D, model.ChangeSource, string, time.Time) error); ok {
+		r0 = rf(ctx, clientID, data, source, actor, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CaptureScrape provides a mock function with given fields: ctx, clientID, at
+func (_m *TimelineServiceInterface) CaptureScrape(ctx context.Context, clientID string, at time.Time) ([]model.Change, error) {
+	ret := _m.Called(ctx, clientID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CaptureScrape")
+	}
+
+	var r0 []model.Change
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]model.Change, error)); ok {
+		return rf(ctx, clientID, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []model.Change); ok {
+		r0 = rf(ctx, clientID, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Change)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, clientID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTimeline provides a mock function with given fields: ctx, clientID, field, query
+func (_m *TimelineServiceInterface) GetTimeline(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) (*model.GetTimelineResponse, error) {
+	ret := _m.Called(ctx, clientID, field, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTimeline")
+	}
+
+	var r0 *model.GetTimelineResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.GetTimelineQuery) (*model.GetTimelineResponse, error)); ok {
+		return rf(ctx, clientID, field, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *model.GetTimelineQuery) *model.GetTimelineResponse); ok {
+		r0 = rf(ctx, clientID, field, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GetTimelineResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *model.GetTimelineQuery) error); ok {
+		r1 = rf(ctx, clientID, field, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTimelineServiceInterface creates a new instance of TimelineServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTimelineServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TimelineServiceInterface {
+	mock := &TimelineServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
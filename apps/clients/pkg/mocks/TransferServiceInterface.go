@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+)
+
+// TransferServiceInterface is an autogenerated mock type for the TransferServiceInterface type
+type TransferServiceInterface struct {
+	mock.Mock
+}
+
+// ExportClients provides a mock function with given fields: ctx, w
+func (_m *TransferServiceInterface) ExportClients(ctx context.Context, w io.Writer) (int, error) {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportClients")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer) (int, error)); ok {
+		return rf(ctx, w)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer) int); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, io.Writer) error); ok {
+		r1 = rf(ctx, w)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportClients provides a mock function with given fields: ctx, r, query
+func (_m *TransferServiceInterface) ImportClients(ctx context.Context, r io.Reader, query *model.ImportClientsQuery) (*model.ImportResult, error) {
+	ret := _m.Called(ctx, r, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportClients")
+	}
+
+	var r0 *model.ImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader, *model.ImportClientsQuery) (*model.ImportResult, error)); ok {
+		return rf(ctx, r, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader, *model.ImportClientsQuery) *model.ImportResult); ok {
+		r0 = rf(ctx, r, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ImportResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, io.Reader, *model.ImportClientsQuery) error); ok {
+		r1 = rf(ctx, r, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTransferServiceInterface creates a new instance of TransferServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTransferServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TransferServiceInterface {
+	mock := &TransferServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,103 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	service "github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WorkflowBackend is an autogenerated mock type for the WorkflowBackend type
+type WorkflowBackend struct {
+	mock.Mock
+}
+
+// Cancel provides a mock function with given fields: ctx, runID
+func (_m *WorkflowBackend) Cancel(ctx context.Context, runID string) error {
+	ret := _m.Called(ctx, runID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Cancel")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, runID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// State provides a mock function with given fields: ctx, runID
+func (_m *WorkflowBackend) State(ctx context.Context, runID string) (string, error) {
+	ret := _m.Called(ctx, runID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for State")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, runID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, runID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, runID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Trigger provides a mock function with given fields: ctx, run
+func (_m *WorkflowBackend) Trigger(ctx context.Context, run service.WorkflowRun) (string, error) {
+	ret := _m.Called(ctx, run)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Trigger")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, service.WorkflowRun) (string, error)); ok {
+		return rf(ctx, run)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, service.WorkflowRun) string); ok {
+		r0 = rf(ctx, run)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, service.WorkflowRun) error); ok {
+		r1 = rf(ctx, run)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWorkflowBackend creates a new instance of WorkflowBackend. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWorkflowBackend(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WorkflowBackend {
+	mock := &WorkflowBackend{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type mongoActivityRepository struct {
+	storage *MongoStorage
+}
+
+func NewMongoActivityRepository(storage *MongoStorage) ActivityRepository {
+	return &mongoActivityRepository{storage: storage}
+}
+
+type ActivityRepository interface {
+	CreateNote(ctx context.Context, note *model.Note) (string, error)
+	GetFeed(ctx context.Context, clientID string, query *model.GetActivityQuery) ([]model.ActivityItem, int, error)
+}
+
+func (r *mongoActivityRepository) CreateNote(ctx context.Context, note *model.Note) (string, error) {
+	if note == nil {
+		return "", fmt.Errorf("%w: cannot insert nil note", errorx.ErrInvalidInput)
+	}
+
+	result, err := r.storage.noteCollection.InsertOne(ctx, note)
+	if err != nil {
+		return "", fmt.Errorf("%w: insert failed", errorx.ErrDependencyFailed)
+	}
+
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%w: failed to convert inserted ID", errorx.ErrInternal)
+	}
+	return insertedID.Hex(), nil
+}
+
+// GetFeed projects every requested source into ActivityItems and merges them with $unionWith,
+// so sorting and paging happen in a single aggregation. query.Types must not be empty.
+func (r *mongoActivityRepository) GetFeed(ctx context.Context, clientID string, query *model.GetActivityQuery) ([]model.ActivityItem, int, error) {
+	var base *mongo.Collection
+	var pipeline mongo.Pipeline
+
+	for _, t := range query.Types {
+		coll, stages := r.activitySource(t, clientID, query)
+		if coll == nil {
+			return nil, 0, fmt.Errorf("%w: unknown activity type '%s'", errorx.ErrInvalidInput, t)
+		}
+		if base == nil {
+			base, pipeline = coll, stages
+			continue
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$unionWith", Value: bson.D{
+			{Key: "coll", Value: coll.Name()},
+			{Key: "pipeline", Value: stages},
+		}}})
+	}
+	if base == nil {
+		return nil, 0, fmt.Errorf("%w: no activity types requested", errorx.ErrInvalidInput)
+	}
+
+	timestamp := bson.D{}
+	if !query.From.IsZero() {
+		timestamp = append(timestamp, bson.E{Key: "$gte", Value: query.From})
+	}
+	if !query.To.IsZero() {
+		timestamp = append(timestamp, bson.E{Key: "$lte", Value: query.To})
+	}
+	if len(timestamp) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.D{{Key: "timestamp", Value: timestamp}}}})
+	}
+
+	skip := (query.Page - 1) * query.PageSize
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: -1}, {Key: "refId", Value: -1}}}},
+		bson.D{{Key: "$facet", Value: bson.D{
+			{Key: "items", Value: bson.A{
+				bson.D{{Key: "$skip", Value: skip}},
+				bson.D{{Key: "$limit", Value: query.PageSize}},
+			}},
+			{Key: "total", Value: bson.A{bson.D{{Key: "$count", Value: "count"}}}},
+		}}},
+	)
+
+	cursor, err := base.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Items []model.ActivityItem `bson:"items"`
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+
+	items := []model.ActivityItem{}
+	total := 0
+	if len(results) > 0 {
+		if results[0].Items != nil {
+			items = results[0].Items
+		}
+		if len(results[0].Total) > 0 {
+			total = results[0].Total[0].Count
+		}
+	}
+	return items, total, nil
+}
+
+// activitySource returns the collection backing an activity type and the stages projecting it into ActivityItems
+func (r *mongoActivityRepository) activitySource(t model.ActivityType, clientID string, query *model.GetActivityQuery) (*mongo.Collection, mongo.Pipeline) {
+	refID := bson.D{{Key: "$toString", Value: "$_id"}}
+	byClient := bson.D{{Key: "$match", Value: bson.D{{Key: "clientId", Value: clientID}}}}
+
+	switch t {
+	case model.ActivityLog:
+		return r.storage.logCollection, mongo.Pipeline{byClient, {{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "type", Value: string(t)},
+			{Key: "refId", Value: refID},
+			{Key: "timestamp", Value: "$timestamp"},
+			{Key: "actor", Value: "$actor"},
+			{Key: "action", Value: "$operation"},
+			{Key: "summary", Value: "$details"},
+		}}}}
+	case model.ActivityJob:
+		return r.storage.jobCollection, mongo.Pipeline{byClient, {{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "type", Value: string(t)},
+			{Key: "refId", Value: refID},
+			{Key: "timestamp", Value: "$createdAt"},
+			{Key: "action", Value: "$type"},
+			{Key: "summary", Value: "$status"},
+		}}}}
+	case model.ActivityArticle:
+		articleIDs := query.ArticleIDs
+		if articleIDs == nil {
+			articleIDs = []bson.ObjectID{}
+		}
+		return r.storage.articleCollection, mongo.Pipeline{
+			{{Key: "$match", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: articleIDs}}}}}},
+			{{Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: r.storage.feedbackCollection.Name()},
+				{Key: "let", Value: bson.D{{Key: "articleId", Value: refID}}},
+				{Key: "pipeline", Value: bson.A{
+					bson.D{{Key: "$match", Value: bson.D{
+						{Key: "clientId", Value: clientID},
+						{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$articleId", "$$articleId"}}}},
+					}}},
+					bson.D{{Key: "$project", Value: bson.D{{Key: "_id", Value: 0}, {Key: "timestamp", Value: 1}}}},
+				}},
+				{Key: "as", Value: "feedback"},
+			}}},
+			{{Key: "$project", Value: bson.D{
+				{Key: "_id", Value: 0},
+				{Key: "type", Value: string(t)},
+				{Key: "refId", Value: refID},
+				{Key: "timestamp", Value: articleLinkTime(query.ArticleLinks)},
+				{Key: "action", Value: "$source"},
+				{Key: "summary", Value: "$title"},
+			}}},
+		}
+	case model.ActivityNote:
+		return r.storage.noteCollection, mongo.Pipeline{byClient, {{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "type", Value: string(t)},
+			{Key: "refId", Value: refID},
+			{Key: "timestamp", Value: "$createdAt"},
+			{Key: "actor", Value: "$author"},
+			{Key: "action", Value: string(t)},
+			{Key: "summary", Value: "$text"},
+		}}}}
+	case model.ActivityChange:
+		return r.storage.timelineCollection, mongo.Pipeline{byClient, {{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "type", Value: string(t)},
+			{Key: "refId", Value: refID},
+			{Key: "timestamp", Value: "$timestamp"},
+			{Key: "actor", Value: "$actor"},
+			{Key: "action", Value: "$field"},
+			{Key: "summary", Value: "$source"},
+			{Key: "value", Value: "$value"},
+		}}}}
+	}
+	return nil, nil
+}
+
+// articleLinkTime dates an article by when it was linked to the client. Links made before link times were recorded
+// fall back to when the client's reviewer gave their verdict on the article, then to when the article was stored.
+func articleLinkTime(links []model.ArticleLink) bson.D {
+	if links == nil {
+		links = []model.ArticleLink{}
+	}
+	link := bson.D{{Key: "$first", Value: bson.D{{Key: "$filter", Value: bson.D{
+		{Key: "input", Value: bson.D{{Key: "$literal", Value: links}}},
+		{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{"$$this.articleId", "$_id"}}}},
+	}}}}}
+	reviewed := bson.D{{Key: "$first", Value: "$feedback.timestamp"}}
+	stored := bson.D{{Key: "$toDate", Value: "$_id"}}
+
+	return bson.D{{Key: "$let", Value: bson.D{
+		{Key: "vars", Value: bson.D{{Key: "link", Value: link}}},
+		{Key: "in", Value: bson.D{{Key: "$ifNull", Value: bson.A{
+			"$$link.linkedAt",
+			bson.D{{Key: "$ifNull", Value: bson.A{reviewed, stored}}},
+		}}}},
+	}}}
+}
@@ -0,0 +1,88 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ActivityRepositorySuite struct {
+	suite.Suite
+	storage *repository.MongoStorage
+	repo    repository.ActivityRepository
+	cleanup func()
+	ctx     context.Context
+}
+
+func (s *ActivityRepositorySuite) SetupSuite() {
+	s.storage, s.cleanup = repository.NewTestMongoStorage(s.T())
+	s.repo = repository.NewMongoActivityRepository(s.storage)
+	s.ctx = context.TODO()
+}
+
+func (s *ActivityRepositorySuite) TearDownSuite() {
+	s.cleanup()
+}
+
+func (s *ActivityRepositorySuite) TestGetFeed() {
+	clientID := bson.NewObjectID().Hex()
+	base := time.Now().UTC().Truncate(time.Millisecond)
+
+	_, err := s.storage.LogCollection().InsertOne(s.ctx, model.Log{ClientID: clientID, Actor: "alice", Operation: model.OperationUpdate, Timestamp: base.Add(-3 * time.Hour)})
+	s.Require().NoError(err)
+	_, err = s.storage.JobCollection().InsertOne(s.ctx, model.Job{ClientID: clientID, Type: model.Scrape, Status: model.JobStatusCompleted, CreatedAt: base.Add(-2 * time.Hour)})
+	s.Require().NoError(err)
+	_, err = s.storage.TimelineCollection().InsertOne(s.ctx, model.TimelinePoint{ClientID: clientID, Field: "netWorth", Value: 100, Source: model.ChangeSourceScrape, Timestamp: base.Add(-time.Hour)})
+	s.Require().NoError(err)
+	_, err = s.repo.CreateNote(s.ctx, &model.Note{ClientID: clientID, Author: "bob", Text: "called client", CreatedAt: base})
+	s.Require().NoError(err)
+	_, err = s.repo.CreateNote(s.ctx, &model.Note{ClientID: bson.NewObjectID().Hex(), Text: "other client", CreatedAt: base})
+	s.Require().NoError(err)
+	// stored after everything else, but linked and reviewed before it
+	res, err := s.storage.ArticleCollection().InsertOne(s.ctx, model.Article{Title: "Profile piece", Source: "Reuters"})
+	s.Require().NoError(err)
+	linked := res.InsertedID.(bson.ObjectID)
+	res, err = s.storage.ArticleCollection().InsertOne(s.ctx, model.Article{Title: "Old interview", Source: "FT"})
+	s.Require().NoError(err)
+	reviewed := res.InsertedID.(bson.ObjectID)
+	_, err = s.storage.FeedbackCollection().InsertOne(s.ctx, model.ArticleFeedback{ClientID: clientID, ArticleID: reviewed.Hex(), Relevant: true, Timestamp: base.Add(-5 * time.Hour)})
+	s.Require().NoError(err)
+
+	query := &model.GetActivityQuery{
+		Types:        model.ActivityTypes,
+		ArticleIDs:   []bson.ObjectID{linked, reviewed},
+		ArticleLinks: []model.ArticleLink{{ArticleID: linked, LinkedAt: base.Add(-4 * time.Hour)}},
+		Page:         1,
+		PageSize:     10,
+	}
+	items, total, err := s.repo.GetFeed(s.ctx, clientID, query)
+	s.Require().NoError(err)
+	s.Equal(6, total)
+	s.Require().Len(items, 6)
+	s.Equal(model.ActivityNote, items[0].Type)
+	s.Equal("called client", items[0].Summary)
+	s.Equal(model.ActivityChange, items[1].Type)
+	s.Equal(model.ActivityJob, items[2].Type)
+	s.Equal(model.ActivityLog, items[3].Type)
+	s.Equal(model.ActivityArticle, items[4].Type)
+	s.Equal(linked.Hex(), items[4].RefID)
+	s.Equal(base.Add(-4*time.Hour), items[4].Timestamp.UTC())
+	s.Equal(reviewed.Hex(), items[5].RefID)
+	s.Equal(base.Add(-5*time.Hour), items[5].Timestamp.UTC())
+
+	query = &model.GetActivityQuery{Types: []model.ActivityType{model.ActivityJob, model.ActivityLog}, Page: 2, PageSize: 1}
+	items, total, err = s.repo.GetFeed(s.ctx, clientID, query)
+	s.Require().NoError(err)
+	s.Equal(2, total)
+	s.Require().Len(items, 1)
+	s.Equal(model.ActivityLog, items[0].Type)
+}
+
+func TestActivityRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ActivityRepositorySuite))
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/owjoel/client-factpack/apps/clients/config"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// LogAnchorStore keeps anchors of the log chain outside the database holding the logs, so someone able to rewrite
+// the logs can't also rewrite their anchors. It also keeps the checkpoint the chain starts from once logs at its
+// start have been purged, which must be just as hard to rewrite.
+type LogAnchorStore interface {
+	Append(ctx context.Context, anchor *model.LogAnchor) error
+	Latest(ctx context.Context) (*model.LogAnchor, error)
+	AppendCheckpoint(ctx context.Context, checkpoint *model.LogCheckpoint) error
+	LatestCheckpoint(ctx context.Context) (*model.LogCheckpoint, error)
+}
+
+// InitLogAnchorStore opens the anchor store picked by AUDIT_ANCHOR_STORE: append-only files next to AUDIT_ANCHOR_FILE,
+// or collections in the separate Mongo deployment at AUDIT_ANCHOR_MONGO_URI
+func InitLogAnchorStore() LogAnchorStore {
+	switch config.AuditAnchorStore {
+	case "file":
+		return NewFileLogAnchorStore(config.AuditAnchorFile)
+	case "mongo":
+		if config.AuditAnchorMongoURI == "" {
+			log.Fatal("Set 'AUDIT_ANCHOR_MONGO_URI' to a deployment other than the logs' to keep anchors in Mongo.")
+		}
+		if config.AuditAnchorMongoURI == config.MongoURI {
+			log.Fatal("'AUDIT_ANCHOR_MONGO_URI' must not point at the deployment holding the logs.")
+		}
+		client, err := mongo.Connect(options.Client().ApplyURI(config.AuditAnchorMongoURI))
+		if err != nil {
+			panic(err)
+		}
+		db := client.Database(database)
+		return NewMongoLogAnchorStore(db.Collection(logAnchors), db.Collection(logCheckpoints))
+	default:
+		log.Fatalf("Unknown AUDIT_ANCHOR_STORE '%s', use 'file' or 'mongo'.", config.AuditAnchorStore)
+		return nil
+	}
+}
+
+type fileLogAnchorStore struct {
+	path           string
+	checkpointPath string
+}
+
+// NewFileLogAnchorStore appends anchors to the NDJSON file at path, and checkpoints to checkpoints.ndjson beside it.
+// Each file is created with its directory when the first line is stored. The files are only ever appended to, so
+// they can sit on write-once or append-only storage.
+func NewFileLogAnchorStore(path string) LogAnchorStore {
+	return &fileLogAnchorStore{path: path, checkpointPath: filepath.Join(filepath.Dir(path), "checkpoints.ndjson")}
+}
+
+// Append adds the anchor as a line to the file, anchoring a sequence number that is already anchored is a no-op
+func (s *fileLogAnchorStore) Append(ctx context.Context, anchor *model.LogAnchor) error {
+	return appendSeqLine(ctx, s.path, anchor.Seq, anchor)
+}
+
+// Latest returns the anchor with the highest sequence number, or nil if nothing was anchored yet
+func (s *fileLogAnchorStore) Latest(ctx context.Context) (*model.LogAnchor, error) {
+	line, err := latestSeqLine(ctx, s.path)
+	if err != nil || line == nil {
+		return nil, err
+	}
+	var anchor model.LogAnchor
+	if err := json.Unmarshal(line, &anchor); err != nil {
+		return nil, fmt.Errorf("%w: anchor file is corrupt", errorx.ErrInternal)
+	}
+	return &anchor, nil
+}
+
+// AppendCheckpoint adds the checkpoint as a line to the checkpoint file, unless the chain was already purged past it
+func (s *fileLogAnchorStore) AppendCheckpoint(ctx context.Context, checkpoint *model.LogCheckpoint) error {
+	return appendSeqLine(ctx, s.checkpointPath, checkpoint.Seq, checkpoint)
+}
+
+// LatestCheckpoint returns the checkpoint with the highest sequence number, or nil if no log was purged yet
+func (s *fileLogAnchorStore) LatestCheckpoint(ctx context.Context) (*model.LogCheckpoint, error) {
+	line, err := latestSeqLine(ctx, s.checkpointPath)
+	if err != nil || line == nil {
+		return nil, err
+	}
+	var checkpoint model.LogCheckpoint
+	if err := json.Unmarshal(line, &checkpoint); err != nil {
+		return nil, fmt.Errorf("%w: anchor file is corrupt", errorx.ErrInternal)
+	}
+	return &checkpoint, nil
+}
+
+// appendSeqLine appends v as a line to the NDJSON file at path, unless the file already has a line with a sequence
+// number of seq or higher
+func appendSeqLine(ctx context.Context, path string, seq int64, v any) error {
+	latest, err := latestSeqLine(ctx, path)
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		var head struct {
+			Seq int64 `json:"seq"`
+		}
+		if err := json.Unmarshal(latest, &head); err != nil {
+			return fmt.Errorf("%w: anchor file is corrupt", errorx.ErrInternal)
+		}
+		if head.Seq >= seq {
+			return nil
+		}
+	}
+
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%w: error encoding log anchor", errorx.ErrInternal)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("%w: error creating anchor directory", errorx.ErrDependencyFailed)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("%w: error opening anchor file", errorx.ErrDependencyFailed)
+	}
+	// a single write, so concurrent appends never interleave within a line
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("%w: error writing log anchor", errorx.ErrDependencyFailed)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("%w: error writing log anchor", errorx.ErrDependencyFailed)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("%w: error writing log anchor", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+// latestSeqLine returns the line of the NDJSON file at path with the highest sequence number, or nil if the file
+// doesn't exist yet
+func latestSeqLine(ctx context.Context, path string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: error opening anchor file", errorx.ErrDependencyFailed)
+	}
+	defer f.Close()
+
+	var latest []byte
+	var latestSeq int64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line struct {
+			Seq int64 `json:"seq"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("%w: anchor file is corrupt", errorx.ErrInternal)
+		}
+		if latest == nil || line.Seq > latestSeq {
+			latest, latestSeq = bytes.Clone(scanner.Bytes()), line.Seq
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error reading anchor file", errorx.ErrDependencyFailed)
+	}
+	return latest, nil
+}
+
+type mongoLogAnchorStore struct {
+	logAnchorCollection     *mongo.Collection
+	logCheckpointCollection *mongo.Collection
+}
+
+// NewMongoLogAnchorStore keeps anchors in anchors and checkpoints in checkpoints, which belong in a deployment other
+// than the logs'
+func NewMongoLogAnchorStore(anchors *mongo.Collection, checkpoints *mongo.Collection) LogAnchorStore {
+	return &mongoLogAnchorStore{logAnchorCollection: anchors, logCheckpointCollection: checkpoints}
+}
+
+// Append stores the anchor, anchoring a sequence number that is already anchored is a no-op
+func (s *mongoLogAnchorStore) Append(ctx context.Context, anchor *model.LogAnchor) error {
+	if _, err := s.logAnchorCollection.InsertOne(ctx, anchor); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return fmt.Errorf("%w: insert failed", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+// Latest returns the anchor with the highest sequence number, or nil if nothing was anchored yet
+func (s *mongoLogAnchorStore) Latest(ctx context.Context) (*model.LogAnchor, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	var anchor model.LogAnchor
+	if err := s.logAnchorCollection.FindOne(ctx, bson.D{}, opts).Decode(&anchor); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: error finding latest log anchor", errorx.ErrDependencyFailed)
+	}
+	return &anchor, nil
+}
+
+// AppendCheckpoint stores the checkpoint, storing one for a sequence number that already has one is a no-op
+func (s *mongoLogAnchorStore) AppendCheckpoint(ctx context.Context, checkpoint *model.LogCheckpoint) error {
+	if _, err := s.logCheckpointCollection.InsertOne(ctx, checkpoint); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return fmt.Errorf("%w: insert failed", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+// LatestCheckpoint returns the checkpoint with the highest sequence number, or nil if no log was purged yet
+func (s *mongoLogAnchorStore) LatestCheckpoint(ctx context.Context) (*model.LogCheckpoint, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	var checkpoint model.LogCheckpoint
+	if err := s.logCheckpointCollection.FindOne(ctx, bson.D{}, opts).Decode(&checkpoint); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: error finding latest log checkpoint", errorx.ErrDependencyFailed)
+	}
+	return &checkpoint, nil
+}
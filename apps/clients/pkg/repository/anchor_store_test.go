@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+func TestFileLogAnchorStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anchors", "anchors.ndjson")
+	store := repository.NewFileLogAnchorStore(path)
+
+	latest, err := store.Latest(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	assert.NoError(t, store.Append(context.TODO(), &model.LogAnchor{Seq: 10, Hash: "ten", AnchoredAt: now}))
+	assert.NoError(t, store.Append(context.TODO(), &model.LogAnchor{Seq: 25, Hash: "twenty-five", AnchoredAt: now}))
+	// anchoring the same head twice keeps the first anchor
+	assert.NoError(t, store.Append(context.TODO(), &model.LogAnchor{Seq: 25, Hash: "other", AnchoredAt: now}))
+
+	latest, err = store.Latest(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, &model.LogAnchor{Seq: 25, Hash: "twenty-five", AnchoredAt: now}, latest)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+}
+
+func TestFileLogAnchorStore_Checkpoints(t *testing.T) {
+	dir := t.TempDir()
+	store := repository.NewFileLogAnchorStore(filepath.Join(dir, "anchors.ndjson"))
+
+	latest, err := store.LatestCheckpoint(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	assert.NoError(t, store.AppendCheckpoint(context.TODO(), &model.LogCheckpoint{Seq: 10, Hash: "ten", PurgedAt: now}))
+	assert.NoError(t, store.AppendCheckpoint(context.TODO(), &model.LogCheckpoint{Seq: 25, Hash: "twenty-five", PurgedAt: now}))
+
+	latest, err = store.LatestCheckpoint(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, &model.LogCheckpoint{Seq: 25, Hash: "twenty-five", PurgedAt: now}, latest)
+
+	// checkpoints are kept apart from anchors
+	anchor, err := store.Latest(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, anchor)
+	_, err = os.Stat(filepath.Join(dir, "checkpoints.ndjson"))
+	assert.NoError(t, err)
+}
+
+func TestFileLogAnchorStore_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anchors.ndjson")
+	assert.NoError(t, os.WriteFile(path, []byte("not json\n"), 0o600))
+
+	_, err := repository.NewFileLogAnchorStore(path).Latest(context.TODO())
+	assert.ErrorIs(t, err, errorx.ErrInternal)
+}
+
+func TestMongoLogAnchorStore(t *testing.T) {
+	storage, cleanup := repository.NewTestMongoStorage(t)
+	defer cleanup()
+
+	store := repository.NewMongoLogAnchorStore(storage.Collection("logAnchors"), storage.Collection("logCheckpoints"))
+
+	latest, err := store.Latest(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	assert.NoError(t, store.Append(context.TODO(), &model.LogAnchor{Seq: 10, Hash: "ten", AnchoredAt: now}))
+	assert.NoError(t, store.Append(context.TODO(), &model.LogAnchor{Seq: 25, Hash: "twenty-five", AnchoredAt: now}))
+	assert.NoError(t, store.Append(context.TODO(), &model.LogAnchor{Seq: 25, Hash: "other", AnchoredAt: now}))
+
+	latest, err = store.Latest(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, &model.LogAnchor{Seq: 25, Hash: "twenty-five", AnchoredAt: now}, latest)
+}
+
+func TestMongoLogAnchorStore_Checkpoints(t *testing.T) {
+	storage, cleanup := repository.NewTestMongoStorage(t)
+	defer cleanup()
+
+	store := repository.NewMongoLogAnchorStore(storage.Collection("logAnchors"), storage.Collection("logCheckpoints"))
+
+	latest, err := store.LatestCheckpoint(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	assert.NoError(t, store.AppendCheckpoint(context.TODO(), &model.LogCheckpoint{Seq: 10, Hash: "ten", PurgedAt: now}))
+	assert.NoError(t, store.AppendCheckpoint(context.TODO(), &model.LogCheckpoint{Seq: 25, Hash: "twenty-five", PurgedAt: now}))
+
+	latest, err = store.LatestCheckpoint(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, &model.LogCheckpoint{Seq: 25, Hash: "twenty-five", PurgedAt: now}, latest)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+)
+
+// ArchiveStore keeps archived logs outside Mongo
+type ArchiveStore interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+type fileArchiveStore struct {
+	dir string
+}
+
+// NewFileArchiveStore stores archives as files in dir, which is created when the first archive is stored
+func NewFileArchiveStore(dir string) ArchiveStore {
+	return &fileArchiveStore{dir: dir}
+}
+
+// Put writes the archive to a temporary file before moving it into place, so an archive is never seen half written.
+// An existing archive is never replaced.
+func (s *fileArchiveStore) Put(ctx context.Context, name string, r io.Reader) error {
+	if err := validArchiveName(name); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("%w: error creating archive directory", errorx.ErrDependencyFailed)
+	}
+
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%w: archive '%s' already exists", errorx.ErrConflict, name)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "."+name+".*")
+	if err != nil {
+		return fmt.Errorf("%w: error creating archive", errorx.ErrDependencyFailed)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%w: error writing archive", errorx.ErrDependencyFailed)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%w: error writing archive", errorx.ErrDependencyFailed)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%w: error writing archive", errorx.ErrDependencyFailed)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("%w: error storing archive", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+// Get opens the named archive, returning ErrNotFound if there is no such archive
+func (s *fileArchiveStore) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	if err := validArchiveName(name); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no archive named '%s'", errorx.ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: error opening archive", errorx.ErrDependencyFailed)
+	}
+	return f, nil
+}
+
+// validArchiveName keeps archives inside the store's directory
+func validArchiveName(name string) error {
+	if name == "" || name != filepath.Base(name) || name[0] == '.' {
+		return fmt.Errorf("%w: invalid archive name '%s'", errorx.ErrInvalidInput, name)
+	}
+	return nil
+}
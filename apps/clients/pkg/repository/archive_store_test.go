@@ -0,0 +1,60 @@
+package repository_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+func TestFileArchiveStore_Put(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	store := repository.NewFileArchiveStore(dir)
+
+	err := store.Put(context.TODO(), "logs-1.ndjson.gz", strings.NewReader("archived"))
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "logs-1.ndjson.gz"))
+	assert.NoError(t, err)
+	assert.Equal(t, "archived", string(data))
+
+	// archives are never replaced, and no temporary files are left behind
+	err = store.Put(context.TODO(), "logs-1.ndjson.gz", strings.NewReader("replaced"))
+	assert.ErrorIs(t, err, errorx.ErrConflict)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileArchiveStore_Get(t *testing.T) {
+	store := repository.NewFileArchiveStore(t.TempDir())
+	assert.NoError(t, store.Put(context.TODO(), "logs-1.ndjson.gz", strings.NewReader("archived")))
+
+	r, err := store.Get(context.TODO(), "logs-1.ndjson.gz")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "archived", string(data))
+
+	_, err = store.Get(context.TODO(), "logs-2.ndjson.gz")
+	assert.ErrorIs(t, err, errorx.ErrNotFound)
+	_, err = store.Get(context.TODO(), "../logs-1.ndjson.gz")
+	assert.ErrorIs(t, err, errorx.ErrInvalidInput)
+}
+
+func TestFileArchiveStore_InvalidName(t *testing.T) {
+	store := repository.NewFileArchiveStore(t.TempDir())
+
+	for _, name := range []string{"", "../logs.ndjson.gz", "nested/logs.ndjson.gz", ".hidden"} {
+		err := store.Put(context.TODO(), name, strings.NewReader("archived"))
+		assert.ErrorIs(t, err, errorx.ErrInvalidInput, name)
+	}
+}
@@ -3,11 +3,14 @@ package repository
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"time"
 
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type mongoArticleRepository struct {
@@ -20,6 +23,12 @@ func NewMongoArticleRepository(storage *MongoStorage) ArticleRepository {
 
 type ArticleRepository interface {
 	GetAll(ctx context.Context, query *model.GetArticlesReq) ([]model.Article, error)
+	GetOne(ctx context.Context, articleID string) (*model.Article, error)
+	GetByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) ([]model.Article, error)
+	CountByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) (int, error)
+	CountSourcesByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) (map[string]int, error)
+	GetSentimentBuckets(ctx context.Context, ids []bson.ObjectID, since time.Time) ([]model.SentimentBucket, error)
+	UpsertByURL(ctx context.Context, a *model.Article) (id bson.ObjectID, inserted bool, err error)
 }
 
 func (r *mongoArticleRepository) GetAll(ctx context.Context, query *model.GetArticlesReq) ([]model.Article, error) {
@@ -49,3 +58,175 @@ func (r *mongoArticleRepository) GetAll(ctx context.Context, query *model.GetArt
 
 	return articles, nil
 }
+
+func (r *mongoArticleRepository) GetOne(ctx context.Context, articleID string) (*model.Article, error) {
+	objID, err := bson.ObjectIDFromHex(articleID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error parsing object id", errorx.ErrInvalidInput)
+	}
+
+	var article model.Article
+	err = r.articleCollection.FindOne(ctx, bson.D{{Key: "_id", Value: objID}}).Decode(&article)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: no article with id %s", errorx.ErrNotFound, articleID)
+		}
+		return nil, fmt.Errorf("%w: error occurred while finding article", errorx.ErrDependencyFailed)
+	}
+
+	return &article, nil
+}
+
+func (r *mongoArticleRepository) GetByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) ([]model.Article, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 10
+	}
+	skip := (query.Page - 1) * query.PageSize
+
+	order := -1
+	if query.Sort == "asc" {
+		order = 1
+	}
+
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(query.PageSize)).
+		SetSort(bson.D{{Key: "publishedAt", Value: order}, {Key: "_id", Value: order}})
+
+	cursor, err := r.articleCollection.Find(ctx, articlesFilter(ids, query, true), opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	articles := []model.Article{}
+	if err := cursor.All(ctx, &articles); err != nil {
+		return nil, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+
+	return articles, nil
+}
+
+func (r *mongoArticleRepository) CountByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) (int, error) {
+	count, err := r.articleCollection.CountDocuments(ctx, articlesFilter(ids, query, true))
+	if err != nil {
+		return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+	}
+	return int(count), nil
+}
+
+// CountSourcesByIDs counts matching articles per source. The source filter itself is ignored so every source stays selectable.
+func (r *mongoArticleRepository) CountSourcesByIDs(ctx context.Context, ids []bson.ObjectID, query *model.GetClientArticlesQuery) (map[string]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: articlesFilter(ids, query, false)}},
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$source"}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+	}
+
+	cursor, err := r.articleCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Source string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Source] = row.Count
+	}
+	return counts, nil
+}
+
+// publishedAtExpr falls back to the insertion time for articles stored before publish dates were recorded
+var publishedAtExpr = bson.D{{Key: "$ifNull", Value: bson.A{"$publishedAt", bson.D{{Key: "$toDate", Value: "$_id"}}}}}
+
+// GetSentimentBuckets groups the given articles published since the cutoff by day and sentiment label
+func (r *mongoArticleRepository) GetSentimentBuckets(ctx context.Context, ids []bson.ObjectID, since time.Time) ([]model.SentimentBucket, error) {
+	signedScore := bson.D{{Key: "$switch", Value: bson.D{
+		{Key: "branches", Value: bson.A{
+			bson.D{{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$sentiment.label", model.SentimentPositive}}}}, {Key: "then", Value: "$sentiment.score"}},
+			bson.D{{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$sentiment.label", model.SentimentNegative}}}}, {Key: "then", Value: bson.D{{Key: "$multiply", Value: bson.A{-1, "$sentiment.score"}}}}},
+		}},
+		{Key: "default", Value: 0},
+	}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}, {Key: "sentiment.label", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+		{{Key: "$addFields", Value: bson.D{{Key: "effectiveDate", Value: publishedAtExpr}}}},
+		{{Key: "$match", Value: bson.D{{Key: "effectiveDate", Value: bson.D{{Key: "$gte", Value: since}}}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "day", Value: bson.D{{Key: "$dateTrunc", Value: bson.D{{Key: "date", Value: "$effectiveDate"}, {Key: "unit", Value: "day"}}}}},
+				{Key: "label", Value: "$sentiment.label"},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "scoreSum", Value: bson.D{{Key: "$sum", Value: signedScore}}},
+		}}},
+		{{Key: "$project", Value: bson.D{{Key: "_id", Value: 0}, {Key: "day", Value: "$_id.day"}, {Key: "label", Value: "$_id.label"}, {Key: "count", Value: 1}, {Key: "scoreSum", Value: 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "day", Value: 1}}}},
+	}
+
+	cursor, err := r.articleCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	buckets := []model.SentimentBucket{}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+	return buckets, nil
+}
+
+func articlesFilter(ids []bson.ObjectID, query *model.GetClientArticlesQuery, withSource bool) bson.M {
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	if withSource && query.Source != "" {
+		filter["source"] = query.Source
+	}
+	if query.Keyword != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(query.Keyword), "$options": "i"}
+		filter["$or"] = bson.A{bson.M{"title": pattern}, bson.M{"summary": pattern}}
+	}
+	return filter
+}
+
+// UpsertByURL inserts the article unless one with the same canonical URL already exists, in which case the existing id is returned
+func (r *mongoArticleRepository) UpsertByURL(ctx context.Context, a *model.Article) (bson.ObjectID, bool, error) {
+	if a.CanonicalURL == "" {
+		return bson.NilObjectID, false, fmt.Errorf("%w: article has no canonical url", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "canonicalUrl", Value: a.CanonicalURL}}
+	update := bson.D{{Key: "$setOnInsert", Value: a}}
+
+	result, err := r.articleCollection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) { // a concurrent ingest of the same url won the insert
+		return bson.NilObjectID, false, fmt.Errorf("%w: mongo upsert error", errorx.ErrDependencyFailed)
+	}
+	if err == nil && result.UpsertedID != nil {
+		id, ok := result.UpsertedID.(bson.ObjectID)
+		if !ok {
+			return bson.NilObjectID, false, fmt.Errorf("%w: failed to cast upserted ID to ObjectID", errorx.ErrInternal)
+		}
+		return id, true, nil
+	}
+
+	var existing struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	opts := options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}})
+	if err := r.articleCollection.FindOne(ctx, filter, opts).Decode(&existing); err != nil {
+		return bson.NilObjectID, false, fmt.Errorf("%w: error finding existing article", errorx.ErrDependencyFailed)
+	}
+	return existing.ID, false, nil
+}
@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
@@ -56,6 +57,83 @@ func (s *ArticleRepositorySuite) TestGetAll() {
 	s.ElementsMatch([]string{"A1", "A2"}, []string{fetched[0].Title, fetched[1].Title})
 }
 
+func (s *ArticleRepositorySuite) TestGetByIDs() {
+	now := time.Now().UTC()
+	articles := []model.Article{
+		{Source: "Reuters", Title: "Merger announced", PublishedAt: now.Add(-2 * time.Hour)},
+		{Source: "Reuters", Title: "Quarterly results", PublishedAt: now.Add(-time.Hour)},
+		{Source: "Bloomberg", Title: "Merger talks", PublishedAt: now},
+		{Source: "Bloomberg", Title: "Unlinked merger", PublishedAt: now},
+	}
+
+	res, err := s.storage.ArticleCollection().InsertMany(s.ctx, articles)
+	s.Require().NoError(err)
+
+	var ids []bson.ObjectID
+	for _, id := range res.InsertedIDs[:3] {
+		ids = append(ids, id.(bson.ObjectID))
+	}
+
+	query := &model.GetClientArticlesQuery{Keyword: "merger", Page: 1, PageSize: 10}
+	fetched, err := s.repo.GetByIDs(s.ctx, ids, query)
+	s.Require().NoError(err)
+	s.Len(fetched, 2)
+	s.Equal("Merger talks", fetched[0].Title)
+
+	count, err := s.repo.CountByIDs(s.ctx, ids, query)
+	s.Require().NoError(err)
+	s.Equal(2, count)
+
+	query.Source = "Reuters"
+	sources, err := s.repo.CountSourcesByIDs(s.ctx, ids, query)
+	s.Require().NoError(err)
+	s.Equal(map[string]int{"Reuters": 1, "Bloomberg": 1}, sources)
+}
+
+func (s *ArticleRepositorySuite) TestSentimentAggregation() {
+	now := time.Now().UTC()
+	articles := []model.Article{
+		{Title: "Good", PublishedAt: now, Sentiment: &model.Sentiment{Label: model.SentimentPositive, Score: 0.8}},
+		{Title: "Bad", PublishedAt: now, Sentiment: &model.Sentiment{Label: model.SentimentNegative, Score: 0.9}},
+		{Title: "Old bad", PublishedAt: now.AddDate(0, 0, -60), Sentiment: &model.Sentiment{Label: model.SentimentNegative, Score: 0.7}},
+	}
+
+	res, err := s.storage.ArticleCollection().InsertMany(s.ctx, articles)
+	s.Require().NoError(err)
+
+	var ids []bson.ObjectID
+	for _, id := range res.InsertedIDs {
+		ids = append(ids, id.(bson.ObjectID))
+	}
+
+	since := now.AddDate(0, 0, -30)
+	buckets, err := s.repo.GetSentimentBuckets(s.ctx, ids, since)
+	s.Require().NoError(err)
+	s.Len(buckets, 2)
+	for _, b := range buckets {
+		if b.Label == model.SentimentNegative {
+			s.InDelta(-0.9, b.ScoreSum, 0.001)
+		}
+	}
+}
+
+func (s *ArticleRepositorySuite) TestUpsertByURL() {
+	article := &model.Article{Title: "A1", URL: "https://www.example.com/a/", CanonicalURL: "https://example.com/a", PublishedAt: time.Now().UTC()}
+
+	id, inserted, err := s.repo.UpsertByURL(s.ctx, article)
+	s.Require().NoError(err)
+	s.True(inserted)
+
+	again, inserted, err := s.repo.UpsertByURL(s.ctx, &model.Article{Title: "A1 (syndicated)", URL: "https://example.com/a", CanonicalURL: "https://example.com/a"})
+	s.Require().NoError(err)
+	s.False(inserted)
+	s.Equal(id, again)
+
+	count, err := s.storage.ArticleCollection().CountDocuments(s.ctx, bson.M{})
+	s.Require().NoError(err)
+	s.Equal(int64(1), count)
+}
+
 func TestArticleRepositorySuite(t *testing.T) {
 	suite.Run(t, new(ArticleRepositorySuite))
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
@@ -28,6 +29,14 @@ type ClientRepository interface {
 	Count(ctx context.Context, query *model.GetClientsQuery) (int, error)
 	Update(ctx context.Context, clientID string, update bson.D) error
 	GetClientNameByID(ctx context.Context, clientID string) (string, error)
+	UpsertByID(ctx context.Context, c *model.Client) (inserted bool, err error)
+	UpsertByName(ctx context.Context, c *model.Client) (inserted bool, err error)
+	ForEach(ctx context.Context, fn func(c *model.Client) error) error
+	AddArticle(ctx context.Context, clientID string, articleID bson.ObjectID) error
+	RemoveArticle(ctx context.Context, clientID string, articleID bson.ObjectID) error
+	AddDocument(ctx context.Context, clientID string, doc *model.ClientDocument) error
+	SetLegalHold(ctx context.Context, clientID string, hold *model.LegalHold) error
+	GetHeldClientIDs(ctx context.Context) ([]string, error)
 }
 
 func (r *mongoClientRepository) Create(ctx context.Context, c *model.Client) (string, error) {
@@ -56,23 +65,31 @@ func (s *mongoClientRepository) GetAll(ctx context.Context, query *model.GetClie
 	}
 	skip := (query.Page - 1) * query.PageSize
 
-	filter := bson.M{}
-	if query.Name != "" {
-		filter["data.profile.names"] = bson.M{
-			"$regex":   query.Name,
-			"$options": "i",
-		}
-	}
+	filter := clientsFilter(query)
 
-	opts := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(query.PageSize))
+	var cursor *mongo.Cursor
+	var err error
+	if query.NegativePressSince != nil {
+		pipeline := append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, negativePressStages(*query.NegativePressSince)...)
+		if query.Sort {
+			pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{{Key: "metadata.updatedAt", Value: -1}}}})
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$skip", Value: int64(skip)}},
+			bson.D{{Key: "$limit", Value: int64(query.PageSize)}},
+		)
+		cursor, err = s.clientCollection.Aggregate(ctx, pipeline)
+	} else {
+		opts := options.Find().
+			SetSkip(int64(skip)).
+			SetLimit(int64(query.PageSize))
+
+		if query.Sort {
+			opts.SetSort(bson.D{{Key: "metadata.updatedAt", Value: -1}})
+		}
 
-	if query.Sort {
-		opts.SetSort(bson.D{{Key: "metadata.updatedAt", Value: -1}})
+		cursor, err = s.clientCollection.Find(ctx, filter, opts)
 	}
-
-	cursor, err := s.clientCollection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
 	}
@@ -107,20 +124,34 @@ func (s *mongoClientRepository) GetOne(ctx context.Context, clientID string) (*m
 }
 
 func (s *mongoClientRepository) Count(ctx context.Context, query *model.GetClientsQuery) (int, error) {
-	filter := bson.M{}
+	filter := clientsFilter(query)
 
-	if query.Name != "" {
-		filter["data.profile.names"] = bson.M{
-			"$regex":   query.Name,
-			"$options": "i",
+	if query.NegativePressSince == nil {
+		count, err := s.clientCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
 		}
+		return int(count), nil
 	}
 
-	count, err := s.clientCollection.CountDocuments(ctx, filter)
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, negativePressStages(*query.NegativePressSince)...)
+	pipeline = append(pipeline, bson.D{{Key: "$count", Value: "total"}})
+	cursor, err := s.clientCollection.Aggregate(ctx, pipeline)
 	if err != nil {
-		return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+		return 0, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Total int `bson:"total"`
 	}
-	return int(count), nil
+	if err := cursor.All(ctx, &rows); err != nil {
+		return 0, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].Total, nil
 }
 
 func (s *mongoClientRepository) Update(ctx context.Context, clientID string, update bson.D) error {
@@ -175,3 +206,303 @@ func (s *mongoClientRepository) GetClientNameByID(ctx context.Context, clientID
 
 	return result.Data.Profile.Names[0], nil
 }
+
+// UpsertByID overwrites the data and metadata of the client with the same ID, inserting it if it does not exist yet
+func (s *mongoClientRepository) UpsertByID(ctx context.Context, c *model.Client) (bool, error) {
+	if c.ID.IsZero() {
+		return false, fmt.Errorf("%w: client has no id", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "_id", Value: c.ID}}
+	result, err := s.clientCollection.UpdateOne(ctx, filter, importUpdate(c), options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return false, fmt.Errorf("%w: mongo upsert error", errorx.ErrDependencyFailed)
+	}
+
+	return result.UpsertedCount > 0, nil
+}
+
+// UpsertByName overwrites the data and metadata of the client whose names contain the primary name of c, inserting
+// it if none match. A name shared by several clients is a conflict, since overwriting any one of them could be the
+// wrong one. The client found is overwritten only if it still has the name, and a client inserted by someone else
+// in the meantime is overwritten rather than duplicated.
+func (s *mongoClientRepository) UpsertByName(ctx context.Context, c *model.Client) (bool, error) {
+	name, ok := primaryName(c.Data)
+	if !ok {
+		return false, fmt.Errorf("%w: client has no name", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "data.profile.names", Value: name}}
+	cursor, err := s.clientCollection.Find(ctx, filter, options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}).SetLimit(2))
+	if err != nil {
+		return false, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+	var matches []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &matches); err != nil {
+		return false, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+
+	switch len(matches) {
+	case 0:
+		result, err := s.clientCollection.UpdateOne(ctx, filter, importUpdate(c), options.UpdateOne().SetUpsert(true))
+		if err != nil {
+			return false, fmt.Errorf("%w: mongo upsert error", errorx.ErrDependencyFailed)
+		}
+		return result.UpsertedCount > 0, nil
+	case 1:
+		byID := bson.D{{Key: "_id", Value: matches[0].ID}, {Key: "data.profile.names", Value: name}}
+		result, err := s.clientCollection.UpdateOne(ctx, byID, importUpdate(c))
+		if err != nil {
+			return false, fmt.Errorf("%w: mongo update error", errorx.ErrDependencyFailed)
+		}
+		if result.MatchedCount == 0 {
+			return false, fmt.Errorf("%w: client named '%s' changed during the import, try again", errorx.ErrConflict, name)
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: more than one client is named '%s', import by id instead", errorx.ErrConflict, name)
+	}
+}
+
+// importUpdate overwrites what an import owns, the client's data and metadata. Articles and documents are only
+// imported with a new client, and a legal hold never is, since holds are placed and lifted through SetLegalHold so
+// that they are audited.
+func importUpdate(c *model.Client) bson.D {
+	onInsert := bson.D{}
+	if c.Articles != nil {
+		onInsert = append(onInsert, bson.E{Key: "articles", Value: c.Articles})
+	}
+	if c.ArticleLinks != nil {
+		onInsert = append(onInsert, bson.E{Key: "articleLinks", Value: c.ArticleLinks})
+	}
+	if c.Documents != nil {
+		onInsert = append(onInsert, bson.E{Key: "documents", Value: c.Documents})
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "data", Value: c.Data},
+		{Key: "metadata", Value: c.Metadata},
+	}}}
+	if len(onInsert) > 0 {
+		update = append(update, bson.E{Key: "$setOnInsert", Value: onInsert})
+	}
+	return update
+}
+
+// ForEach streams every client in the collection to fn, stopping at the first error
+func (s *mongoClientRepository) ForEach(ctx context.Context, fn func(c *model.Client) error) error {
+	cursor, err := s.clientCollection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var client model.Client
+		if err := cursor.Decode(&client); err != nil {
+			return fmt.Errorf("%w: decode error", errorx.ErrInternal)
+		}
+		if err := fn(&client); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("%w: mongo cursor error", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+// AddArticle links an article to a client and records when, linking the same article twice is a no-op
+func (s *mongoClientRepository) AddArticle(ctx context.Context, clientID string, articleID bson.ObjectID) error {
+	objID, err := bson.ObjectIDFromHex(clientID)
+	if err != nil {
+		return fmt.Errorf("%w: error parsing object id", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "_id", Value: objID}, {Key: "articles", Value: bson.D{{Key: "$ne", Value: articleID}}}}
+	update := bson.D{{Key: "$push", Value: bson.D{
+		{Key: "articles", Value: articleID},
+		{Key: "articleLinks", Value: model.ArticleLink{ArticleID: articleID, LinkedAt: time.Now()}},
+	}}}
+
+	result, err := s.clientCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%w: mongo update error", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	// the article is already linked, unless there is no such client
+	count, err := s.clientCollection.CountDocuments(ctx, bson.D{{Key: "_id", Value: objID}})
+	if err != nil {
+		return fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: no client with id %s", errorx.ErrNotFound, clientID)
+	}
+	return nil
+}
+
+// RemoveArticle unlinks an article from a client, returning ErrNotFound if it was not linked
+func (s *mongoClientRepository) RemoveArticle(ctx context.Context, clientID string, articleID bson.ObjectID) error {
+	objID, err := bson.ObjectIDFromHex(clientID)
+	if err != nil {
+		return fmt.Errorf("%w: error parsing object id", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "_id", Value: objID}, {Key: "articles", Value: articleID}}
+	update := bson.D{{Key: "$pull", Value: bson.D{
+		{Key: "articles", Value: articleID},
+		{Key: "articleLinks", Value: bson.D{{Key: "articleId", Value: articleID}}},
+	}}}
+
+	result, err := s.clientCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%w: mongo update error", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: article %s is not linked to client %s", errorx.ErrNotFound, articleID.Hex(), clientID)
+	}
+	return nil
+}
+
+// AddDocument links an uploaded document to a client, linking the same match job's document twice is a no-op
+func (s *mongoClientRepository) AddDocument(ctx context.Context, clientID string, doc *model.ClientDocument) error {
+	objID, err := bson.ObjectIDFromHex(clientID)
+	if err != nil {
+		return fmt.Errorf("%w: error parsing object id", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "_id", Value: objID}, {Key: "documents.jobId", Value: bson.D{{Key: "$ne", Value: doc.JobID}}}}
+	update := bson.D{{Key: "$push", Value: bson.D{{Key: "documents", Value: doc}}}}
+
+	result, err := s.clientCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%w: mongo update error", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	count, err := s.clientCollection.CountDocuments(ctx, bson.D{{Key: "_id", Value: objID}})
+	if err != nil {
+		return fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: no client with id %s", errorx.ErrNotFound, clientID)
+	}
+	return nil
+}
+
+// SetLegalHold places the hold on the client, or lifts the client's hold if hold is nil
+func (s *mongoClientRepository) SetLegalHold(ctx context.Context, clientID string, hold *model.LegalHold) error {
+	objID, err := bson.ObjectIDFromHex(clientID)
+	if err != nil {
+		return fmt.Errorf("%w: error parsing object id", errorx.ErrInvalidInput)
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "legalHold", Value: hold}}}}
+	if hold == nil {
+		update = bson.D{{Key: "$unset", Value: bson.D{{Key: "legalHold", Value: ""}}}}
+	}
+
+	result, err := s.clientCollection.UpdateOne(ctx, bson.D{{Key: "_id", Value: objID}}, update)
+	if err != nil {
+		return fmt.Errorf("%w: mongo update error", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: no client with id %s", errorx.ErrNotFound, clientID)
+	}
+	return nil
+}
+
+// GetHeldClientIDs returns the IDs of the clients under a legal hold, as hex strings like the client IDs on logs
+func (s *mongoClientRepository) GetHeldClientIDs(ctx context.Context) ([]string, error) {
+	filter := bson.D{{Key: "legalHold", Value: bson.D{{Key: "$exists", Value: true}}}}
+	opts := options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := s.clientCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+
+	var held []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &held); err != nil {
+		return nil, fmt.Errorf("%w: mongo decode error", errorx.ErrInternal)
+	}
+
+	ids := make([]string, 0, len(held))
+	for _, c := range held {
+		ids = append(ids, c.ID.Hex())
+	}
+	return ids, nil
+}
+
+func clientsFilter(query *model.GetClientsQuery) bson.M {
+	filter := bson.M{}
+	if query.Name != "" {
+		filter["data.profile.names"] = bson.M{
+			"$regex":   query.Name,
+			"$options": "i",
+		}
+	}
+	if query.NegativePressSince != nil {
+		// clients without articles can't have negative press, so they are left out before the lookup
+		filter["articles.0"] = bson.M{"$exists": true}
+	}
+	return filter
+}
+
+// negativePressStages keeps clients with a negative article published since the cutoff. The articles are looked up
+// per client, stopping at the first match, so the ids of every negative article never have to be collected.
+func negativePressStages(since time.Time) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: article},
+			{Key: "let", Value: bson.D{{Key: "articles", Value: "$articles"}}},
+			{Key: "pipeline", Value: mongo.Pipeline{
+				{{Key: "$match", Value: bson.D{
+					{Key: "sentiment.label", Value: model.SentimentNegative},
+					{Key: "$expr", Value: bson.D{{Key: "$and", Value: bson.A{
+						bson.D{{Key: "$in", Value: bson.A{"$_id", "$$articles"}}},
+						bson.D{{Key: "$gte", Value: bson.A{publishedAtExpr, since}}},
+					}}}},
+				}}},
+				{{Key: "$limit", Value: 1}},
+				{{Key: "$project", Value: bson.D{{Key: "_id", Value: 1}}}},
+			}},
+			{Key: "as", Value: "negativePress"},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "negativePress", Value: bson.D{{Key: "$ne", Value: bson.A{}}}}}}},
+		{{Key: "$project", Value: bson.D{{Key: "negativePress", Value: 0}}}},
+	}
+}
+
+func primaryName(data bson.D) (string, bool) {
+	for _, elem := range data {
+		if elem.Key != "profile" {
+			continue
+		}
+		profile, ok := elem.Value.(bson.D)
+		if !ok {
+			return "", false
+		}
+		for _, p := range profile {
+			if p.Key != "names" {
+				continue
+			}
+			names, ok := p.Value.(bson.A)
+			if !ok || len(names) == 0 {
+				return "", false
+			}
+			name, ok := names[0].(string)
+			return name, ok && name != ""
+		}
+	}
+	return "", false
+}
@@ -3,10 +3,12 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"go.mongodb.org/mongo-driver/v2/bson"
 
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
 )
@@ -81,6 +83,52 @@ func (s *ClientRepositorySuite) TestGetAllAndCount() {
 	s.Equal(1, count)
 }
 
+func (s *ClientRepositorySuite) TestGetAllNegativePress() {
+	_, err := s.storage.ArticleCollection().DeleteMany(s.ctx, bson.D{})
+	s.Require().NoError(err)
+
+	now := time.Now().UTC()
+	res, err := s.storage.ArticleCollection().InsertMany(s.ctx, []model.Article{
+		{Title: "Bad", PublishedAt: now, Sentiment: &model.Sentiment{Label: model.SentimentNegative, Score: 0.9}},
+		{Title: "Old bad", PublishedAt: now.AddDate(0, 0, -60), Sentiment: &model.Sentiment{Label: model.SentimentNegative, Score: 0.7}},
+		{Title: "Good", PublishedAt: now, Sentiment: &model.Sentiment{Label: model.SentimentPositive, Score: 0.8}},
+	})
+	s.Require().NoError(err)
+	bad, oldBad, good := res.InsertedIDs[0].(bson.ObjectID), res.InsertedIDs[1].(bson.ObjectID), res.InsertedIDs[2].(bson.ObjectID)
+
+	clients := []*model.Client{
+		{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Alice Smith"}}}}}, Articles: []bson.ObjectID{good, bad}},
+		{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Alan Jones"}}}}}, Articles: []bson.ObjectID{oldBad, good}},
+		{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Bob Lee"}}}}}, Articles: []bson.ObjectID{bad}},
+		{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Al Green"}}}}}},
+	}
+	for _, c := range clients {
+		_, err := s.repo.Create(s.ctx, c)
+		s.Require().NoError(err)
+	}
+
+	since := now.AddDate(0, 0, -7)
+	query := &model.GetClientsQuery{Name: "Al", Page: 1, PageSize: 10, NegativePressSince: &since}
+
+	fetched, err := s.repo.GetAll(s.ctx, query)
+	s.Require().NoError(err)
+	s.Require().Len(fetched, 1)
+	name, ok := extractName(fetched[0].Data)
+	s.True(ok)
+	s.Equal("Alice Smith", name)
+	s.Equal([]bson.ObjectID{good, bad}, fetched[0].Articles)
+
+	count, err := s.repo.Count(s.ctx, query)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	// no name filter, Bob's article counts too
+	query.Name = ""
+	count, err = s.repo.Count(s.ctx, query)
+	s.Require().NoError(err)
+	s.Equal(2, count)
+}
+
 func (s *ClientRepositorySuite) TestUpdate() {
 	client := &model.Client{
 		Data: bson.D{
@@ -117,6 +165,124 @@ func (s *ClientRepositorySuite) TestGetClientNameByID() {
 	s.Equal("Jane Doe", name)
 }
 
+func (s *ClientRepositorySuite) TestUpsertAndForEach() {
+	id := bson.NewObjectID()
+	byID := &model.Client{ID: id, Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Jane Doe"}}}}}}
+
+	inserted, err := s.repo.UpsertByID(s.ctx, byID)
+	s.Require().NoError(err)
+	s.True(inserted)
+
+	inserted, err = s.repo.UpsertByID(s.ctx, byID)
+	s.Require().NoError(err)
+	s.False(inserted)
+
+	byName := &model.Client{Data: bson.D{{Key: "profile", Value: bson.D{
+		{Key: "names", Value: bson.A{"Jane Doe"}},
+		{Key: "nationality", Value: "British"},
+	}}}}
+	inserted, err = s.repo.UpsertByName(s.ctx, byName)
+	s.Require().NoError(err)
+	s.False(inserted)
+
+	// what the server owns is kept, a legal hold is only ever lifted through SetLegalHold
+	articleID := bson.NewObjectID()
+	s.Require().NoError(s.repo.AddArticle(s.ctx, id.Hex(), articleID))
+	s.Require().NoError(s.repo.SetLegalHold(s.ctx, id.Hex(), &model.LegalHold{Reason: "litigation", SetBy: "alice"}))
+	_, err = s.repo.UpsertByID(s.ctx, byID)
+	s.Require().NoError(err)
+	_, err = s.repo.UpsertByName(s.ctx, byName)
+	s.Require().NoError(err)
+	fetched, err := s.repo.GetOne(s.ctx, id.Hex())
+	s.Require().NoError(err)
+	s.Equal([]bson.ObjectID{articleID}, fetched.Articles)
+	s.Require().NotNil(fetched.LegalHold)
+	s.Equal("litigation", fetched.LegalHold.Reason)
+
+	var seen []bson.ObjectID
+	err = s.repo.ForEach(s.ctx, func(c *model.Client) error {
+		seen = append(seen, c.ID)
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Equal([]bson.ObjectID{id}, seen)
+
+	// a second Jane Doe makes the name ambiguous
+	_, err = s.repo.Create(s.ctx, &model.Client{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Jane Doe", "J. Doe"}}}}}})
+	s.Require().NoError(err)
+	_, err = s.repo.UpsertByName(s.ctx, byName)
+	s.ErrorIs(err, errorx.ErrConflict)
+}
+
+func (s *ClientRepositorySuite) TestAddArticle() {
+	id, err := s.repo.Create(s.ctx, &model.Client{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Alice Smith"}}}}}})
+	s.Require().NoError(err)
+
+	articleID := bson.NewObjectID()
+	s.Require().NoError(s.repo.AddArticle(s.ctx, id, articleID))
+	s.Require().NoError(s.repo.AddArticle(s.ctx, id, articleID))
+
+	fetched, err := s.repo.GetOne(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal([]bson.ObjectID{articleID}, fetched.Articles)
+	// linking again keeps the first link time
+	s.Require().Len(fetched.ArticleLinks, 1)
+	s.Equal(articleID, fetched.ArticleLinks[0].ArticleID)
+	s.False(fetched.ArticleLinks[0].LinkedAt.IsZero())
+
+	err = s.repo.AddArticle(s.ctx, bson.NewObjectID().Hex(), articleID)
+	s.ErrorIs(err, errorx.ErrNotFound)
+
+	s.Require().NoError(s.repo.RemoveArticle(s.ctx, id, articleID))
+	fetched, err = s.repo.GetOne(s.ctx, id)
+	s.Require().NoError(err)
+	s.Empty(fetched.Articles)
+	s.Empty(fetched.ArticleLinks)
+	err = s.repo.RemoveArticle(s.ctx, id, articleID)
+	s.ErrorIs(err, errorx.ErrNotFound)
+}
+
+func (s *ClientRepositorySuite) TestAddDocument() {
+	id, err := s.repo.Create(s.ctx, &model.Client{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Alice Smith"}}}}}})
+	s.Require().NoError(err)
+
+	doc := &model.ClientDocument{JobID: "job-id", FileName: "statement.pdf", LinkedBy: "alice", LinkedAt: time.Now().UTC().Truncate(time.Millisecond)}
+	s.Require().NoError(s.repo.AddDocument(s.ctx, id, doc))
+	s.Require().NoError(s.repo.AddDocument(s.ctx, id, doc))
+
+	fetched, err := s.repo.GetOne(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal([]model.ClientDocument{*doc}, fetched.Documents)
+
+	err = s.repo.AddDocument(s.ctx, bson.NewObjectID().Hex(), doc)
+	s.ErrorIs(err, errorx.ErrNotFound)
+}
+
+func (s *ClientRepositorySuite) TestLegalHold() {
+	held, err := s.repo.Create(s.ctx, &model.Client{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Alice Smith"}}}}}})
+	s.Require().NoError(err)
+	_, err = s.repo.Create(s.ctx, &model.Client{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Bob Lee"}}}}}})
+	s.Require().NoError(err)
+
+	hold := &model.LegalHold{Reason: "litigation", SetBy: "counsel", SetAt: time.Now().UTC().Truncate(time.Millisecond)}
+	s.Require().NoError(s.repo.SetLegalHold(s.ctx, held, hold))
+
+	fetched, err := s.repo.GetOne(s.ctx, held)
+	s.Require().NoError(err)
+	s.Equal(hold, fetched.LegalHold)
+	ids, err := s.repo.GetHeldClientIDs(s.ctx)
+	s.Require().NoError(err)
+	s.Equal([]string{held}, ids)
+
+	s.Require().NoError(s.repo.SetLegalHold(s.ctx, held, nil))
+	ids, err = s.repo.GetHeldClientIDs(s.ctx)
+	s.Require().NoError(err)
+	s.Empty(ids)
+
+	err = s.repo.SetLegalHold(s.ctx, bson.NewObjectID().Hex(), hold)
+	s.ErrorIs(err, errorx.ErrNotFound)
+}
+
 func extractName(data bson.D) (string, bool) {
 	for _, elem := range data {
 		if elem.Key == "profile" {
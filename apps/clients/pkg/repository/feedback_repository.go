@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type mongoFeedbackRepository struct {
+	feedbackCollection *mongo.Collection
+}
+
+func NewMongoFeedbackRepository(storage *MongoStorage) FeedbackRepository {
+	return &mongoFeedbackRepository{feedbackCollection: storage.feedbackCollection}
+}
+
+type FeedbackRepository interface {
+	Upsert(ctx context.Context, feedback *model.ArticleFeedback) error
+	GetStats(ctx context.Context, query *model.GetFeedbackStatsQuery) ([]model.FeedbackStats, error)
+	IsExcluded(ctx context.Context, clientID string, canonicalURL string) (bool, error)
+}
+
+// Upsert stores the feedback, replacing any earlier verdict for the same client and article
+func (r *mongoFeedbackRepository) Upsert(ctx context.Context, feedback *model.ArticleFeedback) error {
+	if feedback == nil {
+		return fmt.Errorf("%w: cannot store nil feedback", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "clientId", Value: feedback.ClientID}, {Key: "articleId", Value: feedback.ArticleID}}
+	_, err := r.feedbackCollection.ReplaceOne(ctx, filter, feedback, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("%w: mongo upsert error", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+// IsExcluded reports whether an article with the canonical URL was marked irrelevant for the client. Feedback stored
+// before canonical URLs were recorded is matched on the article URL instead.
+func (r *mongoFeedbackRepository) IsExcluded(ctx context.Context, clientID string, canonicalURL string) (bool, error) {
+	filter := bson.D{
+		{Key: "clientId", Value: clientID},
+		{Key: "relevant", Value: false},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "canonicalUrl", Value: canonicalURL}},
+			bson.D{{Key: "canonicalUrl", Value: bson.D{{Key: "$exists", Value: false}}}, {Key: "url", Value: canonicalURL}},
+		}},
+	}
+	err := r.feedbackCollection.FindOne(ctx, filter, options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}})).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+	return true, nil
+}
+
+// GetStats counts reviewed and irrelevant articles per source
+func (r *mongoFeedbackRepository) GetStats(ctx context.Context, query *model.GetFeedbackStatsQuery) ([]model.FeedbackStats, error) {
+	match := bson.D{}
+	if query.Source != "" {
+		match = append(match, bson.E{Key: "source", Value: query.Source})
+	}
+	timestamp := bson.D{}
+	if !query.From.IsZero() {
+		timestamp = append(timestamp, bson.E{Key: "$gte", Value: query.From})
+	}
+	if !query.To.IsZero() {
+		timestamp = append(timestamp, bson.E{Key: "$lte", Value: query.To})
+	}
+	if len(timestamp) > 0 {
+		match = append(match, bson.E{Key: "timestamp", Value: timestamp})
+	}
+
+	irrelevant := bson.D{{Key: "$cond", Value: bson.A{"$relevant", 0, 1}}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$source"},
+			{Key: "reviewed", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "irrelevant", Value: bson.D{{Key: "$sum", Value: irrelevant}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.feedbackCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	stats := []model.FeedbackStats{}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+	return stats, nil
+}
@@ -0,0 +1,83 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type FeedbackRepositorySuite struct {
+	suite.Suite
+	storage *repository.MongoStorage
+	repo    repository.FeedbackRepository
+	cleanup func()
+	ctx     context.Context
+}
+
+func (s *FeedbackRepositorySuite) SetupSuite() {
+	s.storage, s.cleanup = repository.NewTestMongoStorage(s.T())
+	s.repo = repository.NewMongoFeedbackRepository(s.storage)
+	s.ctx = context.TODO()
+}
+
+func (s *FeedbackRepositorySuite) TearDownSuite() {
+	s.cleanup()
+}
+
+func (s *FeedbackRepositorySuite) SetupTest() {
+	_, err := s.storage.FeedbackCollection().DeleteMany(s.ctx, bson.M{})
+	s.Require().NoError(err)
+}
+
+func (s *FeedbackRepositorySuite) TestUpsertKeepsLatestVerdict() {
+	now := time.Now().UTC()
+	s.Require().NoError(s.repo.Upsert(s.ctx, &model.ArticleFeedback{ClientID: "c1", ArticleID: "a1", Source: "Reuters", Relevant: true, Timestamp: now}))
+	s.Require().NoError(s.repo.Upsert(s.ctx, &model.ArticleFeedback{ClientID: "c1", ArticleID: "a1", Source: "Reuters", Relevant: false, Reason: "namesake", Timestamp: now}))
+	s.Require().NoError(s.repo.Upsert(s.ctx, &model.ArticleFeedback{ClientID: "c2", ArticleID: "a1", Source: "Reuters", Relevant: true, Timestamp: now}))
+	s.Require().NoError(s.repo.Upsert(s.ctx, &model.ArticleFeedback{ClientID: "c1", ArticleID: "a2", Source: "Bloomberg", Relevant: true, Timestamp: now}))
+
+	stats, err := s.repo.GetStats(s.ctx, &model.GetFeedbackStatsQuery{})
+	s.Require().NoError(err)
+	s.Equal([]model.FeedbackStats{
+		{Source: "Bloomberg", Reviewed: 1, Irrelevant: 0},
+		{Source: "Reuters", Reviewed: 2, Irrelevant: 1},
+	}, stats)
+
+	stats, err = s.repo.GetStats(s.ctx, &model.GetFeedbackStatsQuery{Source: "Bloomberg", From: now.Add(-time.Hour)})
+	s.Require().NoError(err)
+	s.Len(stats, 1)
+}
+
+func (s *FeedbackRepositorySuite) TestIsExcluded() {
+	now := time.Now().UTC()
+	s.Require().NoError(s.repo.Upsert(s.ctx, &model.ArticleFeedback{ClientID: "c1", ArticleID: "a1", URL: "https://www.example.com/a/", CanonicalURL: "https://example.com/a", Relevant: false, Timestamp: now}))
+	s.Require().NoError(s.repo.Upsert(s.ctx, &model.ArticleFeedback{ClientID: "c1", ArticleID: "a2", URL: "https://example.com/b", CanonicalURL: "https://example.com/b", Relevant: true, Timestamp: now}))
+	// stored before canonical URLs were recorded
+	_, err := s.storage.FeedbackCollection().InsertOne(s.ctx, bson.M{"clientId": "c1", "articleId": "a3", "url": "https://example.com/c", "relevant": false})
+	s.Require().NoError(err)
+
+	tests := []struct {
+		clientID  string
+		canonical string
+		excluded  bool
+	}{
+		{"c1", "https://example.com/a", true},
+		{"c2", "https://example.com/a", false},
+		{"c1", "https://example.com/b", false},
+		{"c1", "https://example.com/c", true},
+	}
+	for _, tt := range tests {
+		excluded, err := s.repo.IsExcluded(s.ctx, tt.clientID, tt.canonical)
+		s.Require().NoError(err)
+		s.Equal(tt.excluded, excluded, "%s %s", tt.clientID, tt.canonical)
+	}
+}
+
+func TestFeedbackRepositorySuite(t *testing.T) {
+	suite.Run(t, new(FeedbackRepositorySuite))
+}
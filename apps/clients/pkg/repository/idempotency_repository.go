@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// IdempotencyRepository stores Idempotency-Key records until they expire
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	Complete(ctx context.Context, id string, token string, statusCode int, contentType string, body []byte) error
+	Delete(ctx context.Context, id string, token string) error
+}
+
+type mongoIdempotencyRepository struct {
+	idempotencyCollection *mongo.Collection
+}
+
+func NewMongoIdempotencyRepository(storage *MongoStorage) IdempotencyRepository {
+	return &mongoIdempotencyRepository{idempotencyCollection: storage.idempotencyCollection}
+}
+
+// Reserve stores the record if its key is unused, expired or held by a request that stopped before finishing.
+// Otherwise it leaves the store untouched and returns the record already holding the key.
+func (r *mongoIdempotencyRepository) Reserve(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	now := time.Now()
+	filter := bson.D{
+		{Key: "_id", Value: record.ID},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "expiresAt", Value: bson.D{{Key: "$lt", Value: now}}}},
+			bson.D{
+				{Key: "status", Value: model.IdempotencyInProgress},
+				{Key: "lockedUntil", Value: bson.D{{Key: "$lt", Value: now}}},
+			},
+		}},
+	}
+
+	_, err := r.idempotencyCollection.ReplaceOne(ctx, filter, record, options.Replace().SetUpsert(true))
+	if err == nil {
+		return nil, nil
+	}
+	// the key is live, so the upsert collided with it
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("%w: error reserving idempotency key", errorx.ErrDependencyFailed)
+	}
+
+	var existing model.IdempotencyRecord
+	if err := r.idempotencyCollection.FindOne(ctx, bson.D{{Key: "_id", Value: record.ID}}).Decode(&existing); err != nil {
+		if err == mongo.ErrNoDocuments {
+			// expired and removed in between, the caller can simply try again
+			return nil, fmt.Errorf("%w: idempotency key changed concurrently", errorx.ErrConflict)
+		}
+		return nil, fmt.Errorf("%w: error finding idempotency key", errorx.ErrDependencyFailed)
+	}
+	return &existing, nil
+}
+
+// Complete stores the response to replay for the key, as long as the request holding token still holds it
+func (r *mongoIdempotencyRepository) Complete(ctx context.Context, id string, token string, statusCode int, contentType string, body []byte) error {
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: model.IdempotencyCompleted},
+		{Key: "statusCode", Value: statusCode},
+		{Key: "contentType", Value: contentType},
+		{Key: "body", Value: body},
+	}}}
+	result, err := r.idempotencyCollection.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}, {Key: "token", Value: token}}, update)
+	if err != nil {
+		return fmt.Errorf("%w: error completing idempotency key", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: idempotency key not found or taken over by another request", errorx.ErrNotFound)
+	}
+	return nil
+}
+
+// Delete frees the key so the request can be retried. A key another request has since taken over is left alone.
+func (r *mongoIdempotencyRepository) Delete(ctx context.Context, id string, token string) error {
+	_, err := r.idempotencyCollection.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}, {Key: "token", Value: token}})
+	if err != nil {
+		return fmt.Errorf("%w: error deleting idempotency key", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
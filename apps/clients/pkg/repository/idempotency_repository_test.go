@@ -0,0 +1,128 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+type IdempotencyRepositorySuite struct {
+	suite.Suite
+	repo    repository.IdempotencyRepository
+	storage *repository.MongoStorage
+	cleanup func()
+	ctx     context.Context
+}
+
+func (s *IdempotencyRepositorySuite) SetupSuite() {
+	s.storage, s.cleanup = repository.NewTestMongoStorage(s.T())
+	s.repo = repository.NewMongoIdempotencyRepository(s.storage)
+	s.ctx = context.TODO()
+}
+
+func (s *IdempotencyRepositorySuite) TearDownSuite() {
+	s.cleanup()
+}
+
+func (s *IdempotencyRepositorySuite) SetupTest() {
+	_, err := s.storage.IdempotencyCollection().DeleteMany(s.ctx, map[string]any{})
+	s.Require().NoError(err)
+}
+
+func (s *IdempotencyRepositorySuite) record(hash string, lockedUntil time.Time, expiresAt time.Time) *model.IdempotencyRecord {
+	return &model.IdempotencyRecord{
+		ID:          "scope",
+		Key:         "key-1",
+		RequestHash: hash,
+		Token:       "token-" + hash,
+		Status:      model.IdempotencyInProgress,
+		LockedUntil: lockedUntil,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   expiresAt,
+	}
+}
+
+func (s *IdempotencyRepositorySuite) TestReserveAndComplete() {
+	now := time.Now()
+	existing, err := s.repo.Reserve(s.ctx, s.record("a", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	s.Nil(existing)
+
+	existing, err = s.repo.Reserve(s.ctx, s.record("b", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	s.Require().NotNil(existing)
+	s.Equal("a", existing.RequestHash)
+	s.Equal(model.IdempotencyInProgress, existing.Status)
+
+	s.Require().NoError(s.repo.Complete(s.ctx, "scope", "token-a", 201, "application/json", []byte(`{"jobId":"job-1"}`)))
+	existing, err = s.repo.Reserve(s.ctx, s.record("a", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	s.Require().NotNil(existing)
+	s.Equal(model.IdempotencyCompleted, existing.Status)
+	s.Equal(201, existing.StatusCode)
+	s.Equal([]byte(`{"jobId":"job-1"}`), existing.Body)
+
+	err = s.repo.Complete(s.ctx, "missing", "token-a", 201, "application/json", nil)
+	s.ErrorIs(err, errorx.ErrNotFound)
+}
+
+func (s *IdempotencyRepositorySuite) TestReserveTakesOverStaleKeys() {
+	now := time.Now()
+	// a request that crashed while holding the key
+	_, err := s.repo.Reserve(s.ctx, s.record("a", now.Add(-time.Second), now.Add(time.Hour)))
+	s.Require().NoError(err)
+
+	existing, err := s.repo.Reserve(s.ctx, s.record("b", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	s.Nil(existing)
+
+	// the crashed request can no longer complete the key it lost
+	err = s.repo.Complete(s.ctx, "scope", "token-a", 201, "application/json", nil)
+	s.ErrorIs(err, errorx.ErrNotFound)
+
+	// an expired key the TTL monitor has not removed yet
+	s.Require().NoError(s.repo.Complete(s.ctx, "scope", "token-b", 201, "application/json", nil))
+	_, err = s.storage.IdempotencyCollection().UpdateOne(s.ctx, map[string]any{"_id": "scope"}, map[string]any{"$set": map[string]any{"expiresAt": now.Add(-time.Second)}})
+	s.Require().NoError(err)
+
+	existing, err = s.repo.Reserve(s.ctx, s.record("c", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	s.Nil(existing)
+}
+
+func (s *IdempotencyRepositorySuite) TestDelete() {
+	now := time.Now()
+	_, err := s.repo.Reserve(s.ctx, s.record("a", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.Delete(s.ctx, "scope", "token-a"))
+
+	existing, err := s.repo.Reserve(s.ctx, s.record("b", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	s.Nil(existing)
+}
+
+func (s *IdempotencyRepositorySuite) TestDelete_TakenOver() {
+	now := time.Now()
+	// a request whose lock ran out, then a retry took the key over
+	_, err := s.repo.Reserve(s.ctx, s.record("a", now.Add(-time.Second), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	_, err = s.repo.Reserve(s.ctx, s.record("b", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.Delete(s.ctx, "scope", "token-a"))
+
+	existing, err := s.repo.Reserve(s.ctx, s.record("c", now.Add(time.Minute), now.Add(time.Hour)))
+	s.Require().NoError(err)
+	s.Require().NotNil(existing)
+	s.Equal("b", existing.RequestHash)
+}
+
+func TestIdempotencyRepositorySuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyRepositorySuite))
+}
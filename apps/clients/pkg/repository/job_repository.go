@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -16,6 +17,22 @@ type JobRepository interface {
 	GetOne(ctx context.Context, jobID string) (*model.Job, error)
 	GetAll(ctx context.Context, query *model.GetJobsQuery) ([]model.Job, error)
 	Count(ctx context.Context, query *model.GetJobsQuery) (int, error)
+	CountActive(ctx context.Context, createdBy string) (int, error)
+	CountCreated(ctx context.Context, createdBy string, since time.Time) (int, error)
+	SetFlowRunID(ctx context.Context, jobID string, flowRunID string) error
+	UpdateStatus(ctx context.Context, jobID string, from []model.JobStatus, to model.JobStatus, entry model.JobLog) error
+	GetChain(ctx context.Context, rootID string) ([]model.Job, error)
+	Apply(ctx context.Context, jobID string, from model.JobStatus, update *model.JobUpdate) error
+	Watch(ctx context.Context, jobID string) (<-chan *model.Job, error)
+	FindStale(ctx context.Context, jobType model.JobType, statuses []model.JobStatus, before time.Time) ([]model.Job, error)
+	GetPendingMatches(ctx context.Context, query *model.GetPendingMatchesQuery) ([]model.Job, error)
+	CountPendingMatches(ctx context.Context) (int, error)
+	SetMatchReview(ctx context.Context, jobID string, candidateID bson.ObjectID, review *model.MatchReview) error
+	GetMatchStats(ctx context.Context, query *model.GetMatchStatsQuery) ([]model.MatchStats, error)
+	GetStats(ctx context.Context, query *model.GetJobStatsQuery) (*model.JobStats, error)
+	GetBatchCounts(ctx context.Context, batchID string) (*model.BatchCounts, int, error)
+	FindBatchChildren(ctx context.Context, batchID string, statuses []model.JobStatus) ([]model.Job, error)
+	UpdateBatch(ctx context.Context, batchID string, from time.Time, status model.JobStatus, progress int, counts *model.BatchCounts) (time.Time, error)
 }
 
 type mongoJobRepository struct {
@@ -55,11 +72,6 @@ func (r *mongoJobRepository) GetOne(ctx context.Context, jobID string) (*model.J
 }
 
 func (r *mongoJobRepository) GetAll(ctx context.Context, query *model.GetJobsQuery) ([]model.Job, error) {
-	filter := bson.M{}
-	if query.Status != "" {
-		filter["status"] = query.Status
-	}
-
 	if query.Page < 1 {
 		query.Page = 1
 	}
@@ -68,11 +80,21 @@ func (r *mongoJobRepository) GetAll(ctx context.Context, query *model.GetJobsQue
 	}
 	skip := (query.Page - 1) * query.PageSize
 
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = "updatedAt"
+	}
+	order := -1
+	if query.Sort == "asc" {
+		order = 1
+	}
+
 	opts := options.Find().
 		SetSkip(int64(skip)).
 		SetLimit(int64(query.PageSize)).
-		SetSort(bson.D{{Key: "updatedAt", Value: -1}})
-	cursor, err := r.jobCollection.Find(ctx, filter, opts)
+		SetSort(bson.D{{Key: sortBy, Value: order}, {Key: "_id", Value: order}}).
+		SetProjection(bson.D{{Key: "fileBytes", Value: 0}})
+	cursor, err := r.jobCollection.Find(ctx, jobsFilter(query), opts)
 	if err != nil {
 		return nil, fmt.Errorf("%w: error finding job", errorx.ErrDependencyFailed)
 	}
@@ -86,15 +108,593 @@ func (r *mongoJobRepository) GetAll(ctx context.Context, query *model.GetJobsQue
 }
 
 func (r *mongoJobRepository) Count(ctx context.Context, query *model.GetJobsQuery) (int, error) {
+	count, err := r.jobCollection.CountDocuments(ctx, jobsFilter(query))
+	if err != nil {
+		return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+	}
+	return int(count), nil
+}
+
+// CountActive counts pending and processing jobs created by createdBy, or by anyone if createdBy is empty.
+// Batches run nothing themselves, so only their children are counted.
+func (r *mongoJobRepository) CountActive(ctx context.Context, createdBy string) (int, error) {
+	filter := bson.D{
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{model.JobStatusPending, model.JobStatusProcessing}}}},
+		{Key: "type", Value: bson.D{{Key: "$ne", Value: model.Batch}}},
+	}
+	if createdBy != "" {
+		filter = append(filter, bson.E{Key: "createdBy", Value: createdBy})
+	}
+	count, err := r.jobCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+	}
+	return int(count), nil
+}
+
+// CountCreated counts the jobs createdBy has created since then, batches aside since only their children run
+func (r *mongoJobRepository) CountCreated(ctx context.Context, createdBy string, since time.Time) (int, error) {
+	filter := bson.D{
+		{Key: "createdBy", Value: createdBy},
+		{Key: "createdAt", Value: bson.D{{Key: "$gte", Value: since}}},
+		{Key: "type", Value: bson.D{{Key: "$ne", Value: model.Batch}}},
+	}
+	count, err := r.jobCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+	}
+	return int(count), nil
+}
 
+func jobsFilter(query *model.GetJobsQuery) bson.M {
 	filter := bson.M{}
 	if query.Status != "" {
 		filter["status"] = query.Status
 	}
+	if query.Type != "" {
+		filter["type"] = query.Type
+	}
+	if query.ClientID != "" {
+		filter["clientId"] = query.ClientID
+	}
+	if query.CreatedBy != "" {
+		filter["createdBy"] = query.CreatedBy
+	}
+	if query.BatchID != "" {
+		filter["batchId"] = query.BatchID
+	}
+	if r := timeRange(query.CreatedFrom, query.CreatedTo); len(r) > 0 {
+		filter["createdAt"] = r
+	}
+	if r := timeRange(query.UpdatedFrom, query.UpdatedTo); len(r) > 0 {
+		filter["updatedAt"] = r
+	}
+	return filter
+}
 
-	count, err := r.jobCollection.CountDocuments(ctx, filter)
+func timeRange(from time.Time, to time.Time) bson.M {
+	r := bson.M{}
+	if !from.IsZero() {
+		r["$gte"] = from
+	}
+	if !to.IsZero() {
+		r["$lte"] = to
+	}
+	return r
+}
+
+func (r *mongoJobRepository) SetFlowRunID(ctx context.Context, jobID string, flowRunID string) error {
+	objID, err := bson.ObjectIDFromHex(jobID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid object ID", errorx.ErrInvalidInput)
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "prefectFlowID", Value: flowRunID}}}}
+	result, err := r.jobCollection.UpdateOne(ctx, bson.D{{Key: "_id", Value: objID}}, update)
+	if err != nil {
+		return fmt.Errorf("%w: error updating job", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: job not found", errorx.ErrNotFound)
+	}
+	return nil
+}
+
+// UpdateStatus moves a job to a new status and appends a log entry, only if its current status is one of from.
+// It returns ErrConflict if the job has moved on in the meantime.
+func (r *mongoJobRepository) UpdateStatus(ctx context.Context, jobID string, from []model.JobStatus, to model.JobStatus, entry model.JobLog) error {
+	objID, err := bson.ObjectIDFromHex(jobID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid object ID", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "_id", Value: objID}, {Key: "status", Value: bson.D{{Key: "$in", Value: from}}}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "status", Value: to}, {Key: "updatedAt", Value: entry.Timestamp}}},
+		{Key: "$push", Value: bson.D{{Key: "logs", Value: entry}}},
+	}
+
+	result, err := r.jobCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%w: error updating job", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: job is no longer %v", errorx.ErrConflict, from)
+	}
+	return nil
+}
+
+// GetChain returns the original job and every retry of it, oldest attempt first
+func (r *mongoJobRepository) GetChain(ctx context.Context, rootID string) ([]model.Job, error) {
+	objID, err := bson.ObjectIDFromHex(rootID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid object ID", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "_id", Value: objID}},
+		bson.D{{Key: "rootId", Value: rootID}},
+	}}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "attempt", Value: 1}, {Key: "createdAt", Value: 1}}).
+		SetProjection(bson.D{{Key: "input", Value: 0}, {Key: "logs", Value: 0}, {Key: "matchResults", Value: 0}})
+	cursor, err := r.jobCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error finding jobs", errorx.ErrDependencyFailed)
+	}
+
+	var jobs []model.Job
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("%w: error decoding jobs", errorx.ErrInternal)
+	}
+
+	return jobs, nil
+}
+
+// Apply writes a callback update to a job, only if the job is still in status from.
+// It returns ErrConflict if the job has moved on in the meantime.
+func (r *mongoJobRepository) Apply(ctx context.Context, jobID string, from model.JobStatus, update *model.JobUpdate) error {
+	objID, err := bson.ObjectIDFromHex(jobID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid object ID", errorx.ErrInvalidInput)
+	}
+
+	set := bson.D{{Key: "updatedAt", Value: update.UpdatedAt}}
+	if update.Status != "" {
+		set = append(set, bson.E{Key: "status", Value: update.Status})
+	}
+	if update.Progress != nil {
+		set = append(set, bson.E{Key: "progress", Value: *update.Progress})
+	}
+	if update.ScrapeResult != nil {
+		set = append(set, bson.E{Key: "scrapeResult", Value: *update.ScrapeResult})
+	}
+	if update.MatchResults != nil {
+		set = append(set, bson.E{Key: "matchResults", Value: update.MatchResults})
+	}
+	if update.ExtractedProfile != nil {
+		set = append(set, bson.E{Key: "extractedProfile", Value: update.ExtractedProfile})
+	}
+
+	changes := bson.D{{Key: "$set", Value: set}}
+	if len(update.Logs) > 0 {
+		changes = append(changes, bson.E{Key: "$push", Value: bson.D{{Key: "logs", Value: bson.D{{Key: "$each", Value: update.Logs}}}}})
+	}
+
+	result, err := r.jobCollection.UpdateOne(ctx, bson.D{{Key: "_id", Value: objID}, {Key: "status", Value: from}}, changes)
+	if err != nil {
+		return fmt.Errorf("%w: error updating job", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: job is no longer %s", errorx.ErrConflict, from)
+	}
+	return nil
+}
+
+// Watch streams the job's document every time it changes, using a change stream on the jobs collection.
+// The channel is closed when ctx is done or the stream fails. Change streams need a replica set,
+// so on a standalone server Watch returns ErrDependencyFailed.
+func (r *mongoJobRepository) Watch(ctx context.Context, jobID string) (<-chan *model.Job, error) {
+	objID, err := bson.ObjectIDFromHex(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid object ID", errorx.ErrInvalidInput)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "documentKey._id", Value: objID}}}}}
+	stream, err := r.jobCollection.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return nil, fmt.Errorf("%w: error watching job: %v", errorx.ErrDependencyFailed, err)
+	}
+
+	updates := make(chan *model.Job)
+	go func() {
+		defer close(updates)
+		defer stream.Close(context.Background())
+
+		for stream.Next(ctx) {
+			var change struct {
+				FullDocument *model.Job `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&change); err != nil || change.FullDocument == nil {
+				continue
+			}
+			select {
+			case updates <- change.FullDocument:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// FindStale returns jobs of jobType in one of statuses that haven't been updated since before, oldest first
+func (r *mongoJobRepository) FindStale(ctx context.Context, jobType model.JobType, statuses []model.JobStatus, before time.Time) ([]model.Job, error) {
+	filter := bson.D{
+		{Key: "type", Value: jobType},
+		{Key: "status", Value: bson.D{{Key: "$in", Value: statuses}}},
+		{Key: "updatedAt", Value: bson.D{{Key: "$lt", Value: before}}},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updatedAt", Value: 1}}).
+		SetProjection(bson.D{{Key: "input", Value: 0}, {Key: "matchResults", Value: 0}})
+	cursor, err := r.jobCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error finding stale jobs", errorx.ErrDependencyFailed)
+	}
+
+	var jobs []model.Job
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("%w: error decoding jobs", errorx.ErrInternal)
+	}
+
+	return jobs, nil
+}
+
+// pendingMatchesFilter selects completed match jobs with a candidate nobody has reviewed yet
+var pendingMatchesFilter = bson.D{
+	{Key: "type", Value: model.Match},
+	{Key: "status", Value: model.JobStatusCompleted},
+	{Key: "matchResults", Value: bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "review", Value: bson.D{{Key: "$exists", Value: false}}}}}}},
+}
+
+// GetPendingMatches returns match jobs awaiting review, oldest first, without the uploaded file or logs
+func (r *mongoJobRepository) GetPendingMatches(ctx context.Context, query *model.GetPendingMatchesQuery) ([]model.Job, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 10
+	}
+	skip := (query.Page - 1) * query.PageSize
+
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(query.PageSize)).
+		SetSort(bson.D{{Key: "updatedAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetProjection(bson.D{{Key: "fileBytes", Value: 0}, {Key: "logs", Value: 0}})
+	cursor, err := r.jobCollection.Find(ctx, pendingMatchesFilter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error finding pending matches", errorx.ErrDependencyFailed)
+	}
+
+	var jobs []model.Job
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("%w: error decoding jobs", errorx.ErrInternal)
+	}
+
+	return jobs, nil
+}
+
+func (r *mongoJobRepository) CountPendingMatches(ctx context.Context) (int, error) {
+	count, err := r.jobCollection.CountDocuments(ctx, pendingMatchesFilter)
 	if err != nil {
 		return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
 	}
 	return int(count), nil
 }
+
+// SetMatchReview records the decision on one candidate of a match job.
+// It returns ErrConflict if the candidate has already been reviewed, and ErrNotFound if there is no such candidate.
+func (r *mongoJobRepository) SetMatchReview(ctx context.Context, jobID string, candidateID bson.ObjectID, review *model.MatchReview) error {
+	objID, err := bson.ObjectIDFromHex(jobID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid object ID", errorx.ErrInvalidInput)
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objID},
+		{Key: "matchResults", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+			{Key: "_id", Value: candidateID},
+			{Key: "review", Value: bson.D{{Key: "$exists", Value: false}}},
+		}}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "matchResults.$.review", Value: review}}}}
+
+	result, err := r.jobCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%w: error updating job", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	count, err := r.jobCollection.CountDocuments(ctx, bson.D{{Key: "_id", Value: objID}, {Key: "matchResults._id", Value: candidateID}})
+	if err != nil {
+		return fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: %s is not a candidate of job %s", errorx.ErrNotFound, candidateID.Hex(), jobID)
+	}
+	return fmt.Errorf("%w: candidate %s has already been reviewed", errorx.ErrConflict, candidateID.Hex())
+}
+
+// GetMatchStats counts reviewed and accepted candidates per reviewer, with the mean confidence score of each decision
+func (r *mongoJobRepository) GetMatchStats(ctx context.Context, query *model.GetMatchStatsQuery) ([]model.MatchStats, error) {
+	match := bson.D{{Key: "matchResults.review", Value: bson.D{{Key: "$exists", Value: true}}}}
+	if query.Reviewer != "" {
+		match = append(match, bson.E{Key: "matchResults.review.reviewer", Value: query.Reviewer})
+	}
+	if reviewedAt := timeRange(query.From, query.To); len(reviewedAt) > 0 {
+		match = append(match, bson.E{Key: "matchResults.review.reviewedAt", Value: reviewedAt})
+	}
+
+	accepted := bson.D{{Key: "$eq", Value: bson.A{"$matchResults.review.decision", model.MatchAccepted}}}
+	scoreIf := func(cond bson.D) bson.D {
+		return bson.D{{Key: "$avg", Value: bson.D{{Key: "$cond", Value: bson.A{cond, "$matchResults.confidenceScore", nil}}}}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "type", Value: model.Match}, {Key: "matchResults.review", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+		{{Key: "$unwind", Value: "$matchResults"}},
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$matchResults.review.reviewer"},
+			{Key: "reviewed", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "accepted", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{accepted, 1, 0}}}}}},
+			{Key: "acceptedScore", Value: scoreIf(accepted)},
+			{Key: "rejectedScore", Value: scoreIf(bson.D{{Key: "$not", Value: bson.A{accepted}}})},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.jobCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	stats := []model.MatchStats{}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+	return stats, nil
+}
+
+// maxFailureMessages caps how many distinct failure messages GetStats reports
+const maxFailureMessages = 10
+
+// jobStatsFacets is the shape of the single document GetStats' aggregation returns
+type jobStatsFacets struct {
+	ByStatus []struct {
+		Status model.JobStatus `bson:"_id"`
+		Count  int             `bson:"count"`
+	} `bson:"byStatus"`
+	ByType []struct {
+		Type  model.JobType `bson:"_id"`
+		Count int           `bson:"count"`
+	} `bson:"byType"`
+	Duration   []model.JobDurationStats `bson:"duration"`
+	Failures   []model.JobFailureCount  `bson:"failures"`
+	Throughput []model.JobThroughput    `bson:"throughput"`
+}
+
+// GetStats aggregates the jobs created between query.From and query.To in one pass: counts by status and type,
+// percentiles of the time completed jobs took, failures by last log message and jobs per day.
+// Terminal jobs are not updated again, so a completed job's updatedAt is when it completed. Batches are the exception
+// and are left out of the percentiles.
+func (r *mongoJobRepository) GetStats(ctx context.Context, query *model.GetJobStatsQuery) (*model.JobStats, error) {
+	match := bson.D{{Key: "createdAt", Value: timeRange(query.From, query.To)}}
+	if query.Type != "" {
+		match = append(match, bson.E{Key: "type", Value: query.Type})
+	}
+
+	countBy := func(field string) mongo.Pipeline {
+		return mongo.Pipeline{
+			{{Key: "$group", Value: bson.D{{Key: "_id", Value: field}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+		}
+	}
+	statusIs := func(status model.JobStatus) bson.D {
+		return bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$status", status}}}, 1, 0}}}
+	}
+	// nearest-rank percentile of the sorted durations, in seconds
+	percentile := func(p float64) bson.D {
+		rank := bson.D{{Key: "$ceil", Value: bson.D{{Key: "$multiply", Value: bson.A{p, "$count"}}}}}
+		index := bson.D{{Key: "$max", Value: bson.A{bson.D{{Key: "$subtract", Value: bson.A{rank, 1}}}, 0}}}
+		return bson.D{{Key: "$divide", Value: bson.A{bson.D{{Key: "$arrayElemAt", Value: bson.A{"$durations", index}}}, 1000}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "type", Value: 1},
+			{Key: "status", Value: 1},
+			{Key: "createdAt", Value: 1},
+			{Key: "updatedAt", Value: 1},
+			{Key: "lastMessage", Value: bson.D{{Key: "$last", Value: "$logs.message"}}},
+		}}},
+		{{Key: "$facet", Value: bson.D{
+			{Key: "byStatus", Value: countBy("$status")},
+			{Key: "byType", Value: countBy("$type")},
+			{Key: "duration", Value: mongo.Pipeline{
+				// a batch is updated again whenever its children are retried or cancelled
+				{{Key: "$match", Value: bson.D{{Key: "status", Value: model.JobStatusCompleted}, {Key: "type", Value: bson.D{{Key: "$ne", Value: model.Batch}}}}}},
+				{{Key: "$project", Value: bson.D{{Key: "ms", Value: bson.D{{Key: "$subtract", Value: bson.A{"$updatedAt", "$createdAt"}}}}}}},
+				{{Key: "$sort", Value: bson.D{{Key: "ms", Value: 1}}}},
+				{{Key: "$group", Value: bson.D{{Key: "_id", Value: nil}, {Key: "durations", Value: bson.D{{Key: "$push", Value: "$ms"}}}}}},
+				{{Key: "$set", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$size", Value: "$durations"}}}}}},
+				{{Key: "$project", Value: bson.D{{Key: "_id", Value: 0}, {Key: "count", Value: 1}, {Key: "p50", Value: percentile(0.5)}, {Key: "p95", Value: percentile(0.95)}}}},
+			}},
+			{Key: "failures", Value: mongo.Pipeline{
+				{{Key: "$match", Value: bson.D{{Key: "status", Value: model.JobStatusFailed}}}},
+				{{Key: "$group", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$lastMessage", ""}}}}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+				{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+				{{Key: "$limit", Value: maxFailureMessages}},
+			}},
+			{Key: "throughput", Value: mongo.Pipeline{
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{{Key: "format", Value: "%Y-%m-%d"}, {Key: "date", Value: "$createdAt"}}}}},
+					{Key: "created", Value: bson.D{{Key: "$sum", Value: 1}}},
+					{Key: "completed", Value: bson.D{{Key: "$sum", Value: statusIs(model.JobStatusCompleted)}}},
+					{Key: "failed", Value: bson.D{{Key: "$sum", Value: statusIs(model.JobStatusFailed)}}},
+				}}},
+				{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+			}},
+		}}},
+	}
+
+	cursor, err := r.jobCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var facets []jobStatsFacets
+	if err := cursor.All(ctx, &facets); err != nil || len(facets) != 1 {
+		return nil, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+	f := facets[0]
+
+	stats := &model.JobStats{
+		From:       query.From,
+		To:         query.To,
+		ByStatus:   map[model.JobStatus]int{},
+		ByType:     map[model.JobType]int{},
+		Failures:   f.Failures,
+		Throughput: f.Throughput,
+	}
+	for _, s := range f.ByStatus {
+		stats.ByStatus[s.Status] = s.Count
+		stats.Total += s.Count
+	}
+	for _, t := range f.ByType {
+		stats.ByType[t.Type] = t.Count
+	}
+	if len(f.Duration) > 0 {
+		stats.Duration = f.Duration[0]
+	}
+	return stats, nil
+}
+
+// GetBatchCounts counts the children of a batch by status and averages their progress, counting finished
+// children as 100. Only the latest attempt of a retried child is counted.
+func (r *mongoJobRepository) GetBatchCounts(ctx context.Context, batchID string) (*model.BatchCounts, int, error) {
+	finished := bson.A{model.JobStatusCompleted, model.JobStatusFailed, model.JobStatusCancelled}
+	statusIs := func(status model.JobStatus) bson.D {
+		return bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$status", status}}}, 1, 0}}}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "batchId", Value: batchID}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "attempt", Value: -1}, {Key: "createdAt", Value: -1}}}},
+		// a retry chain is keyed by the id of its original job
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$rootId", bson.D{{Key: "$toString", Value: "$_id"}}}}}},
+			{Key: "status", Value: bson.D{{Key: "$first", Value: "$status"}}},
+			{Key: "progress", Value: bson.D{{Key: "$first", Value: "$progress"}}},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "total", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "pending", Value: statusIs(model.JobStatusPending)},
+			{Key: "processing", Value: statusIs(model.JobStatusProcessing)},
+			{Key: "completed", Value: statusIs(model.JobStatusCompleted)},
+			{Key: "failed", Value: statusIs(model.JobStatusFailed)},
+			{Key: "cancelled", Value: statusIs(model.JobStatusCancelled)},
+			{Key: "progress", Value: bson.D{{Key: "$avg", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$in", Value: bson.A{"$status", finished}}}, 100, bson.D{{Key: "$ifNull", Value: bson.A{"$progress", 0}}},
+			}}}}}},
+		}}},
+	}
+
+	cursor, err := r.jobCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		model.BatchCounts `bson:",inline"`
+		Progress          float64 `bson:"progress"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+	if len(results) == 0 {
+		return &model.BatchCounts{}, 0, nil
+	}
+	return &results[0].BatchCounts, int(results[0].Progress), nil
+}
+
+// FindBatchChildren returns the latest attempt of each child of a batch that is in one of statuses, oldest first
+func (r *mongoJobRepository) FindBatchChildren(ctx context.Context, batchID string, statuses []model.JobStatus) ([]model.Job, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "batchId", Value: batchID}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "attempt", Value: -1}, {Key: "createdAt", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$rootId", bson.D{{Key: "$toString", Value: "$_id"}}}}}},
+			{Key: "job", Value: bson.D{{Key: "$first", Value: "$$ROOT"}}},
+		}}},
+		{{Key: "$replaceRoot", Value: bson.D{{Key: "newRoot", Value: "$job"}}}},
+		{{Key: "$match", Value: bson.D{{Key: "status", Value: bson.D{{Key: "$in", Value: statuses}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.jobCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo aggregate error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []model.Job{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("%w: decode error", errorx.ErrInternal)
+	}
+	return jobs, nil
+}
+
+// UpdateBatch records the status, progress and child counts worked out from a batch's children, as long as the
+// batch hasn't been updated since from, the updatedAt it was read with. It returns the batch's new updatedAt, or
+// ErrConflict if the batch was updated meanwhile.
+func (r *mongoJobRepository) UpdateBatch(ctx context.Context, batchID string, from time.Time, status model.JobStatus, progress int, counts *model.BatchCounts) (time.Time, error) {
+	objID, err := bson.ObjectIDFromHex(batchID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid object ID", errorx.ErrInvalidInput)
+	}
+
+	// Mongo keeps milliseconds, so the next update can match on the returned time
+	updatedAt := time.Now().Truncate(time.Millisecond)
+	batch := bson.D{{Key: "_id", Value: objID}, {Key: "type", Value: model.Batch}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: status},
+		{Key: "progress", Value: progress},
+		{Key: "children", Value: counts},
+		{Key: "updatedAt", Value: updatedAt},
+	}}}
+	result, err := r.jobCollection.UpdateOne(ctx, append(batch, bson.E{Key: "updatedAt", Value: from}), update)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: error updating batch", errorx.ErrDependencyFailed)
+	}
+	if result.MatchedCount > 0 {
+		return updatedAt, nil
+	}
+
+	n, err := r.jobCollection.CountDocuments(ctx, batch)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: error finding batch", errorx.ErrDependencyFailed)
+	}
+	if n == 0 {
+		return time.Time{}, fmt.Errorf("%w: batch not found", errorx.ErrNotFound)
+	}
+	return time.Time{}, fmt.Errorf("%w: batch was updated meanwhile", errorx.ErrConflict)
+}
@@ -7,8 +7,10 @@ import (
 
 	"github.com/stretchr/testify/suite"
 
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type JobRepositorySuite struct {
@@ -62,7 +64,7 @@ func (s *JobRepositorySuite) TestGetAll() {
 	jobs := []*model.Job{
 		{Type: model.Scrape, Status: model.JobStatusPending, CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{Type: model.Match, Status: model.JobStatusCompleted, CreatedAt: time.Now(), UpdatedAt: time.Now()},
-		{Type: model.Scrape, Status: model.JobStatusPending, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{Type: model.Match, Status: model.JobStatusPending, FileBytes: "JVBERi0=", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 	}
 	for _, job := range jobs {
 		_, err := s.repo.Create(s.ctx, job)
@@ -80,6 +82,7 @@ func (s *JobRepositorySuite) TestGetAll() {
 	s.Len(result, 2)
 	for _, j := range result {
 		s.Equal(model.JobStatusPending, j.Status)
+		s.Empty(j.FileBytes)
 	}
 }
 
@@ -104,6 +107,315 @@ func (s *JobRepositorySuite) TestCount() {
 	s.Equal(2, count)
 }
 
+func (s *JobRepositorySuite) TestCountActive() {
+	jobs := []*model.Job{
+		{Status: model.JobStatusPending, CreatedBy: "alice", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{Status: model.JobStatusProcessing, CreatedBy: "alice", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{Status: model.JobStatusCompleted, CreatedBy: "alice", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{Status: model.JobStatusPending, CreatedBy: "bob", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{Type: model.Batch, Status: model.JobStatusPending, CreatedBy: "alice", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+	for _, job := range jobs {
+		_, err := s.repo.Create(s.ctx, job)
+		s.Require().NoError(err)
+	}
+
+	count, err := s.repo.CountActive(s.ctx, "alice")
+	s.Require().NoError(err)
+	s.Equal(2, count)
+
+	count, err = s.repo.CountActive(s.ctx, "")
+	s.Require().NoError(err)
+	s.Equal(3, count)
+}
+
+func (s *JobRepositorySuite) TestCountCreated() {
+	today := time.Now().Truncate(24 * time.Hour)
+	jobs := []*model.Job{
+		{Type: model.Scrape, Status: model.JobStatusCompleted, CreatedBy: "alice", CreatedAt: today.Add(time.Hour)},
+		{Type: model.Match, Status: model.JobStatusPending, CreatedBy: "alice", CreatedAt: today.Add(2 * time.Hour)},
+		{Type: model.Scrape, Status: model.JobStatusPending, CreatedBy: "alice", CreatedAt: today.Add(-time.Hour)},
+		{Type: model.Scrape, Status: model.JobStatusPending, CreatedBy: "bob", CreatedAt: today.Add(time.Hour)},
+		{Type: model.Batch, Status: model.JobStatusPending, CreatedBy: "alice", CreatedAt: today.Add(time.Hour)},
+	}
+	for _, job := range jobs {
+		_, err := s.repo.Create(s.ctx, job)
+		s.Require().NoError(err)
+	}
+
+	count, err := s.repo.CountCreated(s.ctx, "alice", today)
+	s.Require().NoError(err)
+	s.Equal(2, count)
+}
+
+func (s *JobRepositorySuite) TestSetFlowRunIDAndUpdateStatus() {
+	id, err := s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, Status: model.JobStatusPending, CreatedAt: time.Now()})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.SetFlowRunID(s.ctx, id, "flow-run-1"))
+
+	active := []model.JobStatus{model.JobStatusPending, model.JobStatusProcessing}
+	entry := model.JobLog{Message: "Job cancelled by alice", Timestamp: time.Now().UTC()}
+	s.Require().NoError(s.repo.UpdateStatus(s.ctx, id, active, model.JobStatusCancelled, entry))
+
+	fetched, err := s.repo.GetOne(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal("flow-run-1", fetched.PrefectFlowID)
+	s.Equal(model.JobStatusCancelled, fetched.Status)
+	s.Require().Len(fetched.Logs, 1)
+	s.Equal(entry.Message, fetched.Logs[0].Message)
+
+	err = s.repo.UpdateStatus(s.ctx, id, active, model.JobStatusCancelled, entry)
+	s.ErrorIs(err, errorx.ErrConflict)
+}
+
+func (s *JobRepositorySuite) TestGetChain() {
+	rootID, err := s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, Status: model.JobStatusFailed, Attempt: 1, CreatedAt: time.Now()})
+	s.Require().NoError(err)
+	thirdID, err := s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, Status: model.JobStatusPending, RootID: rootID, Attempt: 3, CreatedAt: time.Now()})
+	s.Require().NoError(err)
+	secondID, err := s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, Status: model.JobStatusFailed, RootID: rootID, ParentID: rootID, Attempt: 2, CreatedAt: time.Now()})
+	s.Require().NoError(err)
+	_, err = s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, Status: model.JobStatusFailed, CreatedAt: time.Now()})
+	s.Require().NoError(err)
+
+	chain, err := s.repo.GetChain(s.ctx, rootID)
+	s.Require().NoError(err)
+	s.Require().Len(chain, 3)
+	s.Equal(rootID, chain[0].ID.Hex())
+	s.Equal(secondID, chain[1].ID.Hex())
+	s.Equal(thirdID, chain[2].ID.Hex())
+
+	_, err = s.repo.GetChain(s.ctx, "not-an-id")
+	s.ErrorIs(err, errorx.ErrInvalidInput)
+}
+
+func (s *JobRepositorySuite) TestApply() {
+	id, err := s.repo.Create(s.ctx, &model.Job{Type: model.Match, Status: model.JobStatusProcessing, CreatedAt: time.Now(), Logs: []model.JobLog{}})
+	s.Require().NoError(err)
+
+	progress := 100
+	matched := bson.NewObjectID()
+	update := &model.JobUpdate{
+		Status:       model.JobStatusCompleted,
+		Progress:     &progress,
+		MatchResults: []model.MatchResult{{ID: matched, ConfidenceScore: 0.9}},
+		Logs:         []model.JobLog{{Message: "one", Timestamp: time.Now()}, {Message: "two", Timestamp: time.Now()}},
+		UpdatedAt:    time.Now(),
+	}
+	s.Require().NoError(s.repo.Apply(s.ctx, id, model.JobStatusProcessing, update))
+
+	fetched, err := s.repo.GetOne(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(model.JobStatusCompleted, fetched.Status)
+	s.Equal(100, fetched.Progress)
+	s.Require().Len(fetched.MatchResults, 1)
+	s.Equal(matched, fetched.MatchResults[0].ID)
+	s.Require().Len(fetched.Logs, 2)
+	s.Equal("two", fetched.Logs[1].Message)
+
+	err = s.repo.Apply(s.ctx, id, model.JobStatusProcessing, update)
+	s.ErrorIs(err, errorx.ErrConflict)
+}
+
+func (s *JobRepositorySuite) TestWatch() {
+	_, err := s.repo.Watch(s.ctx, "not-an-id")
+	s.ErrorIs(err, errorx.ErrInvalidInput)
+
+	// the test server is standalone, which is exactly the case the service falls back to polling for
+	_, err = s.repo.Watch(s.ctx, bson.NewObjectID().Hex())
+	s.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (s *JobRepositorySuite) TestGetAllFilters() {
+	base := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	jobs := []*model.Job{
+		{Type: model.Scrape, Status: model.JobStatusCompleted, ClientID: "c1", CreatedBy: "alice", CreatedAt: base, UpdatedAt: base},
+		{Type: model.Match, Status: model.JobStatusFailed, ClientID: "c1", CreatedBy: "alice", CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour)},
+		{Type: model.Scrape, Status: model.JobStatusPending, ClientID: "c2", CreatedBy: "bob", CreatedAt: base.Add(24 * time.Hour), UpdatedAt: base.Add(24 * time.Hour)},
+	}
+	for _, job := range jobs {
+		_, err := s.repo.Create(s.ctx, job)
+		s.Require().NoError(err)
+	}
+
+	mine, err := s.repo.GetAll(s.ctx, &model.GetJobsQuery{CreatedBy: "alice", SortBy: "createdAt", Sort: "asc"})
+	s.Require().NoError(err)
+	s.Require().Len(mine, 2)
+	s.Equal(model.Scrape, mine[0].Type)
+
+	today := &model.GetJobsQuery{CreatedFrom: base, CreatedTo: base.Add(12 * time.Hour), Type: model.Match}
+	matched, err := s.repo.GetAll(s.ctx, today)
+	s.Require().NoError(err)
+	s.Require().Len(matched, 1)
+	s.Equal(model.JobStatusFailed, matched[0].Status)
+
+	count, err := s.repo.Count(s.ctx, &model.GetJobsQuery{ClientID: "c1"})
+	s.Require().NoError(err)
+	s.Equal(2, count)
+
+	count, err = s.repo.Count(s.ctx, &model.GetJobsQuery{UpdatedFrom: base.Add(2 * time.Hour)})
+	s.Require().NoError(err)
+	s.Equal(1, count)
+}
+
+func (s *JobRepositorySuite) TestFindStale() {
+	now := time.Now()
+	stale, err := s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, Status: model.JobStatusPending, UpdatedAt: now.Add(-2 * time.Hour)})
+	s.Require().NoError(err)
+	_, err = s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, Status: model.JobStatusProcessing, UpdatedAt: now})
+	s.Require().NoError(err)
+	_, err = s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, Status: model.JobStatusCompleted, UpdatedAt: now.Add(-2 * time.Hour)})
+	s.Require().NoError(err)
+	_, err = s.repo.Create(s.ctx, &model.Job{Type: model.Match, Status: model.JobStatusPending, UpdatedAt: now.Add(-2 * time.Hour)})
+	s.Require().NoError(err)
+
+	active := []model.JobStatus{model.JobStatusPending, model.JobStatusProcessing}
+	jobs, err := s.repo.FindStale(s.ctx, model.Scrape, active, now.Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(jobs, 1)
+	s.Equal(stale, jobs[0].ID.Hex())
+}
+
+func (s *JobRepositorySuite) TestMatchReviews() {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	first, second := bson.NewObjectID(), bson.NewObjectID()
+	pending, err := s.repo.Create(s.ctx, &model.Job{
+		Type:         model.Match,
+		Status:       model.JobStatusCompleted,
+		Input:        bson.M{"file_name": "statement.pdf"},
+		FileBytes:    "JVBERi0=",
+		MatchResults: []model.MatchResult{{ID: first, ConfidenceScore: 0.9}, {ID: second, ConfidenceScore: 0.3}},
+		UpdatedAt:    now,
+	})
+	s.Require().NoError(err)
+	_, err = s.repo.Create(s.ctx, &model.Job{Type: model.Match, Status: model.JobStatusProcessing, MatchResults: []model.MatchResult{{ID: first}}})
+	s.Require().NoError(err)
+
+	jobs, err := s.repo.GetPendingMatches(s.ctx, &model.GetPendingMatchesQuery{})
+	s.Require().NoError(err)
+	s.Require().Len(jobs, 1)
+	s.Equal(pending, jobs[0].ID.Hex())
+	s.Equal("statement.pdf", jobs[0].Input["file_name"])
+	s.Empty(jobs[0].FileBytes)
+
+	s.Require().NoError(s.repo.SetMatchReview(s.ctx, pending, first, &model.MatchReview{Decision: model.MatchAccepted, Reviewer: "alice", ReviewedAt: now}))
+	err = s.repo.SetMatchReview(s.ctx, pending, first, &model.MatchReview{Decision: model.MatchRejected, Reviewer: "bob", ReviewedAt: now})
+	s.ErrorIs(err, errorx.ErrConflict)
+	err = s.repo.SetMatchReview(s.ctx, pending, bson.NewObjectID(), &model.MatchReview{Decision: model.MatchRejected})
+	s.ErrorIs(err, errorx.ErrNotFound)
+
+	count, err := s.repo.CountPendingMatches(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	s.Require().NoError(s.repo.SetMatchReview(s.ctx, pending, second, &model.MatchReview{Decision: model.MatchRejected, Reviewer: "alice", ReviewedAt: now}))
+	count, err = s.repo.CountPendingMatches(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(0, count)
+
+	stats, err := s.repo.GetMatchStats(s.ctx, &model.GetMatchStatsQuery{Reviewer: "alice"})
+	s.Require().NoError(err)
+	s.Require().Len(stats, 1)
+	s.Equal("alice", stats[0].Reviewer)
+	s.Equal(2, stats[0].Reviewed)
+	s.Equal(1, stats[0].Accepted)
+	s.InDelta(0.9, stats[0].AcceptedScore, 0.001)
+	s.InDelta(0.3, stats[0].RejectedScore, 0.001)
+}
+
+func (s *JobRepositorySuite) TestGetStats() {
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	jobs := []*model.Job{
+		{Type: model.Scrape, Status: model.JobStatusCompleted, CreatedAt: day, UpdatedAt: day.Add(10 * time.Second)},
+		{Type: model.Scrape, Status: model.JobStatusCompleted, CreatedAt: day, UpdatedAt: day.Add(20 * time.Second)},
+		{Type: model.Scrape, Status: model.JobStatusCompleted, CreatedAt: day, UpdatedAt: day.Add(30 * time.Second)},
+		{Type: model.Scrape, Status: model.JobStatusFailed, CreatedAt: day.Add(24 * time.Hour), UpdatedAt: day.Add(24 * time.Hour),
+			Logs: []model.JobLog{{Message: "Job started"}, {Message: "Scraper timed out"}}},
+		{Type: model.Match, Status: model.JobStatusPending, CreatedAt: day.Add(24 * time.Hour), UpdatedAt: day.Add(24 * time.Hour)},
+		{Type: model.Scrape, Status: model.JobStatusCompleted, CreatedAt: day.AddDate(0, 1, 0), UpdatedAt: day.AddDate(0, 1, 0)},
+		// retried a day later, which doesn't make it a day long job
+		{Type: model.Batch, Status: model.JobStatusCompleted, CreatedAt: day, UpdatedAt: day.Add(24 * time.Hour)},
+	}
+	for _, job := range jobs {
+		_, err := s.repo.Create(s.ctx, job)
+		s.Require().NoError(err)
+	}
+
+	stats, err := s.repo.GetStats(s.ctx, &model.GetJobStatsQuery{From: day, To: day.AddDate(0, 0, 7)})
+	s.Require().NoError(err)
+	s.Equal(6, stats.Total)
+	s.Equal(map[model.JobStatus]int{model.JobStatusCompleted: 4, model.JobStatusFailed: 1, model.JobStatusPending: 1}, stats.ByStatus)
+	s.Equal(map[model.JobType]int{model.Scrape: 4, model.Match: 1, model.Batch: 1}, stats.ByType)
+	s.Equal(model.JobDurationStats{Count: 3, P50Seconds: 20, P95Seconds: 30}, stats.Duration)
+	s.Equal([]model.JobFailureCount{{Message: "Scraper timed out", Count: 1}}, stats.Failures)
+	s.Equal([]model.JobThroughput{
+		{Date: "2025-03-01", Created: 4, Completed: 4},
+		{Date: "2025-03-02", Created: 2, Failed: 1},
+	}, stats.Throughput)
+
+	stats, err = s.repo.GetStats(s.ctx, &model.GetJobStatsQuery{Type: model.Match, From: day, To: day.AddDate(0, 0, 7)})
+	s.Require().NoError(err)
+	s.Equal(1, stats.Total)
+	s.Zero(stats.Duration.Count)
+}
+
+func (s *JobRepositorySuite) TestBatches() {
+	now := time.Now()
+	batchID, err := s.repo.Create(s.ctx, &model.Job{Type: model.Batch, Status: model.JobStatusPending, CreatedAt: now, UpdatedAt: now})
+	s.Require().NoError(err)
+
+	failedID, err := s.repo.Create(s.ctx, &model.Job{Type: model.Scrape, BatchID: batchID, Attempt: 1, Status: model.JobStatusFailed, CreatedAt: now, UpdatedAt: now})
+	s.Require().NoError(err)
+	jobs := []*model.Job{
+		{Type: model.Scrape, BatchID: batchID, Attempt: 2, RootID: failedID, ParentID: failedID, Status: model.JobStatusProcessing, Progress: 40, CreatedAt: now, UpdatedAt: now},
+		{Type: model.Scrape, BatchID: batchID, Attempt: 1, Status: model.JobStatusCompleted, CreatedAt: now, UpdatedAt: now},
+		{Type: model.Scrape, BatchID: batchID, Attempt: 1, Status: model.JobStatusFailed, CreatedAt: now, UpdatedAt: now},
+		{Type: model.Scrape, Attempt: 1, Status: model.JobStatusPending, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, job := range jobs {
+		_, err := s.repo.Create(s.ctx, job)
+		s.Require().NoError(err)
+	}
+
+	counts, progress, err := s.repo.GetBatchCounts(s.ctx, batchID)
+	s.Require().NoError(err)
+	s.Equal(&model.BatchCounts{Total: 3, Processing: 1, Completed: 1, Failed: 1}, counts)
+	s.Equal(80, progress) // (40 + 100 + 100) / 3
+
+	failed, err := s.repo.FindBatchChildren(s.ctx, batchID, []model.JobStatus{model.JobStatusFailed})
+	s.Require().NoError(err)
+	s.Require().Len(failed, 1)
+	s.NotEqual(failedID, failed[0].ID.Hex())
+
+	total, err := s.repo.Count(s.ctx, &model.GetJobsQuery{BatchID: batchID})
+	s.Require().NoError(err)
+	s.Equal(4, total)
+
+	batch, err := s.repo.GetOne(s.ctx, batchID)
+	s.Require().NoError(err)
+	updatedAt, err := s.repo.UpdateBatch(s.ctx, batchID, batch.UpdatedAt, model.JobStatusProcessing, progress, counts)
+	s.Require().NoError(err)
+	batch, err = s.repo.GetOne(s.ctx, batchID)
+	s.Require().NoError(err)
+	s.Equal(model.JobStatusProcessing, batch.Status)
+	s.Equal(counts, batch.Children)
+	s.True(updatedAt.Equal(batch.UpdatedAt))
+
+	// a refresh that read the batch before that update must not overwrite it
+	_, err = s.repo.UpdateBatch(s.ctx, batchID, now, model.JobStatusFailed, 100, counts)
+	s.ErrorIs(err, errorx.ErrConflict)
+	_, err = s.repo.UpdateBatch(s.ctx, batchID, updatedAt, model.JobStatusPartial, 100, counts)
+	s.NoError(err)
+
+	_, err = s.repo.UpdateBatch(s.ctx, failedID, now, model.JobStatusCompleted, 100, counts)
+	s.ErrorIs(err, errorx.ErrNotFound)
+
+	active, err := s.repo.CountActive(s.ctx, "")
+	s.Require().NoError(err)
+	s.Equal(2, active) // the batch itself isn't counted
+}
+
 func TestJobRepositorySuite(t *testing.T) {
 	suite.Run(t, new(JobRepositorySuite))
 }
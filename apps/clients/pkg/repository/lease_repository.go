@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// LeaseRepository hands out named, expiring leases so that only one replica runs a background worker at a time
+type LeaseRepository interface {
+	Acquire(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name string, holder string) error
+}
+
+type mongoLeaseRepository struct {
+	leaseCollection *mongo.Collection
+}
+
+func NewMongoLeaseRepository(storage *MongoStorage) LeaseRepository {
+	return &mongoLeaseRepository{leaseCollection: storage.leaseCollection}
+}
+
+// Acquire takes the lease for holder if it is free, expired or already held by holder, and extends it by ttl
+func (r *mongoLeaseRepository) Acquire(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	filter := bson.D{
+		{Key: "_id", Value: name},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "holder", Value: holder}},
+			bson.D{{Key: "expiresAt", Value: bson.D{{Key: "$lt", Value: now}}}},
+		}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "holder", Value: holder},
+		{Key: "expiresAt", Value: now.Add(ttl)},
+	}}}
+
+	_, err := r.leaseCollection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		// the lease exists and is held by someone else, so the upsert collided with it
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: error acquiring lease", errorx.ErrDependencyFailed)
+	}
+	return true, nil
+}
+
+// Release gives up the lease early if holder still has it
+func (r *mongoLeaseRepository) Release(ctx context.Context, name string, holder string) error {
+	_, err := r.leaseCollection.DeleteOne(ctx, bson.D{{Key: "_id", Value: name}, {Key: "holder", Value: holder}})
+	if err != nil {
+		return fmt.Errorf("%w: error releasing lease", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+type LeaseRepositorySuite struct {
+	suite.Suite
+	repo    repository.LeaseRepository
+	storage *repository.MongoStorage
+	cleanup func()
+	ctx     context.Context
+}
+
+func (s *LeaseRepositorySuite) SetupSuite() {
+	s.storage, s.cleanup = repository.NewTestMongoStorage(s.T())
+	s.repo = repository.NewMongoLeaseRepository(s.storage)
+	s.ctx = context.TODO()
+}
+
+func (s *LeaseRepositorySuite) TearDownSuite() {
+	s.cleanup()
+}
+
+func (s *LeaseRepositorySuite) SetupTest() {
+	_, err := s.storage.LeaseCollection().DeleteMany(s.ctx, map[string]any{})
+	s.Require().NoError(err)
+}
+
+func (s *LeaseRepositorySuite) TestAcquire() {
+	ok, err := s.repo.Acquire(s.ctx, "job-reaper", "replica-a", time.Minute)
+	s.Require().NoError(err)
+	s.True(ok)
+
+	ok, err = s.repo.Acquire(s.ctx, "job-reaper", "replica-b", time.Minute)
+	s.Require().NoError(err)
+	s.False(ok, "another replica holds an unexpired lease")
+
+	ok, err = s.repo.Acquire(s.ctx, "job-reaper", "replica-a", time.Minute)
+	s.Require().NoError(err)
+	s.True(ok, "the holder can renew its own lease")
+}
+
+func (s *LeaseRepositorySuite) TestAcquireExpired() {
+	ok, err := s.repo.Acquire(s.ctx, "job-reaper", "replica-a", -time.Second)
+	s.Require().NoError(err)
+	s.True(ok)
+
+	ok, err = s.repo.Acquire(s.ctx, "job-reaper", "replica-b", time.Minute)
+	s.Require().NoError(err)
+	s.True(ok)
+}
+
+func (s *LeaseRepositorySuite) TestRelease() {
+	_, err := s.repo.Acquire(s.ctx, "job-reaper", "replica-a", time.Minute)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.Release(s.ctx, "job-reaper", "replica-b"))
+	ok, err := s.repo.Acquire(s.ctx, "job-reaper", "replica-b", time.Minute)
+	s.Require().NoError(err)
+	s.False(ok, "releasing someone else's lease is a no-op")
+
+	s.Require().NoError(s.repo.Release(s.ctx, "job-reaper", "replica-a"))
+	ok, err = s.repo.Acquire(s.ctx, "job-reaper", "replica-b", time.Minute)
+	s.Require().NoError(err)
+	s.True(ok)
+}
+
+func TestLeaseRepositorySuite(t *testing.T) {
+	suite.Run(t, new(LeaseRepositorySuite))
+}
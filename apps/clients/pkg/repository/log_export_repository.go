@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// LogExportRepository keeps the manifests of audit log exports
+type LogExportRepository interface {
+	Create(ctx context.Context, export *model.LogExport) error
+	GetOne(ctx context.Context, exportID string) (*model.LogExport, error)
+}
+
+type mongoLogExportRepository struct {
+	logExportCollection *mongo.Collection
+}
+
+func NewMongoLogExportRepository(storage *MongoStorage) LogExportRepository {
+	return &mongoLogExportRepository{logExportCollection: storage.logExportCollection}
+}
+
+func (r *mongoLogExportRepository) Create(ctx context.Context, export *model.LogExport) error {
+	if export == nil {
+		return fmt.Errorf("%w: cannot insert nil log export", errorx.ErrInvalidInput)
+	}
+
+	if _, err := r.logExportCollection.InsertOne(ctx, export); err != nil {
+		return fmt.Errorf("%w: insert failed", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+func (r *mongoLogExportRepository) GetOne(ctx context.Context, exportID string) (*model.LogExport, error) {
+	objID, err := bson.ObjectIDFromHex(exportID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid export ID", errorx.ErrInvalidInput)
+	}
+
+	var export model.LogExport
+	if err := r.logExportCollection.FindOne(ctx, bson.D{{Key: "_id", Value: objID}}).Decode(&export); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%w: log export with ID %s not found", errorx.ErrNotFound, exportID)
+		}
+		return nil, fmt.Errorf("%w: failed to query MongoDB", errorx.ErrDependencyFailed)
+	}
+	return &export, nil
+}
@@ -0,0 +1,44 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+func TestMongoLogExportRepository_CreateAndGet(t *testing.T) {
+	storage, cleanup := repository.NewTestMongoStorage(t)
+	defer cleanup()
+
+	repo := repository.NewMongoLogExportRepository(storage)
+
+	from := time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+	export := &model.LogExport{
+		ID:        bson.NewObjectID(),
+		Format:    model.ExportFormatCSV,
+		Filters:   model.LogExportFilters{Actor: "tester", From: &from},
+		Rows:      3,
+		SHA256:    "abc",
+		CreatedBy: "auditor",
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+		Signature: "def",
+	}
+	assert.NoError(t, repo.Create(context.TODO(), export))
+
+	fetched, err := repo.GetOne(context.TODO(), export.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, export, fetched)
+
+	_, err = repo.GetOne(context.TODO(), bson.NewObjectID().Hex())
+	assert.ErrorIs(t, err, errorx.ErrNotFound)
+
+	_, err = repo.GetOne(context.TODO(), "not-an-id")
+	assert.ErrorIs(t, err, errorx.ErrInvalidInput)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"time"
 
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
@@ -25,61 +28,185 @@ type LogRepository interface {
 	GetAll(ctx context.Context, query *model.GetLogsQuery) ([]model.Log, error)
 	GetOne(ctx context.Context, logID string) (*model.Log, error)
 	Count(ctx context.Context) (int, error)
+	ForEach(ctx context.Context, query *model.GetLogsQuery, fn func(l *model.Log) error) error
+	Head(ctx context.Context) (*model.Log, error)
+	ForEachChained(ctx context.Context, after int64, fn func(l *model.Log) error) error
+	FindExpired(ctx context.Context, filter *model.ExpiredLogsFilter, limit int) ([]model.Log, error)
+	Archive(ctx context.Context, logIDs []bson.ObjectID, archive string) error
+	ArchivedRunEnd(ctx context.Context, after int64) (*model.Log, error)
+	DeleteArchived(ctx context.Context, through int64) error
 }
 
+const (
+	// maxChainAttempts bounds how often Create retries when other logs are appended concurrently
+	maxChainAttempts = 10
+	// chainRetryBase is the most Create waits before its first retry, doubling after each
+	chainRetryBase = 5 * time.Millisecond
+)
+
+// Create appends the log to the chain, giving it the next sequence number and hashing it with the hash of the
+// current head. The unique index on seq stops two writers from appending after the same head, the loser waits a
+// random, growing while so that writers that collided don't collide again, and tries again.
 func (r *mongoLogRepository) Create(ctx context.Context, log *model.Log) (string, error) {
 	if log == nil {
 		return "", fmt.Errorf("%w: cannot insert nil log", errorx.ErrInvalidInput)
 	}
+	log.Timestamp = log.Timestamp.UTC().Truncate(time.Millisecond)
 
-	result, err := r.logCollection.InsertOne(ctx, log)
+	for attempt := 0; attempt < maxChainAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", fmt.Errorf("%w: %v", errorx.ErrDependencyFailed, ctx.Err())
+			case <-time.After(rand.N(chainRetryBase << (attempt - 1))):
+			}
+		}
+
+		head, err := r.Head(ctx)
+		if err != nil {
+			return "", err
+		}
+		log.Seq, log.PrevHash = 1, ""
+		if head != nil {
+			log.Seq, log.PrevHash = head.Seq+1, head.Hash
+		}
+		log.Hash = log.ChainHash()
+
+		result, err := r.logCollection.InsertOne(ctx, log)
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: insert failed", errorx.ErrDependencyFailed)
+		}
+
+		insertedID, ok := result.InsertedID.(bson.ObjectID)
+		if !ok {
+			return "", fmt.Errorf("%w: failed to convert inserted ID", errorx.ErrInternal)
+		}
+		return insertedID.Hex(), nil
+	}
+	return "", fmt.Errorf("%w: log chain is busy, please try again", errorx.ErrConflict)
+}
+
+// Head returns the last log of the chain, or nil if no log has been chained yet
+func (r *mongoLogRepository) Head(ctx context.Context) (*model.Log, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}})
+	var log model.Log
+	err := r.logCollection.FindOne(ctx, bson.D{{Key: "seq", Value: bson.D{{Key: "$exists", Value: true}}}}, opts).Decode(&log)
 	if err != nil {
-		return "", fmt.Errorf("%w: insert failed", errorx.ErrDependencyFailed)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: error finding head of log chain", errorx.ErrDependencyFailed)
 	}
+	return &log, nil
+}
 
-	insertedID, ok := result.InsertedID.(bson.ObjectID)
-	if !ok {
-		return "", fmt.Errorf("%w: failed to convert inserted ID", errorx.ErrInternal)
+func (r *mongoLogRepository) GetAll(ctx context.Context, query *model.GetLogsQuery) ([]model.Log, error) {
+	filter, err := logsFilter(query)
+	if err != nil {
+		return nil, err
 	}
 
-	return insertedID.Hex(), nil
+	skip := (query.Page - 1) * query.PageSize
+	limit := query.PageSize
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	cursor, err := r.logCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+
+	var logs []model.Log
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, fmt.Errorf("%w: mongo decode error", errorx.ErrInternal)
+	}
+
+	return logs, nil
 }
 
-func (r *mongoLogRepository) GetAll(ctx context.Context, query *model.GetLogsQuery) ([]model.Log, error) {
-	filter := bson.M{}
+// ForEach streams every log matching the query's filters to fn, oldest first, stopping at the first error.
+// Paging is ignored.
+func (r *mongoLogRepository) ForEach(ctx context.Context, query *model.GetLogsQuery, fn func(l *model.Log) error) error {
+	filter, err := logsFilter(query)
+	if err != nil {
+		return err
+	}
 
-	if query.ClientID != "" {
-		objID, err := bson.ObjectIDFromHex(query.ClientID)
-		if err != nil {
-			return nil, fmt.Errorf("%w: clientId '%s' is not a valid ObjectID", errorx.ErrInvalidInput, query.ClientID)
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.logCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var log model.Log
+		if err := cursor.Decode(&log); err != nil {
+			return fmt.Errorf("%w: decode error", errorx.ErrInternal)
+		}
+		if err := fn(&log); err != nil {
+			return err
 		}
-		filter["clientId"] = objID
 	}
-	if query.Operation != "" {
-		filter["operation"] = query.Operation
+
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("%w: mongo cursor error", errorx.ErrDependencyFailed)
 	}
-	if query.Actor != "" {
-		filter["actor"] = query.Actor
+	return nil
+}
+
+// ForEachChained streams the chained logs after the given sequence number to fn in sequence order, stopping at the
+// first error
+func (r *mongoLogRepository) ForEachChained(ctx context.Context, after int64, fn func(l *model.Log) error) error {
+	filter := bson.D{{Key: "seq", Value: bson.D{{Key: "$gt", Value: after}}}}
+	cursor, err := r.logCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
 	}
+	defer cursor.Close(ctx)
 
-	timeFilter := bson.M{}
-	if !query.From.IsZero() {
-		timeFilter["$gte"] = query.From
+	for cursor.Next(ctx) {
+		var log model.Log
+		if err := cursor.Decode(&log); err != nil {
+			return fmt.Errorf("%w: decode error", errorx.ErrInternal)
+		}
+		if err := fn(&log); err != nil {
+			return err
+		}
 	}
-	if !query.To.IsZero() {
-		timeFilter["$lte"] = query.To
+
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("%w: mongo cursor error", errorx.ErrDependencyFailed)
 	}
-	if len(timeFilter) > 0 {
-		filter["timestamp"] = timeFilter
+	return nil
+}
+
+// FindExpired returns up to limit logs matching the filter that haven't been archived yet, oldest first
+func (r *mongoLogRepository) FindExpired(ctx context.Context, filter *model.ExpiredLogsFilter, limit int) ([]model.Log, error) {
+	operations := filter.Operations
+	if operations == nil {
+		operations = []model.Operation{}
+	}
+	operationFilter := bson.M{"$in": operations}
+	if filter.OtherOperations {
+		operationFilter = bson.M{"$nin": operations}
 	}
 
-	skip := (query.Page - 1) * query.PageSize
-	limit := query.PageSize
-	opts := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(limit)).SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	query := bson.M{
+		"operation":  operationFilter,
+		"timestamp":  bson.M{"$lt": filter.Before},
+		"archivedIn": bson.M{"$exists": false},
+	}
+	if len(filter.HeldClientIDs) > 0 {
+		query["clientId"] = bson.M{"$nin": filter.HeldClientIDs}
+	}
 
-	cursor, err := r.logCollection.Find(ctx, filter, opts)
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.logCollection.Find(ctx, query, opts)
 	if err != nil {
 		return nil, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
 	}
@@ -88,10 +215,83 @@ func (r *mongoLogRepository) GetAll(ctx context.Context, query *model.GetLogsQue
 	if err := cursor.All(ctx, &logs); err != nil {
 		return nil, fmt.Errorf("%w: mongo decode error", errorx.ErrInternal)
 	}
-
 	return logs, nil
 }
 
+// Archive purges logs once they have been written to the named archive. Chained logs are cut down to a stub, keeping
+// what the chain needs to be verified, until DeleteArchived removes them. Logs from before the chain are deleted.
+// Logs already archived are left alone.
+func (r *mongoLogRepository) Archive(ctx context.Context, logIDs []bson.ObjectID, archive string) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+	notArchived := bson.M{"$exists": false}
+
+	chained := bson.M{"_id": bson.M{"$in": logIDs}, "seq": bson.M{"$exists": true}, "archivedIn": notArchived}
+	update := bson.M{
+		"$set":   bson.M{"archivedIn": archive},
+		"$unset": bson.M{"clientId": "", "actor": "", "details": "", "changes": ""},
+	}
+	if _, err := r.logCollection.UpdateMany(ctx, chained, update); err != nil {
+		return fmt.Errorf("%w: error purging archived logs", errorx.ErrDependencyFailed)
+	}
+
+	unchained := bson.M{"_id": bson.M{"$in": logIDs}, "seq": bson.M{"$exists": false}}
+	if _, err := r.logCollection.DeleteMany(ctx, unchained); err != nil {
+		return fmt.Errorf("%w: error purging archived logs", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+// ArchivedRunEnd returns the last log of the unbroken run of archived logs right after the given sequence number, or
+// nil if there is no such run. The head of the chain is never part of the run, since new logs are chained to it, and
+// a run with a missing log stops before the gap, so the gap is still found when the chain is verified.
+func (r *mongoLogRepository) ArchivedRunEnd(ctx context.Context, after int64) (*model.Log, error) {
+	head, err := r.Head(ctx)
+	if err != nil || head == nil {
+		return nil, err
+	}
+	end := head.Seq - 1
+
+	live := bson.D{{Key: "seq", Value: bson.D{{Key: "$gt", Value: after}}}, {Key: "archivedIn", Value: bson.D{{Key: "$exists", Value: false}}}}
+	var first model.Log
+	err = r.logCollection.FindOne(ctx, live, options.FindOne().SetSort(bson.D{{Key: "seq", Value: 1}})).Decode(&first)
+	if err == nil {
+		end = min(end, first.Seq-1)
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: error finding archived logs", errorx.ErrDependencyFailed)
+	}
+	if end <= after {
+		return nil, nil
+	}
+
+	// the sequence numbers from after+1 to end must all be there
+	run := bson.D{{Key: "seq", Value: bson.D{{Key: "$gt", Value: after}, {Key: "$lte", Value: end}}}}
+	count, err := r.logCollection.CountDocuments(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error counting archived logs", errorx.ErrDependencyFailed)
+	}
+	if count != end-after {
+		return nil, nil
+	}
+
+	var last model.Log
+	if err := r.logCollection.FindOne(ctx, bson.D{{Key: "seq", Value: end}}).Decode(&last); err != nil {
+		return nil, fmt.Errorf("%w: error finding archived logs", errorx.ErrDependencyFailed)
+	}
+	return &last, nil
+}
+
+// DeleteArchived deletes the archived logs up to and including the given sequence number, once a checkpoint records
+// where the chain picks up after them
+func (r *mongoLogRepository) DeleteArchived(ctx context.Context, through int64) error {
+	filter := bson.D{{Key: "seq", Value: bson.D{{Key: "$lte", Value: through}}}, {Key: "archivedIn", Value: bson.D{{Key: "$exists", Value: true}}}}
+	if _, err := r.logCollection.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("%w: error deleting archived logs", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
 func (r *mongoLogRepository) GetOne(ctx context.Context, logID string) (*model.Log, error) {
 	objID, err := bson.ObjectIDFromHex(logID)
 	if err != nil {
@@ -110,10 +310,46 @@ func (r *mongoLogRepository) GetOne(ctx context.Context, logID string) (*model.L
 	return &log, nil
 }
 
+// Count counts the logs GetAll can list, leaving out the stubs of archived logs
 func (r *mongoLogRepository) Count(ctx context.Context) (int, error) {
-	count, err := r.logCollection.CountDocuments(ctx, bson.M{})
+	count, err := r.logCollection.CountDocuments(ctx, bson.M{"archivedIn": bson.M{"$exists": false}})
 	if err != nil {
 		return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
 	}
 	return int(count), nil
 }
+
+// logsFilter builds the filter shared by GetAll and ForEach. Client IDs are stored as hex strings on logs, and the
+// stubs left by archived logs are never listed.
+func logsFilter(query *model.GetLogsQuery) (bson.M, error) {
+	filter := bson.M{"archivedIn": bson.M{"$exists": false}}
+
+	if query.ClientID != "" {
+		if _, err := bson.ObjectIDFromHex(query.ClientID); err != nil {
+			return nil, fmt.Errorf("%w: clientId '%s' is not a valid ObjectID", errorx.ErrInvalidInput, query.ClientID)
+		}
+		filter["clientId"] = query.ClientID
+	}
+	if query.Operation != "" {
+		filter["operation"] = query.Operation
+	}
+	if query.Actor != "" {
+		filter["actor"] = query.Actor
+	}
+	if query.Path != "" {
+		// a path also matches the changes under it, "profile" matches "profile.netWorth"
+		filter["changes.path"] = bson.Regex{Pattern: "^" + regexp.QuoteMeta(query.Path) + `(\.|$)`}
+	}
+
+	timeFilter := bson.M{}
+	if !query.From.IsZero() {
+		timeFilter["$gte"] = query.From
+	}
+	if !query.To.IsZero() {
+		timeFilter["$lte"] = query.To
+	}
+	if len(timeFilter) > 0 {
+		filter["timestamp"] = timeFilter
+	}
+	return filter, nil
+}
@@ -2,13 +2,16 @@ package repository_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 
@@ -77,17 +80,253 @@ func TestMongoLogRepository_Count(t *testing.T) {
 	repo := repository.NewMongoLogRepository(storage)
 
 	// Insert some logs
+	var first string
 	for i := 0; i < 3; i++ {
 		log := &model.Log{
 			Actor:     "counter",
 			Operation: model.OperationCreate,
 			Timestamp: time.Now(),
 		}
-		_, err := repo.Create(context.TODO(), log)
+		id, err := repo.Create(context.TODO(), log)
 		assert.NoError(t, err)
+		if i == 0 {
+			first = id
+		}
 	}
 
 	count, err := repo.Count(context.TODO())
 	assert.NoError(t, err)
 	assert.Equal(t, 3, count)
+
+	// archived stubs are not listed, so they are not counted either
+	id, err := bson.ObjectIDFromHex(first)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Archive(context.TODO(), []bson.ObjectID{id}, "logs.ndjson.gz"))
+	count, err = repo.Count(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestMongoLogRepository_ForEach(t *testing.T) {
+	storage, cleanup := repository.NewTestMongoStorage(t)
+	defer cleanup()
+
+	repo := repository.NewMongoLogRepository(storage)
+
+	clientID := "65f1a2b3c4d5e6f708192a3b"
+	now := time.Now()
+	logs := []*model.Log{
+		{Actor: "tester", ClientID: clientID, Operation: model.OperationUpdate, Timestamp: now},
+		{Actor: "tester", ClientID: clientID, Operation: model.OperationCreate, Timestamp: now.Add(-time.Hour)},
+		{Actor: "tester", Operation: model.OperationImport, Timestamp: now.Add(-2 * time.Hour)},
+	}
+	for _, l := range logs {
+		_, err := repo.Create(context.TODO(), l)
+		assert.NoError(t, err)
+	}
+
+	var operations []model.Operation
+	err := repo.ForEach(context.TODO(), &model.GetLogsQuery{ClientID: clientID, Page: 2, PageSize: 1}, func(l *model.Log) error {
+		operations = append(operations, l.Operation)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Operation{model.OperationCreate, model.OperationUpdate}, operations)
+
+	err = repo.ForEach(context.TODO(), &model.GetLogsQuery{ClientID: "not-an-id"}, func(l *model.Log) error { return nil })
+	assert.ErrorIs(t, err, errorx.ErrInvalidInput)
+}
+
+func TestMongoLogRepository_Chain(t *testing.T) {
+	storage, cleanup := repository.NewTestMongoStorage(t)
+	defer cleanup()
+
+	repo := repository.NewMongoLogRepository(storage)
+
+	head, err := repo.Head(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, head)
+
+	// a log from before the chain is left out of it
+	_, err = storage.LogCollection().InsertOne(context.TODO(), model.Log{Actor: "legacy", Timestamp: time.Now()})
+	assert.NoError(t, err)
+
+	// enough writers that most of them collide on the head and have to back off
+	const writers = 16
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Create(context.TODO(), &model.Log{Actor: "tester", Operation: model.OperationUpdate, Timestamp: time.Now()})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	var chained []model.Log
+	err = repo.ForEachChained(context.TODO(), 0, func(l *model.Log) error {
+		chained = append(chained, *l)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, chained, writers)
+	prevHash := ""
+	for i, l := range chained {
+		assert.Equal(t, int64(i+1), l.Seq)
+		assert.Equal(t, prevHash, l.PrevHash)
+		assert.Equal(t, l.ChainHash(), l.Hash)
+		prevHash = l.Hash
+	}
+
+	head, err = repo.Head(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(writers), head.Seq)
+
+	var seqs []int64
+	err = repo.ForEachChained(context.TODO(), writers-2, func(l *model.Log) error {
+		seqs = append(seqs, l.Seq)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{writers - 1, writers}, seqs)
+}
+
+func TestMongoLogRepository_TruncateArchived(t *testing.T) {
+	storage, cleanup := repository.NewTestMongoStorage(t)
+	defer cleanup()
+
+	repo := repository.NewMongoLogRepository(storage)
+
+	ids := make([]bson.ObjectID, 5)
+	for i := range ids {
+		id, err := repo.Create(context.TODO(), &model.Log{Actor: "tester", Operation: model.OperationGet, Timestamp: time.Now()})
+		assert.NoError(t, err)
+		ids[i], err = bson.ObjectIDFromHex(id)
+		assert.NoError(t, err)
+	}
+
+	end, err := repo.ArchivedRunEnd(context.TODO(), 0)
+	assert.NoError(t, err)
+	assert.Nil(t, end)
+
+	// the run stops before the first live log, and never takes in the head
+	assert.NoError(t, repo.Archive(context.TODO(), []bson.ObjectID{ids[0], ids[1], ids[3], ids[4]}, "logs.ndjson.gz"))
+	end, err = repo.ArchivedRunEnd(context.TODO(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), end.Seq)
+
+	assert.NoError(t, repo.DeleteArchived(context.TODO(), end.Seq))
+	var seqs []int64
+	err = repo.ForEachChained(context.TODO(), 0, func(l *model.Log) error {
+		seqs = append(seqs, l.Seq)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 4, 5}, seqs)
+
+	assert.NoError(t, repo.Archive(context.TODO(), []bson.ObjectID{ids[2]}, "logs.ndjson.gz"))
+	end, err = repo.ArchivedRunEnd(context.TODO(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), end.Seq)
+
+	// a gap in the run leaves it alone
+	_, err = storage.LogCollection().DeleteOne(context.TODO(), bson.M{"seq": 3})
+	assert.NoError(t, err)
+	end, err = repo.ArchivedRunEnd(context.TODO(), 2)
+	assert.NoError(t, err)
+	assert.Nil(t, end)
+}
+
+func TestMongoLogRepository_GetAllByChangedPath(t *testing.T) {
+	storage, cleanup := repository.NewTestMongoStorage(t)
+	defer cleanup()
+
+	repo := repository.NewMongoLogRepository(storage)
+
+	logs := []*model.Log{
+		{Actor: "tester", Operation: model.OperationUpdate, Timestamp: time.Now(), Changes: []model.Change{
+			{Path: "profile.netWorth", Old: 100, New: 250, Source: model.ChangeSourceManual},
+		}},
+		{Actor: "tester", Operation: model.OperationUpdate, Timestamp: time.Now(), Changes: []model.Change{
+			{Path: "profile.netWorthHistory", New: "x", Source: model.ChangeSourceManual},
+		}},
+		{Actor: "tester", Operation: model.OperationGet, Timestamp: time.Now()},
+	}
+	for _, l := range logs {
+		_, err := repo.Create(context.TODO(), l)
+		assert.NoError(t, err)
+	}
+
+	result, err := repo.GetAll(context.TODO(), &model.GetLogsQuery{Path: "profile.netWorth", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "profile.netWorth", result[0].Changes[0].Path)
+
+	// a parent path matches the changes under it
+	result, err = repo.GetAll(context.TODO(), &model.GetLogsQuery{Path: "profile", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+}
+
+func TestMongoLogRepository_FindExpiredAndArchive(t *testing.T) {
+	storage, cleanup := repository.NewTestMongoStorage(t)
+	defer cleanup()
+
+	repo := repository.NewMongoLogRepository(storage)
+	old := time.Now().AddDate(0, 0, -100)
+	held := "65f1a2b3c4d5e6f708192a3c"
+
+	_, err := storage.LogCollection().InsertOne(context.TODO(), model.Log{Actor: "legacy", Operation: model.OperationGet, Timestamp: old})
+	assert.NoError(t, err)
+	logs := []*model.Log{
+		{Actor: "tester", ClientID: "65f1a2b3c4d5e6f708192a3b", Operation: model.OperationGet, Details: "viewed", Timestamp: old},
+		{Actor: "tester", ClientID: held, Operation: model.OperationGet, Timestamp: old},
+		{Actor: "tester", Operation: model.OperationGet, Timestamp: time.Now()},
+		{Actor: "tester", Operation: model.OperationUpdate, Timestamp: old},
+	}
+	for _, l := range logs {
+		_, err := repo.Create(context.TODO(), l)
+		assert.NoError(t, err)
+	}
+
+	filter := &model.ExpiredLogsFilter{
+		Operations:    []model.Operation{model.OperationGet},
+		Before:        time.Now().AddDate(0, 0, -90),
+		HeldClientIDs: []string{held},
+	}
+	expired, err := repo.FindExpired(context.TODO(), filter, 10)
+	assert.NoError(t, err)
+	assert.Len(t, expired, 2)
+
+	others, err := repo.FindExpired(context.TODO(), &model.ExpiredLogsFilter{
+		Operations:      []model.Operation{model.OperationGet},
+		OtherOperations: true,
+		Before:          time.Now(),
+	}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, others, 1)
+	assert.Equal(t, model.OperationUpdate, others[0].Operation)
+
+	ids := []bson.ObjectID{expired[0].ID, expired[1].ID}
+	assert.NoError(t, repo.Archive(context.TODO(), ids, "logs-1.ndjson.gz"))
+
+	// the legacy log is deleted, the chained one is cut down to a stub that no longer expires
+	_, err = repo.GetOne(context.TODO(), expired[0].ID.Hex())
+	assert.ErrorIs(t, err, errorx.ErrNotFound)
+	stub, err := repo.GetOne(context.TODO(), expired[1].ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, "logs-1.ndjson.gz", stub.ArchivedIn)
+	assert.Empty(t, stub.ClientID)
+	assert.Empty(t, stub.Details)
+	assert.Equal(t, int64(1), stub.Seq)
+	assert.Equal(t, logs[0].Hash, stub.Hash)
+
+	expired, err = repo.FindExpired(context.TODO(), filter, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+
+	listed, err := repo.GetAll(context.TODO(), &model.GetLogsQuery{Operation: model.OperationGet, Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Len(t, listed, 2)
 }
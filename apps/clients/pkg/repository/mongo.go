@@ -1,28 +1,46 @@
 package repository
 
 import (
+	"context"
 	"log"
 
 	"github.com/owjoel/client-factpack/apps/clients/config"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 const (
-	database   = "client-factpack"
-	article    = "articles"
-	collection = "clients"
-	templates  = "templates"
-	jobs       = "jobs"
-	logs       = "logs"
+	database       = "client-factpack"
+	article        = "articles"
+	collection     = "clients"
+	templates      = "templates"
+	jobs           = "jobs"
+	logs           = "logs"
+	timeline       = "timeline"
+	feedback       = "articleFeedback"
+	notes          = "notes"
+	leases         = "leases"
+	outbox         = "outbox"
+	idempotency    = "idempotencyKeys"
+	logExports     = "logExports"
+	logAnchors     = "logAnchors"
+	logCheckpoints = "logCheckpoints"
 )
 
 type MongoStorage struct {
 	*mongo.Database
-	articleCollection *mongo.Collection
-	clientCollection  *mongo.Collection
-	jobCollection     *mongo.Collection
-	logCollection     *mongo.Collection
+	articleCollection     *mongo.Collection
+	clientCollection      *mongo.Collection
+	jobCollection         *mongo.Collection
+	logCollection         *mongo.Collection
+	timelineCollection    *mongo.Collection
+	feedbackCollection    *mongo.Collection
+	noteCollection        *mongo.Collection
+	leaseCollection       *mongo.Collection
+	outboxCollection      *mongo.Collection
+	idempotencyCollection *mongo.Collection
+	logExportCollection   *mongo.Collection
 }
 
 func InitMongo() *MongoStorage {
@@ -40,7 +58,21 @@ func InitMongo() *MongoStorage {
 	clientColl := db.Collection(collection)
 	jobColl := db.Collection(jobs)
 	logColl := db.Collection(logs)
-	return &MongoStorage{db, articleColl, clientColl, jobColl, logColl}
+	timelineColl := db.Collection(timeline)
+	feedbackColl := db.Collection(feedback)
+	noteColl := db.Collection(notes)
+	leaseColl := db.Collection(leases)
+	outboxColl := db.Collection(outbox)
+	idempotencyColl := db.Collection(idempotency)
+	logExportColl := db.Collection(logExports)
+	ensureArticleIndexes(articleColl)
+	ensureFeedbackIndexes(feedbackColl)
+	ensureJobIndexes(jobColl)
+	ensureLogIndexes(logColl)
+	ensureTimelineIndexes(timelineColl)
+	ensureOutboxIndexes(outboxColl)
+	ensureIdempotencyIndexes(idempotencyColl)
+	return &MongoStorage{db, articleColl, clientColl, jobColl, logColl, timelineColl, feedbackColl, noteColl, leaseColl, outboxColl, idempotencyColl, logExportColl}
 }
 
 func (s *MongoStorage) JobCollection() *mongo.Collection {
@@ -54,3 +86,123 @@ func (s *MongoStorage) ArticleCollection() *mongo.Collection {
 func (s *MongoStorage) ClientCollection() *mongo.Collection {
 	return s.clientCollection
 }
+
+func (s *MongoStorage) TimelineCollection() *mongo.Collection {
+	return s.timelineCollection
+}
+
+func (s *MongoStorage) FeedbackCollection() *mongo.Collection {
+	return s.feedbackCollection
+}
+
+func (s *MongoStorage) NoteCollection() *mongo.Collection {
+	return s.noteCollection
+}
+
+func (s *MongoStorage) LogCollection() *mongo.Collection {
+	return s.logCollection
+}
+
+func (s *MongoStorage) LeaseCollection() *mongo.Collection {
+	return s.leaseCollection
+}
+
+func (s *MongoStorage) OutboxCollection() *mongo.Collection {
+	return s.outboxCollection
+}
+
+func (s *MongoStorage) IdempotencyCollection() *mongo.Collection {
+	return s.idempotencyCollection
+}
+
+func (s *MongoStorage) LogExportCollection() *mongo.Collection {
+	return s.logExportCollection
+}
+
+// ensureArticleIndexes makes canonical URLs unique. Articles written by the pipelines before ingestion went through
+// the API have no canonical URL, so they are left out of the index.
+func ensureArticleIndexes(coll *mongo.Collection) {
+	index := mongo.IndexModel{
+		Keys: bson.D{{Key: "canonicalUrl", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.D{{Key: "canonicalUrl", Value: bson.D{{Key: "$exists", Value: true}}}}),
+	}
+	if _, err := coll.Indexes().CreateOne(context.Background(), index); err != nil {
+		log.Printf("error creating article indexes: %v", err)
+	}
+}
+
+// ensureFeedbackIndexes keeps one verdict per client and article, and backs the lookup of a client's exclusions
+func ensureFeedbackIndexes(coll *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "clientId", Value: 1}, {Key: "articleId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "clientId", Value: 1}, {Key: "canonicalUrl", Value: 1}}},
+	}
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		log.Printf("error creating feedback indexes: %v", err)
+	}
+}
+
+// ensureJobIndexes backs the most common job listings: a user's own jobs and a client's jobs, newest first, and
+// the lookup of a batch's children
+func ensureJobIndexes(coll *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "createdBy", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "clientId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "batchId", Value: 1}, {Key: "attempt", Value: -1}, {Key: "createdAt", Value: -1}}},
+	}
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		log.Printf("error creating job indexes: %v", err)
+	}
+}
+
+// ensureLogIndexes makes sequence numbers unique, so the log chain can't fork. Logs written before the chain
+// have no sequence number and are left out of the index. Expired logs are found by operation and age.
+func ensureLogIndexes(coll *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "seq", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.D{{Key: "seq", Value: bson.D{{Key: "$exists", Value: true}}}}),
+		},
+		{Keys: bson.D{{Key: "operation", Value: 1}, {Key: "timestamp", Value: 1}}},
+	}
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		log.Printf("error creating log indexes: %v", err)
+	}
+}
+
+// ensureTimelineIndexes backs reading a field's history and its latest point
+func ensureTimelineIndexes(coll *mongo.Collection) {
+	index := mongo.IndexModel{Keys: bson.D{{Key: "clientId", Value: 1}, {Key: "field", Value: 1}, {Key: "timestamp", Value: 1}}}
+	if _, err := coll.Indexes().CreateOne(context.Background(), index); err != nil {
+		log.Printf("error creating timeline indexes: %v", err)
+	}
+}
+
+// ensureOutboxIndexes backs the dispatcher's lookup of pending entries that are due, and the lookup of a job's entry
+// when the job is cancelled or failed
+func ensureOutboxIndexes(coll *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}},
+		{Keys: bson.D{{Key: "jobId", Value: 1}}},
+	}
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		log.Printf("error creating outbox indexes: %v", err)
+	}
+}
+
+// ensureIdempotencyIndexes lets Mongo delete idempotency keys once they expire
+func ensureIdempotencyIndexes(coll *mongo.Collection) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	if _, err := coll.Indexes().CreateOne(context.Background(), index); err != nil {
+		log.Printf("error creating idempotency indexes: %v", err)
+	}
+}
@@ -53,7 +53,15 @@ func NewTestMongoStorage(t *testing.T) (*MongoStorage, func()) {
 		clientCollection:  db.Collection("clients"),
 		jobCollection:     db.Collection("jobs"),
 		logCollection:     db.Collection("logs"),
+		timelineCollection: db.Collection("timeline"),
+		feedbackCollection: db.Collection("articleFeedback"),
+		noteCollection:     db.Collection("notes"),
+		leaseCollection:    db.Collection("leases"),
+		outboxCollection:   db.Collection("outbox"),
+		idempotencyCollection: db.Collection("idempotencyKeys"),
+		logExportCollection:   db.Collection("logExports"),
 	}
+	ensureLogIndexes(storage.logCollection)
 
 	cleanup := func() {
 		_ = client.Disconnect(context.TODO())
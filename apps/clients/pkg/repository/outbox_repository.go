@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type OutboxRepository interface {
+	Create(ctx context.Context, entry *model.OutboxEntry) (string, error)
+	Claim(ctx context.Context, lockFor time.Duration) (*model.OutboxEntry, error)
+	MarkDelivered(ctx context.Context, entryID bson.ObjectID, flowRunID string) error
+	Reschedule(ctx context.Context, entryID bson.ObjectID, next time.Time, lastError string) error
+	MarkFailed(ctx context.Context, entryID bson.ObjectID, lastError string) error
+	CancelForJob(ctx context.Context, jobID string, reason string) error
+}
+
+type mongoOutboxRepository struct {
+	outboxCollection *mongo.Collection
+}
+
+func NewMongoOutboxRepository(storage *MongoStorage) OutboxRepository {
+	return &mongoOutboxRepository{outboxCollection: storage.outboxCollection}
+}
+
+func (r *mongoOutboxRepository) Create(ctx context.Context, entry *model.OutboxEntry) (string, error) {
+	res, err := r.outboxCollection.InsertOne(ctx, entry)
+	if err != nil {
+		return "", fmt.Errorf("%w: error creating outbox entry", errorx.ErrDependencyFailed)
+	}
+
+	id, ok := res.InsertedID.(bson.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%w: error getting outbox entry ID", errorx.ErrInternal)
+	}
+	return id.Hex(), nil
+}
+
+// Claim takes the oldest pending entry that is due and counts an attempt against it. The entry isn't due again
+// until lockFor has passed, so other replicas skip it while it is delivered, and pick it up if this one dies.
+// It returns nil if nothing is due.
+func (r *mongoOutboxRepository) Claim(ctx context.Context, lockFor time.Duration) (*model.OutboxEntry, error) {
+	now := time.Now()
+	filter := bson.D{
+		{Key: "status", Value: model.OutboxStatusPending},
+		{Key: "nextAttemptAt", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "nextAttemptAt", Value: now.Add(lockFor)}, {Key: "updatedAt", Value: now}}},
+		{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var entry model.OutboxEntry
+	if err := r.outboxCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&entry); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: error claiming outbox entry", errorx.ErrDependencyFailed)
+	}
+	return &entry, nil
+}
+
+func (r *mongoOutboxRepository) MarkDelivered(ctx context.Context, entryID bson.ObjectID, flowRunID string) error {
+	return r.set(ctx, entryID, bson.D{
+		{Key: "status", Value: model.OutboxStatusDelivered},
+		{Key: "flowRunId", Value: flowRunID},
+		{Key: "lastError", Value: ""},
+	})
+}
+
+func (r *mongoOutboxRepository) Reschedule(ctx context.Context, entryID bson.ObjectID, next time.Time, lastError string) error {
+	return r.set(ctx, entryID, bson.D{
+		{Key: "nextAttemptAt", Value: next},
+		{Key: "lastError", Value: lastError},
+	})
+}
+
+func (r *mongoOutboxRepository) MarkFailed(ctx context.Context, entryID bson.ObjectID, lastError string) error {
+	return r.set(ctx, entryID, bson.D{
+		{Key: "status", Value: model.OutboxStatusFailed},
+		{Key: "lastError", Value: lastError},
+	})
+}
+
+// CancelForJob withdraws the job's entry if it is still waiting to be delivered, so no flow run is started for it.
+// Entries already delivered or given up on are left alone.
+func (r *mongoOutboxRepository) CancelForJob(ctx context.Context, jobID string, reason string) error {
+	filter := bson.D{{Key: "jobId", Value: jobID}, {Key: "status", Value: model.OutboxStatusPending}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: model.OutboxStatusCancelled},
+		{Key: "lastError", Value: reason},
+		{Key: "updatedAt", Value: time.Now()},
+	}}}
+	if _, err := r.outboxCollection.UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("%w: error cancelling outbox entry", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
+
+func (r *mongoOutboxRepository) set(ctx context.Context, entryID bson.ObjectID, fields bson.D) error {
+	fields = append(fields, bson.E{Key: "updatedAt", Value: time.Now()})
+	res, err := r.outboxCollection.UpdateByID(ctx, entryID, bson.D{{Key: "$set", Value: fields}})
+	if err != nil {
+		return fmt.Errorf("%w: error updating outbox entry", errorx.ErrDependencyFailed)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("%w: outbox entry not found", errorx.ErrNotFound)
+	}
+	return nil
+}
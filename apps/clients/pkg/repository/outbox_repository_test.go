@@ -0,0 +1,116 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+type OutboxRepositorySuite struct {
+	suite.Suite
+	repo    repository.OutboxRepository
+	storage *repository.MongoStorage
+	cleanup func()
+	ctx     context.Context
+}
+
+func (s *OutboxRepositorySuite) SetupSuite() {
+	s.storage, s.cleanup = repository.NewTestMongoStorage(s.T())
+	s.repo = repository.NewMongoOutboxRepository(s.storage)
+	s.ctx = context.TODO()
+}
+
+func (s *OutboxRepositorySuite) TearDownSuite() {
+	s.cleanup()
+}
+
+func (s *OutboxRepositorySuite) SetupTest() {
+	_, err := s.storage.OutboxCollection().DeleteMany(s.ctx, bson.M{})
+	s.Require().NoError(err)
+}
+
+func (s *OutboxRepositorySuite) newEntry(jobID string, due time.Time) string {
+	id, err := s.repo.Create(s.ctx, &model.OutboxEntry{
+		JobID:         jobID,
+		Deployment:    "deployment-id",
+		Params:        bson.M{"job_id": jobID},
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: due,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	})
+	s.Require().NoError(err)
+	return id
+}
+
+func (s *OutboxRepositorySuite) TestClaim() {
+	s.newEntry("later", time.Now().Add(time.Hour))
+	s.newEntry("second", time.Now().Add(-time.Minute))
+	s.newEntry("first", time.Now().Add(-time.Hour))
+
+	entry, err := s.repo.Claim(s.ctx, time.Minute)
+	s.Require().NoError(err)
+	s.Require().NotNil(entry)
+	s.Equal("first", entry.JobID)
+	s.Equal(1, entry.Attempts)
+	s.Equal("first", entry.Params["job_id"])
+
+	entry, err = s.repo.Claim(s.ctx, time.Minute)
+	s.Require().NoError(err)
+	s.Require().NotNil(entry)
+	s.Equal("second", entry.JobID)
+
+	entry, err = s.repo.Claim(s.ctx, time.Minute)
+	s.Require().NoError(err)
+	s.Nil(entry, "claimed entries and entries not yet due are skipped")
+}
+
+func (s *OutboxRepositorySuite) TestReschedule() {
+	s.newEntry("job", time.Now().Add(-time.Minute))
+	entry, err := s.repo.Claim(s.ctx, time.Hour)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.Reschedule(s.ctx, entry.ID, time.Now().Add(-time.Second), "prefect unavailable"))
+
+	entry, err = s.repo.Claim(s.ctx, time.Hour)
+	s.Require().NoError(err)
+	s.Require().NotNil(entry)
+	s.Equal(2, entry.Attempts)
+	s.Equal("prefect unavailable", entry.LastError)
+}
+
+func (s *OutboxRepositorySuite) TestMarkDeliveredAndFailed() {
+	s.newEntry("delivered", time.Now().Add(-time.Minute))
+	s.newEntry("failed", time.Now().Add(-time.Second))
+
+	delivered, err := s.repo.Claim(s.ctx, time.Minute)
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.MarkDelivered(s.ctx, delivered.ID, "flow-run-id"))
+
+	failed, err := s.repo.Claim(s.ctx, time.Minute)
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.MarkFailed(s.ctx, failed.ID, "prefect unavailable"))
+
+	entry, err := s.repo.Claim(s.ctx, time.Minute)
+	s.Require().NoError(err)
+	s.Nil(entry, "delivered and failed entries are never claimed again")
+
+	var stored model.OutboxEntry
+	s.Require().NoError(s.storage.OutboxCollection().FindOne(s.ctx, bson.M{"_id": delivered.ID}).Decode(&stored))
+	s.Equal(model.OutboxStatusDelivered, stored.Status)
+	s.Equal("flow-run-id", stored.FlowRunID)
+
+	err = s.repo.MarkFailed(s.ctx, bson.NewObjectID(), "")
+	s.ErrorIs(err, errorx.ErrNotFound)
+}
+
+func TestOutboxRepositorySuite(t *testing.T) {
+	suite.Run(t, new(OutboxRepositorySuite))
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type mongoTimelineRepository struct {
+	timelineCollection *mongo.Collection
+}
+
+func NewMongoTimelineRepository(storage *MongoStorage) TimelineRepository {
+	return &mongoTimelineRepository{timelineCollection: storage.timelineCollection}
+}
+
+type TimelineRepository interface {
+	Create(ctx context.Context, point *model.TimelinePoint) (string, error)
+	GetLatest(ctx context.Context, clientID string, field string) (*model.TimelinePoint, error)
+	GetAll(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) ([]model.TimelinePoint, error)
+	Count(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) (int, error)
+}
+
+func (r *mongoTimelineRepository) Create(ctx context.Context, point *model.TimelinePoint) (string, error) {
+	if point == nil {
+		return "", fmt.Errorf("%w: cannot insert nil timeline point", errorx.ErrInvalidInput)
+	}
+
+	result, err := r.timelineCollection.InsertOne(ctx, point)
+	if err != nil {
+		return "", fmt.Errorf("%w: insert failed", errorx.ErrDependencyFailed)
+	}
+
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%w: failed to convert inserted ID", errorx.ErrInternal)
+	}
+
+	return insertedID.Hex(), nil
+}
+
+// GetLatest returns the most recent point recorded for the field, or nil if the field has no history yet
+func (r *mongoTimelineRepository) GetLatest(ctx context.Context, clientID string, field string) (*model.TimelinePoint, error) {
+	filter := bson.D{{Key: "clientId", Value: clientID}, {Key: "field", Value: field}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	var point model.TimelinePoint
+	err := r.timelineCollection.FindOne(ctx, filter, opts).Decode(&point)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+
+	return &point, nil
+}
+
+// GetAll pages through the field's points that match the query, oldest first
+func (r *mongoTimelineRepository) GetAll(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) ([]model.TimelinePoint, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 500 {
+		query.PageSize = 100
+	}
+	skip := (query.Page - 1) * query.PageSize
+
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(query.PageSize)).
+		SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.timelineCollection.Find(ctx, timelineFilter(clientID, field, query), opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mongo find error", errorx.ErrDependencyFailed)
+	}
+	defer cursor.Close(ctx)
+
+	points := []model.TimelinePoint{}
+	if err := cursor.All(ctx, &points); err != nil {
+		return nil, fmt.Errorf("%w: mongo decode error", errorx.ErrInternal)
+	}
+
+	return points, nil
+}
+
+func (r *mongoTimelineRepository) Count(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) (int, error) {
+	count, err := r.timelineCollection.CountDocuments(ctx, timelineFilter(clientID, field, query))
+	if err != nil {
+		return 0, fmt.Errorf("%w: mongo count error", errorx.ErrDependencyFailed)
+	}
+	return int(count), nil
+}
+
+func timelineFilter(clientID string, field string, query *model.GetTimelineQuery) bson.M {
+	filter := bson.M{"clientId": clientID, "field": field}
+	if query.Source != "" {
+		filter["source"] = query.Source
+	}
+
+	timeFilter := bson.M{}
+	if !query.From.IsZero() {
+		timeFilter["$gte"] = query.From
+	}
+	if !query.To.IsZero() {
+		timeFilter["$lte"] = query.To
+	}
+	if len(timeFilter) > 0 {
+		filter["timestamp"] = timeFilter
+	}
+	return filter
+}
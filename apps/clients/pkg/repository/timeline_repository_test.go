@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+type TimelineRepositorySuite struct {
+	suite.Suite
+	repo    repository.TimelineRepository
+	storage *repository.MongoStorage
+	cleanup func()
+	ctx     context.Context
+}
+
+func (s *TimelineRepositorySuite) SetupSuite() {
+	s.storage, s.cleanup = repository.NewTestMongoStorage(s.T())
+	s.repo = repository.NewMongoTimelineRepository(s.storage)
+	s.ctx = context.TODO()
+}
+
+func (s *TimelineRepositorySuite) TearDownSuite() {
+	s.cleanup()
+}
+
+func (s *TimelineRepositorySuite) SetupTest() {
+	_, err := s.storage.TimelineCollection().DeleteMany(s.ctx, bson.D{})
+	s.Require().NoError(err)
+}
+
+func (s *TimelineRepositorySuite) TestGetLatest_Empty() {
+	latest, err := s.repo.GetLatest(s.ctx, "client-id", "netWorth")
+	s.Require().NoError(err)
+	s.Nil(latest)
+}
+
+func (s *TimelineRepositorySuite) TestCreateAndGetAll() {
+	now := time.Now().UTC()
+	points := []*model.TimelinePoint{
+		{ClientID: "client-id", Field: "netWorth", Value: 100, Source: model.ChangeSourceScrape, Timestamp: now.Add(-2 * time.Hour)},
+		{ClientID: "client-id", Field: "netWorth", Value: 200, Source: model.ChangeSourceManual, Timestamp: now.Add(-time.Hour)},
+		{ClientID: "client-id", Field: "occupations", Value: bson.A{"Investor"}, Source: model.ChangeSourceScrape, Timestamp: now},
+	}
+	for _, p := range points {
+		_, err := s.repo.Create(s.ctx, p)
+		s.Require().NoError(err)
+	}
+
+	series, err := s.repo.GetAll(s.ctx, "client-id", "netWorth", &model.GetTimelineQuery{})
+	s.Require().NoError(err)
+	s.Len(series, 2)
+	s.Equal(model.ChangeSourceScrape, series[0].Source)
+
+	manual, err := s.repo.GetAll(s.ctx, "client-id", "netWorth", &model.GetTimelineQuery{Source: model.ChangeSourceManual})
+	s.Require().NoError(err)
+	s.Len(manual, 1)
+
+	query := &model.GetTimelineQuery{Page: 2, PageSize: 1}
+	page, err := s.repo.GetAll(s.ctx, "client-id", "netWorth", query)
+	s.Require().NoError(err)
+	s.Require().Len(page, 1)
+	s.Equal(model.ChangeSourceManual, page[0].Source)
+	total, err := s.repo.Count(s.ctx, "client-id", "netWorth", query)
+	s.Require().NoError(err)
+	s.Equal(2, total)
+
+	latest, err := s.repo.GetLatest(s.ctx, "client-id", "netWorth")
+	s.Require().NoError(err)
+	s.Equal(model.ChangeSourceManual, latest.Source)
+}
+
+func TestTimelineRepositorySuite(t *testing.T) {
+	suite.Run(t, new(TimelineRepositorySuite))
+}
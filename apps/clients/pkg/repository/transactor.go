@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+)
+
+// Transactor runs writes across repositories atomically
+type Transactor interface {
+	// WithTransaction runs fn in a transaction and commits it if fn succeeds. Repositories must be called with
+	// the context passed to fn for their writes to be part of the transaction.
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type mongoTransactor struct {
+	storage *MongoStorage
+}
+
+func NewMongoTransactor(storage *MongoStorage) Transactor {
+	return &mongoTransactor{storage: storage}
+}
+
+// WithTransaction needs a replica set or sharded cluster, since standalone servers don't support transactions
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.storage.Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("%w: error starting session", errorx.ErrDependencyFailed)
+	}
+	defer session.EndSession(ctx)
+
+	var fnErr error
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		fnErr = fn(ctx)
+		return nil, fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: error committing transaction", errorx.ErrDependencyFailed)
+	}
+	return nil
+}
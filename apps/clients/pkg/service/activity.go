@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const maxNoteLength = 5000
+
+type ActivityService struct {
+	activityRepository repository.ActivityRepository
+	clientRepository   repository.ClientRepository
+}
+
+type ActivityServiceInterface interface {
+	GetActivity(ctx context.Context, clientID string, query *model.GetActivityQuery) (*model.GetActivityResponse, error)
+	AddNote(ctx context.Context, clientID string, req *model.CreateNoteReq) (*model.Note, error)
+}
+
+func NewActivityService(activityRepository repository.ActivityRepository, clientRepository repository.ClientRepository) *ActivityService {
+	return &ActivityService{activityRepository: activityRepository, clientRepository: clientRepository}
+}
+
+// GetActivity returns a page of the client's audit logs, jobs, articles, notes and field changes, newest first.
+// All types are included unless query.Types narrows them down.
+func (s *ActivityService) GetActivity(ctx context.Context, clientID string, query *model.GetActivityQuery) (*model.GetActivityResponse, error) {
+	types, err := activityTypes(query.Types)
+	if err != nil {
+		return nil, err
+	}
+	query.Types = types
+
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, fmt.Errorf("%w: 'to' must not be before 'from'", errorx.ErrInvalidInput)
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 10
+	}
+
+	client, err := s.getClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	query.ArticleIDs = client.Articles
+	if query.ArticleIDs == nil {
+		query.ArticleIDs = []bson.ObjectID{}
+	}
+	query.ArticleLinks = client.ArticleLinks
+
+	items, total, err := s.activityRepository.GetFeed(ctx, clientID, query)
+	if err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting activity", errorx.ErrInternal)
+	}
+
+	return &model.GetActivityResponse{Total: total, Page: query.Page, PageSize: query.PageSize, Items: items}, nil
+}
+
+// AddNote attaches a free text note to a client, notes only appear in the activity feed
+func (s *ActivityService) AddNote(ctx context.Context, clientID string, req *model.CreateNoteReq) (*model.Note, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: note text is required", errorx.ErrValidationFailed)
+	}
+	if len(text) > maxNoteLength {
+		return nil, fmt.Errorf("%w: note is longer than %d characters", errorx.ErrValidationFailed, maxNoteLength)
+	}
+
+	if _, err := s.getClient(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	note := &model.Note{
+		ClientID:  clientID,
+		Author:    GetUsername(ctx),
+		Text:      text,
+		CreatedAt: time.Now().UTC(),
+	}
+	id, err := s.activityRepository.CreateNote(ctx, note)
+	if err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error creating note", errorx.ErrInternal)
+	}
+	note.ID, _ = bson.ObjectIDFromHex(id)
+
+	return note, nil
+}
+
+func (s *ActivityService) getClient(ctx context.Context, clientID string) (*model.Client, error) {
+	client, err := s.clientRepository.GetOne(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting client", errorx.ErrInternal)
+	}
+	return client, nil
+}
+
+// activityTypes validates and de-duplicates requested types, accepting comma separated values
+func activityTypes(requested []model.ActivityType) ([]model.ActivityType, error) {
+	if len(requested) == 0 {
+		return model.ActivityTypes, nil
+	}
+
+	known := map[model.ActivityType]bool{}
+	for _, t := range model.ActivityTypes {
+		known[t] = true
+	}
+
+	seen := map[model.ActivityType]bool{}
+	types := []model.ActivityType{}
+	for _, r := range requested {
+		for _, part := range strings.Split(string(r), ",") {
+			t := model.ActivityType(strings.TrimSpace(part))
+			if t == "" || seen[t] {
+				continue
+			}
+			if !known[t] {
+				return nil, fmt.Errorf("%w: unknown activity type '%s'", errorx.ErrInvalidInput, t)
+			}
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return model.ActivityTypes, nil
+	}
+	return types, nil
+}
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ActivityServiceTestSuite struct {
+	suite.Suite
+	mockRepo        *mocks.ActivityRepository
+	mockClientRepo  *mocks.ClientRepository
+	activityService *service.ActivityService
+}
+
+func (suite *ActivityServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(mocks.ActivityRepository)
+	suite.mockClientRepo = new(mocks.ClientRepository)
+	suite.activityService = service.NewActivityService(suite.mockRepo, suite.mockClientRepo)
+}
+
+func (suite *ActivityServiceTestSuite) TestGetActivity_AllTypes() {
+	articleIDs := []bson.ObjectID{bson.NewObjectID()}
+	links := []model.ArticleLink{{ArticleID: articleIDs[0], LinkedAt: time.Now()}}
+	items := []model.ActivityItem{{Type: model.ActivityNote, Summary: "called client"}}
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{Articles: articleIDs, ArticleLinks: links}, nil)
+	suite.mockRepo.On("GetFeed", mock.Anything, "client-id", mock.MatchedBy(func(q *model.GetActivityQuery) bool {
+		return len(q.Types) == len(model.ActivityTypes) && q.Page == 1 && q.PageSize == 10 && len(q.ArticleIDs) == 1 && len(q.ArticleLinks) == 1
+	})).Return(items, 1, nil)
+
+	res, err := suite.activityService.GetActivity(context.Background(), "client-id", &model.GetActivityQuery{})
+
+	suite.NoError(err)
+	suite.Equal(1, res.Total)
+	suite.Equal(items, res.Items)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ActivityServiceTestSuite) TestGetActivity_TypeFilter() {
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+	suite.mockRepo.On("GetFeed", mock.Anything, "client-id", mock.MatchedBy(func(q *model.GetActivityQuery) bool {
+		return len(q.Types) == 2 && q.Types[0] == model.ActivityJob && q.Types[1] == model.ActivityChange && q.ArticleIDs != nil
+	})).Return([]model.ActivityItem{}, 0, nil)
+
+	_, err := suite.activityService.GetActivity(context.Background(), "client-id", &model.GetActivityQuery{
+		Types: []model.ActivityType{"job,change", "job"},
+	})
+
+	suite.NoError(err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ActivityServiceTestSuite) TestGetActivity_UnknownType() {
+	_, err := suite.activityService.GetActivity(context.Background(), "client-id", &model.GetActivityQuery{
+		Types: []model.ActivityType{"email"},
+	})
+
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+	suite.mockClientRepo.AssertNotCalled(suite.T(), "GetOne", mock.Anything, mock.Anything)
+}
+
+func (suite *ActivityServiceTestSuite) TestGetActivity_ClientNotFound() {
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(nil, errorx.ErrNotFound)
+
+	_, err := suite.activityService.GetActivity(context.Background(), "client-id", &model.GetActivityQuery{})
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+}
+
+func (suite *ActivityServiceTestSuite) TestAddNote() {
+	id := bson.NewObjectID()
+	ctx := context.WithValue(context.Background(), "username", "alice")
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+	suite.mockRepo.On("CreateNote", mock.Anything, mock.MatchedBy(func(n *model.Note) bool {
+		return n.ClientID == "client-id" && n.Author == "alice" && n.Text == "called client" && !n.CreatedAt.IsZero()
+	})).Return(id.Hex(), nil)
+
+	note, err := suite.activityService.AddNote(ctx, "client-id", &model.CreateNoteReq{Text: "  called client "})
+
+	suite.NoError(err)
+	suite.Equal(id, note.ID)
+	suite.WithinDuration(time.Now(), note.CreatedAt, time.Minute)
+}
+
+func (suite *ActivityServiceTestSuite) TestAddNote_ValidationFailed() {
+	for _, text := range []string{"   ", strings.Repeat("x", 5001)} {
+		_, err := suite.activityService.AddNote(context.Background(), "client-id", &model.CreateNoteReq{Text: text})
+		suite.ErrorIs(err, errorx.ErrValidationFailed)
+	}
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateNote", mock.Anything, mock.Anything)
+}
+
+func TestActivityServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ActivityServiceTestSuite))
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// LogAnchorer periodically anchors the head of the log chain. Anchors are keyed by sequence number,
+// so every replica can run an anchorer without anchoring a head twice.
+type LogAnchorer struct {
+	chainService LogChainServiceInterface
+	interval     time.Duration
+}
+
+func NewLogAnchorer(chainService LogChainServiceInterface, interval time.Duration) *LogAnchorer {
+	return &LogAnchorer{chainService: chainService, interval: interval}
+}
+
+// Run anchors the head of the log chain every interval until ctx is done
+func (a *LogAnchorer) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.Tick(ctx)
+		}
+	}
+}
+
+// Tick anchors the head of the log chain once
+func (a *LogAnchorer) Tick(ctx context.Context) {
+	if _, err := a.chainService.AnchorHead(ctx); err != nil {
+		log.Printf("error anchoring log chain: %v", err)
+	}
+}
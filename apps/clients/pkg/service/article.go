@@ -4,22 +4,50 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"strings"
+	"time"
 
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type ArticleService struct {
-	articleRepository repository.ArticleRepository
+	articleRepository  repository.ArticleRepository
+	clientRepository   repository.ClientRepository
+	feedbackRepository repository.FeedbackRepository
+	logService         LogServiceInterface
 }
 
 type ArticleServiceInterface interface {
 	GetAllArticles(ctx context.Context, query *model.GetArticlesReq) (articles []model.Article, err error)
+	GetClientArticles(ctx context.Context, clientID string, query *model.GetClientArticlesQuery) (*model.GetClientArticlesResponse, error)
+	GetClientSentiment(ctx context.Context, clientID string, query *model.GetSentimentQuery) (*model.ClientSentimentResponse, error)
+	CreateArticle(ctx context.Context, req *model.CreateArticleReq) (*model.CreateArticleRes, error)
+	LinkArticle(ctx context.Context, clientID string, articleID string) error
+	UnlinkArticle(ctx context.Context, clientID string, articleID string) error
+	ReviewArticle(ctx context.Context, clientID string, articleID string, req *model.ArticleFeedbackReq) (*model.ArticleFeedback, error)
+	GetFeedbackStats(ctx context.Context, query *model.GetFeedbackStatsQuery) (*model.GetFeedbackStatsResponse, error)
 }
 
-func NewArticleService(articleRepository repository.ArticleRepository) *ArticleService {
-	return &ArticleService{articleRepository: articleRepository}
+const (
+	defaultSentimentDays = 30
+	maxSentimentDays     = 365
+	// trendThreshold is the minimum change in average score between window halves reported as a trend
+	trendThreshold = 0.1
+)
+
+func NewArticleService(articleRepository repository.ArticleRepository, clientRepository repository.ClientRepository, feedbackRepository repository.FeedbackRepository, logService LogServiceInterface) *ArticleService {
+	return &ArticleService{
+		articleRepository:  articleRepository,
+		clientRepository:   clientRepository,
+		feedbackRepository: feedbackRepository,
+		logService:         logService,
+	}
 }
 
 func (s *ArticleService) GetAllArticles(ctx context.Context, query *model.GetArticlesReq) (articles []model.Article, err error) {
@@ -33,3 +61,465 @@ func (s *ArticleService) GetAllArticles(ctx context.Context, query *model.GetArt
 
 	return articles, nil
 }
+
+// GetClientArticles pages through the articles linked to a client through Client.Articles
+func (s *ArticleService) GetClientArticles(ctx context.Context, clientID string, query *model.GetClientArticlesQuery) (*model.GetClientArticlesResponse, error) {
+	if query.Sort != "" && query.Sort != "asc" && query.Sort != "desc" {
+		return nil, fmt.Errorf("%w: sort must be 'asc' or 'desc'", errorx.ErrInvalidInput)
+	}
+
+	client, err := s.clientRepository.GetOne(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting client", errorx.ErrInternal)
+	}
+
+	res := &model.GetClientArticlesResponse{
+		Sources:  map[string]int{},
+		Articles: []model.Article{},
+	}
+	if len(client.Articles) == 0 {
+		res.Page, res.PageSize = query.Page, query.PageSize
+		return res, nil
+	}
+
+	res.Articles, err = s.articleRepository.GetByIDs(ctx, client.Articles, query)
+	if err != nil {
+		return nil, wrapArticleErr(err, "error getting articles")
+	}
+	res.Page, res.PageSize = query.Page, query.PageSize
+
+	res.Total, err = s.articleRepository.CountByIDs(ctx, client.Articles, query)
+	if err != nil {
+		return nil, wrapArticleErr(err, "error counting articles")
+	}
+
+	res.Sources, err = s.articleRepository.CountSourcesByIDs(ctx, client.Articles, query)
+	if err != nil {
+		return nil, wrapArticleErr(err, "error counting article sources")
+	}
+
+	return res, nil
+}
+
+// GetClientSentiment aggregates the sentiment of a client's articles over the last query.Days days
+func (s *ArticleService) GetClientSentiment(ctx context.Context, clientID string, query *model.GetSentimentQuery) (*model.ClientSentimentResponse, error) {
+	if query.Days == 0 {
+		query.Days = defaultSentimentDays
+	}
+	if query.Days < 1 || query.Days > maxSentimentDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", errorx.ErrInvalidInput, maxSentimentDays)
+	}
+
+	client, err := s.clientRepository.GetOne(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting client", errorx.ErrInternal)
+	}
+
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -query.Days)
+
+	buckets := []model.SentimentBucket{}
+	if len(client.Articles) > 0 {
+		buckets, err = s.articleRepository.GetSentimentBuckets(ctx, client.Articles, from)
+		if err != nil {
+			return nil, wrapArticleErr(err, "error aggregating sentiment")
+		}
+	}
+
+	res := summariseSentiment(buckets, from, to)
+	res.ClientID = clientID
+	res.Days = query.Days
+	return res, nil
+}
+
+// CreateArticle stores an article unless one with the same canonical URL exists, then links it to the given clients.
+// Every client must exist before anything is written.
+func (s *ArticleService) CreateArticle(ctx context.Context, req *model.CreateArticleReq) (*model.CreateArticleRes, error) {
+	article, err := newArticle(req)
+	if err != nil {
+		return nil, err
+	}
+
+	clientIDs := uniqueStrings(req.ClientIDs)
+	if len(clientIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one client id is required", errorx.ErrValidationFailed)
+	}
+	for _, clientID := range clientIDs {
+		if _, err := s.clientRepository.GetOne(ctx, clientID); err != nil {
+			if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: error getting client", errorx.ErrInternal)
+		}
+	}
+
+	// clients that already marked the article irrelevant don't get it back
+	linked, excluded := []string{}, []string{}
+	for _, clientID := range clientIDs {
+		skip, err := s.feedbackRepository.IsExcluded(ctx, clientID, article.CanonicalURL)
+		if err != nil {
+			return nil, wrapArticleErr(err, "error checking article feedback")
+		}
+		if skip {
+			excluded = append(excluded, clientID)
+		} else {
+			linked = append(linked, clientID)
+		}
+	}
+
+	articleID, inserted, err := s.articleRepository.UpsertByURL(ctx, article)
+	if err != nil {
+		return nil, wrapArticleErr(err, "error storing article")
+	}
+
+	username := GetUsername(ctx)
+	for _, clientID := range linked {
+		if err := s.clientRepository.AddArticle(ctx, clientID, articleID); err != nil {
+			return nil, wrapLinkErr(err)
+		}
+
+		_, err := s.logService.CreateLog(ctx, &model.Log{
+			ClientID:  clientID,
+			Actor:     username,
+			Operation: model.OperationAddArticle,
+			Details:   fmt.Sprintf("User %s added article %s (%s) to client %s", username, articleID.Hex(), article.CanonicalURL, clientID),
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			log.Printf("error creating log: %v", err) // don't return error since it's not critical
+		}
+	}
+
+	return &model.CreateArticleRes{ID: articleID.Hex(), Duplicate: !inserted, ClientIDs: linked, Excluded: excluded}, nil
+}
+
+// LinkArticle manually attaches an existing article to a client
+func (s *ArticleService) LinkArticle(ctx context.Context, clientID string, articleID string) error {
+	article, err := s.getArticle(ctx, articleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.clientRepository.AddArticle(ctx, clientID, article.ID); err != nil {
+		return wrapLinkErr(err)
+	}
+
+	s.logArticleChange(ctx, clientID, model.OperationLinkArticle, fmt.Sprintf("linked article %s", article.ID.Hex()))
+	return nil
+}
+
+// UnlinkArticle detaches an article from a client without recording any feedback
+func (s *ArticleService) UnlinkArticle(ctx context.Context, clientID string, articleID string) error {
+	objID, err := bson.ObjectIDFromHex(articleID)
+	if err != nil {
+		return fmt.Errorf("%w: error parsing article id", errorx.ErrInvalidInput)
+	}
+
+	if err := s.clientRepository.RemoveArticle(ctx, clientID, objID); err != nil {
+		return wrapLinkErr(err)
+	}
+
+	s.logArticleChange(ctx, clientID, model.OperationUnlinkArticle, fmt.Sprintf("unlinked article %s", articleID))
+	return nil
+}
+
+// ReviewArticle records whether an article is really about the client. Irrelevant articles are also unlinked,
+// and articles with the same canonical URL are no longer linked to this client from then on.
+func (s *ArticleService) ReviewArticle(ctx context.Context, clientID string, articleID string, req *model.ArticleFeedbackReq) (*model.ArticleFeedback, error) {
+	if req.Relevant == nil {
+		return nil, fmt.Errorf("%w: relevant is required", errorx.ErrValidationFailed)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if !*req.Relevant && reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required when marking an article irrelevant", errorx.ErrValidationFailed)
+	}
+
+	if _, err := s.clientRepository.GetOne(ctx, clientID); err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting client", errorx.ErrInternal)
+	}
+	article, err := s.getArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	feedback := &model.ArticleFeedback{
+		ClientID:     clientID,
+		ArticleID:    article.ID.Hex(),
+		URL:          article.URL,
+		CanonicalURL: articleCanonicalURL(article),
+		Source:       article.Source,
+		Relevant:     *req.Relevant,
+		Reason:       reason,
+		Actor:        GetUsername(ctx),
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := s.feedbackRepository.Upsert(ctx, feedback); err != nil {
+		return nil, wrapArticleErr(err, "error storing feedback")
+	}
+
+	details := fmt.Sprintf("marked article %s relevant", article.ID.Hex())
+	if !feedback.Relevant {
+		// already unlinked is fine, the verdict is what matters
+		if err := s.clientRepository.RemoveArticle(ctx, clientID, article.ID); err != nil && !errors.Is(err, errorx.ErrNotFound) {
+			return nil, wrapLinkErr(err)
+		}
+		details = fmt.Sprintf("marked article %s irrelevant: %s", article.ID.Hex(), reason)
+	}
+
+	s.logArticleChange(ctx, clientID, model.OperationReviewArticle, details)
+	return feedback, nil
+}
+
+// GetFeedbackStats reports false positive rates of reviewed articles, overall and per source
+func (s *ArticleService) GetFeedbackStats(ctx context.Context, query *model.GetFeedbackStatsQuery) (*model.GetFeedbackStatsResponse, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, fmt.Errorf("%w: 'to' must not be before 'from'", errorx.ErrInvalidInput)
+	}
+
+	sources, err := s.feedbackRepository.GetStats(ctx, query)
+	if err != nil {
+		return nil, wrapArticleErr(err, "error getting feedback stats")
+	}
+
+	res := &model.GetFeedbackStatsResponse{Sources: sources}
+	for i := range res.Sources {
+		src := &res.Sources[i]
+		src.FalsePositiveRate = falsePositiveRate(src.Irrelevant, src.Reviewed)
+		res.Reviewed += src.Reviewed
+		res.Irrelevant += src.Irrelevant
+	}
+	res.FalsePositiveRate = falsePositiveRate(res.Irrelevant, res.Reviewed)
+	return res, nil
+}
+
+func (s *ArticleService) getArticle(ctx context.Context, articleID string) (*model.Article, error) {
+	article, err := s.articleRepository.GetOne(ctx, articleID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting article", errorx.ErrInternal)
+	}
+	return article, nil
+}
+
+func (s *ArticleService) logArticleChange(ctx context.Context, clientID string, op model.Operation, details string) {
+	username := GetUsername(ctx)
+	_, err := s.logService.CreateLog(ctx, &model.Log{
+		ClientID:  clientID,
+		Actor:     username,
+		Operation: op,
+		Details:   fmt.Sprintf("User %s %s for client %s", username, details, clientID),
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
+}
+
+func falsePositiveRate(irrelevant, reviewed int) float64 {
+	if reviewed == 0 {
+		return 0
+	}
+	return round2(float64(irrelevant) / float64(reviewed))
+}
+
+func wrapLinkErr(err error) error {
+	if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+		return err
+	}
+	return fmt.Errorf("%w: error updating client articles", errorx.ErrInternal)
+}
+
+// newArticle validates an ingestion request and builds the article to store
+func newArticle(req *model.CreateArticleReq) (*model.Article, error) {
+	article := &model.Article{
+		Source:  strings.TrimSpace(req.Source),
+		Title:   strings.TrimSpace(req.Title),
+		URL:     strings.TrimSpace(req.URL),
+		Summary: strings.TrimSpace(req.Summary),
+	}
+	if article.Source == "" {
+		return nil, fmt.Errorf("%w: source is required", errorx.ErrValidationFailed)
+	}
+	if article.Title == "" {
+		return nil, fmt.Errorf("%w: title is required", errorx.ErrValidationFailed)
+	}
+
+	canonical, err := canonicalizeURL(article.URL)
+	if err != nil {
+		return nil, err
+	}
+	article.CanonicalURL = canonical
+
+	// without a publish date the zero time would be stored and defeat the insertion time fallback
+	article.PublishedAt = time.Now().UTC()
+	if req.PublishedAt != nil && !req.PublishedAt.IsZero() {
+		article.PublishedAt = req.PublishedAt.UTC()
+	}
+
+	if req.Sentiment != nil {
+		switch req.Sentiment.Label {
+		case model.SentimentPositive, model.SentimentNeutral, model.SentimentNegative:
+		default:
+			return nil, fmt.Errorf("%w: unknown sentiment label '%s'", errorx.ErrValidationFailed, req.Sentiment.Label)
+		}
+		if req.Sentiment.Score < 0 || req.Sentiment.Score > 1 {
+			return nil, fmt.Errorf("%w: sentiment score must be between 0 and 1", errorx.ErrValidationFailed)
+		}
+		article.Sentiment = req.Sentiment
+	}
+
+	return article, nil
+}
+
+// trackingParams are query parameters that vary per share link but not per article
+var trackingParams = map[string]bool{"fbclid": true, "gclid": true, "mc_cid": true, "mc_eid": true, "igshid": true}
+
+// canonicalizeURL normalises an article URL so the same article shared through different links compares equal.
+// The scheme and host are lowercased, "www.", default ports, fragments, tracking parameters and trailing slashes
+// are dropped, and the remaining query parameters are sorted.
+func canonicalizeURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("%w: invalid article url '%s'", errorx.ErrValidationFailed, raw)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%w: article url must be http or https", errorx.ErrValidationFailed)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode() // Encode sorts by key
+
+	return u.String(), nil
+}
+
+// articleCanonicalURL returns the article's canonical URL. Articles stored by the pipelines before ingestion went
+// through the API have none, so it is worked out from their URL, which is kept as is if it can't be parsed.
+func articleCanonicalURL(article *model.Article) string {
+	if article.CanonicalURL != "" {
+		return article.CanonicalURL
+	}
+	canonical, err := canonicalizeURL(article.URL)
+	if err != nil {
+		return article.URL
+	}
+	return canonical
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := []string{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// summariseSentiment folds daily buckets into totals and compares the average score of the two halves of the window
+func summariseSentiment(buckets []model.SentimentBucket, from, to time.Time) *model.ClientSentimentResponse {
+	res := &model.ClientSentimentResponse{
+		From:   from,
+		To:     to,
+		Counts: map[string]int{model.SentimentPositive: 0, model.SentimentNeutral: 0, model.SentimentNegative: 0},
+		Daily:  []model.SentimentDay{},
+	}
+
+	midpoint := from.Add(to.Sub(from) / 2)
+	var scoreSum, earlySum, lateSum float64
+	var earlyCount, lateCount int
+	days := map[time.Time]int{}
+
+	for _, b := range buckets {
+		res.Total += b.Count
+		res.Counts[b.Label] += b.Count
+		scoreSum += b.ScoreSum
+
+		if b.Day.Before(midpoint) {
+			earlySum += b.ScoreSum
+			earlyCount += b.Count
+		} else {
+			lateSum += b.ScoreSum
+			lateCount += b.Count
+		}
+
+		idx, ok := days[b.Day]
+		if !ok {
+			idx = len(res.Daily)
+			days[b.Day] = idx
+			res.Daily = append(res.Daily, model.SentimentDay{Day: b.Day, Counts: map[string]int{}})
+		}
+		day := &res.Daily[idx]
+		dayTotal := 0
+		for _, c := range day.Counts {
+			dayTotal += c
+		}
+		day.AverageScore = (day.AverageScore*float64(dayTotal) + b.ScoreSum) / float64(dayTotal+b.Count)
+		day.Counts[b.Label] += b.Count
+	}
+
+	if res.Total > 0 {
+		res.AverageScore = round2(scoreSum / float64(res.Total))
+	}
+
+	if earlyCount == 0 || lateCount == 0 {
+		res.Trend = model.SentimentTrendInsufficient
+		return res
+	}
+
+	res.TrendDelta = round2(lateSum/float64(lateCount) - earlySum/float64(earlyCount))
+	switch {
+	case res.TrendDelta >= trendThreshold:
+		res.Trend = model.SentimentTrendImproving
+	case res.TrendDelta <= -trendThreshold:
+		res.Trend = model.SentimentTrendDeclining
+	default:
+		res.Trend = model.SentimentTrendStable
+	}
+	return res
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+func wrapArticleErr(err error, msg string) error {
+	if errors.Is(err, errorx.ErrDependencyFailed) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", errorx.ErrInternal, msg)
+}
@@ -3,7 +3,7 @@ package service_test
 import (
 	"context"
 	"testing"
-	// "time"
+	"time"
 
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
@@ -18,13 +18,19 @@ import (
 type ArticleServiceTestSuite struct {
 	suite.Suite
 	mockRepo *mocks.ArticleRepository
+	mockClientRepo *mocks.ClientRepository
+	mockFeedbackRepo *mocks.FeedbackRepository
+	mockLog *mocks.LogServiceInterface
 	articleService *service.ArticleService
 }
 
 
 func (suite *ArticleServiceTestSuite) SetupTest() {
 	suite.mockRepo = new(mocks.ArticleRepository)
-	suite.articleService = service.NewArticleService(suite.mockRepo)
+	suite.mockClientRepo = new(mocks.ClientRepository)
+	suite.mockFeedbackRepo = new(mocks.FeedbackRepository)
+	suite.mockLog = new(mocks.LogServiceInterface)
+	suite.articleService = service.NewArticleService(suite.mockRepo, suite.mockClientRepo, suite.mockFeedbackRepo, suite.mockLog)
 }
 
 func (suite *ArticleServiceTestSuite) TestGetAllArticles() {
@@ -57,6 +63,359 @@ func (suite *ArticleServiceTestSuite) TestGetAllArticles_InvalidInput() {
 	suite.Nil(articles)
 }
 
+func (suite *ArticleServiceTestSuite) TestGetClientArticles() {
+	articleIDs := []bson.ObjectID{bson.NewObjectID(), bson.NewObjectID()}
+	query := &model.GetClientArticlesQuery{Source: "Reuters", Page: 1, PageSize: 10}
+	articles := []model.Article{{ID: articleIDs[0], Source: "Reuters", Title: "Article 1"}}
+
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{Articles: articleIDs}, nil)
+	suite.mockRepo.On("GetByIDs", mock.Anything, articleIDs, query).Return(articles, nil)
+	suite.mockRepo.On("CountByIDs", mock.Anything, articleIDs, query).Return(1, nil)
+	suite.mockRepo.On("CountSourcesByIDs", mock.Anything, articleIDs, query).Return(map[string]int{"Reuters": 1, "Bloomberg": 1}, nil)
+
+	res, err := suite.articleService.GetClientArticles(context.Background(), "client-id", query)
+
+	suite.NoError(err)
+	suite.Equal(1, res.Total)
+	suite.Equal(articles, res.Articles)
+	suite.Equal(2, len(res.Sources))
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ArticleServiceTestSuite) TestGetClientArticles_NoArticles() {
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+
+	res, err := suite.articleService.GetClientArticles(context.Background(), "client-id", &model.GetClientArticlesQuery{})
+
+	suite.NoError(err)
+	suite.Equal(0, res.Total)
+	suite.Empty(res.Articles)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetByIDs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestGetClientArticles_InvalidSort() {
+	res, err := suite.articleService.GetClientArticles(context.Background(), "client-id", &model.GetClientArticlesQuery{Sort: "title"})
+
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+	suite.Nil(res)
+}
+
+func (suite *ArticleServiceTestSuite) TestGetClientArticles_ClientNotFound() {
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(nil, errorx.ErrNotFound)
+
+	res, err := suite.articleService.GetClientArticles(context.Background(), "client-id", &model.GetClientArticlesQuery{})
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.Nil(res)
+}
+
+func (suite *ArticleServiceTestSuite) TestGetClientArticles_RepoError() {
+	articleIDs := []bson.ObjectID{bson.NewObjectID()}
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{Articles: articleIDs}, nil)
+	suite.mockRepo.On("GetByIDs", mock.Anything, articleIDs, mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	res, err := suite.articleService.GetClientArticles(context.Background(), "client-id", &model.GetClientArticlesQuery{})
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.Nil(res)
+}
+
+func (suite *ArticleServiceTestSuite) TestGetClientSentiment() {
+	articleIDs := []bson.ObjectID{bson.NewObjectID()}
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	buckets := []model.SentimentBucket{
+		{Day: now.AddDate(0, 0, -25), Label: model.SentimentPositive, Count: 2, ScoreSum: 1.6},
+		{Day: now.AddDate(0, 0, -2), Label: model.SentimentNegative, Count: 1, ScoreSum: -0.9},
+		{Day: now.AddDate(0, 0, -2), Label: model.SentimentNeutral, Count: 1, ScoreSum: 0},
+	}
+
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{Articles: articleIDs}, nil)
+	suite.mockRepo.On("GetSentimentBuckets", mock.Anything, articleIDs, mock.Anything).Return(buckets, nil)
+
+	res, err := suite.articleService.GetClientSentiment(context.Background(), "client-id", &model.GetSentimentQuery{})
+
+	suite.NoError(err)
+	suite.Equal(30, res.Days)
+	suite.Equal(4, res.Total)
+	suite.Equal(map[string]int{"positive": 2, "neutral": 1, "negative": 1}, res.Counts)
+	suite.InDelta(0.18, res.AverageScore, 0.001)
+	suite.Equal(model.SentimentTrendDeclining, res.Trend)
+	suite.InDelta(-1.25, res.TrendDelta, 0.001)
+	suite.Len(res.Daily, 2)
+	suite.InDelta(-0.45, res.Daily[1].AverageScore, 0.001)
+}
+
+func (suite *ArticleServiceTestSuite) TestGetClientSentiment_NoArticles() {
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+
+	res, err := suite.articleService.GetClientSentiment(context.Background(), "client-id", &model.GetSentimentQuery{Days: 7})
+
+	suite.NoError(err)
+	suite.Equal(0, res.Total)
+	suite.Equal(model.SentimentTrendInsufficient, res.Trend)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetSentimentBuckets", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestGetClientSentiment_InvalidWindow() {
+	res, err := suite.articleService.GetClientSentiment(context.Background(), "client-id", &model.GetSentimentQuery{Days: 1000})
+
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+	suite.Nil(res)
+}
+
+func (suite *ArticleServiceTestSuite) TestCreateArticle() {
+	clientID := bson.NewObjectID().Hex()
+	articleID := bson.NewObjectID()
+	published := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	suite.mockClientRepo.On("GetOne", mock.Anything, clientID).Return(&model.Client{}, nil)
+	suite.mockFeedbackRepo.On("IsExcluded", mock.Anything, clientID, "https://example.com/news/story?id=7").Return(false, nil).Once()
+	suite.mockRepo.On("UpsertByURL", mock.Anything, mock.MatchedBy(func(a *model.Article) bool {
+		return a.CanonicalURL == "https://example.com/news/story?id=7" && a.Title == "Story" && a.PublishedAt.Equal(published)
+	})).Return(articleID, true, nil)
+	suite.mockClientRepo.On("AddArticle", mock.Anything, clientID, articleID).Return(nil).Once()
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.ClientID == clientID && l.Operation == model.OperationAddArticle
+	})).Return("log-id", nil)
+
+	res, err := suite.articleService.CreateArticle(context.Background(), &model.CreateArticleReq{
+		Source:      "Reuters",
+		Title:       " Story ",
+		URL:         "HTTPS://www.Example.com:443/news/story/?utm_source=feed&id=7#comments",
+		PublishedAt: &published,
+		ClientIDs:   []string{clientID, clientID},
+	})
+
+	suite.NoError(err)
+	suite.Equal(articleID.Hex(), res.ID)
+	suite.False(res.Duplicate)
+	suite.Equal([]string{clientID}, res.ClientIDs)
+	suite.Empty(res.Excluded)
+	suite.mockClientRepo.AssertExpectations(suite.T())
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *ArticleServiceTestSuite) TestCreateArticle_Excluded() {
+	linked := bson.NewObjectID().Hex()
+	excluded := bson.NewObjectID().Hex()
+	articleID := bson.NewObjectID()
+
+	suite.mockClientRepo.On("GetOne", mock.Anything, mock.Anything).Return(&model.Client{}, nil)
+	// marked irrelevant through another link to the same article
+	suite.mockFeedbackRepo.On("IsExcluded", mock.Anything, excluded, "https://example.com/news/story").Return(true, nil)
+	suite.mockFeedbackRepo.On("IsExcluded", mock.Anything, linked, "https://example.com/news/story").Return(false, nil)
+	suite.mockRepo.On("UpsertByURL", mock.Anything, mock.Anything).Return(articleID, false, nil)
+	suite.mockClientRepo.On("AddArticle", mock.Anything, linked, articleID).Return(nil).Once()
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+
+	res, err := suite.articleService.CreateArticle(context.Background(), &model.CreateArticleReq{
+		Source:    "Reuters",
+		Title:     "Story",
+		URL:       "https://www.example.com/news/story/?utm_medium=social",
+		ClientIDs: []string{excluded, linked},
+	})
+
+	suite.NoError(err)
+	suite.Equal([]string{linked}, res.ClientIDs)
+	suite.Equal([]string{excluded}, res.Excluded)
+	suite.mockClientRepo.AssertNotCalled(suite.T(), "AddArticle", mock.Anything, excluded, mock.Anything)
+	suite.mockClientRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ArticleServiceTestSuite) TestCreateArticle_FeedbackError() {
+	clientID := bson.NewObjectID().Hex()
+	suite.mockClientRepo.On("GetOne", mock.Anything, clientID).Return(&model.Client{}, nil)
+	suite.mockFeedbackRepo.On("IsExcluded", mock.Anything, clientID, mock.Anything).Return(false, errorx.ErrDependencyFailed)
+
+	_, err := suite.articleService.CreateArticle(context.Background(), &model.CreateArticleReq{
+		Source:    "Reuters",
+		Title:     "Story",
+		URL:       "https://example.com/news/story",
+		ClientIDs: []string{clientID},
+	})
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpsertByURL", mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestCreateArticle_Duplicate() {
+	clientID := bson.NewObjectID().Hex()
+	articleID := bson.NewObjectID()
+
+	suite.mockClientRepo.On("GetOne", mock.Anything, clientID).Return(&model.Client{}, nil)
+	suite.mockFeedbackRepo.On("IsExcluded", mock.Anything, clientID, mock.Anything).Return(false, nil)
+	suite.mockRepo.On("UpsertByURL", mock.Anything, mock.MatchedBy(func(a *model.Article) bool {
+		return !a.PublishedAt.IsZero()
+	})).Return(articleID, false, nil)
+	suite.mockClientRepo.On("AddArticle", mock.Anything, clientID, articleID).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("", assert.AnError)
+
+	res, err := suite.articleService.CreateArticle(context.Background(), &model.CreateArticleReq{
+		Source:    "Reuters",
+		Title:     "Story",
+		URL:       "https://example.com/news/story",
+		ClientIDs: []string{clientID},
+	})
+
+	suite.NoError(err)
+	suite.True(res.Duplicate)
+	suite.Equal(articleID.Hex(), res.ID)
+}
+
+func (suite *ArticleServiceTestSuite) TestCreateArticle_ClientNotFound() {
+	clientID := bson.NewObjectID().Hex()
+	suite.mockClientRepo.On("GetOne", mock.Anything, clientID).Return(nil, errorx.ErrNotFound)
+
+	res, err := suite.articleService.CreateArticle(context.Background(), &model.CreateArticleReq{
+		Source:    "Reuters",
+		Title:     "Story",
+		URL:       "https://example.com/news/story",
+		ClientIDs: []string{clientID},
+	})
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.Nil(res)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpsertByURL", mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestCreateArticle_ValidationFailed() {
+	clientIDs := []string{bson.NewObjectID().Hex()}
+	cases := map[string]*model.CreateArticleReq{
+		"missing title":   {Source: "Reuters", URL: "https://example.com/a", ClientIDs: clientIDs},
+		"relative url":    {Source: "Reuters", Title: "Story", URL: "/news/a", ClientIDs: clientIDs},
+		"ftp url":         {Source: "Reuters", Title: "Story", URL: "ftp://example.com/a", ClientIDs: clientIDs},
+		"no clients":      {Source: "Reuters", Title: "Story", URL: "https://example.com/a"},
+		"bad sentiment":   {Source: "Reuters", Title: "Story", URL: "https://example.com/a", ClientIDs: clientIDs, Sentiment: &model.Sentiment{Label: "angry", Score: 0.5}},
+		"score too large": {Source: "Reuters", Title: "Story", URL: "https://example.com/a", ClientIDs: clientIDs, Sentiment: &model.Sentiment{Label: model.SentimentNegative, Score: 2}},
+	}
+
+	for name, req := range cases {
+		res, err := suite.articleService.CreateArticle(context.Background(), req)
+		suite.ErrorIs(err, errorx.ErrValidationFailed, name)
+		suite.Nil(res, name)
+	}
+	suite.mockClientRepo.AssertNotCalled(suite.T(), "GetOne", mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestLinkArticle() {
+	articleID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, articleID.Hex()).Return(&model.Article{ID: articleID}, nil)
+	suite.mockClientRepo.On("AddArticle", mock.Anything, "client-id", articleID).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationLinkArticle && l.ClientID == "client-id"
+	})).Return("log-id", nil)
+
+	err := suite.articleService.LinkArticle(context.Background(), "client-id", articleID.Hex())
+
+	suite.NoError(err)
+	suite.mockClientRepo.AssertExpectations(suite.T())
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *ArticleServiceTestSuite) TestLinkArticle_ArticleNotFound() {
+	suite.mockRepo.On("GetOne", mock.Anything, "missing").Return(nil, errorx.ErrNotFound)
+
+	err := suite.articleService.LinkArticle(context.Background(), "client-id", "missing")
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.mockClientRepo.AssertNotCalled(suite.T(), "AddArticle", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestUnlinkArticle() {
+	articleID := bson.NewObjectID()
+	suite.mockClientRepo.On("RemoveArticle", mock.Anything, "client-id", articleID).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+
+	err := suite.articleService.UnlinkArticle(context.Background(), "client-id", articleID.Hex())
+
+	suite.NoError(err)
+	suite.mockClientRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ArticleServiceTestSuite) TestUnlinkArticle_NotLinked() {
+	articleID := bson.NewObjectID()
+	suite.mockClientRepo.On("RemoveArticle", mock.Anything, "client-id", articleID).Return(errorx.ErrNotFound)
+
+	err := suite.articleService.UnlinkArticle(context.Background(), "client-id", articleID.Hex())
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestReviewArticle_Irrelevant() {
+	articleID := bson.NewObjectID()
+	relevant := false
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+	// stored by a pipeline before canonical URLs were recorded
+	suite.mockRepo.On("GetOne", mock.Anything, articleID.Hex()).Return(&model.Article{ID: articleID, URL: "https://www.example.com/a/?fbclid=x", Source: "Reuters"}, nil)
+	suite.mockFeedbackRepo.On("Upsert", mock.Anything, mock.MatchedBy(func(f *model.ArticleFeedback) bool {
+		return !f.Relevant && f.Reason == "different person" && f.URL == "https://www.example.com/a/?fbclid=x" &&
+			f.CanonicalURL == "https://example.com/a" && f.Source == "Reuters"
+	})).Return(nil)
+	// already unlinked earlier, the verdict is still recorded
+	suite.mockClientRepo.On("RemoveArticle", mock.Anything, "client-id", articleID).Return(errorx.ErrNotFound)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationReviewArticle
+	})).Return("log-id", nil)
+
+	feedback, err := suite.articleService.ReviewArticle(context.Background(), "client-id", articleID.Hex(), &model.ArticleFeedbackReq{Relevant: &relevant, Reason: " different person "})
+
+	suite.NoError(err)
+	suite.Equal(articleID.Hex(), feedback.ArticleID)
+	suite.mockFeedbackRepo.AssertExpectations(suite.T())
+	suite.mockClientRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ArticleServiceTestSuite) TestReviewArticle_Relevant() {
+	articleID := bson.NewObjectID()
+	relevant := true
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+	suite.mockRepo.On("GetOne", mock.Anything, articleID.Hex()).Return(&model.Article{ID: articleID}, nil)
+	suite.mockFeedbackRepo.On("Upsert", mock.Anything, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+
+	feedback, err := suite.articleService.ReviewArticle(context.Background(), "client-id", articleID.Hex(), &model.ArticleFeedbackReq{Relevant: &relevant})
+
+	suite.NoError(err)
+	suite.True(feedback.Relevant)
+	suite.mockClientRepo.AssertNotCalled(suite.T(), "RemoveArticle", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestReviewArticle_ValidationFailed() {
+	relevant := false
+
+	_, err := suite.articleService.ReviewArticle(context.Background(), "client-id", "article-id", &model.ArticleFeedbackReq{})
+	suite.ErrorIs(err, errorx.ErrValidationFailed)
+
+	_, err = suite.articleService.ReviewArticle(context.Background(), "client-id", "article-id", &model.ArticleFeedbackReq{Relevant: &relevant})
+	suite.ErrorIs(err, errorx.ErrValidationFailed)
+
+	suite.mockFeedbackRepo.AssertNotCalled(suite.T(), "Upsert", mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleServiceTestSuite) TestGetFeedbackStats() {
+	query := &model.GetFeedbackStatsQuery{}
+	suite.mockFeedbackRepo.On("GetStats", mock.Anything, query).Return([]model.FeedbackStats{
+		{Source: "Bloomberg", Reviewed: 4, Irrelevant: 1},
+		{Source: "Reuters", Reviewed: 2, Irrelevant: 2},
+	}, nil)
+
+	res, err := suite.articleService.GetFeedbackStats(context.Background(), query)
+
+	suite.NoError(err)
+	suite.Equal(6, res.Reviewed)
+	suite.Equal(3, res.Irrelevant)
+	suite.Equal(0.5, res.FalsePositiveRate)
+	suite.Equal(0.25, res.Sources[0].FalsePositiveRate)
+	suite.Equal(1.0, res.Sources[1].FalsePositiveRate)
+}
+
+func (suite *ArticleServiceTestSuite) TestGetFeedbackStats_InvalidRange() {
+	now := time.Now()
+	_, err := suite.articleService.GetFeedbackStats(context.Background(), &model.GetFeedbackStatsQuery{From: now, To: now.Add(-time.Hour)})
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+}
+
 func TestArticleServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(ArticleServiceTestSuite))
 }
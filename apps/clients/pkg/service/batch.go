@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MaxBatchSize caps how many children one batch may start
+const MaxBatchSize = 100
+
+// SubmitBatch creates a batch job and submits each child as part of it, setting each child's ID.
+// Call it inside a transaction so the batch and all its children are written together.
+func (s *JobService) SubmitBatch(ctx context.Context, batch *model.Job, children []*model.Job) (string, error) {
+	batch.Type = model.Batch
+	batch.Status = model.JobStatusPending
+	batch.Children = &model.BatchCounts{Total: len(children), Pending: len(children)}
+
+	id, err := s.CreateJob(ctx, batch)
+	if err != nil {
+		return "", err
+	}
+
+	for _, child := range children {
+		child.BatchID = id
+		childID, err := s.SubmitJob(ctx, child)
+		if err != nil {
+			return "", err
+		}
+		child.ID, _ = bson.ObjectIDFromHex(childID)
+	}
+	return id, nil
+}
+
+// GetBatchChildren lists every job of a batch, retries included, filtered and paged like GetAllJobs
+func (s *JobService) GetBatchChildren(ctx context.Context, batchID string, query *model.GetJobsQuery) (total int, jobs []model.Job, err error) {
+	if _, err := s.getBatch(ctx, batchID); err != nil {
+		return 0, nil, err
+	}
+
+	query.BatchID = batchID
+	return s.GetAllJobs(ctx, query)
+}
+
+// CancelBatch cancels every pending or processing child of a batch. Children that can't be cancelled are reported
+// in the response rather than failing the request. Only the creator of the batch or an admin can cancel it.
+func (s *JobService) CancelBatch(ctx context.Context, batchID string) (*model.BatchActionResponse, error) {
+	batch, err := s.getBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !canCancel(ctx, batch) {
+		return nil, fmt.Errorf("%w: only the creator of the batch or an admin can cancel it", errorx.ErrForbidden)
+	}
+
+	children, err := s.findBatchChildren(ctx, batchID, activeJobStatuses)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, fmt.Errorf("%w: batch has no pending or processing children", errorx.ErrConflict)
+	}
+
+	return s.applyToChildren(ctx, batch, children, func(child *model.Job) (*model.Job, error) {
+		return child, s.cancel(ctx, child.ID.Hex(), child)
+	})
+}
+
+// RetryBatch starts a new attempt of every failed child of a batch, the batch is pending again until they finish.
+// All attempts count towards the caller's quotas, none are started if they don't fit.
+func (s *JobService) RetryBatch(ctx context.Context, batchID string) (*model.BatchActionResponse, error) {
+	batch, err := s.getBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := s.findBatchChildren(ctx, batchID, []model.JobStatus{model.JobStatusFailed})
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, fmt.Errorf("%w: batch has no failed children", errorx.ErrConflict)
+	}
+	if err := quotaError(s.quotaService.CheckJobs(ctx, len(children))); err != nil {
+		return nil, err
+	}
+
+	return s.applyToChildren(ctx, batch, children, func(child *model.Job) (*model.Job, error) {
+		return s.retry(ctx, child)
+	})
+}
+
+// applyToChildren runs action on each child, collecting the jobs it returns and the children it failed for,
+// then brings the batch up to date
+func (s *JobService) applyToChildren(ctx context.Context, batch *model.Job, children []model.Job, action func(child *model.Job) (*model.Job, error)) (*model.BatchActionResponse, error) {
+	res := &model.BatchActionResponse{Jobs: []model.Job{}, Errors: []model.BatchChildError{}}
+	for i := range children {
+		child := &children[i]
+		job, err := action(child)
+		if err != nil {
+			log.Printf("error applying batch action to job %s of batch %s: %v", child.ID.Hex(), batch.ID.Hex(), err)
+			res.Errors = append(res.Errors, model.BatchChildError{JobID: child.ID.Hex(), Message: err.Error()})
+			continue
+		}
+		res.Jobs = append(res.Jobs, *job)
+	}
+
+	if err := s.refreshBatch(ctx, batch); err != nil {
+		log.Printf("error refreshing batch %s: %v", batch.ID.Hex(), err) // don't return error since the children were updated
+	}
+	res.Batch = batch
+	return res, nil
+}
+
+// getBatch returns the job if it is a batch, otherwise ErrNotFound
+func (s *JobService) getBatch(ctx context.Context, batchID string) (*model.Job, error) {
+	batch, err := s.GetJob(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Type != model.Batch {
+		return nil, fmt.Errorf("%w: job %s is not a batch", errorx.ErrNotFound, batchID)
+	}
+	return batch, nil
+}
+
+func (s *JobService) findBatchChildren(ctx context.Context, batchID string, statuses []model.JobStatus) ([]model.Job, error) {
+	children, err := s.jobRepository.FindBatchChildren(ctx, batchID, statuses)
+	if err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error finding batch children", errorx.ErrInternal)
+	}
+	return children, nil
+}
+
+// refreshBatch works out a batch's status, progress and child counts from its children and stores them if they
+// changed. A change of status is audited and notified like any other job's.
+// The batch is only stored if nothing updated it since it was read. Otherwise it is read and counted again, so
+// children finishing together can't store stale counts or record the same change twice.
+func (s *JobService) refreshBatch(ctx context.Context, batch *model.Job) error {
+	for {
+		counts, progress, err := s.jobRepository.GetBatchCounts(ctx, batch.ID.Hex())
+		if err != nil {
+			return err
+		}
+
+		status := batchStatus(counts)
+		if status == batch.Status && progress == batch.Progress && batch.Children != nil && *batch.Children == *counts {
+			return nil
+		}
+		updatedAt, err := s.jobRepository.UpdateBatch(ctx, batch.ID.Hex(), batch.UpdatedAt, status, progress, counts)
+		if errors.Is(err, errorx.ErrConflict) {
+			// another refresh stored the batch first, and there are only so many of those, so this ends
+			latest, err := s.jobRepository.GetOne(ctx, batch.ID.Hex())
+			if err != nil {
+				return err
+			}
+			*batch = *latest
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		previous := batch.Status
+		batch.Status, batch.Progress, batch.Children, batch.UpdatedAt = status, progress, counts, updatedAt
+		if status != previous {
+			s.recordTransition(ctx, batch, previous)
+		}
+		return nil
+	}
+}
+
+// refreshBatchOf brings the batch the job belongs to, if any, up to date after the job changed
+func (s *JobService) refreshBatchOf(ctx context.Context, job *model.Job) {
+	if job.BatchID == "" {
+		return
+	}
+	batch, err := s.getBatch(ctx, job.BatchID)
+	if err == nil {
+		err = s.refreshBatch(ctx, batch)
+	}
+	if err != nil {
+		log.Printf("error refreshing batch %s of job %s: %v", job.BatchID, job.ID.Hex(), err) // don't return error since the job was updated
+	}
+}
+
+// batchStatus aggregates the statuses of a batch's children. The batch is pending while any child is pending and
+// processing while any is running. Once all have finished it is completed, failed or cancelled if they all ended
+// that way, partial if some completed and some didn't, and failed otherwise.
+func batchStatus(counts *model.BatchCounts) model.JobStatus {
+	switch {
+	case counts.Pending > 0:
+		return model.JobStatusPending
+	case counts.Processing > 0:
+		return model.JobStatusProcessing
+	case counts.Completed == counts.Total:
+		return model.JobStatusCompleted
+	case counts.Cancelled == counts.Total:
+		return model.JobStatusCancelled
+	case counts.Completed > 0:
+		return model.JobStatusPartial
+	default:
+		return model.JobStatusFailed
+	}
+}
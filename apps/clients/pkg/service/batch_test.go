@@ -0,0 +1,367 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// batch is a batch job created by alice whose stored state matches counts
+func (suite *JobServiceTestSuite) batch(status model.JobStatus, counts model.BatchCounts) *model.Job {
+	batchID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, batchID.Hex()).Return(&model.Job{
+		ID:        batchID,
+		Type:      model.Batch,
+		CreatedBy: "alice",
+		Status:    status,
+		Children:  &counts,
+	}, nil)
+	return &model.Job{ID: batchID}
+}
+
+func (suite *JobServiceTestSuite) TestSubmitBatch() {
+	batchID := bson.NewObjectID().Hex()
+	childIDs := []string{bson.NewObjectID().Hex(), bson.NewObjectID().Hex()}
+	children := []*model.Job{{Type: model.Scrape, ClientID: "client-1"}, {Type: model.Scrape, ClientID: "client-2"}}
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+		return job.Type == model.Batch
+	})).Return(batchID, nil).Once()
+	for i, childID := range childIDs {
+		suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+			return job.Type == model.Scrape && job.BatchID == batchID && job.ClientID == children[i].ClientID
+		})).Return(childID, nil).Once()
+	}
+	suite.mockOutbox.On("Create", mock.Anything, mock.Anything).Return("entry-id", nil).Twice()
+
+	batch := &model.Job{CreatedBy: "alice"}
+	id, err := suite.jobService.SubmitBatch(context.Background(), batch, children)
+
+	suite.NoError(err)
+	suite.Equal(batchID, id)
+	suite.Equal(model.JobStatusPending, batch.Status)
+	suite.Equal(&model.BatchCounts{Total: 2, Pending: 2}, batch.Children)
+	suite.Equal(childIDs[0], children[0].ID.Hex())
+	suite.Equal(childIDs[1], children[1].ID.Hex())
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockOutbox.AssertExpectations(suite.T())
+}
+
+// child is a processing child of batch, it returns the child's ID
+func (suite *JobServiceTestSuite) child(batch *model.Job) string {
+	jobID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{
+		ID:      jobID,
+		Type:    model.Scrape,
+		BatchID: batch.ID.Hex(),
+		Status:  model.JobStatusProcessing,
+	}, nil)
+	suite.mockRepo.On("Apply", mock.Anything, jobID.Hex(), model.JobStatusProcessing, mock.Anything).Return(nil)
+	return jobID.Hex()
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_RefreshesBatchStatus() {
+	tests := []struct {
+		name   string
+		counts model.BatchCounts
+		status model.JobStatus
+	}{
+		{"any pending", model.BatchCounts{Total: 3, Pending: 1, Processing: 1, Completed: 1}, model.JobStatusPending},
+		{"any processing", model.BatchCounts{Total: 3, Processing: 1, Failed: 2}, model.JobStatusProcessing},
+		{"all completed", model.BatchCounts{Total: 2, Completed: 2}, model.JobStatusCompleted},
+		{"all cancelled", model.BatchCounts{Total: 2, Cancelled: 2}, model.JobStatusCancelled},
+		{"some failed", model.BatchCounts{Total: 3, Completed: 2, Failed: 1}, model.JobStatusPartial},
+		{"none completed", model.BatchCounts{Total: 3, Failed: 1, Cancelled: 2}, model.JobStatusFailed},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			batch := suite.batch(model.JobStatusProcessing, model.BatchCounts{Total: tt.counts.Total, Processing: tt.counts.Total})
+			childID := suite.child(batch)
+			suite.mockRepo.On("GetBatchCounts", mock.Anything, batch.ID.Hex()).Return(&tt.counts, 50, nil)
+			suite.mockRepo.On("UpdateBatch", mock.Anything, batch.ID.Hex(), time.Time{}, tt.status, 50, &tt.counts).Return(time.Now(), nil)
+			suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+			suite.mockNotifier.On("Publish", mock.Anything).Return(nil)
+
+			_, err := suite.jobService.HandleCallback(context.Background(), childID, &model.JobCallbackReq{Status: model.JobStatusFailed})
+
+			suite.NoError(err)
+			suite.mockRepo.AssertExpectations(suite.T())
+		})
+	}
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_BatchFinishedNotifies() {
+	counts := model.BatchCounts{Total: 2, Completed: 1, Failed: 1}
+	batch := suite.batch(model.JobStatusProcessing, model.BatchCounts{Total: 2, Processing: 1, Failed: 1})
+	childID := suite.child(batch)
+	suite.mockRepo.On("GetBatchCounts", mock.Anything, batch.ID.Hex()).Return(&counts, 100, nil)
+	suite.mockRepo.On("UpdateBatch", mock.Anything, batch.ID.Hex(), mock.Anything, model.JobStatusPartial, 100, &counts).Return(time.Now(), nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.Anything).Return(nil)
+
+	_, err := suite.jobService.HandleCallback(context.Background(), childID, &model.JobCallbackReq{Status: model.JobStatusCompleted})
+
+	suite.NoError(err)
+	suite.mockLog.AssertCalled(suite.T(), "CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationJobStatus && l.Details == fmt.Sprintf("batch job %s moved from processing to partial", batch.ID.Hex())
+	}))
+	suite.mockNotifier.AssertCalled(suite.T(), "Publish", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.JobID == batch.ID.Hex() && n.Type == model.Batch && n.Status == model.JobStatusPartial && n.Priority == model.PriorityMedium
+	}))
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_BatchUnchanged() {
+	counts := model.BatchCounts{Total: 2, Processing: 1, Completed: 1}
+	batch := suite.batch(model.JobStatusProcessing, counts)
+	childID := suite.child(batch)
+
+	// the child only reported a log line, so neither it nor its batch changed
+	_, err := suite.jobService.HandleCallback(context.Background(), childID, &model.JobCallbackReq{Logs: []string{"still going"}})
+
+	suite.NoError(err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetBatchCounts", mock.Anything, mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_ProgressRefreshesBatch() {
+	counts := model.BatchCounts{Total: 2, Processing: 1, Completed: 1}
+	batch := suite.batch(model.JobStatusProcessing, counts)
+	childID := suite.child(batch)
+	suite.mockRepo.On("GetBatchCounts", mock.Anything, batch.ID.Hex()).Return(&counts, 70, nil)
+	suite.mockRepo.On("UpdateBatch", mock.Anything, batch.ID.Hex(), mock.Anything, model.JobStatusProcessing, 70, &counts).Return(time.Now(), nil)
+	progress := 40
+
+	_, err := suite.jobService.HandleCallback(context.Background(), childID, &model.JobCallbackReq{Progress: &progress})
+
+	suite.NoError(err)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockNotifier.AssertNotCalled(suite.T(), "Publish", mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_BatchRefreshErrorIsNotFatal() {
+	batch := suite.batch(model.JobStatusProcessing, model.BatchCounts{Total: 1, Processing: 1})
+	childID := suite.child(batch)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.Anything).Return(nil)
+	suite.mockRepo.On("GetBatchCounts", mock.Anything, batch.ID.Hex()).Return(nil, 0, errorx.ErrDependencyFailed)
+
+	job, err := suite.jobService.HandleCallback(context.Background(), childID, &model.JobCallbackReq{Status: model.JobStatusFailed})
+
+	suite.NoError(err)
+	suite.Equal(model.JobStatusFailed, job.Status)
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_BatchRefreshConflict() {
+	batchID := bson.NewObjectID()
+	read := time.Now().Add(-time.Minute)
+	stored := time.Now()
+	suite.mockRepo.On("GetOne", mock.Anything, batchID.Hex()).Return(&model.Job{
+		ID: batchID, Type: model.Batch, Status: model.JobStatusProcessing, Children: &model.BatchCounts{Total: 2, Processing: 2}, UpdatedAt: read,
+	}, nil).Once()
+	// a sibling that finished at the same time stored the batch first
+	counts := model.BatchCounts{Total: 2, Completed: 1, Failed: 1}
+	suite.mockRepo.On("GetOne", mock.Anything, batchID.Hex()).Return(&model.Job{
+		ID: batchID, Type: model.Batch, Status: model.JobStatusPartial, Progress: 100, Children: &counts, UpdatedAt: stored,
+	}, nil).Once()
+	childID := suite.child(&model.Job{ID: batchID})
+	suite.mockRepo.On("GetBatchCounts", mock.Anything, batchID.Hex()).Return(&counts, 100, nil)
+	suite.mockRepo.On("UpdateBatch", mock.Anything, batchID.Hex(), read, model.JobStatusPartial, 100, &counts).Return(time.Time{}, errorx.ErrConflict).Once()
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.Anything).Return(nil)
+
+	_, err := suite.jobService.HandleCallback(context.Background(), childID, &model.JobCallbackReq{Status: model.JobStatusCompleted})
+
+	suite.NoError(err)
+	suite.mockRepo.AssertExpectations(suite.T())
+	// the sibling's refresh recorded the batch's change, this one only the child's
+	suite.mockLog.AssertNumberOfCalls(suite.T(), "CreateLog", 1)
+	suite.mockNotifier.AssertNotCalled(suite.T(), "Publish", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.Type == model.Batch
+	}))
+}
+
+func (suite *JobServiceTestSuite) TestGetJob_DoesNotRefreshBatch() {
+	batch := suite.batch(model.JobStatusPending, model.BatchCounts{Total: 1, Pending: 1})
+
+	job, err := suite.jobService.GetJob(context.Background(), batch.ID.Hex())
+
+	suite.NoError(err)
+	suite.Equal(model.JobStatusPending, job.Status)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetBatchCounts", mock.Anything, mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_RejectsBatch() {
+	batch := suite.batch(model.JobStatusPending, model.BatchCounts{Total: 1, Pending: 1})
+
+	_, err := suite.jobService.HandleCallback(context.Background(), batch.ID.Hex(), &model.JobCallbackReq{Status: model.JobStatusCompleted})
+
+	suite.ErrorIs(err, errorx.ErrConflict)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Apply", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestCancelAndRetryJob_RejectBatch() {
+	counts := model.BatchCounts{Total: 1, Failed: 1}
+	batch := suite.batch(model.JobStatusFailed, counts)
+	ctx := context.WithValue(context.Background(), "username", "alice")
+
+	_, err := suite.jobService.CancelJob(ctx, batch.ID.Hex())
+	suite.ErrorIs(err, errorx.ErrConflict)
+
+	_, err = suite.jobService.RetryJob(ctx, batch.ID.Hex())
+	suite.ErrorIs(err, errorx.ErrConflict)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestGetBatchChildren() {
+	counts := model.BatchCounts{Total: 2, Pending: 2}
+	batch := suite.batch(model.JobStatusPending, counts)
+	suite.mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(q *model.GetJobsQuery) bool {
+		return q.BatchID == batch.ID.Hex() && q.Status == model.JobStatusPending
+	})).Return([]model.Job{{ClientID: "client-1"}, {ClientID: "client-2"}}, nil)
+	suite.mockRepo.On("Count", mock.Anything, mock.Anything).Return(2, nil)
+
+	total, jobs, err := suite.jobService.GetBatchChildren(context.Background(), batch.ID.Hex(), &model.GetJobsQuery{Status: model.JobStatusPending})
+
+	suite.NoError(err)
+	suite.Equal(2, total)
+	suite.Len(jobs, 2)
+}
+
+func (suite *JobServiceTestSuite) TestGetBatchChildren_NotABatch() {
+	jobID := bson.NewObjectID().Hex()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Type: model.Scrape}, nil)
+
+	_, _, err := suite.jobService.GetBatchChildren(context.Background(), jobID, &model.GetJobsQuery{})
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetAll", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestCancelBatch() {
+	counts := model.BatchCounts{Total: 3, Processing: 2, Completed: 1}
+	batch := suite.batch(model.JobStatusProcessing, counts)
+	running, moved := bson.NewObjectID(), bson.NewObjectID()
+	suite.mockRepo.On("FindBatchChildren", mock.Anything, batch.ID.Hex(), []model.JobStatus{model.JobStatusPending, model.JobStatusProcessing}).Return([]model.Job{
+		{ID: running, Type: model.Scrape, BatchID: batch.ID.Hex(), Status: model.JobStatusProcessing, PrefectFlowID: "flow-run-1"},
+		{ID: moved, BatchID: batch.ID.Hex(), Status: model.JobStatusProcessing},
+	}, nil)
+	suite.mockWorkflow.On("Cancel", mock.Anything, "flow-run-1").Return(nil)
+	suite.mockRepo.On("UpdateStatus", mock.Anything, running.Hex(), mock.Anything, model.JobStatusCancelled, mock.Anything).Return(nil)
+	suite.mockRepo.On("UpdateStatus", mock.Anything, moved.Hex(), mock.Anything, model.JobStatusCancelled, mock.Anything).Return(errorx.ErrConflict)
+	after := model.BatchCounts{Total: 3, Completed: 2, Cancelled: 1}
+	// counted again when the cancelled child refreshes its batch, and once more after all the children
+	suite.mockRepo.On("GetBatchCounts", mock.Anything, batch.ID.Hex()).Return(&after, 100, nil)
+	suite.mockRepo.On("UpdateBatch", mock.Anything, batch.ID.Hex(), mock.Anything, model.JobStatusPartial, 100, &after).Return(time.Now(), nil).Once()
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.Anything).Return(nil)
+	ctx := context.WithValue(context.Background(), "username", "alice")
+
+	res, err := suite.jobService.CancelBatch(ctx, batch.ID.Hex())
+
+	suite.Require().NoError(err)
+	suite.Equal(model.JobStatusPartial, res.Batch.Status)
+	suite.Require().Len(res.Jobs, 1)
+	suite.Equal(model.JobStatusCancelled, res.Jobs[0].Status)
+	suite.Equal([]model.BatchChildError{{JobID: moved.Hex(), Message: errorx.ErrConflict.Error()}}, res.Errors)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockLog.AssertCalled(suite.T(), "CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Details == fmt.Sprintf("scrape job %s moved from processing to cancelled", running.Hex())
+	}))
+}
+
+func (suite *JobServiceTestSuite) TestCancelBatch_Errors() {
+	counts := model.BatchCounts{Total: 1, Completed: 1}
+
+	suite.Run("not the creator", func() {
+		suite.SetupTest()
+		batch := suite.batch(model.JobStatusCompleted, counts)
+
+		_, err := suite.jobService.CancelBatch(context.WithValue(context.Background(), "username", "bob"), batch.ID.Hex())
+
+		suite.ErrorIs(err, errorx.ErrForbidden)
+	})
+
+	suite.Run("nothing to cancel", func() {
+		suite.SetupTest()
+		batch := suite.batch(model.JobStatusCompleted, counts)
+		suite.mockRepo.On("FindBatchChildren", mock.Anything, batch.ID.Hex(), mock.Anything).Return([]model.Job{}, nil)
+
+		_, err := suite.jobService.CancelBatch(context.WithValue(context.Background(), "username", "alice"), batch.ID.Hex())
+
+		suite.ErrorIs(err, errorx.ErrConflict)
+	})
+}
+
+func (suite *JobServiceTestSuite) TestRetryBatch() {
+	counts := model.BatchCounts{Total: 2, Completed: 1, Failed: 1}
+	batch := suite.batch(model.JobStatusPartial, counts)
+	failedID := bson.NewObjectID()
+	newID := bson.NewObjectID().Hex()
+	suite.mockRepo.On("FindBatchChildren", mock.Anything, batch.ID.Hex(), []model.JobStatus{model.JobStatusFailed}).Return([]model.Job{{
+		ID:         failedID,
+		Type:       model.Scrape,
+		BatchID:    batch.ID.Hex(),
+		Deployment: "scrape-deployment",
+		Input:      bson.M{"target": "Jane Doe"},
+		Status:     model.JobStatusFailed,
+	}}, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+		return job.BatchID == batch.ID.Hex() && job.RootID == failedID.Hex() && job.Attempt == 2
+	})).Return(newID, nil)
+	suite.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(entry *model.OutboxEntry) bool {
+		return entry.Params["job_id"] == newID
+	})).Return("entry-id", nil)
+	after := model.BatchCounts{Total: 2, Pending: 1, Completed: 1}
+	suite.mockRepo.On("GetBatchCounts", mock.Anything, batch.ID.Hex()).Return(&after, 50, nil).Once()
+	suite.mockRepo.On("UpdateBatch", mock.Anything, batch.ID.Hex(), mock.Anything, model.JobStatusPending, 50, &after).Return(time.Now(), nil).Once()
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+
+	res, err := suite.jobService.RetryBatch(context.Background(), batch.ID.Hex())
+
+	suite.Require().NoError(err)
+	suite.Equal(model.JobStatusPending, res.Batch.Status)
+	suite.Require().Len(res.Jobs, 1)
+	suite.Equal(newID, res.Jobs[0].ID.Hex())
+	suite.Empty(res.Errors)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockOutbox.AssertExpectations(suite.T())
+	suite.mockNotifier.AssertNotCalled(suite.T(), "Publish", mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestRetryBatch_OverQuota() {
+	counts := model.BatchCounts{Total: 3, Completed: 1, Failed: 2}
+	batch := suite.batch(model.JobStatusPartial, counts)
+	suite.mockRepo.On("FindBatchChildren", mock.Anything, batch.ID.Hex(), []model.JobStatus{model.JobStatusFailed}).Return([]model.Job{
+		{ID: bson.NewObjectID(), Status: model.JobStatusFailed, Deployment: "d", Input: bson.M{"a": 1}},
+		{ID: bson.NewObjectID(), Status: model.JobStatusFailed, Deployment: "d", Input: bson.M{"a": 2}},
+	}, nil)
+	suite.mockQuota = new(mocks.QuotaServiceInterface)
+	suite.jobService = service.NewJobService(suite.mockRepo, suite.mockOutbox, suite.mockWorkflow, suite.mockLog, suite.mockNotifier, suite.mockTx, suite.mockQuota, suite.mockTimeline)
+	suite.mockQuota.On("CheckJobs", mock.Anything, 2).Return(&service.QuotaExceededError{Quota: "concurrent", Limit: 3})
+
+	_, err := suite.jobService.RetryBatch(context.Background(), batch.ID.Hex())
+
+	suite.ErrorIs(err, errorx.ErrTooManyRequests)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestRetryBatch_NothingFailed() {
+	counts := model.BatchCounts{Total: 1, Completed: 1}
+	batch := suite.batch(model.JobStatusCompleted, counts)
+	suite.mockRepo.On("FindBatchChildren", mock.Anything, batch.ID.Hex(), mock.Anything).Return([]model.Job{}, nil)
+
+	_, err := suite.jobService.RetryBatch(context.Background(), batch.ID.Hex())
+
+	suite.ErrorIs(err, errorx.ErrConflict)
+	suite.mockWorkflow.AssertNotCalled(suite.T(), "Trigger", mock.Anything, mock.Anything)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/owjoel/client-factpack/apps/clients/config"
@@ -18,7 +19,9 @@ type ClientService struct {
 	clientRepository repository.ClientRepository
 	jobService       JobServiceInterface
 	logService       LogServiceInterface
-	prefectFlowRunner   PrefectFlowRunnerInterface
+	timelineService  TimelineServiceInterface
+	transactor       repository.Transactor
+	quotaService     QuotaServiceInterface
 }
 
 type ClientServiceInterface interface {
@@ -27,11 +30,13 @@ type ClientServiceInterface interface {
 	CreateClientByName(ctx context.Context, req *model.CreateClientByNameReq) (string, error)
 	UpdateClient(ctx context.Context, clientID string, changes []model.SimpleChanges) error
 	RescrapeClient(ctx context.Context, clientID string) error
+	RescrapeClients(ctx context.Context, req *model.RescrapeClientsReq) (*model.Job, error)
 	MatchClient(ctx context.Context, req *model.MatchClientReq, clientID string) (string, error)
+	SetLegalHold(ctx context.Context, clientID string, req *model.SetLegalHoldReq) error
 }
 
-func NewClientService(clientRepository repository.ClientRepository, jobService JobServiceInterface, logService LogServiceInterface, prefectFlowRunner PrefectFlowRunnerInterface) *ClientService {
-	return &ClientService{clientRepository: clientRepository, jobService: jobService, logService: logService, prefectFlowRunner: prefectFlowRunner}
+func NewClientService(clientRepository repository.ClientRepository, jobService JobServiceInterface, logService LogServiceInterface, timelineService TimelineServiceInterface, transactor repository.Transactor, quotaService QuotaServiceInterface) *ClientService {
+	return &ClientService{clientRepository: clientRepository, jobService: jobService, logService: logService, timelineService: timelineService, transactor: transactor, quotaService: quotaService}
 }
 
 func (s *ClientService) GetClient(ctx context.Context, clientID string) (*model.Client, error) {
@@ -59,6 +64,14 @@ func (s *ClientService) GetClient(ctx context.Context, clientID string) (*model.
 }
 
 func (s *ClientService) GetAllClients(ctx context.Context, query *model.GetClientsQuery) (total int, clients []model.Client, err error) {
+	if query.NegativePressDays < 0 {
+		return 0, nil, fmt.Errorf("%w: negativePressDays must be positive", errorx.ErrInvalidInput)
+	}
+	if query.NegativePressDays > 0 {
+		since := time.Now().UTC().AddDate(0, 0, -query.NegativePressDays)
+		query.NegativePressSince = &since
+	}
+
 	clients, err = s.clientRepository.GetAll(ctx, query)
 	if err != nil {
 		if errors.Is(err, errorx.ErrDependencyFailed) {
@@ -80,8 +93,23 @@ func (s *ClientService) GetAllClients(ctx context.Context, query *model.GetClien
 }
 
 func (s *ClientService) CreateClientByName(ctx context.Context, req *model.CreateClientByNameReq) (string, error) {
+	if err := s.checkQuota(ctx); err != nil {
+		return "", err
+	}
+
+	// the id is assigned up front so the job can reference the client it creates
+	clientObjID := bson.NewObjectID()
 	job := &model.Job{
-		Type:      model.Scrape,
+		Type:       model.Scrape,
+		ClientID:   clientObjID.Hex(),
+		CreatedBy:  GetUsername(ctx),
+		Deployment: config.PrefectScrapeFlowID,
+		Input: bson.M{
+			"target":    req.Name,
+			"client_id": clientObjID.Hex(),
+			"username":  GetUsername(ctx),
+		},
+		Attempt:   1,
 		Status:    model.JobStatusPending,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -92,16 +120,10 @@ func (s *ClientService) CreateClientByName(ctx context.Context, req *model.Creat
 			},
 		},
 	}
-	id, err := s.jobService.CreateJob(ctx, job)
-	if err != nil {
-		if errors.Is(err, errorx.ErrDependencyFailed) {
-			return "", err
-		}
-		return "", fmt.Errorf("%w: error creating job", errorx.ErrInternal)
-	}
 
 	// create client profile
 	client := &model.Client{
+		ID: clientObjID,
 		Data: bson.D{
 			{
 				Key: "profile", Value: bson.D{
@@ -116,93 +138,167 @@ func (s *ClientService) CreateClientByName(ctx context.Context, req *model.Creat
 		},
 	}
 
-	clientId, err := s.clientRepository.Create(ctx, client)
-	if err != nil {
-		if errors.Is(err, errorx.ErrDependencyFailed) {
-			return "", err
+	// the job, the client and the queued flow run are written together, so a failure leaves nothing behind
+	var id string
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.jobService.SubmitJob(ctx, job)
+		if err != nil {
+			if errors.Is(err, errorx.ErrDependencyFailed) {
+				return err
+			}
+			return fmt.Errorf("%w: error creating job", errorx.ErrInternal)
 		}
-		return "", fmt.Errorf("%w: error creating client", errorx.ErrInternal)
-	}
 
-	// trigger prefect workflow with job id
-	err = s.prefectFlowRunner.Trigger(config.PrefectScrapeFlowID, map[string]interface{}{
-		"job_id":    id,
-		"target":    req.Name,
-		"client_id": clientId,
-		"username":  GetUsername(ctx),
+		if _, err := s.clientRepository.Create(ctx, client); err != nil {
+			if errors.Is(err, errorx.ErrDependencyFailed) {
+				return err
+			}
+			return fmt.Errorf("%w: error creating client", errorx.ErrInternal)
+		}
+		return nil
 	})
 	if err != nil {
-		return "", fmt.Errorf("%w: error triggering prefect workflow", errorx.ErrInternal)
+		return "", submitError(err)
 	}
 
 	username := GetUsername(ctx)
-	s.logService.CreateLog(ctx, &model.Log{
-		ClientID:  clientId,
+	_, err = s.logService.CreateLog(ctx, &model.Log{
+		ClientID:  clientObjID.Hex(),
 		Actor:     username,
 		Operation: model.OperationCreateAndScrape,
 		Details:   fmt.Sprintf("User %s created a new client profile with job id %s", username, id),
 		Timestamp: time.Now(),
 	})
+	if err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
 
 	return id, nil
 }
 
 func (s *ClientService) RescrapeClient(ctx context.Context, clientID string) error {
-	job := &model.Job{
-		Type:      model.Scrape,
-		Status:    model.JobStatusPending,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	if err := s.checkQuota(ctx); err != nil {
+		return err
+	}
+
+	job, err := s.rescrapeJob(ctx, clientID)
+	if err != nil {
+		return err
+	}
+
+	id, err := s.submitJob(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	s.logRescrape(ctx, clientID, id)
+	return nil
+}
+
+// RescrapeClients starts a batch with a rescrape job for each client. All jobs count towards the caller's quotas.
+func (s *ClientService) RescrapeClients(ctx context.Context, req *model.RescrapeClientsReq) (*model.Job, error) {
+	clientIDs := []string{}
+	seen := map[string]bool{}
+	for _, id := range req.ClientIDs {
+		if !seen[id] {
+			seen[id] = true
+			clientIDs = append(clientIDs, id)
+		}
+	}
+	if len(clientIDs) == 0 || len(clientIDs) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: between 1 and %d clients can be rescraped at once", errorx.ErrValidationFailed, MaxBatchSize)
+	}
+	if err := s.checkQuotaFor(ctx, len(clientIDs)); err != nil {
+		return nil, err
+	}
+
+	children := make([]*model.Job, 0, len(clientIDs))
+	for _, clientID := range clientIDs {
+		job, err := s.rescrapeJob(ctx, clientID)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, job)
+	}
+
+	username := GetUsername(ctx)
+	now := time.Now()
+	batch := &model.Job{
+		CreatedBy: username,
+		Input:     bson.M{"client_ids": clientIDs, "username": username},
+		CreatedAt: now,
+		UpdatedAt: now,
 		Logs: []model.JobLog{
 			{
-				Message:   "Job [RESCRAPE] created and submitted to Prefect",
-				Timestamp: time.Now(),
+				Message:   fmt.Sprintf("Batch [RESCRAPE] of %d clients created", len(clientIDs)),
+				Timestamp: now,
 			},
 		},
 	}
 
-	id, err := s.jobService.CreateJob(ctx, job)
+	var id string
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.jobService.SubmitBatch(ctx, batch, children)
+		return err
+	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrDependencyFailed) {
-			return err
-		}
-		return fmt.Errorf("%w: error creating job", errorx.ErrInternal)
+		return nil, submitError(err)
+	}
+	batch.ID, _ = bson.ObjectIDFromHex(id)
+
+	for _, child := range children {
+		s.logRescrape(ctx, child.ClientID, child.ID.Hex())
 	}
+	return batch, nil
+}
 
+// rescrapeJob builds a job to scrape the client again under its current name
+func (s *ClientService) rescrapeJob(ctx context.Context, clientID string) (*model.Job, error) {
 	clientName, err := s.clientRepository.GetClientNameByID(ctx, clientID)
 	if err != nil {
 		if errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrInvalidInput) {
-			return err
+			return nil, err
 		}
-		return fmt.Errorf("%w: error getting client name", errorx.ErrInternal)
+		return nil, fmt.Errorf("%w: error getting client name", errorx.ErrInternal)
 	}
 
-	err = s.prefectFlowRunner.Trigger(
-		config.PrefectScrapeFlowID,
-		map[string]interface{}{
-			"job_id":    id,
+	return &model.Job{
+		Type:       model.Scrape,
+		ClientID:   clientID,
+		CreatedBy:  GetUsername(ctx),
+		Deployment: config.PrefectScrapeFlowID,
+		Input: bson.M{
 			"target":    clientName,
 			"client_id": clientID,
 			"username":  GetUsername(ctx),
 		},
-	)
-	if err != nil {
-		return fmt.Errorf("%w: error triggering prefect workflow", errorx.ErrInternal)
-	}
+		Attempt:   1,
+		Status:    model.JobStatusPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Logs: []model.JobLog{
+			{
+				Message:   "Job [RESCRAPE] created and submitted to Prefect",
+				Timestamp: time.Now(),
+			},
+		},
+	}, nil
+}
 
+func (s *ClientService) logRescrape(ctx context.Context, clientID string, jobID string) {
 	username := GetUsername(ctx)
-	_, err = s.logService.CreateLog(ctx, &model.Log{
+	_, err := s.logService.CreateLog(ctx, &model.Log{
 		ClientID:  clientID,
 		Actor:     username,
 		Operation: model.OperationScrape,
-		Details:   fmt.Sprintf("User %s rescrapped client profile with job id %s", username, id),
+		Details:   fmt.Sprintf("User %s rescrapped client profile with job id %s", username, jobID),
 		Timestamp: time.Now(),
 	})
 	if err != nil {
 		log.Printf("error creating log: %v", err) // don't return error since it's not critical
 	}
-
-	return nil
 }
 
 func (s *ClientService) UpdateClient(ctx context.Context, clientID string, changes []model.SimpleChanges) error {
@@ -219,6 +315,7 @@ func (s *ClientService) UpdateClient(ctx context.Context, clientID string, chang
 	}
 
 	update := bson.D{}
+	var audited []model.Change
 	for _, change := range changes {
 		if change.Path == "" {
 			continue
@@ -226,6 +323,10 @@ func (s *ClientService) UpdateClient(ctx context.Context, clientID string, chang
 		// Prefix with "data." to target fields inside the data object
 		key := "data." + change.Path
 		update = append(update, bson.E{Key: key, Value: change.New})
+
+		// the old value is taken from the stored profile, not the request
+		old, _ := lookupPath(client.Data, change.Path)
+		audited = append(audited, model.Change{Path: change.Path, Old: old, New: change.New, Source: model.ChangeSourceManual})
 	}
 
 	if len(update) == 0 {
@@ -240,12 +341,19 @@ func (s *ClientService) UpdateClient(ctx context.Context, clientID string, chang
 	}
 
 	username := GetUsername(ctx)
+	if updated, err := s.clientRepository.GetOne(ctx, clientID); err != nil || updated == nil {
+		log.Printf("error reloading client %s for timeline: %v", clientID, err)
+	} else if err := s.timelineService.Capture(ctx, clientID, updated.Data, model.ChangeSourceManual, username, time.Now()); err != nil {
+		log.Printf("error capturing timeline: %v", err) // don't return error since it's not critical
+	}
+
 	_, err = s.logService.CreateLog(ctx, &model.Log{
 		ClientID:  clientID,
 		Actor:     username,
 		Operation: model.OperationUpdate,
-		Details:   fmt.Sprintf("User %s updated client profile with id %s", username, clientID),
+		Details:   fmt.Sprintf("User %s updated client profile with id %s, changing %s", username, clientID, changedPaths(audited)),
 		Timestamp: time.Now(),
+		Changes:   audited,
 	})
 	if err != nil {
 		log.Printf("error creating log: %v", err) // don't return error since it's not critical
@@ -255,8 +363,22 @@ func (s *ClientService) UpdateClient(ctx context.Context, clientID string, chang
 }
 
 func (s *ClientService) MatchClient(ctx context.Context, req *model.MatchClientReq, clientID string) (string, error) {
+	if err := s.checkQuota(ctx); err != nil {
+		return "", err
+	}
+
 	job := &model.Job{
-		Type:      model.Match,
+		Type:       model.Match,
+		ClientID:   clientID,
+		CreatedBy:  GetUsername(ctx),
+		Deployment: config.PrefectMatchFlowID,
+		Input: bson.M{
+			"file_name": req.FileName,
+			"target_id": clientID,
+			"username":  GetUsername(ctx),
+		},
+		FileBytes: req.FileBytes,
+		Attempt:   1,
 		Status:    model.JobStatusPending,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -268,27 +390,75 @@ func (s *ClientService) MatchClient(ctx context.Context, req *model.MatchClientR
 		},
 	}
 
-	id, err := s.jobService.CreateJob(ctx, job)
-	if err != nil {
-		if errors.Is(err, errorx.ErrDependencyFailed) {
-			return "", err
+	return s.submitJob(ctx, job)
+}
+
+// SetLegalHold places a legal hold on the client, or lifts it. None of a held client's logs are purged, however old.
+func (s *ClientService) SetLegalHold(ctx context.Context, clientID string, req *model.SetLegalHoldReq) error {
+	username := GetUsername(ctx)
+	placed := req.Hold != nil && *req.Hold
+
+	var hold *model.LegalHold
+	details := fmt.Sprintf("User %s lifted the legal hold on client %s", username, clientID)
+	if placed {
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			return fmt.Errorf("%w: a reason is required to place a legal hold", errorx.ErrInvalidInput)
 		}
-		return "", fmt.Errorf("%w: error creating job", errorx.ErrInternal)
+		hold = &model.LegalHold{Reason: reason, SetBy: username, SetAt: time.Now()}
+		details = fmt.Sprintf("User %s placed a legal hold on client %s: %s", username, clientID, reason)
 	}
 
-	err = s.prefectFlowRunner.Trigger(
-		config.PrefectMatchFlowID,
-		map[string]interface{}{
-			"job_id":     id,
-			"file_name":  req.FileName,
-			"file_bytes": req.FileBytes,
-			"target_id": clientID,
-			"username":  GetUsername(ctx),
-		},
-	)
+	if err := s.clientRepository.SetLegalHold(ctx, clientID, hold); err != nil {
+		return err
+	}
+
+	_, err := s.logService.CreateLog(ctx, &model.Log{
+		ClientID:  clientID,
+		Actor:     username,
+		Operation: model.OperationLegalHold,
+		Details:   details,
+		Timestamp: time.Now(),
+	})
 	if err != nil {
-		return "", fmt.Errorf("%w: error triggering prefect workflow", errorx.ErrInternal)
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
 	}
+	return nil
+}
 
+// checkQuota stops a job from being created when the caller, or everyone together, is over a job quota
+func (s *ClientService) checkQuota(ctx context.Context) error {
+	return quotaError(s.quotaService.Check(ctx))
+}
+
+func (s *ClientService) checkQuotaFor(ctx context.Context, jobs int) error {
+	return quotaError(s.quotaService.CheckJobs(ctx, jobs))
+}
+
+func quotaError(err error) error {
+	if err == nil || errors.Is(err, errorx.ErrTooManyRequests) || errors.Is(err, errorx.ErrValidationFailed) || errors.Is(err, errorx.ErrDependencyFailed) {
+		return err
+	}
+	return fmt.Errorf("%w: error checking quotas", errorx.ErrInternal)
+}
+
+// submitJob saves the job and queues its flow run in one transaction
+func (s *ClientService) submitJob(ctx context.Context, job *model.Job) (string, error) {
+	var id string
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.jobService.SubmitJob(ctx, job)
+		return err
+	})
+	if err != nil {
+		return "", submitError(err)
+	}
 	return id, nil
 }
+
+func submitError(err error) error {
+	if errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInternal) {
+		return err
+	}
+	return fmt.Errorf("%w: error creating job", errorx.ErrInternal)
+}
@@ -2,7 +2,9 @@ package service_test
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/owjoel/client-factpack/apps/clients/config"
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
@@ -21,15 +23,30 @@ type ClientServiceTestSuite struct {
 	mockRepo      *mocks.ClientRepository
 	mockLog       *mocks.LogServiceInterface
 	mockJob       *mocks.JobServiceInterface
-	mockPrefect   *mocks.PrefectFlowRunnerInterface
+	mockTimeline  *mocks.TimelineServiceInterface
+	mockTx        *mocks.Transactor
+	mockQuota     *mocks.QuotaServiceInterface
 }
 
 func (suite *ClientServiceTestSuite) SetupTest() {
 	suite.mockRepo = new(mocks.ClientRepository)
 	suite.mockLog = new(mocks.LogServiceInterface)
 	suite.mockJob = new(mocks.JobServiceInterface)
-	suite.mockPrefect = new(mocks.PrefectFlowRunnerInterface)
-	suite.clientService = service.NewClientService(suite.mockRepo, suite.mockJob, suite.mockLog, suite.mockPrefect)
+	suite.mockTimeline = new(mocks.TimelineServiceInterface)
+	suite.mockTx = new(mocks.Transactor)
+	suite.mockTx.On("WithTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+	suite.mockTimeline.On("Capture", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockQuota = new(mocks.QuotaServiceInterface)
+	suite.mockQuota.On("Check", mock.Anything).Return(nil).Maybe()
+	suite.clientService = service.NewClientService(suite.mockRepo, suite.mockJob, suite.mockLog, suite.mockTimeline, suite.mockTx, suite.mockQuota)
+}
+
+// overQuota makes the quota check fail for the rest of the test
+func (suite *ClientServiceTestSuite) overQuota() {
+	suite.mockQuota.ExpectedCalls = nil
+	suite.mockQuota.On("Check", mock.Anything).Return(&service.QuotaExceededError{Quota: "daily", Limit: 50, RetryAfter: time.Hour})
 }
 
 func (suite *ClientServiceTestSuite) TestGetClient() {
@@ -45,6 +62,8 @@ func (suite *ClientServiceTestSuite) TestGetClient() {
 	suite.Equal(expectedClient, client)
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
+	// reading a profile writes nothing to its timeline
+	suite.mockTimeline.AssertNotCalled(suite.T(), "Capture", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func (suite *ClientServiceTestSuite) TestGetClient_Error() {
@@ -188,6 +207,24 @@ func (suite *ClientServiceTestSuite) TestGetAllClients_Error_CountDependencyFail
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+func (suite *ClientServiceTestSuite) TestGetAllClients_NegativePress() {
+	query := &model.GetClientsQuery{NegativePressDays: 7}
+	expectedClients := []model.Client{{Articles: []bson.ObjectID{bson.NewObjectID()}}}
+	cutoff := time.Now().UTC().AddDate(0, 0, -7)
+
+	suite.mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(q *model.GetClientsQuery) bool {
+		return q.NegativePressSince != nil && q.NegativePressSince.Sub(cutoff).Abs() < time.Minute
+	})).Return(expectedClients, nil)
+	suite.mockRepo.On("Count", mock.Anything, query).Return(1, nil)
+
+	total, clients, err := suite.clientService.GetAllClients(context.Background(), query)
+
+	suite.NoError(err)
+	suite.Equal(1, total)
+	suite.Equal(expectedClients, clients)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
 func (suite *ClientServiceTestSuite) TestCreateClientByName() {
 	req := &model.CreateClientByNameReq{Name: "Test Client"}
 	expectedJobID := "job-id"
@@ -196,17 +233,17 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName() {
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
-	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(expectedClientID, nil)
-
-	suite.mockPrefect.
-		On("Trigger", config.PrefectScrapeFlowID, mock.MatchedBy(func(params map[string]interface{}) bool {
-			return params["job_id"] == expectedJobID &&
-				params["client_id"] == expectedClientID &&
-				params["target"] == req.Name &&
-				params["username"] == username
-		})).
-		Return(nil)
+	var jobClientID string
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+		jobClientID = job.ClientID
+		return job.Deployment == config.PrefectScrapeFlowID &&
+			job.Input["target"] == req.Name &&
+			job.Input["client_id"] == job.ClientID &&
+			job.Input["username"] == username
+	})).Return(expectedJobID, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(c *model.Client) bool {
+		return c.ID.Hex() == jobClientID
+	})).Return(expectedClientID, nil)
 
 	suite.mockLog.On("CreateLog", mock.Anything, mock.AnythingOfType("*model.Log")).Return("test-log-id", nil)
 
@@ -217,7 +254,7 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName() {
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
+	suite.mockTx.AssertNumberOfCalls(suite.T(), "WithTransaction", 1)
 }
 
 func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateJobError() {
@@ -227,7 +264,7 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateJobError() {
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, assert.AnError)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return(expectedJobID, assert.AnError)
 
 	jobID, err := suite.clientService.CreateClientByName(ctx, req)
 
@@ -237,7 +274,6 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateJobError() {
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateJobDependencyFailed() {
@@ -247,7 +283,7 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateJobDependencyF
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, errorx.ErrDependencyFailed)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return(expectedJobID, errorx.ErrDependencyFailed)
 
 	jobID, err := suite.clientService.CreateClientByName(ctx, req)
 
@@ -257,7 +293,6 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateJobDependencyF
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateClientError() {
@@ -268,7 +303,7 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateClientError()
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
 	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(expectedClientID, assert.AnError)
 
 	jobID, err := suite.clientService.CreateClientByName(ctx, req)
@@ -279,7 +314,6 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateClientError()
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateClientDependencyFailed() {
@@ -290,7 +324,7 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateClientDependen
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
 	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(expectedClientID, errorx.ErrDependencyFailed)
 
 	jobID, err := suite.clientService.CreateClientByName(ctx, req)
@@ -301,35 +335,84 @@ func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateClientDependen
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
-func (suite *ClientServiceTestSuite) TestCreateClientByName_TriggerError() {
+func (suite *ClientServiceTestSuite) TestCreateClientByName_TransactionError() {
 	req := &model.CreateClientByNameReq{Name: "Test Client"}
-	expectedJobID := "job-id"
-	username := "test-user"
-	expectedClientID := "client-id"
+	ctx := context.WithValue(context.Background(), "username", "test-user")
+
+	suite.mockTx = new(mocks.Transactor)
+	suite.mockTx.On("WithTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return errorx.ErrDependencyFailed // the commit failed, so nothing was written
+	})
+	suite.clientService = service.NewClientService(suite.mockRepo, suite.mockJob, suite.mockLog, suite.mockTimeline, suite.mockTx, suite.mockQuota)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return("job-id", nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return("client-id", nil)
 
-	ctx := context.WithValue(context.Background(), "username", username)
+	jobID, err := suite.clientService.CreateClientByName(ctx, req)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
-	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(expectedClientID, nil)
-	suite.mockPrefect.On("Trigger", config.PrefectScrapeFlowID, mock.MatchedBy(func(params map[string]interface{}) bool {
-		return params["job_id"] == expectedJobID &&
-			params["client_id"] == expectedClientID &&
-			params["target"] == req.Name &&
-			params["username"] == username
-	})).Return(assert.AnError)
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.Equal("", jobID)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+}
+
+func (suite *ClientServiceTestSuite) TestCreateClientByName_CreateLogError() {
+	req := &model.CreateClientByNameReq{Name: "Test Client"}
+	ctx := context.WithValue(context.Background(), "username", "test-user")
+
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return("job-id", nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return("client-id", nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.AnythingOfType("*model.Log")).Return("", assert.AnError)
 
 	jobID, err := suite.clientService.CreateClientByName(ctx, req)
 
-	suite.Error(err)
-	suite.Equal("", jobID)
-	suite.ErrorIs(err, errorx.ErrInternal)
-	suite.mockRepo.AssertExpectations(suite.T())
-	suite.mockJob.AssertExpectations(suite.T())
+	suite.NoError(err)
+	suite.Equal("job-id", jobID)
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
+}
+
+func (suite *ClientServiceTestSuite) TestCreateClientByName_QuotaExceeded() {
+	suite.overQuota()
+
+	_, err := suite.clientService.CreateClientByName(context.Background(), &model.CreateClientByNameReq{Name: "Jane Doe"})
+
+	suite.ErrorIs(err, errorx.ErrTooManyRequests)
+	var quota *service.QuotaExceededError
+	suite.ErrorAs(err, &quota)
+	suite.mockJob.AssertNotCalled(suite.T(), "SubmitJob", mock.Anything, mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *ClientServiceTestSuite) TestCreateClientByName_QuotaCheckFailed() {
+	suite.mockQuota.ExpectedCalls = nil
+	suite.mockQuota.On("Check", mock.Anything).Return(errorx.ErrDependencyFailed)
+
+	_, err := suite.clientService.CreateClientByName(context.Background(), &model.CreateClientByNameReq{Name: "Jane Doe"})
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.mockJob.AssertNotCalled(suite.T(), "SubmitJob", mock.Anything, mock.Anything)
+}
+
+func (suite *ClientServiceTestSuite) TestRescrapeClient_QuotaExceeded() {
+	suite.overQuota()
+
+	err := suite.clientService.RescrapeClient(context.Background(), "client-id")
+
+	suite.ErrorIs(err, errorx.ErrTooManyRequests)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetClientNameByID", mock.Anything, mock.Anything)
+	suite.mockJob.AssertNotCalled(suite.T(), "SubmitJob", mock.Anything, mock.Anything)
+}
+
+func (suite *ClientServiceTestSuite) TestMatchClient_QuotaExceeded() {
+	suite.overQuota()
+
+	_, err := suite.clientService.MatchClient(context.Background(), &model.MatchClientReq{FileName: "a.pdf"}, "client-id")
+
+	suite.ErrorIs(err, errorx.ErrTooManyRequests)
+	suite.mockJob.AssertNotCalled(suite.T(), "SubmitJob", mock.Anything, mock.Anything)
 }
 
 func (suite *ClientServiceTestSuite) TestRescrapeClient() {
@@ -339,14 +422,11 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient() {
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+		return job.ClientID == clientID && job.CreatedBy == username &&
+			job.Deployment == config.PrefectScrapeFlowID && job.Input["target"] == "Test Client" && job.Attempt == 1
+	})).Return(expectedJobID, nil)
 	suite.mockRepo.On("GetClientNameByID", mock.Anything, clientID).Return("Test Client", nil)
-	suite.mockPrefect.On("Trigger", config.PrefectScrapeFlowID, mock.MatchedBy(func(params map[string]interface{}) bool {
-		return params["job_id"] == expectedJobID &&
-			params["client_id"] == clientID &&
-			params["target"] == "Test Client" &&
-			params["username"] == username
-	})).Return(nil)
 	suite.mockLog.On("CreateLog", mock.Anything, mock.AnythingOfType("*model.Log")).Return("test-log-id", nil)
 
 	err := suite.clientService.RescrapeClient(ctx, clientID)
@@ -355,7 +435,72 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient() {
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
+}
+
+func (suite *ClientServiceTestSuite) TestRescrapeClients() {
+	ctx := context.WithValue(context.Background(), "username", "test-user")
+	batchID := bson.NewObjectID().Hex()
+	childIDs := []bson.ObjectID{bson.NewObjectID(), bson.NewObjectID()}
+
+	suite.mockQuota.On("CheckJobs", mock.Anything, 2).Return(nil)
+	suite.mockRepo.On("GetClientNameByID", mock.Anything, "client-1").Return("First Client", nil)
+	suite.mockRepo.On("GetClientNameByID", mock.Anything, "client-2").Return("Second Client", nil)
+	suite.mockJob.On("SubmitBatch", mock.Anything, mock.MatchedBy(func(batch *model.Job) bool {
+		return batch.CreatedBy == "test-user"
+	}), mock.MatchedBy(func(children []*model.Job) bool {
+		return len(children) == 2 && children[0].ClientID == "client-1" && children[1].Input["target"] == "Second Client"
+	})).Run(func(args mock.Arguments) {
+		for i, child := range args.Get(2).([]*model.Job) {
+			child.ID = childIDs[i]
+		}
+	}).Return(batchID, nil)
+	for i, clientID := range []string{"client-1", "client-2"} {
+		// each client's log names the job that rescrapes it, not the batch
+		suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+			return l.ClientID == clientID && strings.HasSuffix(l.Details, "with job id "+childIDs[i].Hex())
+		})).Return("test-log-id", nil).Once()
+	}
+
+	batch, err := suite.clientService.RescrapeClients(ctx, &model.RescrapeClientsReq{ClientIDs: []string{"client-1", "client-2", "client-1"}})
+
+	suite.NoError(err)
+	suite.Equal(batchID, batch.ID.Hex())
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockJob.AssertExpectations(suite.T())
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *ClientServiceTestSuite) TestRescrapeClients_InvalidSize() {
+	tooMany := make([]string, service.MaxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = bson.NewObjectID().Hex()
+	}
+
+	for _, ids := range [][]string{nil, tooMany} {
+		_, err := suite.clientService.RescrapeClients(context.Background(), &model.RescrapeClientsReq{ClientIDs: ids})
+		suite.ErrorIs(err, errorx.ErrValidationFailed)
+	}
+	suite.mockJob.AssertNotCalled(suite.T(), "SubmitBatch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ClientServiceTestSuite) TestRescrapeClients_QuotaExceeded() {
+	suite.mockQuota.On("CheckJobs", mock.Anything, 3).Return(&service.QuotaExceededError{Quota: "concurrent", Limit: 5, RetryAfter: time.Minute})
+
+	_, err := suite.clientService.RescrapeClients(context.Background(), &model.RescrapeClientsReq{ClientIDs: []string{"a", "b", "c"}})
+
+	suite.ErrorIs(err, errorx.ErrTooManyRequests)
+	suite.mockJob.AssertNotCalled(suite.T(), "SubmitBatch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ClientServiceTestSuite) TestRescrapeClients_UnknownClient() {
+	suite.mockQuota.On("CheckJobs", mock.Anything, 2).Return(nil)
+	suite.mockRepo.On("GetClientNameByID", mock.Anything, "client-1").Return("First Client", nil)
+	suite.mockRepo.On("GetClientNameByID", mock.Anything, "client-2").Return("", errorx.ErrNotFound)
+
+	_, err := suite.clientService.RescrapeClients(context.Background(), &model.RescrapeClientsReq{ClientIDs: []string{"client-1", "client-2"}})
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.mockJob.AssertNotCalled(suite.T(), "SubmitBatch", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateJobError() {
@@ -365,7 +510,8 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateJobError() {
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, assert.AnError)
+	suite.mockRepo.On("GetClientNameByID", mock.Anything, clientID).Return("Test Client", nil)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return(expectedJobID, assert.AnError)
 
 	err := suite.clientService.RescrapeClient(ctx, clientID)
 
@@ -374,7 +520,6 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateJobError() {
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateJobDependencyFailed() {
@@ -384,7 +529,8 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateJobDependencyFaile
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, errorx.ErrDependencyFailed)
+	suite.mockRepo.On("GetClientNameByID", mock.Anything, clientID).Return("Test Client", nil)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return(expectedJobID, errorx.ErrDependencyFailed)
 
 	err := suite.clientService.RescrapeClient(ctx, clientID)
 
@@ -393,17 +539,14 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateJobDependencyFaile
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestRescrapeClient_GetClientNameByIDError() {
 	clientID := "test-client-id"
-	expectedJobID := "job-id"
 	username := "test-user"
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
 	suite.mockRepo.On("GetClientNameByID", mock.Anything, clientID).Return("", assert.AnError)
 
 	err := suite.clientService.RescrapeClient(ctx, clientID)
@@ -413,17 +556,14 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient_GetClientNameByIDError()
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestRescrapeClient_GetClientNameByIDDependencyFailed() {
 	clientID := "test-client-id"
-	expectedJobID := "job-id"
 	username := "test-user"
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
 	suite.mockRepo.On("GetClientNameByID", mock.Anything, clientID).Return("", errorx.ErrDependencyFailed)
 
 	err := suite.clientService.RescrapeClient(ctx, clientID)
@@ -433,33 +573,22 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient_GetClientNameByIDDepende
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
-func (suite *ClientServiceTestSuite) TestRescrapeClient_TriggerError() {
+func (suite *ClientServiceTestSuite) TestRescrapeClient_TransactionError() {
 	clientID := "test-client-id"
-	expectedJobID := "job-id"
-	username := "test-user"
-
-	ctx := context.WithValue(context.Background(), "username", username)
+	ctx := context.WithValue(context.Background(), "username", "test-user")
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
+	suite.mockTx = new(mocks.Transactor)
+	suite.mockTx.On("WithTransaction", mock.Anything, mock.Anything).Return(assert.AnError)
+	suite.clientService = service.NewClientService(suite.mockRepo, suite.mockJob, suite.mockLog, suite.mockTimeline, suite.mockTx, suite.mockQuota)
 	suite.mockRepo.On("GetClientNameByID", mock.Anything, clientID).Return("Test Client", nil)
-	suite.mockPrefect.On("Trigger", config.PrefectScrapeFlowID, mock.MatchedBy(func(params map[string]interface{}) bool {
-		return params["job_id"] == expectedJobID &&
-			params["client_id"] == clientID &&
-			params["target"] == "Test Client" &&
-			params["username"] == username
-	})).Return(assert.AnError)
 
 	err := suite.clientService.RescrapeClient(ctx, clientID)
 
-	suite.Error(err)
 	suite.ErrorIs(err, errorx.ErrInternal)
-	suite.mockRepo.AssertExpectations(suite.T())
-	suite.mockJob.AssertExpectations(suite.T())
-	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
+	suite.mockJob.AssertNotCalled(suite.T(), "SubmitJob", mock.Anything, mock.Anything)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
 }
 
 func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateLogError() {
@@ -469,14 +598,8 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateLogError() {
 
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return(expectedJobID, nil)
 	suite.mockRepo.On("GetClientNameByID", mock.Anything, clientID).Return("Test Client", nil)
-	suite.mockPrefect.On("Trigger", config.PrefectScrapeFlowID, mock.MatchedBy(func(params map[string]interface{}) bool {
-		return params["job_id"] == expectedJobID &&
-			params["client_id"] == clientID &&
-			params["target"] == "Test Client" &&
-			params["username"] == username
-	})).Return(nil)
 	suite.mockLog.On("CreateLog", mock.Anything, mock.AnythingOfType("*model.Log")).Return("", assert.AnError)
 
 	err := suite.clientService.RescrapeClient(ctx, clientID)
@@ -485,7 +608,6 @@ func (suite *ClientServiceTestSuite) TestRescrapeClient_CreateLogError() {
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockJob.AssertExpectations(suite.T())
 	suite.mockLog.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestUpdateClient() {
@@ -520,6 +642,78 @@ func (suite *ClientServiceTestSuite) TestUpdateClient() {
 	suite.mockLog.AssertExpectations(suite.T())
 }
 
+func (suite *ClientServiceTestSuite) TestUpdateClient_RecordsChanges() {
+	clientID := "test-client-id"
+	changes := []model.SimpleChanges{
+		{Path: "profile.age", Old: 99, New: 42},
+		{Path: "profile.nickname", New: "JD"},
+	}
+	ctx := context.WithValue(context.Background(), "username", "test-user")
+	stored := &model.Client{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "age", Value: int32(41)}}}}}
+
+	suite.mockRepo.On("GetOne", mock.Anything, clientID).Return(stored, nil)
+	suite.mockRepo.On("Update", mock.Anything, clientID, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(log *model.Log) bool {
+		// old values come from the stored profile, not the request
+		return assert.ObjectsAreEqual([]model.Change{
+			{Path: "profile.age", Old: int32(41), New: 42, Source: model.ChangeSourceManual},
+			{Path: "profile.nickname", Old: nil, New: "JD", Source: model.ChangeSourceManual},
+		}, log.Changes) && strings.Contains(log.Details, "profile.age, profile.nickname")
+	})).Return("test-log-id", nil)
+
+	err := suite.clientService.UpdateClient(ctx, clientID, changes)
+
+	suite.NoError(err)
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *ClientServiceTestSuite) TestUpdateClient_CapturesManualTimeline() {
+	clientID := "test-client-id"
+	username := "test-user"
+	changes := []model.SimpleChanges{
+		{
+			Path: "profile.occupations",
+			Old:  bson.A{"Investor"},
+			New:  bson.A{"Investor", "Philanthropist"},
+		},
+	}
+	ctx := context.WithValue(context.Background(), "username", username)
+
+	suite.mockTimeline = new(mocks.TimelineServiceInterface)
+	suite.clientService = service.NewClientService(suite.mockRepo, suite.mockJob, suite.mockLog, suite.mockTimeline, suite.mockTx, suite.mockQuota)
+
+	suite.mockRepo.On("GetOne", mock.Anything, clientID).Return(&model.Client{}, nil)
+	suite.mockRepo.On("Update", mock.Anything, clientID, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("test-log-id", nil)
+	suite.mockTimeline.On("Capture", mock.Anything, clientID, mock.Anything, model.ChangeSourceManual, username, mock.Anything).Return(nil).Once()
+
+	err := suite.clientService.UpdateClient(ctx, clientID, changes)
+
+	suite.NoError(err)
+	suite.mockTimeline.AssertExpectations(suite.T())
+	// scraped changes are captured when their job completes, not on the next edit
+	suite.mockTimeline.AssertNumberOfCalls(suite.T(), "Capture", 1)
+}
+
+func (suite *ClientServiceTestSuite) TestUpdateClient_CaptureErrorIgnored() {
+	clientID := "test-client-id"
+	username := "test-user"
+	changes := []model.SimpleChanges{{Path: "profile.netWorth.estimatedValue", New: 100}}
+	ctx := context.WithValue(context.Background(), "username", username)
+
+	suite.mockTimeline = new(mocks.TimelineServiceInterface)
+	suite.clientService = service.NewClientService(suite.mockRepo, suite.mockJob, suite.mockLog, suite.mockTimeline, suite.mockTx, suite.mockQuota)
+
+	suite.mockRepo.On("GetOne", mock.Anything, clientID).Return(&model.Client{}, nil)
+	suite.mockRepo.On("Update", mock.Anything, clientID, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("test-log-id", nil)
+	suite.mockTimeline.On("Capture", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errorx.ErrDependencyFailed)
+
+	err := suite.clientService.UpdateClient(ctx, clientID, changes)
+
+	suite.NoError(err)
+}
+
 func (suite *ClientServiceTestSuite) TestUpdateClient_GetOneError() {
 	clientID := "test-client-id"
 	username := "test-user"
@@ -674,15 +868,14 @@ func (suite *ClientServiceTestSuite) TestMatchClient() {
 	username := "test-user"
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return("job-id", nil)
-
-	suite.mockPrefect.On("Trigger", config.PrefectMatchFlowID, mock.MatchedBy(func(params map[string]interface{}) bool {
-		return params["job_id"] == "job-id" &&
-			params["file_name"] == "test-file-name" &&
-			params["file_bytes"] == "test-file-bytes" &&
-			params["target_id"] == clientID &&
-			params["username"] == username
-	})).Return(nil)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+		return job.Deployment == config.PrefectMatchFlowID &&
+			job.Input["file_name"] == "test-file-name" &&
+			job.FileBytes == "test-file-bytes" &&
+			job.Input["file_bytes"] == nil &&
+			job.Input["target_id"] == clientID &&
+			job.Input["username"] == username
+	})).Return("job-id", nil)
 
 	jobID, err := suite.clientService.MatchClient(ctx, &model.MatchClientReq{
 		FileName:  "test-file-name",
@@ -692,7 +885,6 @@ func (suite *ClientServiceTestSuite) TestMatchClient() {
 	suite.NoError(err)
 	suite.Equal("job-id", jobID)
 	suite.mockJob.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestMatchClient_CreateJobError() {
@@ -700,7 +892,7 @@ func (suite *ClientServiceTestSuite) TestMatchClient_CreateJobError() {
 	username := "test-user"
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return("", assert.AnError)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return("", assert.AnError)
 
 	jobID, err := suite.clientService.MatchClient(ctx, &model.MatchClientReq{
 		FileName:  "test-file-name",
@@ -711,7 +903,6 @@ func (suite *ClientServiceTestSuite) TestMatchClient_CreateJobError() {
 	suite.ErrorIs(err, errorx.ErrInternal)
 	suite.Empty(jobID)
 	suite.mockJob.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
 }
 
 func (suite *ClientServiceTestSuite) TestMatchClient_CreateJobDependencyFailed() {
@@ -719,7 +910,7 @@ func (suite *ClientServiceTestSuite) TestMatchClient_CreateJobDependencyFailed()
 	username := "test-user"
 	ctx := context.WithValue(context.Background(), "username", username)
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return("", errorx.ErrDependencyFailed)
+	suite.mockJob.On("SubmitJob", mock.Anything, mock.Anything).Return("", errorx.ErrDependencyFailed)
 	
 	jobID, err := suite.clientService.MatchClient(ctx, &model.MatchClientReq{
 		FileName:  "test-file-name",
@@ -732,30 +923,74 @@ func (suite *ClientServiceTestSuite) TestMatchClient_CreateJobDependencyFailed()
 	suite.mockJob.AssertExpectations(suite.T())
 }
 
-func (suite *ClientServiceTestSuite) TestMatchClient_TriggerError() {
+func (suite *ClientServiceTestSuite) TestMatchClient_TransactionError() {
 	clientID := "test-client-id"
-	username := "test-user"
-	ctx := context.WithValue(context.Background(), "username", username)
+	ctx := context.WithValue(context.Background(), "username", "test-user")
 
-	suite.mockJob.On("CreateJob", mock.Anything, mock.Anything).Return("job-id", nil)
-	suite.mockPrefect.On("Trigger", config.PrefectMatchFlowID, mock.MatchedBy(func(params map[string]interface{}) bool {
-		return params["job_id"] == "job-id" &&
-			params["file_name"] == "test-file-name" &&
-			params["file_bytes"] == "test-file-bytes" &&
-			params["target_id"] == clientID &&
-			params["username"] == username
-	})).Return(assert.AnError)
+	suite.mockTx = new(mocks.Transactor)
+	suite.mockTx.On("WithTransaction", mock.Anything, mock.Anything).Return(errorx.ErrDependencyFailed)
+	suite.clientService = service.NewClientService(suite.mockRepo, suite.mockJob, suite.mockLog, suite.mockTimeline, suite.mockTx, suite.mockQuota)
 
 	jobID, err := suite.clientService.MatchClient(ctx, &model.MatchClientReq{
 		FileName:  "test-file-name",
 		FileBytes: "test-file-bytes",
 	}, clientID)
 
-	suite.Error(err)
-	suite.ErrorIs(err, errorx.ErrInternal)
-	suite.Empty("", jobID)
-	suite.mockJob.AssertExpectations(suite.T())
-	suite.mockPrefect.AssertExpectations(suite.T())
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.Empty(jobID)
+}
+
+func (suite *ClientServiceTestSuite) TestSetLegalHold_Place() {
+	clientID := "65f1a2b3c4d5e6f708192a3b"
+	ctx := context.WithValue(context.Background(), "username", "counsel")
+	hold := true
+
+	suite.mockRepo.On("SetLegalHold", mock.Anything, clientID, mock.MatchedBy(func(h *model.LegalHold) bool {
+		return h != nil && h.Reason == "litigation 2026-14" && h.SetBy == "counsel" && !h.SetAt.IsZero()
+	})).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationLegalHold && l.ClientID == clientID && strings.Contains(l.Details, "placed a legal hold")
+	})).Return("log-id", nil)
+
+	err := suite.clientService.SetLegalHold(ctx, clientID, &model.SetLegalHoldReq{Hold: &hold, Reason: " litigation 2026-14 "})
+
+	suite.NoError(err)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *ClientServiceTestSuite) TestSetLegalHold_Lift() {
+	clientID := "65f1a2b3c4d5e6f708192a3b"
+	hold := false
+
+	suite.mockRepo.On("SetLegalHold", mock.Anything, clientID, (*model.LegalHold)(nil)).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationLegalHold && strings.Contains(l.Details, "lifted the legal hold")
+	})).Return("log-id", nil)
+
+	err := suite.clientService.SetLegalHold(context.Background(), clientID, &model.SetLegalHoldReq{Hold: &hold})
+
+	suite.NoError(err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ClientServiceTestSuite) TestSetLegalHold_NoReason() {
+	hold := true
+
+	err := suite.clientService.SetLegalHold(context.Background(), "65f1a2b3c4d5e6f708192a3b", &model.SetLegalHoldReq{Hold: &hold, Reason: "  "})
+
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+	suite.mockRepo.AssertNotCalled(suite.T(), "SetLegalHold", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ClientServiceTestSuite) TestSetLegalHold_NotFound() {
+	hold := true
+	suite.mockRepo.On("SetLegalHold", mock.Anything, mock.Anything, mock.Anything).Return(errorx.ErrNotFound)
+
+	err := suite.clientService.SetLegalHold(context.Background(), "65f1a2b3c4d5e6f708192a3b", &model.SetLegalHoldReq{Hold: &hold, Reason: "audit"})
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
 }
 
 func TestClientServiceTestSuite(t *testing.T) {
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// OutboxDispatcher periodically triggers the flow runs queued by SubmitJob. Entries are claimed one at a time,
+// so every replica can run a dispatcher without triggering a flow twice.
+type OutboxDispatcher struct {
+	jobService  JobServiceInterface
+	interval    time.Duration
+	maxAttempts int
+}
+
+func NewOutboxDispatcher(jobService JobServiceInterface, interval time.Duration, maxAttempts int) *OutboxDispatcher {
+	return &OutboxDispatcher{jobService: jobService, interval: interval, maxAttempts: maxAttempts}
+}
+
+// Run dispatches due outbox entries every interval until ctx is done
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Tick(ctx)
+		}
+	}
+}
+
+// Tick dispatches every outbox entry that is currently due
+func (d *OutboxDispatcher) Tick(ctx context.Context) {
+	if _, err := d.jobService.DispatchOutbox(ctx, d.maxAttempts); err != nil {
+		log.Printf("error dispatching outbox: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOutboxDispatcher_Tick(t *testing.T) {
+	jobService := new(mocks.JobServiceInterface)
+	jobService.On("DispatchOutbox", mock.Anything, 3).Return(0, errorx.ErrDependencyFailed).Once()
+	jobService.On("DispatchOutbox", mock.Anything, 3).Return(2, nil).Once()
+	dispatcher := service.NewOutboxDispatcher(jobService, time.Second, 3)
+
+	dispatcher.Tick(context.Background())
+	dispatcher.Tick(context.Background())
+
+	jobService.AssertExpectations(t)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	// idempotencyLock is how long a request may hold its key before a retry may take it over
+	idempotencyLock = time.Minute
+)
+
+// IdempotencyService records requests sent with an Idempotency-Key so that repeats get the first response
+type IdempotencyService struct {
+	idempotencyRepository repository.IdempotencyRepository
+	ttl                   time.Duration
+}
+
+type IdempotencyServiceInterface interface {
+	Begin(ctx context.Context, key string, method string, path string, requestHash string) (*model.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record *model.IdempotencyRecord, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, record *model.IdempotencyRecord) error
+}
+
+func NewIdempotencyService(idempotencyRepository repository.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{idempotencyRepository: idempotencyRepository, ttl: ttl}
+}
+
+// Begin claims key for the current user's request to method and path. It returns the stored record and true if an
+// earlier request with the same key and body already completed, so its response should be replayed.
+// Reusing a key with a different body, or while the first request is still running, is an ErrConflict.
+func (s *IdempotencyService) Begin(ctx context.Context, key string, method string, path string, requestHash string) (*model.IdempotencyRecord, bool, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, false, fmt.Errorf("%w: Idempotency-Key must be between 1 and %d characters", errorx.ErrInvalidInput, maxIdempotencyKeyLength)
+	}
+
+	actor := GetUsername(ctx)
+	now := time.Now()
+	record := &model.IdempotencyRecord{
+		ID:          idempotencyID(actor, method, path, key),
+		Key:         key,
+		Actor:       actor,
+		Method:      method,
+		Path:        path,
+		RequestHash: requestHash,
+		Token:       newIdempotencyToken(),
+		Status:      model.IdempotencyInProgress,
+		LockedUntil: now.Add(idempotencyLock),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	existing, err := s.idempotencyRepository.Reserve(ctx, record)
+	if err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrConflict) {
+			return nil, false, err
+		}
+		return nil, false, fmt.Errorf("%w: error reserving idempotency key", errorx.ErrInternal)
+	}
+	if existing == nil {
+		return record, false, nil
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, false, fmt.Errorf("%w: Idempotency-Key was already used for a different request", errorx.ErrConflict)
+	}
+	if existing.Status != model.IdempotencyCompleted {
+		return nil, false, fmt.Errorf("%w: a request with this Idempotency-Key is still in progress", errorx.ErrConflict)
+	}
+	return existing, true, nil
+}
+
+// Complete stores the response to replay for the record's key
+func (s *IdempotencyService) Complete(ctx context.Context, record *model.IdempotencyRecord, statusCode int, contentType string, body []byte) error {
+	if err := s.idempotencyRepository.Complete(ctx, record.ID, record.Token, statusCode, contentType, body); err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("%w: error completing idempotency key", errorx.ErrInternal)
+	}
+	return nil
+}
+
+// Release frees the record's key after a request that failed in a way worth retrying
+func (s *IdempotencyService) Release(ctx context.Context, record *model.IdempotencyRecord) error {
+	if err := s.idempotencyRepository.Delete(ctx, record.ID, record.Token); err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) {
+			return err
+		}
+		return fmt.Errorf("%w: error releasing idempotency key", errorx.ErrInternal)
+	}
+	return nil
+}
+
+func idempotencyID(actor string, method string, path string, key string) string {
+	sum := sha256.Sum256([]byte(actor + "\x00" + method + "\x00" + path + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newIdempotencyToken tells apart requests reserving the same key, so a request that lost its key after its lock
+// ran out can't complete or release it for the one that took it over
+func newIdempotencyToken() string {
+	token := make([]byte, 16)
+	_, _ = rand.Read(token)
+	return hex.EncodeToString(token)
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotencyServiceTestSuite struct {
+	suite.Suite
+	mockRepo           *mocks.IdempotencyRepository
+	idempotencyService *service.IdempotencyService
+	ctx                context.Context
+}
+
+func (suite *IdempotencyServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(mocks.IdempotencyRepository)
+	suite.idempotencyService = service.NewIdempotencyService(suite.mockRepo, time.Hour)
+	suite.ctx = context.WithValue(context.Background(), "username", "alice")
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_Reserves() {
+	suite.mockRepo.On("Reserve", mock.Anything, mock.MatchedBy(func(r *model.IdempotencyRecord) bool {
+		return r.Key == "key-1" && r.Actor == "alice" && r.Status == model.IdempotencyInProgress &&
+			r.RequestHash == "hash" && r.Token != "" && r.ExpiresAt.Sub(r.CreatedAt) == time.Hour
+	})).Return(nil, nil)
+
+	record, replay, err := suite.idempotencyService.Begin(suite.ctx, "key-1", "POST", "/api/v1/clients/scrape", "hash")
+
+	suite.NoError(err)
+	suite.False(replay)
+	suite.NotEmpty(record.ID)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_ScopedToUserAndRoute() {
+	var ids []string
+	suite.mockRepo.On("Reserve", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ids = append(ids, args.Get(1).(*model.IdempotencyRecord).ID)
+	}).Return(nil, nil)
+
+	_, _, _ = suite.idempotencyService.Begin(suite.ctx, "key-1", "POST", "/api/v1/clients/scrape", "hash")
+	_, _, _ = suite.idempotencyService.Begin(context.WithValue(context.Background(), "username", "bob"), "key-1", "POST", "/api/v1/clients/scrape", "hash")
+	_, _, _ = suite.idempotencyService.Begin(suite.ctx, "key-1", "POST", "/api/v1/clients/abc/scrape", "hash")
+
+	suite.Require().Len(ids, 3)
+	suite.NotEqual(ids[0], ids[1])
+	suite.NotEqual(ids[0], ids[2])
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_Replay() {
+	stored := &model.IdempotencyRecord{ID: "id", RequestHash: "hash", Status: model.IdempotencyCompleted, StatusCode: 201, Body: []byte(`{"data":"job-1"}`)}
+	suite.mockRepo.On("Reserve", mock.Anything, mock.Anything).Return(stored, nil)
+
+	record, replay, err := suite.idempotencyService.Begin(suite.ctx, "key-1", "POST", "/api/v1/clients/scrape", "hash")
+
+	suite.NoError(err)
+	suite.True(replay)
+	suite.Equal(stored, record)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_Conflicts() {
+	tests := []struct {
+		name     string
+		existing *model.IdempotencyRecord
+	}{
+		{"different body", &model.IdempotencyRecord{RequestHash: "other", Status: model.IdempotencyCompleted}},
+		{"still in progress", &model.IdempotencyRecord{RequestHash: "hash", Status: model.IdempotencyInProgress}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			suite.mockRepo.On("Reserve", mock.Anything, mock.Anything).Return(tt.existing, nil)
+
+			_, _, err := suite.idempotencyService.Begin(suite.ctx, "key-1", "POST", "/api/v1/clients/scrape", "hash")
+
+			suite.ErrorIs(err, errorx.ErrConflict)
+		})
+	}
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_InvalidKey() {
+	long := make([]byte, 256)
+	for i := range long {
+		long[i] = 'k'
+	}
+
+	_, _, err := suite.idempotencyService.Begin(suite.ctx, string(long), "POST", "/api/v1/clients/scrape", "hash")
+
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Reserve", mock.Anything, mock.Anything)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_DependencyFailed() {
+	suite.mockRepo.On("Reserve", mock.Anything, mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	_, _, err := suite.idempotencyService.Begin(suite.ctx, "key-1", "POST", "/api/v1/clients/scrape", "hash")
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestCompleteAndRelease() {
+	record := &model.IdempotencyRecord{ID: "id", Token: "token"}
+	suite.mockRepo.On("Complete", mock.Anything, "id", "token", 201, "application/json", []byte("{}")).Return(nil)
+	suite.mockRepo.On("Delete", mock.Anything, "id", "token").Return(nil)
+
+	suite.NoError(suite.idempotencyService.Complete(suite.ctx, record, 201, "application/json", []byte("{}")))
+	suite.NoError(suite.idempotencyService.Release(suite.ctx, record))
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func TestIdempotencyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyServiceTestSuite))
+}
@@ -4,24 +4,75 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type JobService struct {
-	jobRepository repository.JobRepository
+	jobRepository     repository.JobRepository
+	outboxRepository  repository.OutboxRepository
+	workflow          WorkflowBackend
+	logService        LogServiceInterface
+	notifier          NotifierInterface
+	transactor        repository.Transactor
+	quotaService      QuotaServiceInterface
+	timelineService   TimelineServiceInterface
 }
 
 type JobServiceInterface interface {
 	CreateJob(ctx context.Context, job *model.Job) (string, error)
+	SubmitJob(ctx context.Context, job *model.Job) (string, error)
 	GetJob(ctx context.Context, jobID string) (*model.Job, error)
 	GetAllJobs(ctx context.Context, query *model.GetJobsQuery) (total int, jobs []model.Job, err error)
+	GetStats(ctx context.Context, query *model.GetJobStatsQuery) (*model.JobStats, error)
+	SetFlowRunID(ctx context.Context, jobID string, flowRunID string) error
+	CancelJob(ctx context.Context, jobID string) (*model.Job, error)
+	RetryJob(ctx context.Context, jobID string) (*model.Job, error)
+	GetRetryChain(ctx context.Context, job *model.Job) ([]model.JobAttempt, error)
+	HandleCallback(ctx context.Context, jobID string, req *model.JobCallbackReq) (*model.Job, error)
+	StreamJob(ctx context.Context, jobID string, lastEventID string, send func(model.JobEvent) error) error
+	ReapStaleJobs(ctx context.Context, timeouts map[model.JobType]time.Duration, checkPrefect bool) (int, error)
+	DispatchOutbox(ctx context.Context, maxAttempts int) (int, error)
+	SubmitBatch(ctx context.Context, batch *model.Job, children []*model.Job) (string, error)
+	GetBatchChildren(ctx context.Context, batchID string, query *model.GetJobsQuery) (total int, jobs []model.Job, err error)
+	CancelBatch(ctx context.Context, batchID string) (*model.BatchActionResponse, error)
+	RetryBatch(ctx context.Context, batchID string) (*model.BatchActionResponse, error)
 }
 
-func NewJobService(jobRepository repository.JobRepository) *JobService {
-	return &JobService{jobRepository: jobRepository}
+// JobPollInterval is how often StreamJob re-reads a job when change streams are unavailable
+var JobPollInterval = 2 * time.Second
+
+// activeJobStatuses are the statuses a job can still be cancelled from
+var activeJobStatuses = []model.JobStatus{model.JobStatusPending, model.JobStatusProcessing}
+
+// activeFlowRunStates are the Prefect state types of flow runs that may still report back
+var activeFlowRunStates = []string{"SCHEDULED", "PENDING", "RUNNING", "PAUSED", "CANCELLING"}
+
+// jobStatsWindow is the window GetStats covers by default, and maxJobStatsWindow the longest it accepts
+const (
+	jobStatsWindow    = 30 * 24 * time.Hour
+	maxJobStatsWindow = 366 * 24 * time.Hour
+)
+
+// outboxClaimTimeout is how long a claimed outbox entry is left alone before another dispatcher may retry it
+const outboxClaimTimeout = time.Minute
+
+// outboxRetryBase and outboxRetryMax bound the backoff between attempts to trigger a flow run
+const (
+	outboxRetryBase = 5 * time.Second
+	outboxRetryMax  = 5 * time.Minute
+)
+
+func NewJobService(jobRepository repository.JobRepository, outboxRepository repository.OutboxRepository, workflow WorkflowBackend, logService LogServiceInterface, notifier NotifierInterface, transactor repository.Transactor, quotaService QuotaServiceInterface, timelineService TimelineServiceInterface) *JobService {
+	return &JobService{jobRepository: jobRepository, outboxRepository: outboxRepository, workflow: workflow, logService: logService, notifier: notifier, transactor: transactor, quotaService: quotaService, timelineService: timelineService}
 }
 
 func (s *JobService) CreateJob(ctx context.Context, job *model.Job) (string, error) {
@@ -36,6 +87,36 @@ func (s *JobService) CreateJob(ctx context.Context, job *model.Job) (string, err
 	return id, nil
 }
 
+// SubmitJob creates the job along with an outbox entry that DispatchOutbox turns into a flow run of its deployment.
+// Call it inside a transaction so the job and its entry are written together.
+func (s *JobService) SubmitJob(ctx context.Context, job *model.Job) (string, error) {
+	id, err := s.CreateJob(ctx, job)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	_, err = s.outboxRepository.Create(ctx, &model.OutboxEntry{
+		JobID:         id,
+		JobType:       job.Type,
+		Deployment:    job.Deployment,
+		Params:        jobParams(job, id),
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: error queueing job", errorx.ErrInternal)
+	}
+
+	return id, nil
+}
+
+// GetJob returns a job. A batch's status, progress and child counts are kept up to date as its children change.
 func (s *JobService) GetJob(ctx context.Context, jobID string) (*model.Job, error) {
 	job, err := s.jobRepository.GetOne(ctx, jobID)
 	if err != nil {
@@ -47,7 +128,42 @@ func (s *JobService) GetJob(ctx context.Context, jobID string) (*model.Job, erro
 	return job, nil
 }
 
+// GetRetryChain lists every attempt of the job's retry chain, including the original
+func (s *JobService) GetRetryChain(ctx context.Context, job *model.Job) ([]model.JobAttempt, error) {
+	rootID := job.RootID
+	if rootID == "" {
+		rootID = job.ID.Hex()
+	}
+
+	jobs, err := s.jobRepository.GetChain(ctx, rootID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrInvalidInput) || errors.Is(err, errorx.ErrDependencyFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting retry chain", errorx.ErrInternal)
+	}
+
+	chain := make([]model.JobAttempt, 0, len(jobs))
+	for _, j := range jobs {
+		chain = append(chain, model.JobAttempt{
+			ID:        j.ID.Hex(),
+			Attempt:   attemptNumber(j),
+			Status:    j.Status,
+			CreatedBy: j.CreatedBy,
+			CreatedAt: j.CreatedAt,
+		})
+	}
+	return chain, nil
+}
+
 func (s *JobService) GetAllJobs(ctx context.Context, query *model.GetJobsQuery) (total int, jobs []model.Job, err error) {
+	if err := validateJobsQuery(query); err != nil {
+		return 0, nil, err
+	}
+	if query.CreatedBy == "me" {
+		query.CreatedBy = GetUsername(ctx)
+	}
+
 	jobs, err = s.jobRepository.GetAll(ctx, query)
 	if err != nil {
 		if errors.Is(err, errorx.ErrDependencyFailed) {
@@ -67,3 +183,770 @@ func (s *JobService) GetAllJobs(ctx context.Context, query *model.GetJobsQuery)
 	return total, jobs, nil
 }
 
+// GetStats reports on the jobs created in the query's window, the last 30 days unless given.
+// Days without jobs are included in the throughput with zero counts.
+func (s *JobService) GetStats(ctx context.Context, query *model.GetJobStatsQuery) (*model.JobStats, error) {
+	if query.Type != "" && !query.Type.Valid() {
+		return nil, fmt.Errorf("%w: unknown job type '%s'", errorx.ErrInvalidInput, query.Type)
+	}
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-jobStatsWindow)
+	}
+	if query.From.After(query.To) {
+		return nil, fmt.Errorf("%w: from is after to", errorx.ErrInvalidInput)
+	}
+	if query.To.Sub(query.From) > maxJobStatsWindow {
+		return nil, fmt.Errorf("%w: the window can be at most %d days", errorx.ErrInvalidInput, int(maxJobStatsWindow.Hours()/24))
+	}
+
+	stats, err := s.jobRepository.GetStats(ctx, query)
+	if err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting job stats", errorx.ErrInternal)
+	}
+
+	completed, failed := stats.ByStatus[model.JobStatusCompleted], stats.ByStatus[model.JobStatusFailed]
+	if completed+failed > 0 {
+		stats.SuccessRate = float64(completed) / float64(completed+failed)
+	}
+	if stats.Failures == nil {
+		stats.Failures = []model.JobFailureCount{}
+	}
+	stats.Throughput = fillThroughput(stats.Throughput, query.From, query.To)
+	return stats, nil
+}
+
+// fillThroughput returns one entry per UTC day from from to to, taking the counts from days
+func fillThroughput(days []model.JobThroughput, from time.Time, to time.Time) []model.JobThroughput {
+	counts := make(map[string]model.JobThroughput, len(days))
+	for _, d := range days {
+		counts[d.Date] = d
+	}
+
+	filled := []model.JobThroughput{}
+	last := to.UTC().Format(time.DateOnly)
+	for day := from.UTC(); ; day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		if date > last {
+			break
+		}
+		t, ok := counts[date]
+		if !ok {
+			t = model.JobThroughput{Date: date}
+		}
+		filled = append(filled, t)
+	}
+	return filled
+}
+
+func (s *JobService) SetFlowRunID(ctx context.Context, jobID string, flowRunID string) error {
+	if err := s.jobRepository.SetFlowRunID(ctx, jobID, flowRunID); err != nil {
+		if errors.Is(err, errorx.ErrInvalidInput) || errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) {
+			return err
+		}
+		return fmt.Errorf("%w: error recording flow run", errorx.ErrInternal)
+	}
+	return nil
+}
+
+// CancelJob stops the job's flow run and marks it cancelled. Only the job's creator or an admin may cancel it.
+func (s *JobService) CancelJob(ctx context.Context, jobID string) (*model.Job, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canCancel(ctx, job) {
+		return nil, fmt.Errorf("%w: only the creator of the job or an admin can cancel it", errorx.ErrForbidden)
+	}
+	if job.Type == model.Batch {
+		return nil, fmt.Errorf("%w: cancel a batch through its children", errorx.ErrConflict)
+	}
+
+	if err := s.cancel(ctx, jobID, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// canCancel reports whether the caller created the job or is an admin
+func canCancel(ctx context.Context, job *model.Job) bool {
+	return IsAdmin(ctx) || (job.CreatedBy != "" && job.CreatedBy == GetUsername(ctx))
+}
+
+// cancel stops the job's flow run and marks the job cancelled, then audits and notifies the change
+func (s *JobService) cancel(ctx context.Context, jobID string, job *model.Job) error {
+	if job.Status != model.JobStatusPending && job.Status != model.JobStatusProcessing {
+		return fmt.Errorf("%w: job is already %s", errorx.ErrConflict, job.Status)
+	}
+	previous := job.Status
+
+	username := GetUsername(ctx)
+	message := fmt.Sprintf("Job cancelled by %s", username)
+	if job.PrefectFlowID != "" {
+		if err := s.workflow.Cancel(ctx, job.PrefectFlowID); err != nil {
+			return fmt.Errorf("%w: error cancelling flow run: %v", errorx.ErrDependencyFailed, err)
+		}
+	} else {
+		message += " before its flow run was started"
+	}
+
+	entry := model.JobLog{Message: message, Timestamp: time.Now()}
+	if err := s.endJob(ctx, jobID, model.JobStatusCancelled, entry); err != nil {
+		if errors.Is(err, errorx.ErrConflict) || errors.Is(err, errorx.ErrDependencyFailed) {
+			return err
+		}
+		return fmt.Errorf("%w: error cancelling job", errorx.ErrInternal)
+	}
+
+	job.Status = model.JobStatusCancelled
+	job.UpdatedAt = entry.Timestamp
+	job.Logs = append(job.Logs, entry)
+	s.recordTransition(ctx, job, previous)
+	return nil
+}
+
+// endJob moves an active job to a final status and withdraws its queued flow run in one transaction, so the
+// dispatcher never starts a run for a job that has already ended
+func (s *JobService) endJob(ctx context.Context, jobID string, status model.JobStatus, entry model.JobLog) error {
+	return s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.jobRepository.UpdateStatus(ctx, jobID, activeJobStatuses, status, entry); err != nil {
+			return err
+		}
+		return s.outboxRepository.CancelForJob(ctx, jobID, entry.Message)
+	})
+}
+
+// RetryJob creates a new attempt of a failed job with the same input and triggers the same deployment
+func (s *JobService) RetryJob(ctx context.Context, jobID string) (*model.Job, error) {
+	parent, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Type == model.Batch {
+		return nil, fmt.Errorf("%w: retry a batch through its children", errorx.ErrConflict)
+	}
+	if err := retryable(parent); err != nil {
+		return nil, err
+	}
+	// a retry is a new job, so it counts towards the caller's quotas
+	if err := quotaError(s.quotaService.Check(ctx)); err != nil {
+		return nil, err
+	}
+
+	job, err := s.retry(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	s.refreshBatchOf(ctx, job)
+	return job, nil
+}
+
+// retryable returns an error if the job can't be retried
+func retryable(job *model.Job) error {
+	if job.Status != model.JobStatusFailed {
+		return fmt.Errorf("%w: only failed jobs can be retried, job is %s", errorx.ErrConflict, job.Status)
+	}
+	if len(job.Input) == 0 {
+		return fmt.Errorf("%w: job has no recorded input to retry with", errorx.ErrValidationFailed)
+	}
+	return nil
+}
+
+// retry submits the next attempt of a failed job, in the same batch as the job
+func (s *JobService) retry(ctx context.Context, parent *model.Job) (*model.Job, error) {
+	if err := retryable(parent); err != nil {
+		return nil, err
+	}
+
+	rootID := parent.RootID
+	if rootID == "" {
+		rootID = parent.ID.Hex()
+	}
+
+	username := GetUsername(ctx)
+	input := bson.M{}
+	for k, v := range parent.Input {
+		input[k] = v
+	}
+	input["username"] = username
+
+	now := time.Now()
+	job := &model.Job{
+		Type:       parent.Type,
+		ClientID:   parent.ClientID,
+		CreatedBy:  username,
+		Deployment: parent.Deployment,
+		Input:      input,
+		FileBytes:  parent.FileBytes,
+		ParentID:   parent.ID.Hex(),
+		RootID:     rootID,
+		BatchID:    parent.BatchID,
+		Attempt:    attemptNumber(*parent) + 1,
+		Status:     model.JobStatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Logs: []model.JobLog{
+			{
+				Message:   fmt.Sprintf("Retry of job %s requested by %s", parent.ID.Hex(), username),
+				Timestamp: now,
+			},
+		},
+	}
+
+	// the attempt and its queued flow run are written together, DispatchOutbox starts the run
+	var id string
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.SubmitJob(ctx, job)
+		return err
+	})
+	if err != nil {
+		return nil, submitError(err)
+	}
+	job.ID, _ = bson.ObjectIDFromHex(id)
+
+	return job, nil
+}
+
+// HandleCallback applies a status transition, progress, log lines or results reported by a Prefect flow.
+// Repeating the job's current terminal status is a no-op, so flows can safely retry a callback.
+func (s *JobService) HandleCallback(ctx context.Context, jobID string, req *model.JobCallbackReq) (*model.Job, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Type == model.Batch {
+		return nil, fmt.Errorf("%w: a batch's status follows its children", errorx.ErrConflict)
+	}
+	if req.Status != "" && !req.Status.Valid() {
+		return nil, fmt.Errorf("%w: unknown job status '%s'", errorx.ErrValidationFailed, req.Status)
+	}
+	if req.Status == job.Status && job.Status.Terminal() {
+		return job, nil
+	}
+	if job.Status.Terminal() {
+		return nil, fmt.Errorf("%w: job is already %s", errorx.ErrConflict, job.Status)
+	}
+	if req.Status != "" && req.Status != job.Status && !job.Status.CanTransitionTo(req.Status) {
+		return nil, fmt.Errorf("%w: job cannot move from %s to %s", errorx.ErrConflict, job.Status, req.Status)
+	}
+
+	update, err := newJobUpdate(job, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.jobRepository.Apply(ctx, jobID, job.Status, update); err != nil {
+		if errors.Is(err, errorx.ErrConflict) || errors.Is(err, errorx.ErrDependencyFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error updating job", errorx.ErrInternal)
+	}
+
+	previous := job.Status
+	applyJobUpdate(job, update)
+	if job.Status != previous {
+		s.recordTransition(ctx, job, previous)
+		if job.Type == model.Scrape && job.Status == model.JobStatusCompleted {
+			s.captureScrape(ctx, job)
+		}
+	} else if update.Progress != nil {
+		// the batch's progress averages its children's
+		s.refreshBatchOf(ctx, job)
+	}
+
+	return job, nil
+}
+
+// captureScrape records on the client's timeline what a completed scrape job wrote to the profile, and audits the
+// changes. It runs once per job, since a repeated completed callback returns before here.
+func (s *JobService) captureScrape(ctx context.Context, job *model.Job) {
+	if job.ClientID == "" {
+		return
+	}
+	changes, err := s.timelineService.CaptureScrape(ctx, job.ClientID, job.UpdatedAt)
+	if err != nil {
+		log.Printf("error capturing timeline of client %s after scrape job %s: %v", job.ClientID, job.ID.Hex(), err) // don't return error since it's not critical
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	_, err = s.logService.CreateLog(ctx, &model.Log{
+		ClientID:  job.ClientID,
+		Actor:     pipelineActor,
+		Operation: model.OperationScrape,
+		Details:   fmt.Sprintf("Scrape job %s changed client profile with id %s, changing %s", job.ID.Hex(), job.ClientID, changedPaths(changes)),
+		Timestamp: job.UpdatedAt,
+		Changes:   changes,
+	})
+	if err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
+}
+
+func newJobUpdate(job *model.Job, req *model.JobCallbackReq) (*model.JobUpdate, error) {
+	now := time.Now()
+	update := &model.JobUpdate{UpdatedAt: now}
+	if req.Status != job.Status {
+		update.Status = req.Status
+	}
+
+	if req.Progress != nil {
+		if *req.Progress < 0 || *req.Progress > 100 {
+			return nil, fmt.Errorf("%w: progress must be between 0 and 100", errorx.ErrValidationFailed)
+		}
+		update.Progress = req.Progress
+	}
+
+	if req.ScrapeResult != "" {
+		if job.Type != model.Scrape {
+			return nil, fmt.Errorf("%w: scrape result reported for a %s job", errorx.ErrValidationFailed, job.Type)
+		}
+		id, err := bson.ObjectIDFromHex(req.ScrapeResult)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid scrape result ID", errorx.ErrValidationFailed)
+		}
+		update.ScrapeResult = &id
+	}
+
+	if req.MatchResults != nil {
+		if job.Type != model.Match {
+			return nil, fmt.Errorf("%w: match results reported for a %s job", errorx.ErrValidationFailed, job.Type)
+		}
+		for i, m := range req.MatchResults {
+			if m.ID.IsZero() || m.ConfidenceScore < 0 || m.ConfidenceScore > 1 {
+				return nil, fmt.Errorf("%w: match results need a client ID and a confidence score between 0 and 1", errorx.ErrValidationFailed)
+			}
+			// only reviewers decide on candidates
+			req.MatchResults[i].Review = nil
+		}
+		update.MatchResults = req.MatchResults
+	}
+
+	if req.ExtractedProfile != nil {
+		if job.Type != model.Match {
+			return nil, fmt.Errorf("%w: extracted profile reported for a %s job", errorx.ErrValidationFailed, job.Type)
+		}
+		update.ExtractedProfile = req.ExtractedProfile
+	}
+
+	if req.Message != "" {
+		update.Logs = append(update.Logs, model.JobLog{Message: req.Message, Timestamp: now})
+	}
+	for _, line := range req.Logs {
+		if line != "" {
+			update.Logs = append(update.Logs, model.JobLog{Message: line, Timestamp: now})
+		}
+	}
+
+	return update, nil
+}
+
+func applyJobUpdate(job *model.Job, update *model.JobUpdate) {
+	if update.Status != "" {
+		job.Status = update.Status
+	}
+	if update.Progress != nil {
+		job.Progress = *update.Progress
+	}
+	if update.ScrapeResult != nil {
+		job.ScrapeResult = *update.ScrapeResult
+	}
+	if update.MatchResults != nil {
+		job.MatchResults = update.MatchResults
+	}
+	if update.ExtractedProfile != nil {
+		job.ExtractedProfile = update.ExtractedProfile
+	}
+	job.Logs = append(job.Logs, update.Logs...)
+	job.UpdatedAt = update.UpdatedAt
+}
+
+// recordTransition audits a job's status change and notifies the job's creator
+func (s *JobService) recordTransition(ctx context.Context, job *model.Job, previous model.JobStatus) {
+	_, err := s.logService.CreateLog(ctx, &model.Log{
+		ClientID:  job.ClientID,
+		Actor:     pipelineActor,
+		Operation: model.OperationJobStatus,
+		Details:   fmt.Sprintf("%s job %s moved from %s to %s", job.Type, job.ID.Hex(), previous, job.Status),
+		Timestamp: job.UpdatedAt,
+	})
+	if err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
+	s.refreshBatchOf(ctx, job)
+
+	if job.Status == model.JobStatusPending {
+		return
+	}
+
+	priority := model.PriorityLow
+	if job.Status == model.JobStatusFailed || job.Status == model.JobStatusPartial {
+		priority = model.PriorityMedium
+	}
+	notification := &model.Notification{
+		NotificationType: model.NotificationTypeJob,
+		Username:         job.CreatedBy,
+		JobID:            job.ID.Hex(),
+		Status:           job.Status,
+		Type:             job.Type,
+		ClientID:         job.ClientID,
+		Priority:         priority,
+	}
+	if target, ok := job.Input["target"].(string); ok && target != "" {
+		notification.ClientName = []string{target}
+	}
+	if err := s.notifier.Publish(notification); err != nil {
+		log.Printf("error publishing notification for job %s: %v", job.ID.Hex(), err) // don't return error since the job was updated
+	}
+}
+
+// ReapStaleJobs fails pending and processing jobs that haven't been updated within their type's timeout.
+// When checkPrefect is set, jobs whose flow run Prefect still reports as active, or whose flow run state can't be
+// read, are left alone until a later check.
+func (s *JobService) ReapStaleJobs(ctx context.Context, timeouts map[model.JobType]time.Duration, checkPrefect bool) (int, error) {
+	reaped := 0
+	for jobType, timeout := range timeouts {
+		jobs, err := s.jobRepository.FindStale(ctx, jobType, activeJobStatuses, time.Now().Add(-timeout))
+		if err != nil {
+			if errors.Is(err, errorx.ErrDependencyFailed) {
+				return reaped, err
+			}
+			return reaped, fmt.Errorf("%w: error finding stale jobs", errorx.ErrInternal)
+		}
+
+		for i := range jobs {
+			job := &jobs[i]
+			message := fmt.Sprintf("Job timed out after %s without an update", timeout)
+			if checkPrefect && job.PrefectFlowID != "" {
+				state, err := s.workflow.State(ctx, job.PrefectFlowID)
+				switch {
+				case err != nil:
+					// Prefect may be down rather than the run lost, so check again on the next tick
+					log.Printf("error getting state of flow run %s for job %s: %v", job.PrefectFlowID, job.ID.Hex(), err)
+					continue
+				case slices.Contains(activeFlowRunStates, state):
+					continue
+				default:
+					message += fmt.Sprintf(", flow run is %s", state)
+				}
+			}
+
+			if err := s.failJob(ctx, job, message); err != nil {
+				if errors.Is(err, errorx.ErrConflict) {
+					continue // the flow reported back in the meantime
+				}
+				return reaped, err
+			}
+			reaped++
+		}
+	}
+	return reaped, nil
+}
+
+// DispatchOutbox triggers the flow runs of every outbox entry that is due, and returns how many were started.
+// A failed trigger is retried with backoff, and the job is failed once maxAttempts triggers have failed.
+func (s *JobService) DispatchOutbox(ctx context.Context, maxAttempts int) (int, error) {
+	delivered := 0
+	for {
+		entry, err := s.outboxRepository.Claim(ctx, outboxClaimTimeout)
+		if err != nil {
+			if errors.Is(err, errorx.ErrDependencyFailed) {
+				return delivered, err
+			}
+			return delivered, fmt.Errorf("%w: error claiming outbox entry", errorx.ErrInternal)
+		}
+		if entry == nil {
+			return delivered, nil
+		}
+
+		if s.deliver(ctx, entry, maxAttempts) {
+			delivered++
+		}
+	}
+}
+
+// deliver triggers the entry's flow run and records the outcome on the entry and its job. Entries of jobs that are
+// no longer pending, cancelled or failed while the entry was queued or backing off, are withdrawn instead.
+func (s *JobService) deliver(ctx context.Context, entry *model.OutboxEntry, maxAttempts int) bool {
+	job, err := s.jobRepository.GetOne(ctx, entry.JobID)
+	if err != nil && !errors.Is(err, errorx.ErrNotFound) {
+		// the claim runs out and the entry is tried again
+		log.Printf("error getting job %s to start its flow run: %v", entry.JobID, err)
+		return false
+	}
+	if err != nil || job.Status != model.JobStatusPending {
+		reason := "job no longer exists"
+		if job != nil {
+			reason = fmt.Sprintf("job is already %s", job.Status)
+		}
+		if err := s.outboxRepository.CancelForJob(ctx, entry.JobID, reason); err != nil {
+			log.Printf("error withdrawing outbox entry %s: %v", entry.ID.Hex(), err)
+		}
+		return false
+	}
+
+	flowRunID, err := s.workflow.Trigger(ctx, WorkflowRun{JobType: entry.JobType, Deployment: entry.Deployment, Params: entry.Params})
+	if err == nil {
+		if err := s.outboxRepository.MarkDelivered(ctx, entry.ID, flowRunID); err != nil {
+			log.Printf("error marking outbox entry %s delivered: %v", entry.ID.Hex(), err)
+		}
+		if flowRunID != "" {
+			if err := s.SetFlowRunID(ctx, entry.JobID, flowRunID); err != nil {
+				log.Printf("error recording flow run %s for job %s: %v", flowRunID, entry.JobID, err) // don't return error since the run was triggered
+			}
+			s.stopIfEnded(ctx, entry.JobID, flowRunID)
+		}
+		return true
+	}
+
+	log.Printf("error triggering flow run for job %s, attempt %d: %v", entry.JobID, entry.Attempts, err)
+	if entry.Attempts < maxAttempts {
+		if err := s.outboxRepository.Reschedule(ctx, entry.ID, time.Now().Add(outboxBackoff(entry.Attempts)), err.Error()); err != nil {
+			log.Printf("error rescheduling outbox entry %s: %v", entry.ID.Hex(), err)
+		}
+		return false
+	}
+
+	if err := s.outboxRepository.MarkFailed(ctx, entry.ID, err.Error()); err != nil {
+		log.Printf("error marking outbox entry %s failed: %v", entry.ID.Hex(), err)
+	}
+	message := fmt.Sprintf("Could not start flow after %d attempts: %v", entry.Attempts, err)
+	if err := s.failJob(ctx, job, message); err != nil && !errors.Is(err, errorx.ErrConflict) {
+		log.Printf("error failing job %s: %v", entry.JobID, err)
+	}
+	return false
+}
+
+// stopIfEnded cancels a flow run that was started while its job was being cancelled or failed, which deliver can't
+// rule out when the job ends between its check and the trigger
+func (s *JobService) stopIfEnded(ctx context.Context, jobID string, flowRunID string) {
+	job, err := s.jobRepository.GetOne(ctx, jobID)
+	if err != nil {
+		log.Printf("error getting job %s after starting its flow run: %v", jobID, err)
+		return
+	}
+	if job.Status != model.JobStatusCancelled && job.Status != model.JobStatusFailed {
+		return
+	}
+	if err := s.workflow.Cancel(ctx, flowRunID); err != nil {
+		log.Printf("error cancelling flow run %s of %s job %s: %v", flowRunID, job.Status, jobID, err)
+	}
+}
+
+// failJob marks an active job failed with message as its last log entry and withdraws its queued flow run, then
+// audits and notifies the change.
+// It returns ErrConflict if the job is no longer active.
+func (s *JobService) failJob(ctx context.Context, job *model.Job, message string) error {
+	previous := job.Status
+	entry := model.JobLog{Message: message, Timestamp: time.Now()}
+	if err := s.endJob(ctx, job.ID.Hex(), model.JobStatusFailed, entry); err != nil {
+		if errors.Is(err, errorx.ErrConflict) || errors.Is(err, errorx.ErrDependencyFailed) {
+			return err
+		}
+		return fmt.Errorf("%w: error failing job", errorx.ErrInternal)
+	}
+
+	job.Status = model.JobStatusFailed
+	job.UpdatedAt = entry.Timestamp
+	job.Logs = append(job.Logs, entry)
+	s.recordTransition(ctx, job, previous)
+	return nil
+}
+
+// outboxBackoff doubles the wait after each failed attempt, up to outboxRetryMax
+func outboxBackoff(attempts int) time.Duration {
+	wait := outboxRetryBase
+	for i := 1; i < attempts && wait < outboxRetryMax; i++ {
+		wait *= 2
+	}
+	return min(wait, outboxRetryMax)
+}
+
+// StreamJob sends the job's status and log entries as events until the job finishes or ctx is done.
+// lastEventID is the ID of the last event the client received, if it is resuming a stream.
+func (s *JobService) StreamJob(ctx context.Context, jobID string, lastEventID string, send func(model.JobEvent) error) error {
+	cursor, err := parseJobEventID(lastEventID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// start watching before the first read, so nothing written in between is missed
+	updates, err := s.jobRepository.Watch(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrInvalidInput) {
+			return err
+		}
+		log.Printf("change streams unavailable, polling job %s instead: %v", jobID, err)
+		updates = s.pollJob(ctx, jobID)
+	}
+
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := cursor.send(job, send); err != nil {
+			return err
+		}
+		if job.Status.Terminal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case next, ok := <-updates:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Printf("change stream for job %s closed, polling instead", jobID)
+				updates = s.pollJob(ctx, jobID)
+				continue
+			}
+			job = next
+		}
+	}
+}
+
+// pollJob re-reads the job every JobPollInterval until ctx is done
+func (s *JobService) pollJob(ctx context.Context, jobID string) <-chan *model.Job {
+	updates := make(chan *model.Job)
+	go func() {
+		defer close(updates)
+		ticker := time.NewTicker(JobPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			job, err := s.jobRepository.GetOne(ctx, jobID)
+			if err != nil {
+				log.Printf("error polling job %s: %v", jobID, err)
+				continue
+			}
+			select {
+			case updates <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates
+}
+
+// jobEventCursor tracks what a stream's client has already been sent
+type jobEventCursor struct {
+	logs     int
+	status   model.JobStatus
+	progress int
+}
+
+func parseJobEventID(id string) (*jobEventCursor, error) {
+	cursor := &jobEventCursor{progress: -1}
+	if id == "" {
+		return cursor, nil
+	}
+
+	count, status, ok := strings.Cut(id, "-")
+	logs, err := strconv.Atoi(count)
+	if !ok || err != nil || logs < 0 || !model.JobStatus(status).Valid() {
+		return nil, fmt.Errorf("%w: invalid Last-Event-ID '%s'", errorx.ErrInvalidInput, id)
+	}
+	cursor.logs = logs
+	cursor.status = model.JobStatus(status)
+	return cursor, nil
+}
+
+func (c *jobEventCursor) id() string {
+	return fmt.Sprintf("%d-%s", c.logs, c.status)
+}
+
+// send emits the log entries and status change in job that the client hasn't seen yet, logs first
+func (c *jobEventCursor) send(job *model.Job, send func(model.JobEvent) error) error {
+	for c.logs < len(job.Logs) {
+		entry := job.Logs[c.logs]
+		c.logs++
+		if err := send(model.JobEvent{ID: c.id(), Type: model.JobEventLog, Log: &entry}); err != nil {
+			return err
+		}
+	}
+
+	if job.Status == c.status && job.Progress == c.progress {
+		return nil
+	}
+	c.status = job.Status
+	c.progress = job.Progress
+	return send(model.JobEvent{ID: c.id(), Type: model.JobEventStatus, Status: job.Status, Progress: job.Progress})
+}
+
+func validateJobsQuery(query *model.GetJobsQuery) error {
+	if query.Status != "" && !query.Status.Valid() {
+		return fmt.Errorf("%w: unknown job status '%s'", errorx.ErrInvalidInput, query.Status)
+	}
+	if query.Type != "" && !query.Type.Valid() {
+		return fmt.Errorf("%w: unknown job type '%s'", errorx.ErrInvalidInput, query.Type)
+	}
+	if query.ClientID != "" {
+		if _, err := bson.ObjectIDFromHex(query.ClientID); err != nil {
+			return fmt.Errorf("%w: clientId '%s' is not a valid ObjectID", errorx.ErrInvalidInput, query.ClientID)
+		}
+	}
+	if query.BatchID != "" {
+		if _, err := bson.ObjectIDFromHex(query.BatchID); err != nil {
+			return fmt.Errorf("%w: batchId '%s' is not a valid ObjectID", errorx.ErrInvalidInput, query.BatchID)
+		}
+	}
+	if query.SortBy != "" && !slices.Contains(model.JobSortFields, query.SortBy) {
+		return fmt.Errorf("%w: sortBy must be one of %s", errorx.ErrInvalidInput, strings.Join(model.JobSortFields, ", "))
+	}
+	if query.Sort != "" && query.Sort != "asc" && query.Sort != "desc" {
+		return fmt.Errorf("%w: sort must be 'asc' or 'desc'", errorx.ErrInvalidInput)
+	}
+	if !query.CreatedFrom.IsZero() && !query.CreatedTo.IsZero() && query.CreatedFrom.After(query.CreatedTo) {
+		return fmt.Errorf("%w: createdFrom is after createdTo", errorx.ErrInvalidInput)
+	}
+	if !query.UpdatedFrom.IsZero() && !query.UpdatedTo.IsZero() && query.UpdatedFrom.After(query.UpdatedTo) {
+		return fmt.Errorf("%w: updatedFrom is after updatedTo", errorx.ErrInvalidInput)
+	}
+	return nil
+}
+
+// jobParams builds the Prefect flow parameters from a job's recorded input
+func jobParams(job *model.Job, jobID string) map[string]interface{} {
+	params := make(map[string]interface{}, len(job.Input)+2)
+	for k, v := range job.Input {
+		params[k] = v
+	}
+	if job.FileBytes != "" {
+		params["file_bytes"] = job.FileBytes
+	}
+	params["job_id"] = jobID
+	return params
+}
+
+// attemptNumber treats jobs created before retries were tracked as the first attempt
+func attemptNumber(job model.Job) int {
+	if job.Attempt < 1 {
+		return 1
+	}
+	return job.Attempt
+}
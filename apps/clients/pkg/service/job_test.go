@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,13 +18,33 @@ import (
 
 type JobServiceTestSuite struct {
 	suite.Suite
-	mockRepo   *mocks.JobRepository
-	jobService *service.JobService
+	mockRepo    *mocks.JobRepository
+	mockOutbox   *mocks.OutboxRepository
+	mockWorkflow *mocks.WorkflowBackend
+	mockLog      *mocks.LogServiceInterface
+	mockNotifier *mocks.NotifierInterface
+	mockTx       *mocks.Transactor
+	mockQuota    *mocks.QuotaServiceInterface
+	mockTimeline *mocks.TimelineServiceInterface
+	jobService   *service.JobService
 }
 
 func (suite *JobServiceTestSuite) SetupTest() {
 	suite.mockRepo = new(mocks.JobRepository)
-	suite.jobService = service.NewJobService(suite.mockRepo)
+	suite.mockOutbox = new(mocks.OutboxRepository)
+	suite.mockWorkflow = new(mocks.WorkflowBackend)
+	suite.mockLog = new(mocks.LogServiceInterface)
+	suite.mockNotifier = new(mocks.NotifierInterface)
+	suite.mockTx = new(mocks.Transactor)
+	suite.mockTx.On("WithTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+	suite.mockOutbox.On("CancelForJob", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockQuota = new(mocks.QuotaServiceInterface)
+	suite.mockQuota.On("Check", mock.Anything).Return(nil).Maybe()
+	suite.mockQuota.On("CheckJobs", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockTimeline = new(mocks.TimelineServiceInterface)
+	suite.jobService = service.NewJobService(suite.mockRepo, suite.mockOutbox, suite.mockWorkflow, suite.mockLog, suite.mockNotifier, suite.mockTx, suite.mockQuota, suite.mockTimeline)
 }
 
 func (suite *JobServiceTestSuite) TestCreateJob_Success() {
@@ -278,6 +299,867 @@ func (suite *JobServiceTestSuite) TestGetAllJobs_RepoCountReturnsError() {
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+func (suite *JobServiceTestSuite) TestCancelJob_ByCreator() {
+	jobID := bson.NewObjectID().Hex()
+	ctx := context.WithValue(context.Background(), "username", "alice")
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusProcessing, CreatedBy: "alice", PrefectFlowID: "flow-run-1"}, nil)
+	suite.mockWorkflow.On("Cancel", mock.Anything, "flow-run-1").Return(nil)
+	suite.mockRepo.On("UpdateStatus", mock.Anything, jobID, mock.Anything, model.JobStatusCancelled, mock.MatchedBy(func(l model.JobLog) bool {
+		return l.Message == "Job cancelled by alice"
+	})).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationJobStatus && strings.HasSuffix(l.Details, "moved from processing to cancelled")
+	})).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.Username == "alice" && n.Status == model.JobStatusCancelled
+	})).Return(nil)
+
+	job, err := suite.jobService.CancelJob(ctx, jobID)
+
+	suite.NoError(err)
+	suite.Equal(model.JobStatusCancelled, job.Status)
+	suite.Len(job.Logs, 1)
+	suite.mockWorkflow.AssertExpectations(suite.T())
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockOutbox.AssertCalled(suite.T(), "CancelForJob", mock.Anything, jobID, "Job cancelled by alice")
+	suite.mockLog.AssertExpectations(suite.T())
+	suite.mockNotifier.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestCancelJob_ByAdminWithoutFlowRun() {
+	jobID := bson.NewObjectID().Hex()
+	ctx := context.WithValue(context.Background(), "username", "bob")
+	ctx = context.WithValue(ctx, "groups", []string{"admin"})
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusPending, CreatedBy: "alice"}, nil)
+	suite.mockRepo.On("UpdateStatus", mock.Anything, jobID, mock.Anything, model.JobStatusCancelled, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.Anything).Return(nil)
+
+	job, err := suite.jobService.CancelJob(ctx, jobID)
+
+	suite.NoError(err)
+	suite.Equal(model.JobStatusCancelled, job.Status)
+	suite.mockWorkflow.AssertNotCalled(suite.T(), "Cancel", mock.Anything, mock.Anything)
+	// the queued flow run is withdrawn with the status change
+	suite.mockOutbox.AssertCalled(suite.T(), "CancelForJob", mock.Anything, jobID, "Job cancelled by bob before its flow run was started")
+	suite.mockTx.AssertNumberOfCalls(suite.T(), "WithTransaction", 1)
+}
+
+func (suite *JobServiceTestSuite) TestCancelJob_OutboxError() {
+	jobID := bson.NewObjectID().Hex()
+	ctx := context.WithValue(context.Background(), "username", "alice")
+	suite.mockOutbox = new(mocks.OutboxRepository)
+	suite.jobService = service.NewJobService(suite.mockRepo, suite.mockOutbox, suite.mockWorkflow, suite.mockLog, suite.mockNotifier, suite.mockTx, suite.mockQuota, suite.mockTimeline)
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusPending, CreatedBy: "alice"}, nil)
+	suite.mockRepo.On("UpdateStatus", mock.Anything, jobID, mock.Anything, model.JobStatusCancelled, mock.Anything).Return(nil)
+	suite.mockOutbox.On("CancelForJob", mock.Anything, jobID, mock.Anything).Return(errorx.ErrDependencyFailed)
+
+	_, err := suite.jobService.CancelJob(ctx, jobID)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestCancelJob_Forbidden() {
+	jobID := bson.NewObjectID().Hex()
+	ctx := context.WithValue(context.Background(), "username", "mallory")
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusPending, CreatedBy: "alice"}, nil)
+
+	_, err := suite.jobService.CancelJob(ctx, jobID)
+
+	suite.ErrorIs(err, errorx.ErrForbidden)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestCancelJob_AlreadyFinished() {
+	jobID := bson.NewObjectID().Hex()
+	ctx := context.WithValue(context.Background(), "username", "alice")
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusCompleted, CreatedBy: "alice", PrefectFlowID: "flow-run-1"}, nil)
+
+	_, err := suite.jobService.CancelJob(ctx, jobID)
+
+	suite.ErrorIs(err, errorx.ErrConflict)
+	suite.mockWorkflow.AssertNotCalled(suite.T(), "Cancel", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestCancelJob_PrefectError() {
+	jobID := bson.NewObjectID().Hex()
+	ctx := context.WithValue(context.Background(), "username", "alice")
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusPending, CreatedBy: "alice", PrefectFlowID: "flow-run-1"}, nil)
+	suite.mockWorkflow.On("Cancel", mock.Anything, "flow-run-1").Return(assert.AnError)
+
+	_, err := suite.jobService.CancelJob(ctx, jobID)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestRetryJob_Success() {
+	parentID := bson.NewObjectID()
+	newID := bson.NewObjectID().Hex()
+	ctx := context.WithValue(context.Background(), "username", "bob")
+	suite.mockRepo.On("GetOne", mock.Anything, parentID.Hex()).Return(&model.Job{
+		ID:         parentID,
+		Type:       model.Scrape,
+		ClientID:   "client-1",
+		CreatedBy:  "alice",
+		Deployment: "scrape-deployment",
+		Input:      bson.M{"target": "Jane Doe", "client_id": "client-1", "username": "alice"},
+		Status:     model.JobStatusFailed,
+	}, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+		return job.ParentID == parentID.Hex() && job.RootID == parentID.Hex() && job.Attempt == 2 &&
+			job.CreatedBy == "bob" && job.Status == model.JobStatusPending && job.Input["target"] == "Jane Doe"
+	})).Return(newID, nil)
+	suite.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(entry *model.OutboxEntry) bool {
+		return entry.JobID == newID && entry.JobType == model.Scrape && entry.Deployment == "scrape-deployment" &&
+			entry.Params["job_id"] == newID && entry.Params["target"] == "Jane Doe" && entry.Params["username"] == "bob"
+	})).Return("entry-id", nil)
+
+	job, err := suite.jobService.RetryJob(ctx, parentID.Hex())
+
+	suite.NoError(err)
+	suite.Equal(newID, job.ID.Hex())
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockOutbox.AssertExpectations(suite.T())
+	// the flow run is started by the dispatcher, not by the request
+	suite.mockWorkflow.AssertNotCalled(suite.T(), "Trigger", mock.Anything, mock.Anything)
+	suite.mockTx.AssertNumberOfCalls(suite.T(), "WithTransaction", 1)
+}
+
+func (suite *JobServiceTestSuite) TestRetryJob_KeepsFile() {
+	parentID := bson.NewObjectID()
+	newID := bson.NewObjectID().Hex()
+	suite.mockRepo.On("GetOne", mock.Anything, parentID.Hex()).Return(&model.Job{
+		ID:         parentID,
+		Type:       model.Match,
+		Deployment: "match-deployment",
+		Input:      bson.M{"file_name": "statement.pdf"},
+		FileBytes:  "JVBERi0=",
+		Status:     model.JobStatusFailed,
+	}, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+		return job.FileBytes == "JVBERi0="
+	})).Return(newID, nil)
+	suite.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(entry *model.OutboxEntry) bool {
+		return entry.Params["file_bytes"] == "JVBERi0="
+	})).Return("entry-id", nil)
+
+	_, err := suite.jobService.RetryJob(context.Background(), parentID.Hex())
+
+	suite.NoError(err)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockOutbox.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestRetryJob_KeepsRootOfChain() {
+	rootID := bson.NewObjectID().Hex()
+	parentID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, parentID.Hex()).Return(&model.Job{
+		ID:         parentID,
+		Deployment: "match-deployment",
+		Input:      bson.M{"target_id": "client-1"},
+		RootID:     rootID,
+		Attempt:    3,
+		Status:     model.JobStatusFailed,
+	}, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(job *model.Job) bool {
+		return job.RootID == rootID && job.ParentID == parentID.Hex() && job.Attempt == 4
+	})).Return(bson.NewObjectID().Hex(), nil)
+	suite.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(entry *model.OutboxEntry) bool {
+		return entry.Deployment == "match-deployment"
+	})).Return("entry-id", nil)
+
+	_, err := suite.jobService.RetryJob(context.Background(), parentID.Hex())
+
+	suite.NoError(err)
+	suite.mockOutbox.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestRetryJob_OverQuota() {
+	parentID := bson.NewObjectID()
+	suite.mockQuota = new(mocks.QuotaServiceInterface)
+	suite.jobService = service.NewJobService(suite.mockRepo, suite.mockOutbox, suite.mockWorkflow, suite.mockLog, suite.mockNotifier, suite.mockTx, suite.mockQuota, suite.mockTimeline)
+	suite.mockRepo.On("GetOne", mock.Anything, parentID.Hex()).Return(&model.Job{ID: parentID, Status: model.JobStatusFailed, Deployment: "d", Input: bson.M{"a": 1}}, nil)
+	suite.mockQuota.On("Check", mock.Anything).Return(&service.QuotaExceededError{Quota: "daily", Limit: 5})
+
+	_, err := suite.jobService.RetryJob(context.Background(), parentID.Hex())
+
+	suite.ErrorIs(err, errorx.ErrTooManyRequests)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestRetryJob_NotFailed() {
+	jobID := bson.NewObjectID().Hex()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusCompleted, Deployment: "d", Input: bson.M{"a": 1}}, nil)
+
+	_, err := suite.jobService.RetryJob(context.Background(), jobID)
+
+	suite.ErrorIs(err, errorx.ErrConflict)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+	suite.mockQuota.AssertNotCalled(suite.T(), "Check", mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestRetryJob_NoRecordedInput() {
+	jobID := bson.NewObjectID().Hex()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusFailed}, nil)
+
+	_, err := suite.jobService.RetryJob(context.Background(), jobID)
+
+	suite.ErrorIs(err, errorx.ErrValidationFailed)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestRetryJob_SubmitError() {
+	parentID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, parentID.Hex()).Return(&model.Job{ID: parentID, Status: model.JobStatusFailed, Deployment: "d", Input: bson.M{"a": 1}}, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(bson.NewObjectID().Hex(), nil)
+	suite.mockOutbox.On("Create", mock.Anything, mock.Anything).Return("", errorx.ErrDependencyFailed)
+
+	_, err := suite.jobService.RetryJob(context.Background(), parentID.Hex())
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *JobServiceTestSuite) TestGetRetryChain() {
+	rootID := bson.NewObjectID()
+	retryID := bson.NewObjectID()
+	suite.mockRepo.On("GetChain", mock.Anything, rootID.Hex()).Return([]model.Job{
+		{ID: rootID, Status: model.JobStatusFailed},
+		{ID: retryID, RootID: rootID.Hex(), Attempt: 2, Status: model.JobStatusProcessing},
+	}, nil)
+
+	chain, err := suite.jobService.GetRetryChain(context.Background(), &model.Job{ID: retryID, RootID: rootID.Hex(), Attempt: 2})
+
+	suite.NoError(err)
+	suite.Require().Len(chain, 2)
+	suite.Equal(1, chain[0].Attempt)
+	suite.Equal(retryID.Hex(), chain[1].ID)
+	suite.Equal(model.JobStatusProcessing, chain[1].Status)
+}
+
+func (suite *JobServiceTestSuite) TestGetRetryChain_RepoError() {
+	jobID := bson.NewObjectID()
+	suite.mockRepo.On("GetChain", mock.Anything, jobID.Hex()).Return(nil, assert.AnError)
+
+	_, err := suite.jobService.GetRetryChain(context.Background(), &model.Job{ID: jobID})
+
+	suite.ErrorIs(err, errorx.ErrInternal)
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_Completed() {
+	jobID := bson.NewObjectID()
+	resultID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{
+		ID:        jobID,
+		Type:      model.Scrape,
+		ClientID:  "client-1",
+		CreatedBy: "alice",
+		Input:     bson.M{"target": "Jane Doe"},
+		Status:    model.JobStatusProcessing,
+	}, nil)
+	suite.mockRepo.On("Apply", mock.Anything, jobID.Hex(), model.JobStatusProcessing, mock.MatchedBy(func(u *model.JobUpdate) bool {
+		return u.Status == model.JobStatusCompleted && *u.ScrapeResult == resultID && len(u.Logs) == 2
+	})).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationJobStatus && l.ClientID == "client-1"
+	})).Return("log-id", nil)
+	changes := []model.Change{{Path: "profile.netWorth", Old: int64(100), New: int64(250), Source: model.ChangeSourceScrape}}
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationScrape && l.Actor == "pipeline" && l.ClientID == "client-1" &&
+			strings.Contains(l.Details, jobID.Hex()) && assert.ObjectsAreEqual(changes, l.Changes)
+	})).Return("log-id", nil).Once()
+	suite.mockNotifier.On("Publish", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.JobID == jobID.Hex() && n.Username == "alice" && n.Status == model.JobStatusCompleted &&
+			n.Type == model.Scrape && n.ClientName[0] == "Jane Doe" && n.Priority == model.PriorityLow
+	})).Return(nil)
+	// the profile the job wrote is captured once, as of its completion
+	suite.mockTimeline.On("CaptureScrape", mock.Anything, "client-1", mock.MatchedBy(func(at time.Time) bool {
+		return time.Since(at) < time.Minute
+	})).Return(changes, nil).Once()
+
+	job, err := suite.jobService.HandleCallback(context.Background(), jobID.Hex(), &model.JobCallbackReq{
+		Status:       model.JobStatusCompleted,
+		Message:      "Scrape completed",
+		Logs:         []string{"Profile saved"},
+		ScrapeResult: resultID.Hex(),
+	})
+
+	suite.NoError(err)
+	suite.Equal(model.JobStatusCompleted, job.Status)
+	suite.Len(job.Logs, 2)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockLog.AssertExpectations(suite.T())
+	suite.mockNotifier.AssertExpectations(suite.T())
+	suite.mockTimeline.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_CaptureErrorIsNotFatal() {
+	jobID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{ID: jobID, Type: model.Scrape, ClientID: "client-1", Status: model.JobStatusProcessing}, nil)
+	suite.mockRepo.On("Apply", mock.Anything, jobID.Hex(), model.JobStatusProcessing, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.Anything).Return(nil)
+	suite.mockTimeline.On("CaptureScrape", mock.Anything, "client-1", mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	job, err := suite.jobService.HandleCallback(context.Background(), jobID.Hex(), &model.JobCallbackReq{Status: model.JobStatusCompleted})
+
+	suite.NoError(err)
+	suite.Equal(model.JobStatusCompleted, job.Status)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationScrape
+	}))
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_ProgressOnly() {
+	jobID := bson.NewObjectID()
+	progress := 40
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{ID: jobID, Type: model.Match, Status: model.JobStatusProcessing}, nil)
+	suite.mockRepo.On("Apply", mock.Anything, jobID.Hex(), model.JobStatusProcessing, mock.MatchedBy(func(u *model.JobUpdate) bool {
+		return u.Status == "" && *u.Progress == 40
+	})).Return(nil)
+
+	job, err := suite.jobService.HandleCallback(context.Background(), jobID.Hex(), &model.JobCallbackReq{Status: model.JobStatusProcessing, Progress: &progress})
+
+	suite.NoError(err)
+	suite.Equal(40, job.Progress)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+	suite.mockNotifier.AssertNotCalled(suite.T(), "Publish", mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_MatchResults() {
+	jobID := bson.NewObjectID()
+	candidate := bson.NewObjectID()
+	profile := bson.M{"profile": bson.M{"age": 42}}
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{ID: jobID, Type: model.Match, Status: model.JobStatusProcessing}, nil)
+	suite.mockRepo.On("Apply", mock.Anything, jobID.Hex(), model.JobStatusProcessing, mock.MatchedBy(func(u *model.JobUpdate) bool {
+		return len(u.MatchResults) == 1 && u.MatchResults[0].Review == nil && u.ExtractedProfile != nil
+	})).Return(nil)
+
+	job, err := suite.jobService.HandleCallback(context.Background(), jobID.Hex(), &model.JobCallbackReq{
+		MatchResults: []model.MatchResult{{
+			ID:              candidate,
+			ConfidenceScore: 0.8,
+			// flows cannot decide on candidates for the reviewers
+			Review: &model.MatchReview{Decision: model.MatchAccepted},
+		}},
+		ExtractedProfile: profile,
+	})
+
+	suite.NoError(err)
+	suite.Equal(profile, job.ExtractedProfile)
+	suite.Nil(job.MatchResults[0].Review)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_NotifierErrorIsNotFatal() {
+	jobID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{ID: jobID, Type: model.Match, Status: model.JobStatusPending}, nil)
+	suite.mockRepo.On("Apply", mock.Anything, jobID.Hex(), model.JobStatusPending, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("", assert.AnError)
+	suite.mockNotifier.On("Publish", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.Status == model.JobStatusFailed && n.Priority == model.PriorityMedium
+	})).Return(assert.AnError)
+
+	job, err := suite.jobService.HandleCallback(context.Background(), jobID.Hex(), &model.JobCallbackReq{Status: model.JobStatusFailed, Message: "boom"})
+
+	suite.NoError(err)
+	suite.Equal(model.JobStatusFailed, job.Status)
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_RepeatedTerminalStatus() {
+	jobID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{ID: jobID, Status: model.JobStatusCompleted}, nil)
+
+	job, err := suite.jobService.HandleCallback(context.Background(), jobID.Hex(), &model.JobCallbackReq{Status: model.JobStatusCompleted})
+
+	suite.NoError(err)
+	suite.Equal(model.JobStatusCompleted, job.Status)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Apply", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_InvalidTransitions() {
+	overflow := 101
+	tests := []struct {
+		name    string
+		current model.JobStatus
+		req     model.JobCallbackReq
+		err     error
+	}{
+		{"cancelled job keeps running", model.JobStatusCancelled, model.JobCallbackReq{Status: model.JobStatusProcessing}, errorx.ErrConflict},
+		{"completed job fails", model.JobStatusCompleted, model.JobCallbackReq{Status: model.JobStatusFailed}, errorx.ErrConflict},
+		{"log on finished job", model.JobStatusFailed, model.JobCallbackReq{Message: "late"}, errorx.ErrConflict},
+		{"back to pending", model.JobStatusProcessing, model.JobCallbackReq{Status: model.JobStatusPending}, errorx.ErrConflict},
+		{"unknown status", model.JobStatusProcessing, model.JobCallbackReq{Status: "done"}, errorx.ErrValidationFailed},
+		{"progress out of range", model.JobStatusProcessing, model.JobCallbackReq{Progress: &overflow}, errorx.ErrValidationFailed},
+		{"match results on scrape job", model.JobStatusProcessing, model.JobCallbackReq{MatchResults: []model.MatchResult{{ID: bson.NewObjectID(), ConfidenceScore: 0.5}}}, errorx.ErrValidationFailed},
+		{"bad scrape result", model.JobStatusProcessing, model.JobCallbackReq{ScrapeResult: "nope"}, errorx.ErrValidationFailed},
+		{"extracted profile on scrape job", model.JobStatusProcessing, model.JobCallbackReq{ExtractedProfile: bson.M{"profile": bson.M{}}}, errorx.ErrValidationFailed},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			jobID := bson.NewObjectID()
+			suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{ID: jobID, Type: model.Scrape, Status: tt.current}, nil)
+
+			_, err := suite.jobService.HandleCallback(context.Background(), jobID.Hex(), &tt.req)
+
+			suite.ErrorIs(err, tt.err)
+			suite.mockRepo.AssertNotCalled(suite.T(), "Apply", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func (suite *JobServiceTestSuite) TestHandleCallback_ConcurrentChange() {
+	jobID := bson.NewObjectID()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{ID: jobID, Status: model.JobStatusProcessing}, nil)
+	suite.mockRepo.On("Apply", mock.Anything, jobID.Hex(), model.JobStatusProcessing, mock.Anything).Return(errorx.ErrConflict)
+
+	_, err := suite.jobService.HandleCallback(context.Background(), jobID.Hex(), &model.JobCallbackReq{Status: model.JobStatusCompleted})
+
+	suite.ErrorIs(err, errorx.ErrConflict)
+	suite.mockNotifier.AssertNotCalled(suite.T(), "Publish", mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestGetAllJobs_CreatedByMe() {
+	ctx := context.WithValue(context.Background(), "username", "alice")
+	query := &model.GetJobsQuery{CreatedBy: "me", Type: model.Scrape, SortBy: "createdAt", Sort: "asc"}
+	matches := mock.MatchedBy(func(q *model.GetJobsQuery) bool { return q.CreatedBy == "alice" })
+	suite.mockRepo.On("GetAll", mock.Anything, matches).Return([]model.Job{}, nil)
+	suite.mockRepo.On("Count", mock.Anything, matches).Return(0, nil)
+
+	_, _, err := suite.jobService.GetAllJobs(ctx, query)
+
+	suite.NoError(err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestGetAllJobs_InvalidQuery() {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		query model.GetJobsQuery
+	}{
+		{"unknown status", model.GetJobsQuery{Status: "done"}},
+		{"unknown type", model.GetJobsQuery{Type: "crawl"}},
+		{"bad client id", model.GetJobsQuery{ClientID: "client-1"}},
+		{"unknown sort field", model.GetJobsQuery{SortBy: "logs"}},
+		{"bad sort order", model.GetJobsQuery{Sort: "up"}},
+		{"created range reversed", model.GetJobsQuery{CreatedFrom: now, CreatedTo: now.Add(-time.Hour)}},
+		{"updated range reversed", model.GetJobsQuery{UpdatedFrom: now, UpdatedTo: now.Add(-time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			_, _, err := suite.jobService.GetAllJobs(context.Background(), &tt.query)
+			suite.ErrorIs(err, errorx.ErrInvalidInput)
+		})
+	}
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetAll", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestGetStats_Success() {
+	from := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	suite.mockRepo.On("GetStats", mock.Anything, mock.Anything).Return(&model.JobStats{
+		Total:      4,
+		ByStatus:   map[model.JobStatus]int{model.JobStatusCompleted: 3, model.JobStatusFailed: 1},
+		Throughput: []model.JobThroughput{{Date: "2025-03-02", Created: 4, Completed: 3, Failed: 1}},
+	}, nil)
+
+	stats, err := suite.jobService.GetStats(context.Background(), &model.GetJobStatsQuery{From: from, To: to})
+
+	suite.Require().NoError(err)
+	suite.Equal(0.75, stats.SuccessRate)
+	suite.Equal([]model.JobThroughput{
+		{Date: "2025-03-01"},
+		{Date: "2025-03-02", Created: 4, Completed: 3, Failed: 1},
+		{Date: "2025-03-03"},
+	}, stats.Throughput)
+	suite.NotNil(stats.Failures)
+}
+
+func (suite *JobServiceTestSuite) TestGetStats_DefaultWindow() {
+	suite.mockRepo.On("GetStats", mock.Anything, mock.MatchedBy(func(q *model.GetJobStatsQuery) bool {
+		return time.Since(q.To) < time.Minute && q.To.Sub(q.From) == 30*24*time.Hour
+	})).Return(&model.JobStats{ByStatus: map[model.JobStatus]int{}}, nil)
+
+	stats, err := suite.jobService.GetStats(context.Background(), &model.GetJobStatsQuery{})
+
+	suite.Require().NoError(err)
+	suite.Zero(stats.SuccessRate)
+	suite.Len(stats.Throughput, 31)
+}
+
+func (suite *JobServiceTestSuite) TestGetStats_InvalidQuery() {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		query model.GetJobStatsQuery
+	}{
+		{"unknown type", model.GetJobStatsQuery{Type: "crawl"}},
+		{"range reversed", model.GetJobStatsQuery{From: now, To: now.Add(-time.Hour)}},
+		{"window too long", model.GetJobStatsQuery{From: now.AddDate(-2, 0, 0), To: now}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			_, err := suite.jobService.GetStats(context.Background(), &tt.query)
+			suite.ErrorIs(err, errorx.ErrInvalidInput)
+		})
+	}
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetStats", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestGetStats_RepoErrors() {
+	suite.mockRepo.On("GetStats", mock.Anything, mock.Anything).Return(nil, errorx.ErrDependencyFailed).Once()
+	_, err := suite.jobService.GetStats(context.Background(), &model.GetJobStatsQuery{})
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+
+	suite.mockRepo.On("GetStats", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	_, err = suite.jobService.GetStats(context.Background(), &model.GetJobStatsQuery{})
+	suite.ErrorIs(err, errorx.ErrInternal)
+}
+
+func collectEvents(events *[]model.JobEvent) func(model.JobEvent) error {
+	return func(e model.JobEvent) error {
+		*events = append(*events, e)
+		return nil
+	}
+}
+
+func (suite *JobServiceTestSuite) TestStreamJob_ChangeStream() {
+	jobID := bson.NewObjectID().Hex()
+	updates := make(chan *model.Job, 2)
+	updates <- &model.Job{Status: model.JobStatusProcessing, Logs: []model.JobLog{{Message: "started"}}}
+	updates <- &model.Job{Status: model.JobStatusCompleted, Progress: 100, Logs: []model.JobLog{{Message: "started"}, {Message: "done"}}}
+	suite.mockRepo.On("Watch", mock.Anything, jobID).Return((<-chan *model.Job)(updates), nil)
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusPending}, nil)
+
+	var events []model.JobEvent
+	err := suite.jobService.StreamJob(context.Background(), jobID, "", collectEvents(&events))
+
+	suite.NoError(err)
+	suite.Require().Len(events, 5)
+	suite.Equal(model.JobEvent{ID: "0-pending", Type: model.JobEventStatus, Status: model.JobStatusPending}, events[0])
+	suite.Equal("1-pending", events[1].ID)
+	suite.Equal("started", events[1].Log.Message)
+	suite.Equal("1-processing", events[2].ID)
+	suite.Equal("2-processing", events[3].ID)
+	suite.Equal(model.JobEvent{ID: "2-completed", Type: model.JobEventStatus, Status: model.JobStatusCompleted, Progress: 100}, events[4])
+}
+
+func (suite *JobServiceTestSuite) TestStreamJob_ResumeFromLastEventID() {
+	jobID := bson.NewObjectID().Hex()
+	suite.mockRepo.On("Watch", mock.Anything, jobID).Return((<-chan *model.Job)(make(chan *model.Job)), nil)
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{
+		Status: model.JobStatusFailed,
+		Logs:   []model.JobLog{{Message: "started"}, {Message: "scraping"}, {Message: "boom"}},
+	}, nil)
+
+	var events []model.JobEvent
+	err := suite.jobService.StreamJob(context.Background(), jobID, "2-processing", collectEvents(&events))
+
+	suite.NoError(err)
+	suite.Require().Len(events, 2)
+	suite.Equal("boom", events[0].Log.Message)
+	suite.Equal("3-processing", events[0].ID)
+	suite.Equal("3-failed", events[1].ID)
+}
+
+func (suite *JobServiceTestSuite) TestStreamJob_PollsWithoutChangeStreams() {
+	service.JobPollInterval = time.Millisecond
+	defer func() { service.JobPollInterval = 2 * time.Second }()
+
+	jobID := bson.NewObjectID().Hex()
+	suite.mockRepo.On("Watch", mock.Anything, jobID).Return(nil, errorx.ErrDependencyFailed)
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusProcessing}, nil).Once()
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusCompleted}, nil)
+
+	var events []model.JobEvent
+	err := suite.jobService.StreamJob(context.Background(), jobID, "", collectEvents(&events))
+
+	suite.NoError(err)
+	suite.Require().Len(events, 2)
+	suite.Equal(model.JobStatusProcessing, events[0].Status)
+	suite.Equal(model.JobStatusCompleted, events[1].Status)
+}
+
+func (suite *JobServiceTestSuite) TestStreamJob_StopsWhenClientDisconnects() {
+	jobID := bson.NewObjectID().Hex()
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.mockRepo.On("Watch", mock.Anything, jobID).Return((<-chan *model.Job)(make(chan *model.Job)), nil)
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(&model.Job{Status: model.JobStatusProcessing}, nil)
+
+	err := suite.jobService.StreamJob(ctx, jobID, "", func(model.JobEvent) error {
+		cancel()
+		return nil
+	})
+
+	suite.NoError(err)
+}
+
+func (suite *JobServiceTestSuite) TestStreamJob_Errors() {
+	jobID := bson.NewObjectID().Hex()
+
+	err := suite.jobService.StreamJob(context.Background(), jobID, "garbage", collectEvents(new([]model.JobEvent)))
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+
+	suite.mockRepo.On("Watch", mock.Anything, jobID).Return((<-chan *model.Job)(make(chan *model.Job)), nil)
+	suite.mockRepo.On("GetOne", mock.Anything, jobID).Return(nil, errorx.ErrNotFound)
+	err = suite.jobService.StreamJob(context.Background(), jobID, "", collectEvents(new([]model.JobEvent)))
+	suite.ErrorIs(err, errorx.ErrNotFound)
+}
+
+func (suite *JobServiceTestSuite) TestReapStaleJobs() {
+	timeouts := map[model.JobType]time.Duration{model.Scrape: 30 * time.Minute}
+	noFlow := model.Job{ID: bson.NewObjectID(), Type: model.Scrape, Status: model.JobStatusPending, CreatedBy: "alice"}
+	running := model.Job{ID: bson.NewObjectID(), Type: model.Scrape, Status: model.JobStatusProcessing, PrefectFlowID: "run-1"}
+	crashed := model.Job{ID: bson.NewObjectID(), Type: model.Scrape, Status: model.JobStatusProcessing, PrefectFlowID: "run-2"}
+	unknown := model.Job{ID: bson.NewObjectID(), Type: model.Scrape, Status: model.JobStatusPending, PrefectFlowID: "run-3"}
+
+	suite.mockRepo.On("FindStale", mock.Anything, model.Scrape, []model.JobStatus{model.JobStatusPending, model.JobStatusProcessing}, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= 30*time.Minute
+	})).Return([]model.Job{noFlow, running, crashed, unknown}, nil)
+	suite.mockWorkflow.On("State", mock.Anything, "run-1").Return("RUNNING", nil)
+	suite.mockWorkflow.On("State", mock.Anything, "run-2").Return("CRASHED", nil)
+	suite.mockWorkflow.On("State", mock.Anything, "run-3").Return("", assert.AnError)
+
+	var messages []string
+	for _, job := range []model.Job{noFlow, crashed} {
+		suite.mockRepo.On("UpdateStatus", mock.Anything, job.ID.Hex(), mock.Anything, model.JobStatusFailed, mock.Anything).
+			Run(func(args mock.Arguments) { messages = append(messages, args.Get(4).(model.JobLog).Message) }).
+			Return(nil)
+	}
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.Status == model.JobStatusFailed && n.Priority == model.PriorityMedium
+	})).Return(nil)
+
+	reaped, err := suite.jobService.ReapStaleJobs(context.Background(), timeouts, true)
+
+	suite.NoError(err)
+	suite.Equal(2, reaped)
+	suite.Equal([]string{
+		"Job timed out after 30m0s without an update",
+		"Job timed out after 30m0s without an update, flow run is CRASHED",
+	}, messages)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, running.ID.Hex(), mock.Anything, mock.Anything, mock.Anything)
+	// Prefect couldn't be asked, so the job is checked again on the next tick rather than failed
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, unknown.ID.Hex(), mock.Anything, mock.Anything, mock.Anything)
+	suite.mockNotifier.AssertNumberOfCalls(suite.T(), "Publish", 2)
+}
+
+func (suite *JobServiceTestSuite) TestReapStaleJobs_WithoutPrefectCheck() {
+	timeouts := map[model.JobType]time.Duration{model.Match: time.Hour}
+	job := model.Job{ID: bson.NewObjectID(), Type: model.Match, Status: model.JobStatusProcessing, PrefectFlowID: "run-1"}
+	updated := model.Job{ID: bson.NewObjectID(), Type: model.Match, Status: model.JobStatusProcessing}
+
+	suite.mockRepo.On("FindStale", mock.Anything, model.Match, mock.Anything, mock.Anything).Return([]model.Job{job, updated}, nil)
+	suite.mockRepo.On("UpdateStatus", mock.Anything, job.ID.Hex(), mock.Anything, model.JobStatusFailed, mock.Anything).Return(nil)
+	suite.mockRepo.On("UpdateStatus", mock.Anything, updated.ID.Hex(), mock.Anything, model.JobStatusFailed, mock.Anything).Return(errorx.ErrConflict)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.Anything).Return(nil)
+
+	reaped, err := suite.jobService.ReapStaleJobs(context.Background(), timeouts, false)
+
+	suite.NoError(err)
+	suite.Equal(1, reaped)
+	suite.mockWorkflow.AssertNotCalled(suite.T(), "State", mock.Anything, mock.Anything)
+	suite.mockNotifier.AssertNumberOfCalls(suite.T(), "Publish", 1)
+}
+
+func (suite *JobServiceTestSuite) TestReapStaleJobs_RepoError() {
+	suite.mockRepo.On("FindStale", mock.Anything, model.Scrape, mock.Anything, mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	_, err := suite.jobService.ReapStaleJobs(context.Background(), map[model.JobType]time.Duration{model.Scrape: time.Hour}, true)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *JobServiceTestSuite) TestSubmitJob() {
+	job := &model.Job{
+		Type:       model.Scrape,
+		Deployment: "scrape-deployment",
+		Input:      bson.M{"target": "Test Client", "username": "alice"},
+	}
+	suite.mockRepo.On("Create", mock.Anything, job).Return("job-id", nil)
+	suite.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(entry *model.OutboxEntry) bool {
+		return entry.JobID == "job-id" &&
+			entry.JobType == model.Scrape &&
+			entry.Deployment == "scrape-deployment" &&
+			entry.Status == model.OutboxStatusPending &&
+			entry.Params["job_id"] == "job-id" &&
+			entry.Params["target"] == "Test Client" &&
+			!entry.NextAttemptAt.After(time.Now())
+	})).Return("entry-id", nil)
+
+	id, err := suite.jobService.SubmitJob(context.Background(), job)
+
+	suite.NoError(err)
+	suite.Equal("job-id", id)
+	suite.mockOutbox.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestSubmitJob_FileBytes() {
+	job := &model.Job{
+		Type:       model.Match,
+		Deployment: "match-deployment",
+		Input:      bson.M{"file_name": "statement.pdf"},
+		FileBytes:  "JVBERi0=",
+	}
+	suite.mockRepo.On("Create", mock.Anything, job).Return("job-id", nil)
+	suite.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(entry *model.OutboxEntry) bool {
+		return entry.Params["file_bytes"] == "JVBERi0=" && entry.Params["file_name"] == "statement.pdf"
+	})).Return("entry-id", nil)
+
+	_, err := suite.jobService.SubmitJob(context.Background(), job)
+
+	suite.NoError(err)
+	suite.mockOutbox.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestSubmitJob_Errors() {
+	job := &model.Job{Deployment: "scrape-deployment"}
+	suite.mockRepo.On("Create", mock.Anything, job).Return("", errorx.ErrDependencyFailed).Once()
+
+	_, err := suite.jobService.SubmitJob(context.Background(), job)
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.mockOutbox.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+
+	suite.mockRepo.On("Create", mock.Anything, job).Return("job-id", nil)
+	suite.mockOutbox.On("Create", mock.Anything, mock.Anything).Return("", assert.AnError)
+
+	_, err = suite.jobService.SubmitJob(context.Background(), job)
+	suite.ErrorIs(err, errorx.ErrInternal)
+}
+
+func (suite *JobServiceTestSuite) TestDispatchOutbox_Delivers() {
+	entry := &model.OutboxEntry{ID: bson.NewObjectID(), JobID: "job-id", JobType: model.Scrape, Deployment: "scrape-deployment", Params: bson.M{"job_id": "job-id"}, Attempts: 1}
+	suite.mockOutbox.On("Claim", mock.Anything, time.Minute).Return(entry, nil).Once()
+	suite.mockOutbox.On("Claim", mock.Anything, time.Minute).Return(nil, nil).Once()
+	suite.mockWorkflow.On("Trigger", mock.Anything, service.WorkflowRun{
+		JobType:    model.Scrape,
+		Deployment: "scrape-deployment",
+		Params:     bson.M{"job_id": "job-id"},
+	}).Return("flow-run-1", nil)
+	suite.mockOutbox.On("MarkDelivered", mock.Anything, entry.ID, "flow-run-1").Return(nil)
+	suite.mockRepo.On("SetFlowRunID", mock.Anything, "job-id", "flow-run-1").Return(nil)
+	suite.mockRepo.On("GetOne", mock.Anything, "job-id").Return(&model.Job{Status: model.JobStatusPending}, nil)
+
+	delivered, err := suite.jobService.DispatchOutbox(context.Background(), 5)
+
+	suite.NoError(err)
+	suite.Equal(1, delivered)
+	suite.mockOutbox.AssertExpectations(suite.T())
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockWorkflow.AssertNotCalled(suite.T(), "Cancel", mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestDispatchOutbox_WithdrawsEndedJobs() {
+	cancelled := &model.OutboxEntry{ID: bson.NewObjectID(), JobID: "cancelled-job", Deployment: "scrape-deployment", Attempts: 2}
+	deleted := &model.OutboxEntry{ID: bson.NewObjectID(), JobID: "deleted-job", Deployment: "scrape-deployment", Attempts: 1}
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(cancelled, nil).Once()
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(deleted, nil).Once()
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	suite.mockRepo.On("GetOne", mock.Anything, "cancelled-job").Return(&model.Job{Status: model.JobStatusCancelled}, nil)
+	suite.mockRepo.On("GetOne", mock.Anything, "deleted-job").Return(nil, errorx.ErrNotFound)
+
+	delivered, err := suite.jobService.DispatchOutbox(context.Background(), 5)
+
+	suite.NoError(err)
+	suite.Equal(0, delivered)
+	suite.mockWorkflow.AssertNotCalled(suite.T(), "Trigger", mock.Anything, mock.Anything)
+	suite.mockOutbox.AssertCalled(suite.T(), "CancelForJob", mock.Anything, "cancelled-job", "job is already cancelled")
+	suite.mockOutbox.AssertCalled(suite.T(), "CancelForJob", mock.Anything, "deleted-job", "job no longer exists")
+}
+
+func (suite *JobServiceTestSuite) TestDispatchOutbox_StopsRunOfJobCancelledMeanwhile() {
+	entry := &model.OutboxEntry{ID: bson.NewObjectID(), JobID: "job-id", Deployment: "scrape-deployment", Attempts: 1}
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(entry, nil).Once()
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	suite.mockRepo.On("GetOne", mock.Anything, "job-id").Return(&model.Job{Status: model.JobStatusPending}, nil).Once()
+	suite.mockWorkflow.On("Trigger", mock.Anything, mock.Anything).Return("flow-run-1", nil)
+	suite.mockOutbox.On("MarkDelivered", mock.Anything, entry.ID, "flow-run-1").Return(nil)
+	suite.mockRepo.On("SetFlowRunID", mock.Anything, "job-id", "flow-run-1").Return(nil)
+	suite.mockRepo.On("GetOne", mock.Anything, "job-id").Return(&model.Job{Status: model.JobStatusCancelled}, nil)
+	suite.mockWorkflow.On("Cancel", mock.Anything, "flow-run-1").Return(nil)
+
+	delivered, err := suite.jobService.DispatchOutbox(context.Background(), 5)
+
+	suite.NoError(err)
+	suite.Equal(1, delivered)
+	suite.mockWorkflow.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestDispatchOutbox_JobLookupError() {
+	entry := &model.OutboxEntry{ID: bson.NewObjectID(), JobID: "job-id", Deployment: "scrape-deployment", Attempts: 1}
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(entry, nil).Once()
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	suite.mockRepo.On("GetOne", mock.Anything, "job-id").Return(nil, errorx.ErrDependencyFailed)
+
+	delivered, err := suite.jobService.DispatchOutbox(context.Background(), 5)
+
+	suite.NoError(err)
+	suite.Equal(0, delivered)
+	// the entry is left to be claimed again once its claim runs out
+	suite.mockWorkflow.AssertNotCalled(suite.T(), "Trigger", mock.Anything, mock.Anything)
+	suite.mockOutbox.AssertNotCalled(suite.T(), "CancelForJob", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestDispatchOutbox_RetriesWithBackoff() {
+	entry := &model.OutboxEntry{ID: bson.NewObjectID(), JobID: "job-id", Deployment: "scrape-deployment", Attempts: 3}
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(entry, nil).Once()
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	suite.mockRepo.On("GetOne", mock.Anything, "job-id").Return(&model.Job{Status: model.JobStatusPending}, nil)
+	suite.mockWorkflow.On("Trigger", mock.Anything, mock.Anything).Return("", assert.AnError)
+	suite.mockOutbox.On("Reschedule", mock.Anything, entry.ID, mock.MatchedBy(func(next time.Time) bool {
+		wait := time.Until(next)
+		return wait > 19*time.Second && wait <= 20*time.Second
+	}), assert.AnError.Error()).Return(nil)
+
+	delivered, err := suite.jobService.DispatchOutbox(context.Background(), 5)
+
+	suite.NoError(err)
+	suite.Equal(0, delivered)
+	suite.mockOutbox.AssertExpectations(suite.T())
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobServiceTestSuite) TestDispatchOutbox_FailsJobAfterMaxAttempts() {
+	jobID := bson.NewObjectID()
+	entry := &model.OutboxEntry{ID: bson.NewObjectID(), JobID: jobID.Hex(), Deployment: "scrape-deployment", Attempts: 5}
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(entry, nil).Once()
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	suite.mockWorkflow.On("Trigger", mock.Anything, mock.Anything).Return("", assert.AnError)
+	suite.mockOutbox.On("MarkFailed", mock.Anything, entry.ID, assert.AnError.Error()).Return(nil)
+	suite.mockRepo.On("GetOne", mock.Anything, jobID.Hex()).Return(&model.Job{ID: jobID, Type: model.Scrape, Status: model.JobStatusPending, CreatedBy: "alice"}, nil)
+	suite.mockRepo.On("UpdateStatus", mock.Anything, jobID.Hex(), []model.JobStatus{model.JobStatusPending, model.JobStatusProcessing}, model.JobStatusFailed, mock.MatchedBy(func(entry model.JobLog) bool {
+		return entry.Message == "Could not start flow after 5 attempts: "+assert.AnError.Error()
+	})).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockNotifier.On("Publish", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.Username == "alice" && n.Status == model.JobStatusFailed
+	})).Return(nil)
+
+	delivered, err := suite.jobService.DispatchOutbox(context.Background(), 5)
+
+	suite.NoError(err)
+	suite.Equal(0, delivered)
+	suite.mockOutbox.AssertExpectations(suite.T())
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockNotifier.AssertExpectations(suite.T())
+}
+
+func (suite *JobServiceTestSuite) TestDispatchOutbox_ClaimError() {
+	suite.mockOutbox.On("Claim", mock.Anything, mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	_, err := suite.jobService.DispatchOutbox(context.Background(), 5)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
 func TestJobServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(JobServiceTestSuite))
 }
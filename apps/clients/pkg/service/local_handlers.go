@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fixtureSource is recorded as the source of profiles written by the stub scraper
+const fixtureSource = "fixture"
+
+// StubScrapeHandler fills the client being scraped with a fixture profile instead of scraping the web
+func StubScrapeHandler(clientRepository repository.ClientRepository) LocalWorkflowHandler {
+	return func(ctx context.Context, run *LocalRun) error {
+		target, clientID := run.Param("target"), run.Param("client_id")
+		if target == "" || clientID == "" {
+			return errors.New("scrape run needs a target and a client_id")
+		}
+
+		if err := run.Log(ctx, fmt.Sprintf("[%s] Using fixture profile", target)); err != nil {
+			return err
+		}
+		update := bson.D{
+			{Key: "data.profile", Value: fixtureProfile(target)},
+			{Key: "metadata.scraped", Value: true},
+			{Key: "metadata.sources", Value: bson.A{fixtureSource}},
+			{Key: "metadata.updatedAt", Value: time.Now().UTC()},
+		}
+		if err := clientRepository.Update(ctx, clientID, update); err != nil {
+			return fmt.Errorf("error saving fixture profile: %w", err)
+		}
+		return run.Log(ctx, fmt.Sprintf("[%s] Profile saved", target))
+	}
+}
+
+// StubMatchHandler attributes the uploaded document to the client it was uploaded for
+func StubMatchHandler() LocalWorkflowHandler {
+	return func(ctx context.Context, run *LocalRun) error {
+		targetID, err := bson.ObjectIDFromHex(run.Param("target_id"))
+		if err != nil {
+			return errors.New("match run needs a valid target_id")
+		}
+
+		if err := run.Log(ctx, fmt.Sprintf("Matched %s against fixture profiles", run.Param("file_name"))); err != nil {
+			return err
+		}
+		return run.Report(ctx, &model.JobCallbackReq{
+			MatchResults: []model.MatchResult{{ID: targetID, ConfidenceScore: 0.9}},
+		})
+	}
+}
+
+// StubSyncHandler stands in for refreshing the vector index, which the local backend has none of
+func StubSyncHandler() LocalWorkflowHandler {
+	return func(ctx context.Context, run *LocalRun) error {
+		if run.Param("target_id") == "" {
+			return errors.New("sync run needs a target_id")
+		}
+		return run.Log(ctx, fmt.Sprintf("Skipped syncing client %s, there is no vector index locally", run.Param("target_id")))
+	}
+}
+
+func fixtureProfile(name string) bson.D {
+	return bson.D{
+		{Key: "names", Value: bson.A{name}},
+		{Key: "gender", Value: "Unknown"},
+		{Key: "dateOfBirth", Value: "1970-01-01"},
+		{Key: "description", Value: fmt.Sprintf("%s is a fixture profile written by the local workflow backend.", name)},
+		{Key: "nationality", Value: "Singaporean"},
+		{Key: "currentResidence", Value: bson.D{{Key: "city", Value: "Singapore"}, {Key: "country", Value: "Singapore"}}},
+		{Key: "netWorth", Value: bson.D{{Key: "estimatedValue", Value: 1000000000}, {Key: "currency", Value: "USD"}, {Key: "source", Value: fixtureSource}}},
+		{Key: "industries", Value: bson.A{"Finance"}},
+		{Key: "occupations", Value: bson.A{"Investor"}},
+		{Key: "pastOccupations", Value: bson.A{}},
+		{Key: "careerTimeline", Value: bson.A{}},
+		{Key: "socials", Value: bson.A{}},
+	}
+}
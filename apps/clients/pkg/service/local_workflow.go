@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+)
+
+// LocalWorkflowHandler does the work of one job type in process. It reports logs and results through run,
+// the way a Prefect flow calls back; the backend reports the processing, completed and failed statuses itself.
+type LocalWorkflowHandler func(ctx context.Context, run *LocalRun) error
+
+// JobCallbackHandler receives the status updates of workflow runs
+type JobCallbackHandler interface {
+	HandleCallback(ctx context.Context, jobID string, req *model.JobCallbackReq) (*model.Job, error)
+}
+
+// LocalRun is a run of a LocalWorkflowHandler
+type LocalRun struct {
+	ID     string
+	JobID  string
+	Params map[string]interface{}
+
+	callbacks JobCallbackHandler
+}
+
+// Report sends an update for the run's job, like the callback of a Prefect flow
+func (r *LocalRun) Report(ctx context.Context, req *model.JobCallbackReq) error {
+	_, err := r.callbacks.HandleCallback(ctx, r.JobID, req)
+	return err
+}
+
+// Log appends a line to the run's job log
+func (r *LocalRun) Log(ctx context.Context, message string) error {
+	return r.Report(ctx, &model.JobCallbackReq{Message: message})
+}
+
+// Param returns a string parameter of the run, or "" if it is missing
+func (r *LocalRun) Param(name string) string {
+	value, _ := r.Params[name].(string)
+	return value
+}
+
+type localRunState struct {
+	state  string
+	cancel context.CancelFunc
+}
+
+// LocalWorkflowBackend runs registered Go handlers in the clients service instead of Prefect, so jobs can be run
+// end to end in development and tests without a Prefect workspace. Runs are kept in memory only.
+type LocalWorkflowBackend struct {
+	mu        sync.Mutex
+	handlers  map[model.JobType]LocalWorkflowHandler
+	runs      map[string]*localRunState
+	callbacks JobCallbackHandler
+	wg        sync.WaitGroup
+}
+
+func NewLocalWorkflowBackend() *LocalWorkflowBackend {
+	return &LocalWorkflowBackend{
+		handlers: map[model.JobType]LocalWorkflowHandler{},
+		runs:     map[string]*localRunState{},
+	}
+}
+
+// Register sets the handler that runs jobs of jobType
+func (b *LocalWorkflowBackend) Register(jobType model.JobType, handler LocalWorkflowHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[jobType] = handler
+}
+
+// SetCallbackHandler sets where runs report to. It is set after construction because the job service that
+// receives the callbacks also triggers the runs.
+func (b *LocalWorkflowBackend) SetCallbackHandler(callbacks JobCallbackHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.callbacks = callbacks
+}
+
+// Trigger starts the handler of the run's job type in the background and returns the id of the run
+func (b *LocalWorkflowBackend) Trigger(ctx context.Context, run WorkflowRun) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	handler, ok := b.handlers[run.JobType]
+	if !ok {
+		return "", fmt.Errorf("no local handler registered for %s jobs", run.JobType)
+	}
+	if b.callbacks == nil {
+		return "", errors.New("local workflow backend has no callback handler")
+	}
+
+	jobID, _ := run.Params["job_id"].(string)
+	localRun := &LocalRun{ID: newLocalRunID(), JobID: jobID, Params: run.Params, callbacks: b.callbacks}
+
+	// the run outlives the request that triggered it
+	runCtx, cancel := context.WithCancel(context.Background())
+	b.runs[localRun.ID] = &localRunState{state: RunStateRunning, cancel: cancel}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer cancel()
+		b.setState(localRun.ID, b.execute(runCtx, handler, localRun))
+	}()
+
+	log.Printf("Started local %s run %s for job %s", run.JobType, localRun.ID, jobID)
+	return localRun.ID, nil
+}
+
+// execute drives the job through the same lifecycle as a Prefect flow and returns the run's final state
+func (b *LocalWorkflowBackend) execute(ctx context.Context, handler LocalWorkflowHandler, run *LocalRun) string {
+	err := run.Report(ctx, &model.JobCallbackReq{Status: model.JobStatusProcessing, Message: "Local run started"})
+	if err == nil {
+		err = handler(ctx, run)
+	}
+
+	// a cancelled job was already marked cancelled by the job service
+	if ctx.Err() != nil {
+		return RunStateCancelled
+	}
+
+	if err != nil {
+		log.Printf("Local run %s for job %s failed: %v", run.ID, run.JobID, err)
+		if err := run.Report(ctx, &model.JobCallbackReq{Status: model.JobStatusFailed, Message: err.Error()}); err != nil {
+			log.Printf("error reporting failure of local run %s: %v", run.ID, err)
+		}
+		return RunStateFailed
+	}
+
+	progress := 100
+	if err := run.Report(ctx, &model.JobCallbackReq{Status: model.JobStatusCompleted, Progress: &progress, Message: "Local run completed"}); err != nil {
+		log.Printf("error reporting completion of local run %s: %v", run.ID, err)
+		return RunStateFailed
+	}
+	return RunStateCompleted
+}
+
+// Cancel stops the run's handler through its context
+func (b *LocalWorkflowBackend) Cancel(ctx context.Context, runID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	run, ok := b.runs[runID]
+	if !ok {
+		return fmt.Errorf("unknown local run %s", runID)
+	}
+	if run.state != RunStateRunning {
+		return fmt.Errorf("local run %s is already %s", runID, run.state)
+	}
+	run.cancel()
+	return nil
+}
+
+func (b *LocalWorkflowBackend) State(ctx context.Context, runID string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	run, ok := b.runs[runID]
+	if !ok {
+		return "", fmt.Errorf("unknown local run %s", runID)
+	}
+	return run.state, nil
+}
+
+// Wait blocks until every run that has been started has finished
+func (b *LocalWorkflowBackend) Wait() {
+	b.wg.Wait()
+}
+
+func (b *LocalWorkflowBackend) setState(runID string, state string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.runs[runID].state = state
+}
+
+func newLocalRunID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return "local-" + hex.EncodeToString(id)
+}
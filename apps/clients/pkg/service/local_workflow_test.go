@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type LocalWorkflowTestSuite struct {
+	suite.Suite
+	mockCallbacks *mocks.JobCallbackHandler
+	backend       *service.LocalWorkflowBackend
+
+	mu      sync.Mutex
+	reports []model.JobCallbackReq
+}
+
+func (suite *LocalWorkflowTestSuite) SetupTest() {
+	suite.reports = nil
+	suite.mockCallbacks = new(mocks.JobCallbackHandler)
+	suite.mockCallbacks.On("HandleCallback", mock.Anything, "job-id", mock.Anything).
+		Run(func(args mock.Arguments) {
+			suite.mu.Lock()
+			defer suite.mu.Unlock()
+			suite.reports = append(suite.reports, *args.Get(2).(*model.JobCallbackReq))
+		}).
+		Return(&model.Job{}, nil).Maybe()
+	suite.backend = service.NewLocalWorkflowBackend()
+	suite.backend.SetCallbackHandler(suite.mockCallbacks)
+}
+
+func (suite *LocalWorkflowTestSuite) trigger(jobType model.JobType, params map[string]interface{}) string {
+	params["job_id"] = "job-id"
+	runID, err := suite.backend.Trigger(context.Background(), service.WorkflowRun{JobType: jobType, Params: params})
+	suite.Require().NoError(err)
+	return runID
+}
+
+func (suite *LocalWorkflowTestSuite) TestRunsHandlerThroughJobLifecycle() {
+	suite.backend.Register(model.Scrape, func(ctx context.Context, run *service.LocalRun) error {
+		return run.Log(ctx, "scraping "+run.Param("target"))
+	})
+
+	runID := suite.trigger(model.Scrape, map[string]interface{}{"target": "Jane Doe"})
+	suite.backend.Wait()
+
+	suite.Require().Len(suite.reports, 3)
+	suite.Equal(model.JobStatusProcessing, suite.reports[0].Status)
+	suite.Equal("scraping Jane Doe", suite.reports[1].Message)
+	suite.Equal(model.JobStatusCompleted, suite.reports[2].Status)
+	suite.Equal(100, *suite.reports[2].Progress)
+
+	state, err := suite.backend.State(context.Background(), runID)
+	suite.NoError(err)
+	suite.Equal(service.RunStateCompleted, state)
+}
+
+func (suite *LocalWorkflowTestSuite) TestHandlerError() {
+	suite.backend.Register(model.Match, func(ctx context.Context, run *service.LocalRun) error {
+		return errors.New("no document text")
+	})
+
+	runID := suite.trigger(model.Match, map[string]interface{}{})
+	suite.backend.Wait()
+
+	suite.Require().Len(suite.reports, 2)
+	suite.Equal(model.JobCallbackReq{Status: model.JobStatusFailed, Message: "no document text"}, suite.reports[1])
+	state, _ := suite.backend.State(context.Background(), runID)
+	suite.Equal(service.RunStateFailed, state)
+}
+
+func (suite *LocalWorkflowTestSuite) TestCancel() {
+	started := make(chan struct{})
+	suite.backend.Register(model.Scrape, func(ctx context.Context, run *service.LocalRun) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	runID := suite.trigger(model.Scrape, map[string]interface{}{})
+	<-started
+	suite.NoError(suite.backend.Cancel(context.Background(), runID))
+	suite.backend.Wait()
+
+	suite.Len(suite.reports, 1, "a cancelled run doesn't report a final status")
+	state, _ := suite.backend.State(context.Background(), runID)
+	suite.Equal(service.RunStateCancelled, state)
+	suite.Error(suite.backend.Cancel(context.Background(), runID))
+}
+
+func (suite *LocalWorkflowTestSuite) TestUnknownRunsAndJobTypes() {
+	_, err := suite.backend.Trigger(context.Background(), service.WorkflowRun{JobType: model.Match})
+	suite.Error(err)
+
+	_, err = suite.backend.State(context.Background(), "local-unknown")
+	suite.Error(err)
+	suite.Error(suite.backend.Cancel(context.Background(), "local-unknown"))
+}
+
+func (suite *LocalWorkflowTestSuite) TestStubScrapeHandler() {
+	clientID := bson.NewObjectID().Hex()
+	clientRepo := new(mocks.ClientRepository)
+	clientRepo.On("Update", mock.Anything, clientID, mock.MatchedBy(func(update bson.D) bool {
+		profile, ok := update[0].Value.(bson.D)
+		return update[0].Key == "data.profile" && ok && profile[0].Value.(bson.A)[0] == "Jane Doe"
+	})).Return(nil)
+	suite.backend.Register(model.Scrape, service.StubScrapeHandler(clientRepo))
+
+	suite.trigger(model.Scrape, map[string]interface{}{"target": "Jane Doe", "client_id": clientID})
+	suite.backend.Wait()
+
+	clientRepo.AssertExpectations(suite.T())
+	suite.Equal(model.JobStatusCompleted, suite.reports[len(suite.reports)-1].Status)
+}
+
+func (suite *LocalWorkflowTestSuite) TestStubMatchHandler() {
+	targetID := bson.NewObjectID()
+	suite.backend.Register(model.Match, service.StubMatchHandler())
+
+	suite.trigger(model.Match, map[string]interface{}{"target_id": targetID.Hex(), "file_name": "kyc.pdf"})
+	suite.backend.Wait()
+
+	var results []model.MatchResult
+	for _, report := range suite.reports {
+		if report.MatchResults != nil {
+			results = report.MatchResults
+		}
+	}
+	suite.Require().Len(results, 1)
+	suite.Equal(targetID, results[0].ID)
+	suite.Equal(model.JobStatusCompleted, suite.reports[len(suite.reports)-1].Status)
+}
+
+func TestLocalWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(LocalWorkflowTestSuite))
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
@@ -55,3 +56,12 @@ func (s *LogService) CreateLog(ctx context.Context, log *model.Log) (string, err
 	}
 	return id, nil
 }
+
+// changedPaths lists the paths of changes for a log's details
+func changedPaths(changes []model.Change) string {
+	paths := make([]string, len(changes))
+	for i, c := range changes {
+		paths[i] = c.Path
+	}
+	return strings.Join(paths, ", ")
+}
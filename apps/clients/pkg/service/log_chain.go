@@ -0,0 +1,175 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+// errChainBroken stops the walk of the log chain at the first break
+var errChainBroken = errors.New("log chain broken")
+
+type LogChainService struct {
+	logRepository repository.LogRepository
+	anchorStore   repository.LogAnchorStore
+	archiveStore  repository.ArchiveStore
+}
+
+type LogChainServiceInterface interface {
+	VerifyChain(ctx context.Context) (*model.ChainVerification, error)
+	AnchorHead(ctx context.Context) (*model.LogAnchor, error)
+}
+
+func NewLogChainService(logRepository repository.LogRepository, anchorStore repository.LogAnchorStore, archiveStore repository.ArchiveStore) *LogChainService {
+	return &LogChainService{logRepository: logRepository, anchorStore: anchorStore, archiveStore: archiveStore}
+}
+
+// VerifyChain walks the log chain from the latest checkpoint, or the first log if none were purged yet, checking each
+// log's sequence number, hash and link to the log before it, and that the chain still reaches and agrees with the
+// latest anchor. It reports the first break found. The content of archived logs has been purged from Mongo, so their
+// hash is checked against the copy in the archive they name instead.
+func (s *LogChainService) VerifyChain(ctx context.Context) (*model.ChainVerification, error) {
+	anchor, err := s.anchorStore.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, err := s.anchorStore.LatestCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ChainVerification{Anchor: anchor, Checkpoint: checkpoint}
+	var after int64
+	if checkpoint != nil {
+		// the logs up to the checkpoint were purged, the first log left must link to it
+		after = checkpoint.Seq
+		result.HeadSeq, result.HeadHash = checkpoint.Seq, checkpoint.Hash
+		if anchor != nil && anchor.Seq == checkpoint.Seq && anchor.Hash != checkpoint.Hash {
+			result.Break = &model.ChainBreak{Seq: checkpoint.Seq, Reason: model.ChainBreakAnchorMismatch}
+			result.VerifiedAt = time.Now()
+			return result, nil
+		}
+	}
+
+	archives := map[string]map[int64]string{}
+	err = s.logRepository.ForEachChained(ctx, after, func(l *model.Log) error {
+		reason, seq := chainBreak(l, result, anchor)
+		if reason == "" && l.ArchivedIn != "" {
+			hashes, ok := archives[l.ArchivedIn]
+			if !ok {
+				hashes, err = s.archivedHashes(ctx, l.ArchivedIn)
+				if err != nil {
+					return err
+				}
+				archives[l.ArchivedIn] = hashes
+			}
+			if hash, ok := hashes[l.Seq]; !ok || hash != l.Hash {
+				reason, seq = model.ChainBreakArchive, l.Seq
+			}
+		}
+		if reason != "" {
+			result.Break = &model.ChainBreak{Seq: seq, Reason: reason}
+			if seq == l.Seq {
+				result.Break.LogID = l.ID.Hex()
+			}
+			return errChainBroken
+		}
+
+		result.Checked++
+		if l.ArchivedIn != "" {
+			result.Archived++
+		}
+		result.HeadSeq, result.HeadHash = l.Seq, l.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+
+	if result.Break == nil && anchor != nil && result.HeadSeq < anchor.Seq {
+		result.Break = &model.ChainBreak{Seq: result.HeadSeq + 1, Reason: model.ChainBreakTruncated}
+	}
+	result.Valid = result.Break == nil
+	result.VerifiedAt = time.Now()
+	return result, nil
+}
+
+// AnchorHead records the current head of the log chain in the anchor store. It returns the latest anchor without
+// writing one if the head is already anchored, and nil if no log has been chained yet.
+func (s *LogChainService) AnchorHead(ctx context.Context) (*model.LogAnchor, error) {
+	head, err := s.logRepository.Head(ctx)
+	if err != nil || head == nil {
+		return nil, err
+	}
+
+	latest, err := s.anchorStore.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.Seq == head.Seq {
+		return latest, nil
+	}
+
+	anchor := &model.LogAnchor{Seq: head.Seq, Hash: head.Hash, AnchoredAt: time.Now()}
+	if err := s.anchorStore.Append(ctx, anchor); err != nil {
+		return nil, err
+	}
+	return anchor, nil
+}
+
+// archivedHashes reads the named archive and returns the hash of each log in it by sequence number. A log whose
+// archived content no longer matches its hash is left out, as is every log of an archive that is missing or can't be
+// read, so the stubs pointing at them break the chain.
+func (s *LogChainService) archivedHashes(ctx context.Context, name string) (map[int64]string, error) {
+	hashes := map[int64]string{}
+	r, err := s.archiveStore.Get(ctx, name)
+	if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrInvalidInput) {
+		return hashes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return hashes, nil
+	}
+	decoder := json.NewDecoder(gz)
+	for {
+		var l model.Log
+		if err := decoder.Decode(&l); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return map[int64]string{}, nil
+			}
+			return hashes, nil
+		}
+		if l.ChainHash() == l.Hash {
+			hashes[l.Seq] = l.Hash
+		}
+	}
+}
+
+// chainBreak checks a log against the chain verified so far, returning why and at which sequence number it breaks
+// the chain, or an empty reason if it doesn't
+func chainBreak(l *model.Log, verified *model.ChainVerification, anchor *model.LogAnchor) (model.ChainBreakReason, int64) {
+	expected := verified.HeadSeq + 1
+	switch {
+	case l.Seq != expected:
+		return model.ChainBreakGap, expected
+	case l.ArchivedIn == "" && l.ChainHash() != l.Hash:
+		return model.ChainBreakHash, l.Seq
+	case l.PrevHash != verified.HeadHash:
+		return model.ChainBreakPrevHash, l.Seq
+	case anchor != nil && anchor.Seq == l.Seq && anchor.Hash != l.Hash:
+		return model.ChainBreakAnchorMismatch, l.Seq
+	}
+	return "", 0
+}
@@ -0,0 +1,447 @@
+package service_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type LogChainServiceTestSuite struct {
+	suite.Suite
+	mockLogRepo     *mocks.LogRepository
+	mockAnchorStore *mocks.LogAnchorStore
+	mockStore       *mocks.ArchiveStore
+	chainService    *service.LogChainService
+}
+
+func (suite *LogChainServiceTestSuite) SetupTest() {
+	suite.mockLogRepo = new(mocks.LogRepository)
+	suite.mockAnchorStore = new(mocks.LogAnchorStore)
+	suite.mockStore = new(mocks.ArchiveStore)
+	suite.chainService = service.NewLogChainService(suite.mockLogRepo, suite.mockAnchorStore, suite.mockStore)
+}
+
+// chain builds n correctly chained logs
+func chain(n int) []*model.Log {
+	logs := make([]*model.Log, n)
+	prevHash := ""
+	for i := range logs {
+		l := &model.Log{
+			ID:        bson.NewObjectID(),
+			Actor:     "alice",
+			Operation: model.OperationUpdate,
+			Details:   "changed a field",
+			Timestamp: time.Date(2025, 3, 1, 9, i, 0, 0, time.UTC),
+			Seq:       int64(i + 1),
+			PrevHash:  prevHash,
+		}
+		l.Hash = l.ChainHash()
+		prevHash = l.Hash
+		logs[i] = l
+	}
+	return logs
+}
+
+func (suite *LogChainServiceTestSuite) mockChain(logs []*model.Log, anchor *model.LogAnchor) {
+	suite.mockChainFrom(nil, logs, anchor)
+}
+
+// mockChainFrom serves the logs left after the checkpoint
+func (suite *LogChainServiceTestSuite) mockChainFrom(checkpoint *model.LogCheckpoint, logs []*model.Log, anchor *model.LogAnchor) {
+	var after int64
+	if checkpoint != nil {
+		after = checkpoint.Seq
+	}
+	suite.mockAnchorStore.On("Latest", mock.Anything).Return(anchor, nil)
+	suite.mockAnchorStore.On("LatestCheckpoint", mock.Anything).Return(checkpoint, nil)
+	suite.mockLogRepo.On("ForEachChained", mock.Anything, after, mock.Anything).Return(func(ctx context.Context, after int64, fn func(*model.Log) error) error {
+		for _, l := range logs {
+			if err := fn(l); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// mockArchive stores the logs in the named archive, then cuts them down to stubs pointing at it
+func (suite *LogChainServiceTestSuite) mockArchive(name string, logs ...*model.Log) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, l := range logs {
+		line, err := json.Marshal(l)
+		suite.Require().NoError(err)
+		_, err = gz.Write(append(line, '\n'))
+		suite.Require().NoError(err)
+	}
+	suite.Require().NoError(gz.Close())
+	suite.mockStore.On("Get", mock.Anything, name).Return(func(ctx context.Context, name string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+
+	for _, l := range logs {
+		l.ArchivedIn, l.Actor, l.Details = name, "", ""
+	}
+}
+
+func (suite *LogChainServiceTestSuite) TestChainHash() {
+	l := chain(1)[0]
+	hash := l.ChainHash()
+
+	// sub-millisecond precision is lost in Mongo and must not change the hash
+	l.Timestamp = l.Timestamp.Add(500 * time.Microsecond)
+	suite.Equal(hash, l.ChainHash())
+
+	l.Details = "changed another field"
+	suite.NotEqual(hash, l.ChainHash())
+}
+
+func (suite *LogChainServiceTestSuite) TestChainHash_ChangesSurviveStorage() {
+	l := chain(1)[0]
+	l.Changes = []model.Change{{
+		Path:   "profile.netWorth",
+		Old:    nil,
+		New:    map[string]any{"estimatedValue": float64(250), "currency": "USD"},
+		Source: model.ChangeSourceManual,
+	}}
+	l.Hash = l.ChainHash()
+
+	raw, err := bson.Marshal(l)
+	suite.Require().NoError(err)
+	var stored model.Log
+	suite.Require().NoError(bson.Unmarshal(raw, &stored))
+
+	suite.Equal(l.Hash, stored.ChainHash())
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_Valid() {
+	logs := chain(3)
+	suite.mockChain(logs, &model.LogAnchor{Seq: 2, Hash: logs[1].Hash})
+
+	result, err := suite.chainService.VerifyChain(context.Background())
+
+	suite.NoError(err)
+	suite.True(result.Valid)
+	suite.Nil(result.Break)
+	suite.Equal(int64(3), result.Checked)
+	suite.Equal(int64(3), result.HeadSeq)
+	suite.Equal(logs[2].Hash, result.HeadHash)
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_Archived() {
+	logs := chain(3)
+	// purged logs keep only their place in the chain, the archive is read once
+	suite.mockArchive("logs-20260301T000000Z-1.ndjson.gz", logs[:2]...)
+	suite.mockChain(logs, &model.LogAnchor{Seq: 3, Hash: logs[2].Hash})
+
+	result, err := suite.chainService.VerifyChain(context.Background())
+
+	suite.NoError(err)
+	suite.True(result.Valid)
+	suite.Equal(int64(3), result.Checked)
+	suite.Equal(int64(2), result.Archived)
+	suite.mockStore.AssertNumberOfCalls(suite.T(), "Get", 1)
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_ArchivedBreaks() {
+	const name = "logs-20260301T000000Z-1.ndjson.gz"
+	tests := []struct {
+		name   string
+		tamper func(logs []*model.Log)
+		seq    int64
+		reason model.ChainBreakReason
+	}{
+		{
+			name: "forged stub",
+			tamper: func(logs []*model.Log) {
+				suite.mockArchive(name, logs[0])
+				logs[0].Hash = "forged"
+			},
+			seq:    1,
+			reason: model.ChainBreakArchive,
+		},
+		{
+			// a live log passed off as archived to hide its content
+			name: "stub not in archive",
+			tamper: func(logs []*model.Log) {
+				suite.mockArchive(name, logs[0])
+				logs[1].ArchivedIn, logs[1].Details = name, ""
+			},
+			seq:    2,
+			reason: model.ChainBreakArchive,
+		},
+		{
+			name: "altered archive",
+			tamper: func(logs []*model.Log) {
+				altered := *logs[1]
+				altered.Details = "nothing happened"
+				suite.mockArchive(name, logs[0], &altered)
+				logs[1].ArchivedIn, logs[1].Actor, logs[1].Details = name, "", ""
+			},
+			seq:    2,
+			reason: model.ChainBreakArchive,
+		},
+		{
+			name: "missing archive",
+			tamper: func(logs []*model.Log) {
+				suite.mockStore.On("Get", mock.Anything, name).Return(nil, errorx.ErrNotFound)
+				logs[0].ArchivedIn, logs[0].Details = name, ""
+			},
+			seq:    1,
+			reason: model.ChainBreakArchive,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			logs := chain(3)
+			tt.tamper(logs)
+			suite.mockChain(logs, nil)
+
+			result, err := suite.chainService.VerifyChain(context.Background())
+
+			suite.NoError(err)
+			suite.False(result.Valid)
+			suite.Equal(&model.ChainBreak{Seq: tt.seq, LogID: logs[tt.seq-1].ID.Hex(), Reason: tt.reason}, result.Break)
+		})
+	}
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_ArchiveStoreError() {
+	logs := chain(2)
+	logs[0].ArchivedIn = "logs-20260301T000000Z-1.ndjson.gz"
+	suite.mockStore.On("Get", mock.Anything, logs[0].ArchivedIn).Return(nil, errorx.ErrDependencyFailed)
+	suite.mockChain(logs, nil)
+
+	_, err := suite.chainService.VerifyChain(context.Background())
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_FromCheckpoint() {
+	logs := chain(5)
+	checkpoint := &model.LogCheckpoint{Seq: 2, Hash: logs[1].Hash}
+	suite.mockChainFrom(checkpoint, logs[2:], &model.LogAnchor{Seq: 4, Hash: logs[3].Hash})
+
+	result, err := suite.chainService.VerifyChain(context.Background())
+
+	suite.NoError(err)
+	suite.True(result.Valid)
+	suite.Equal(checkpoint, result.Checkpoint)
+	suite.Equal(int64(3), result.Checked)
+	suite.Equal(int64(5), result.HeadSeq)
+	suite.mockLogRepo.AssertExpectations(suite.T())
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_CheckpointBreaks() {
+	tests := []struct {
+		name       string
+		checkpoint func(logs []*model.Log) *model.LogCheckpoint
+		anchor     func(logs []*model.Log) *model.LogAnchor
+		seq        int64
+		reason     model.ChainBreakReason
+	}{
+		{
+			name:       "forged checkpoint",
+			checkpoint: func(logs []*model.Log) *model.LogCheckpoint { return &model.LogCheckpoint{Seq: 2, Hash: "forged"} },
+			seq:        3,
+			reason:     model.ChainBreakPrevHash,
+		},
+		{
+			// logs deleted past the checkpoint
+			name:       "gap after checkpoint",
+			checkpoint: func(logs []*model.Log) *model.LogCheckpoint { return &model.LogCheckpoint{Seq: 1, Hash: logs[0].Hash} },
+			seq:        2,
+			reason:     model.ChainBreakGap,
+		},
+		{
+			name:       "anchor disagrees",
+			checkpoint: func(logs []*model.Log) *model.LogCheckpoint { return &model.LogCheckpoint{Seq: 2, Hash: logs[1].Hash} },
+			anchor:     func(logs []*model.Log) *model.LogAnchor { return &model.LogAnchor{Seq: 2, Hash: "other"} },
+			seq:        2,
+			reason:     model.ChainBreakAnchorMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			logs := chain(4)
+			var anchor *model.LogAnchor
+			if tt.anchor != nil {
+				anchor = tt.anchor(logs)
+			}
+			suite.mockChainFrom(tt.checkpoint(logs), logs[2:], anchor)
+
+			result, err := suite.chainService.VerifyChain(context.Background())
+
+			suite.NoError(err)
+			suite.False(result.Valid)
+			suite.Equal(tt.seq, result.Break.Seq)
+			suite.Equal(tt.reason, result.Break.Reason)
+		})
+	}
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_Empty() {
+	suite.mockChain(nil, nil)
+
+	result, err := suite.chainService.VerifyChain(context.Background())
+
+	suite.NoError(err)
+	suite.True(result.Valid)
+	suite.Zero(result.Checked)
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_Breaks() {
+	tests := []struct {
+		name   string
+		tamper func(logs []*model.Log) []*model.Log
+		anchor func(logs []*model.Log) *model.LogAnchor
+		seq    int64
+		reason model.ChainBreakReason
+	}{
+		{
+			name:   "edited",
+			tamper: func(logs []*model.Log) []*model.Log { logs[1].Details = "nothing happened"; return logs },
+			seq:    2,
+			reason: model.ChainBreakHash,
+		},
+		{
+			name:   "deleted",
+			tamper: func(logs []*model.Log) []*model.Log { return append(logs[:1], logs[2:]...) },
+			seq:    2,
+			reason: model.ChainBreakGap,
+		},
+		{
+			name: "relinked",
+			tamper: func(logs []*model.Log) []*model.Log {
+				logs[2].PrevHash = logs[0].Hash
+				logs[2].Hash = logs[2].ChainHash()
+				return logs
+			},
+			seq:    3,
+			reason: model.ChainBreakPrevHash,
+		},
+		{
+			name: "rewritten",
+			tamper: func(logs []*model.Log) []*model.Log {
+				rewritten := chain(4)
+				rewritten[0].Actor = "mallory"
+				rewritten[0].Hash = rewritten[0].ChainHash()
+				for i := 1; i < len(rewritten); i++ {
+					rewritten[i].PrevHash = rewritten[i-1].Hash
+					rewritten[i].Hash = rewritten[i].ChainHash()
+				}
+				return rewritten
+			},
+			anchor: func(logs []*model.Log) *model.LogAnchor { return &model.LogAnchor{Seq: 3, Hash: logs[2].Hash} },
+			seq:    3,
+			reason: model.ChainBreakAnchorMismatch,
+		},
+		{
+			name:   "truncated",
+			tamper: func(logs []*model.Log) []*model.Log { return logs[:2] },
+			anchor: func(logs []*model.Log) *model.LogAnchor { return &model.LogAnchor{Seq: 4, Hash: logs[3].Hash} },
+			seq:    3,
+			reason: model.ChainBreakTruncated,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			logs := chain(4)
+			var anchor *model.LogAnchor
+			if tt.anchor != nil {
+				anchor = tt.anchor(logs)
+			}
+			suite.mockChain(tt.tamper(logs), anchor)
+
+			result, err := suite.chainService.VerifyChain(context.Background())
+
+			suite.NoError(err)
+			suite.False(result.Valid)
+			suite.Equal(tt.seq, result.Break.Seq)
+			suite.Equal(tt.reason, result.Break.Reason)
+			suite.Equal(tt.seq-1, result.Checked)
+		})
+	}
+}
+
+func (suite *LogChainServiceTestSuite) TestVerifyChain_RepoError() {
+	suite.mockAnchorStore.On("Latest", mock.Anything).Return(nil, nil)
+	suite.mockAnchorStore.On("LatestCheckpoint", mock.Anything).Return(nil, nil)
+	suite.mockLogRepo.On("ForEachChained", mock.Anything, mock.Anything, mock.Anything).Return(errorx.ErrDependencyFailed)
+
+	_, err := suite.chainService.VerifyChain(context.Background())
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *LogChainServiceTestSuite) TestAnchorHead() {
+	head := chain(5)[4]
+	suite.mockLogRepo.On("Head", mock.Anything).Return(head, nil)
+	suite.mockAnchorStore.On("Latest", mock.Anything).Return(&model.LogAnchor{Seq: 3, Hash: "old"}, nil)
+	suite.mockAnchorStore.On("Append", mock.Anything, mock.MatchedBy(func(a *model.LogAnchor) bool {
+		return a.Seq == 5 && a.Hash == head.Hash
+	})).Return(nil)
+
+	anchor, err := suite.chainService.AnchorHead(context.Background())
+
+	suite.NoError(err)
+	suite.Equal(int64(5), anchor.Seq)
+	suite.mockAnchorStore.AssertExpectations(suite.T())
+}
+
+func (suite *LogChainServiceTestSuite) TestAnchorHead_AlreadyAnchored() {
+	head := chain(2)[1]
+	latest := &model.LogAnchor{Seq: 2, Hash: head.Hash}
+	suite.mockLogRepo.On("Head", mock.Anything).Return(head, nil)
+	suite.mockAnchorStore.On("Latest", mock.Anything).Return(latest, nil)
+
+	anchor, err := suite.chainService.AnchorHead(context.Background())
+
+	suite.NoError(err)
+	suite.Equal(latest, anchor)
+	suite.mockAnchorStore.AssertNotCalled(suite.T(), "Append", mock.Anything, mock.Anything)
+}
+
+func (suite *LogChainServiceTestSuite) TestAnchorHead_NoLogs() {
+	suite.mockLogRepo.On("Head", mock.Anything).Return(nil, nil)
+
+	anchor, err := suite.chainService.AnchorHead(context.Background())
+
+	suite.NoError(err)
+	suite.Nil(anchor)
+	suite.mockAnchorStore.AssertNotCalled(suite.T(), "Latest", mock.Anything)
+}
+
+func TestLogChainServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LogChainServiceTestSuite))
+}
+
+func TestLogAnchorer_Tick(t *testing.T) {
+	chainService := new(mocks.LogChainServiceInterface)
+	chainService.On("AnchorHead", mock.Anything).Return(nil, errorx.ErrDependencyFailed).Once()
+	chainService.On("AnchorHead", mock.Anything).Return(&model.LogAnchor{Seq: 1}, nil).Once()
+	anchorer := service.NewLogAnchorer(chainService, time.Second)
+
+	anchorer.Tick(context.Background())
+	anchorer.Tick(context.Background())
+
+	chainService.AssertExpectations(t)
+}
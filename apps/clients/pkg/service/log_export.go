@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// logExportColumns is the header row of CSV exports
+var logExportColumns = []string{"id", "timestamp", "actor", "operation", "clientId", "details", "seq", "hash", "changes"}
+
+// exportFinishTimeout bounds storing an export's manifest and auditing its outcome once streaming stops
+const exportFinishTimeout = 10 * time.Second
+
+type LogExportService struct {
+	logRepository    repository.LogRepository
+	exportRepository repository.LogExportRepository
+	logService       LogServiceInterface
+	secret           string
+}
+
+type LogExportServiceInterface interface {
+	StartExport(ctx context.Context, query *model.ExportLogsQuery) (*model.LogExport, error)
+	WriteExport(ctx context.Context, export *model.LogExport, w io.Writer) error
+	GetExport(ctx context.Context, exportID string) (*model.LogExport, error)
+}
+
+func NewLogExportService(logRepository repository.LogRepository, exportRepository repository.LogExportRepository, logService LogServiceInterface, secret string) *LogExportService {
+	return &LogExportService{logRepository: logRepository, exportRepository: exportRepository, logService: logService, secret: secret}
+}
+
+// StartExport checks an export request and returns the manifest the export will be written to, so bad requests are
+// rejected before anything is streamed. The format defaults to CSV. The export is audited before it starts, and
+// refused if it can't be.
+func (s *LogExportService) StartExport(ctx context.Context, query *model.ExportLogsQuery) (*model.LogExport, error) {
+	if s.secret == "" {
+		return nil, fmt.Errorf("%w: no signing secret configured for log exports", errorx.ErrInternal)
+	}
+
+	if query.Format == "" {
+		query.Format = model.ExportFormatCSV
+	}
+	if !query.Format.Valid() {
+		return nil, fmt.Errorf("%w: unknown export format '%s'", errorx.ErrInvalidInput, query.Format)
+	}
+	if query.ClientID != "" {
+		if _, err := bson.ObjectIDFromHex(query.ClientID); err != nil {
+			return nil, fmt.Errorf("%w: clientId '%s' is not a valid ObjectID", errorx.ErrInvalidInput, query.ClientID)
+		}
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, fmt.Errorf("%w: 'to' must not be before 'from'", errorx.ErrInvalidInput)
+	}
+
+	// times are kept to the millisecond and in UTC as Mongo stores them, so a stored manifest encodes as it was signed
+	export := &model.LogExport{
+		ID:     bson.NewObjectID(),
+		Format: query.Format,
+		Filters: model.LogExportFilters{
+			ClientID:  query.ClientID,
+			Operation: query.Operation,
+			Actor:     query.Actor,
+			Path:      query.Path,
+			From:      storedTime(query.From),
+			To:        storedTime(query.To),
+		},
+		CreatedBy: GetUsername(ctx),
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	details := fmt.Sprintf("User %s started export %s of logs as %s", export.CreatedBy, export.ID.Hex(), export.Format)
+	if err := s.auditExport(ctx, export, details); err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error auditing log export", errorx.ErrInternal)
+	}
+	return export, nil
+}
+
+// WriteExport streams every log matching the export's filters to w, oldest first. Once all are written the manifest
+// is completed with the row count and hash of what was written, signed and stored, and the export is audited as
+// completed. An export that stops early is audited as aborted with the rows written so far. Both outcomes are recorded
+// even if the caller has gone away.
+func (s *LogExportService) WriteExport(ctx context.Context, export *model.LogExport, w io.Writer) error {
+	hash := sha256.New()
+	out := io.MultiWriter(w, hash)
+	query := &model.GetLogsQuery{
+		ClientID:  export.Filters.ClientID,
+		Operation: export.Filters.Operation,
+		Actor:     export.Filters.Actor,
+		Path:      export.Filters.Path,
+	}
+	if export.Filters.From != nil {
+		query.From = *export.Filters.From
+	}
+	if export.Filters.To != nil {
+		query.To = *export.Filters.To
+	}
+
+	var rows int
+	var err error
+	switch export.Format {
+	case model.ExportFormatCSV:
+		rows, err = s.writeCSV(ctx, query, out)
+	case model.ExportFormatNDJSON:
+		rows, err = s.writeNDJSON(ctx, query, out)
+	default:
+		err = fmt.Errorf("%w: unknown export format '%s'", errorx.ErrInvalidInput, export.Format)
+	}
+
+	// the request's context ends as soon as the client disconnects
+	done, cancel := context.WithTimeout(context.WithoutCancel(ctx), exportFinishTimeout)
+	defer cancel()
+
+	if err == nil {
+		err = s.storeManifest(done, export, rows, hex.EncodeToString(hash.Sum(nil)))
+	}
+	if err != nil {
+		details := fmt.Sprintf("User %s aborted export %s of logs as %s after %d logs: %v", export.CreatedBy, export.ID.Hex(), export.Format, rows, err)
+		if err := s.auditExport(done, export, details); err != nil {
+			log.Printf("error creating log: %v", err) // don't return error since the export's own error is returned
+		}
+		return err
+	}
+
+	details := fmt.Sprintf("User %s exported %d logs as %s (export %s, sha256 %s)", export.CreatedBy, rows, export.Format, export.ID.Hex(), export.SHA256)
+	if err := s.auditExport(done, export, details); err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
+	return nil
+}
+
+// storeManifest completes the manifest with the row count and hash of what was written, then signs and stores it
+func (s *LogExportService) storeManifest(ctx context.Context, export *model.LogExport, rows int, sum string) error {
+	export.Rows = rows
+	export.SHA256 = sum
+	signature, err := signLogExport(s.secret, export)
+	if err != nil {
+		return err
+	}
+	export.Signature = signature
+	return s.exportRepository.Create(ctx, export)
+}
+
+func (s *LogExportService) auditExport(ctx context.Context, export *model.LogExport, details string) error {
+	_, err := s.logService.CreateLog(ctx, &model.Log{
+		ClientID:  export.Filters.ClientID,
+		Actor:     export.CreatedBy,
+		Operation: model.OperationExportLogs,
+		Details:   details,
+		Timestamp: time.Now(),
+	})
+	return err
+}
+
+func (s *LogExportService) GetExport(ctx context.Context, exportID string) (*model.LogExport, error) {
+	return s.exportRepository.GetOne(ctx, exportID)
+}
+
+func (s *LogExportService) writeCSV(ctx context.Context, query *model.GetLogsQuery, w io.Writer) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(logExportColumns); err != nil {
+		return 0, fmt.Errorf("%w: error writing export stream", errorx.ErrInternal)
+	}
+
+	rows := 0
+	err := s.logRepository.ForEach(ctx, query, func(l *model.Log) error {
+		record := []string{l.ID.Hex(), l.Timestamp.UTC().Format(time.RFC3339Nano), l.Actor, string(l.Operation), l.ClientID, l.Details, "", l.Hash, ""}
+		if l.Seq > 0 {
+			record[6] = strconv.FormatInt(l.Seq, 10)
+		}
+		if len(l.Changes) > 0 {
+			changes, err := json.Marshal(l.Changes)
+			if err != nil {
+				return fmt.Errorf("%w: error encoding changes of log %s", errorx.ErrInternal, l.ID.Hex())
+			}
+			record[8] = string(changes)
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("%w: error writing export stream", errorx.ErrInternal)
+		}
+		rows++
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return rows, fmt.Errorf("%w: error writing export stream", errorx.ErrInternal)
+	}
+	return rows, nil
+}
+
+func (s *LogExportService) writeNDJSON(ctx context.Context, query *model.GetLogsQuery, w io.Writer) (int, error) {
+	rows := 0
+	err := s.logRepository.ForEach(ctx, query, func(l *model.Log) error {
+		line, err := json.Marshal(l)
+		if err != nil {
+			return fmt.Errorf("%w: error encoding log %s", errorx.ErrInternal, l.ID.Hex())
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("%w: error writing export stream", errorx.ErrInternal)
+		}
+		rows++
+		return nil
+	})
+	return rows, err
+}
+
+// signLogExport returns the hex HMAC-SHA256 of the manifest's JSON encoding, leaving out the signature
+func signLogExport(secret string, export *model.LogExport) (string, error) {
+	unsigned := *export
+	unsigned.Signature = ""
+	payload, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", fmt.Errorf("%w: error encoding export manifest", errorx.ErrInternal)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// storedTime is t as Mongo will return it, or nil if t is unset
+func storedTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	stored := t.UTC().Truncate(time.Millisecond)
+	return &stored
+}
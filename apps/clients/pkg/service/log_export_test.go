@@ -0,0 +1,244 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const exportSecret = "export-secret"
+
+type LogExportServiceTestSuite struct {
+	suite.Suite
+	mockLogRepo    *mocks.LogRepository
+	mockExportRepo *mocks.LogExportRepository
+	mockLog        *mocks.LogServiceInterface
+	exportService  *service.LogExportService
+	logs           []*model.Log
+}
+
+func (suite *LogExportServiceTestSuite) SetupTest() {
+	suite.mockLogRepo = new(mocks.LogRepository)
+	suite.mockExportRepo = new(mocks.LogExportRepository)
+	suite.mockLog = new(mocks.LogServiceInterface)
+	suite.exportService = service.NewLogExportService(suite.mockLogRepo, suite.mockExportRepo, suite.mockLog, exportSecret)
+
+	at := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	suite.logs = []*model.Log{
+		{ID: bson.NewObjectID(), ClientID: "65f1a2b3c4d5e6f708192a3b", Actor: "alice", Operation: model.OperationUpdate, Details: `Changed "name", then notes`, Timestamp: at, Seq: 7, Hash: "abc",
+			Changes: []model.Change{{Path: "profile.names", Old: "Jane", New: "Jane Doe", Source: model.ChangeSourceManual}}},
+		{ID: bson.NewObjectID(), Actor: "bob", Operation: model.OperationImport, Timestamp: at.Add(time.Hour)},
+	}
+}
+
+func (suite *LogExportServiceTestSuite) mockLogs() {
+	suite.mockLogRepo.On("ForEach", mock.Anything, mock.Anything, mock.Anything).Return(func(ctx context.Context, query *model.GetLogsQuery, fn func(*model.Log) error) error {
+		for _, l := range suite.logs {
+			if err := fn(l); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (suite *LogExportServiceTestSuite) start(query *model.ExportLogsQuery) *model.LogExport {
+	ctx := context.WithValue(context.Background(), "username", "auditor")
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationExportLogs && l.Actor == "auditor" && strings.Contains(l.Details, "started export")
+	})).Return("log-id", nil).Once()
+	export, err := suite.exportService.StartExport(ctx, query)
+	suite.Require().NoError(err)
+	return export
+}
+
+func (suite *LogExportServiceTestSuite) TestStartExport_Defaults() {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 123456789, time.FixedZone("SGT", 8*60*60))
+	export := suite.start(&model.ExportLogsQuery{GetLogsQuery: model.GetLogsQuery{Actor: "alice", From: from}})
+
+	suite.Equal(model.ExportFormatCSV, export.Format)
+	suite.False(export.ID.IsZero())
+	suite.Equal("auditor", export.CreatedBy)
+	suite.Equal("alice", export.Filters.Actor)
+	suite.Equal(time.Date(2025, 2, 28, 16, 0, 0, 123000000, time.UTC), *export.Filters.From)
+	suite.Nil(export.Filters.To)
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *LogExportServiceTestSuite) TestStartExport_AuditError() {
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("", errorx.ErrDependencyFailed)
+
+	_, err := suite.exportService.StartExport(context.Background(), &model.ExportLogsQuery{})
+
+	// an export that can't be audited is not started
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *LogExportServiceTestSuite) TestStartExport_Invalid() {
+	now := time.Now()
+	queries := []*model.ExportLogsQuery{
+		{Format: "xml"},
+		{GetLogsQuery: model.GetLogsQuery{ClientID: "not-an-id"}},
+		{GetLogsQuery: model.GetLogsQuery{From: now, To: now.Add(-time.Hour)}},
+	}
+	for _, query := range queries {
+		_, err := suite.exportService.StartExport(context.Background(), query)
+		suite.ErrorIs(err, errorx.ErrInvalidInput)
+	}
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+}
+
+func (suite *LogExportServiceTestSuite) TestStartExport_NoSecret() {
+	exportService := service.NewLogExportService(suite.mockLogRepo, suite.mockExportRepo, suite.mockLog, "")
+
+	_, err := exportService.StartExport(context.Background(), &model.ExportLogsQuery{})
+
+	suite.ErrorIs(err, errorx.ErrInternal)
+}
+
+func (suite *LogExportServiceTestSuite) TestWriteExport_CSV() {
+	suite.mockLogs()
+	suite.mockExportRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationExportLogs && l.Actor == "auditor" && strings.Contains(l.Details, "exported 2 logs as csv")
+	})).Return("log-id", nil)
+	export := suite.start(&model.ExportLogsQuery{Format: model.ExportFormatCSV})
+
+	var buf bytes.Buffer
+	err := suite.exportService.WriteExport(context.WithValue(context.Background(), "username", "auditor"), export, &buf)
+
+	suite.NoError(err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	suite.Len(lines, 3)
+	suite.Equal("id,timestamp,actor,operation,clientId,details,seq,hash,changes", lines[0])
+	suite.Equal(suite.logs[0].ID.Hex()+`,2025-03-01T09:30:00Z,alice,update,65f1a2b3c4d5e6f708192a3b,"Changed ""name"", then notes",7,abc,`+
+		`"[{""path"":""profile.names"",""old"":""Jane"",""new"":""Jane Doe"",""source"":""manual""}]"`, lines[1])
+	suite.Equal(suite.logs[1].ID.Hex()+`,2025-03-01T10:30:00Z,bob,import,,,,,`, lines[2])
+
+	sum := sha256.Sum256(buf.Bytes())
+	suite.Equal(2, export.Rows)
+	suite.Equal(hex.EncodeToString(sum[:]), export.SHA256)
+	suite.mockExportRepo.AssertCalled(suite.T(), "Create", mock.Anything, export)
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *LogExportServiceTestSuite) TestWriteExport_NDJSON() {
+	suite.mockLogs()
+	suite.mockExportRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	export := suite.start(&model.ExportLogsQuery{Format: model.ExportFormatNDJSON})
+
+	var buf bytes.Buffer
+	err := suite.exportService.WriteExport(context.Background(), export, &buf)
+
+	suite.NoError(err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	suite.Len(lines, 2)
+	var first model.Log
+	suite.NoError(json.Unmarshal([]byte(lines[0]), &first))
+	suite.Equal("alice", first.Actor)
+	suite.Equal(2, export.Rows)
+}
+
+func (suite *LogExportServiceTestSuite) TestWriteExport_SignatureSurvivesStorage() {
+	suite.mockLogs()
+	suite.mockExportRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	export := suite.start(&model.ExportLogsQuery{GetLogsQuery: model.GetLogsQuery{From: time.Now().Add(-time.Hour)}})
+
+	suite.NoError(suite.exportService.WriteExport(context.Background(), export, &bytes.Buffer{}))
+	suite.NotEmpty(export.Signature)
+
+	// the manifest read back from Mongo must verify against its signature
+	raw, err := bson.Marshal(export)
+	suite.Require().NoError(err)
+	var stored model.LogExport
+	suite.Require().NoError(bson.Unmarshal(raw, &stored))
+
+	signature := stored.Signature
+	stored.Signature = ""
+	payload, err := json.Marshal(stored)
+	suite.Require().NoError(err)
+	mac := hmac.New(sha256.New, []byte(exportSecret))
+	mac.Write(payload)
+	suite.Equal(hex.EncodeToString(mac.Sum(nil)), signature)
+}
+
+func (suite *LogExportServiceTestSuite) TestWriteExport_RepoError() {
+	suite.mockLogRepo.On("ForEach", mock.Anything, mock.Anything, mock.Anything).Return(errorx.ErrDependencyFailed)
+	export := suite.start(&model.ExportLogsQuery{})
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationExportLogs && strings.Contains(l.Details, "aborted export "+export.ID.Hex()) &&
+			strings.Contains(l.Details, "after 0 logs")
+	})).Return("log-id", nil).Once()
+
+	err := suite.exportService.WriteExport(context.Background(), export, &bytes.Buffer{})
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.mockExportRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *LogExportServiceTestSuite) TestWriteExport_ClientGone() {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "username", "auditor"))
+	suite.mockLogRepo.On("ForEach", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, _ *model.GetLogsQuery, fn func(*model.Log) error) error {
+		if err := fn(suite.logs[0]); err != nil {
+			return err
+		}
+		// the client disconnects part way through
+		cancel()
+		return context.Canceled
+	})
+	export := suite.start(&model.ExportLogsQuery{Format: model.ExportFormatNDJSON})
+	suite.mockLog.On("CreateLog", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}), mock.MatchedBy(func(l *model.Log) bool {
+		return strings.Contains(l.Details, "aborted export") && strings.Contains(l.Details, "after 1 logs")
+	})).Return("log-id", nil).Once()
+
+	err := suite.exportService.WriteExport(ctx, export, &bytes.Buffer{})
+
+	suite.ErrorIs(err, context.Canceled)
+	// the abort is audited even though the request's context is done
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *LogExportServiceTestSuite) TestWriteExport_StoresManifestAfterClientGone() {
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.mockLogRepo.On("ForEach", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, _ *model.GetLogsQuery, fn func(*model.Log) error) error {
+		// the last row is written just as the client disconnects
+		err := fn(suite.logs[0])
+		cancel()
+		return err
+	})
+	live := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil })
+	suite.mockExportRepo.On("Create", live, mock.Anything).Return(nil).Once()
+	export := suite.start(&model.ExportLogsQuery{})
+	suite.mockLog.On("CreateLog", live, mock.MatchedBy(func(l *model.Log) bool {
+		return strings.Contains(l.Details, "exported 1 logs")
+	})).Return("log-id", nil).Once()
+
+	err := suite.exportService.WriteExport(ctx, export, &bytes.Buffer{})
+
+	suite.NoError(err)
+	suite.mockExportRepo.AssertExpectations(suite.T())
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func TestLogExportServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LogExportServiceTestSuite))
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	// retentionActor is the actor of the logs written when expired logs are archived
+	retentionActor = "retention"
+	// retentionBatchSize is the most logs written to one archive
+	retentionBatchSize = 1000
+	// retentionLease is held by the replica currently allowed to purge expired logs
+	retentionLease = "log-retention"
+)
+
+type LogRetentionService struct {
+	logRepository    repository.LogRepository
+	clientRepository repository.ClientRepository
+	archiveStore     repository.ArchiveStore
+	anchorStore      repository.LogAnchorStore
+	logService       LogServiceInterface
+	retention        map[model.Operation]time.Duration
+	defaultRetention time.Duration
+}
+
+type LogRetentionServiceInterface interface {
+	Purge(ctx context.Context, now time.Time) (*model.LogPurgeResult, error)
+}
+
+// NewLogRetentionService keeps the logs of each operation in retention for that long, and the logs of any other
+// operation for defaultRetention. A retention of 0 keeps logs forever. Checkpoints of the log chain are recorded in
+// anchorStore as archived logs are deleted from its start.
+func NewLogRetentionService(logRepository repository.LogRepository, clientRepository repository.ClientRepository, archiveStore repository.ArchiveStore, anchorStore repository.LogAnchorStore, logService LogServiceInterface, retention map[model.Operation]time.Duration, defaultRetention time.Duration) *LogRetentionService {
+	return &LogRetentionService{
+		logRepository:    logRepository,
+		clientRepository: clientRepository,
+		archiveStore:     archiveStore,
+		anchorStore:      anchorStore,
+		logService:       logService,
+		retention:        retention,
+		defaultRetention: defaultRetention,
+	}
+}
+
+// Purge archives the logs that have outlived their retention as of now, in batches, and purges each batch once its
+// archive is stored. Logs of clients under a legal hold are kept. Archived logs are then deleted from the start of the
+// log chain. It stops at the first error, returning what was purged so far.
+func (s *LogRetentionService) Purge(ctx context.Context, now time.Time) (*model.LogPurgeResult, error) {
+	held, err := s.clientRepository.GetHeldClientIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.LogPurgeResult{Archives: []model.LogArchive{}}
+	for _, filter := range s.expiredFilters(now, held) {
+		for {
+			logs, err := s.logRepository.FindExpired(ctx, filter, retentionBatchSize)
+			if err != nil {
+				return result, err
+			}
+			if len(logs) == 0 {
+				break
+			}
+
+			archive, err := s.archive(ctx, now, logs)
+			if err != nil {
+				return result, err
+			}
+			result.Archived += archive.Logs
+			result.Archives = append(result.Archives, *archive)
+
+			if len(logs) < retentionBatchSize {
+				break
+			}
+		}
+	}
+
+	checkpoint, err := s.truncateChain(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Checkpoint = checkpoint
+	return result, nil
+}
+
+// truncateChain deletes the archived logs at the start of the log chain. Their stubs are only needed to verify the
+// chain, so a checkpoint of the last one is recorded first and verification picks up from it. Logs archived further
+// along the chain stay stubs until every log before them is archived too. It returns the new checkpoint, or nil if
+// there was nothing more to delete.
+func (s *LogRetentionService) truncateChain(ctx context.Context) (*model.LogCheckpoint, error) {
+	latest, err := s.anchorStore.LatestCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var after int64
+	if latest != nil {
+		after = latest.Seq
+	}
+
+	end, err := s.logRepository.ArchivedRunEnd(ctx, after)
+	if err != nil {
+		return nil, err
+	}
+	if end == nil {
+		if latest != nil {
+			// finishes a deletion that failed after its checkpoint was recorded
+			return nil, s.logRepository.DeleteArchived(ctx, latest.Seq)
+		}
+		return nil, nil
+	}
+
+	checkpoint := &model.LogCheckpoint{Seq: end.Seq, Hash: end.Hash, PurgedAt: time.Now().UTC()}
+	if err := s.anchorStore.AppendCheckpoint(ctx, checkpoint); err != nil {
+		return nil, err
+	}
+	if err := s.logRepository.DeleteArchived(ctx, checkpoint.Seq); err != nil {
+		return nil, err
+	}
+
+	_, err = s.logService.CreateLog(ctx, &model.Log{
+		Actor:     retentionActor,
+		Operation: model.OperationArchiveLogs,
+		Details:   fmt.Sprintf("Deleted archived logs up to seq %d from the log chain, which now starts after hash %s", checkpoint.Seq, checkpoint.Hash),
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
+	return checkpoint, nil
+}
+
+// expiredFilters returns a filter per operation with a retention, then one for every other operation if they have a
+// default retention
+func (s *LogRetentionService) expiredFilters(now time.Time, held []string) []*model.ExpiredLogsFilter {
+	operations := make([]model.Operation, 0, len(s.retention))
+	for op := range s.retention {
+		operations = append(operations, op)
+	}
+	sort.Slice(operations, func(i, j int) bool { return operations[i] < operations[j] })
+
+	var filters []*model.ExpiredLogsFilter
+	for _, op := range operations {
+		if s.retention[op] <= 0 {
+			continue
+		}
+		filters = append(filters, &model.ExpiredLogsFilter{
+			Operations:    []model.Operation{op},
+			Before:        now.Add(-s.retention[op]),
+			HeldClientIDs: held,
+		})
+	}
+	if s.defaultRetention > 0 {
+		filters = append(filters, &model.ExpiredLogsFilter{
+			Operations:      operations,
+			OtherOperations: true,
+			Before:          now.Add(-s.defaultRetention),
+			HeldClientIDs:   held,
+		})
+	}
+	return filters
+}
+
+// archive stores the logs as a gzipped NDJSON file, one log per line, then purges them
+func (s *LogRetentionService) archive(ctx context.Context, now time.Time, logs []model.Log) (*model.LogArchive, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	ids := make([]bson.ObjectID, 0, len(logs))
+	for i := range logs {
+		line, err := json.Marshal(&logs[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: error encoding log %s", errorx.ErrInternal, logs[i].ID.Hex())
+		}
+		if _, err := gz.Write(append(line, '\n')); err != nil {
+			return nil, fmt.Errorf("%w: error compressing archive", errorx.ErrInternal)
+		}
+		ids = append(ids, logs[i].ID)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("%w: error compressing archive", errorx.ErrInternal)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	archive := &model.LogArchive{
+		Name:   fmt.Sprintf("logs-%s-%s.ndjson.gz", now.UTC().Format("20060102T150405Z"), bson.NewObjectID().Hex()),
+		Logs:   len(logs),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+	if err := s.archiveStore.Put(ctx, archive.Name, &buf); err != nil {
+		return nil, err
+	}
+	// the archive is kept if purging fails, the logs are archived again on the next run
+	if err := s.logRepository.Archive(ctx, ids, archive.Name); err != nil {
+		return nil, err
+	}
+
+	_, err := s.logService.CreateLog(ctx, &model.Log{
+		Actor:     retentionActor,
+		Operation: model.OperationArchiveLogs,
+		Details:   fmt.Sprintf("Archived %d expired logs to %s (sha256 %s) and purged them", archive.Logs, archive.Name, archive.SHA256),
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
+	return archive, nil
+}
+
+// LogPurger periodically archives and purges expired logs. Only the replica holding the retention lease does any
+// work on a tick, so logs are never archived twice.
+type LogPurger struct {
+	retentionService LogRetentionServiceInterface
+	leaseRepository  repository.LeaseRepository
+	holder           string
+	interval         time.Duration
+}
+
+func NewLogPurger(retentionService LogRetentionServiceInterface, leaseRepository repository.LeaseRepository, interval time.Duration) *LogPurger {
+	return &LogPurger{retentionService: retentionService, leaseRepository: leaseRepository, holder: reaperHolder(), interval: interval}
+}
+
+// Run purges expired logs every interval until ctx is done, then releases the lease
+func (p *LogPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			release, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := p.leaseRepository.Release(release, retentionLease, p.holder); err != nil {
+				log.Printf("error releasing %s lease: %v", retentionLease, err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			p.Tick(ctx)
+		}
+	}
+}
+
+// Tick purges expired logs once if this replica holds, or can take, the lease
+func (p *LogPurger) Tick(ctx context.Context) {
+	acquired, err := p.leaseRepository.Acquire(ctx, retentionLease, p.holder, 2*p.interval)
+	if err != nil {
+		log.Printf("error acquiring %s lease: %v", retentionLease, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	result, err := p.retentionService.Purge(ctx, time.Now())
+	if err != nil {
+		log.Printf("error purging expired logs: %v", err)
+	}
+	if result != nil && result.Archived > 0 {
+		log.Printf("archived and purged %d expired logs in %d archives", result.Archived, len(result.Archives))
+	}
+}
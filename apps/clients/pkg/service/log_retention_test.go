@@ -0,0 +1,275 @@
+package service_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type LogRetentionServiceTestSuite struct {
+	suite.Suite
+	mockLogRepo      *mocks.LogRepository
+	mockClientRepo   *mocks.ClientRepository
+	mockStore        *mocks.ArchiveStore
+	mockLog          *mocks.LogServiceInterface
+	mockAnchorStore  *mocks.LogAnchorStore
+	retentionService *service.LogRetentionService
+	now              time.Time
+	archives         map[string][]byte
+}
+
+func (suite *LogRetentionServiceTestSuite) SetupTest() {
+	suite.mockLogRepo = new(mocks.LogRepository)
+	suite.mockClientRepo = new(mocks.ClientRepository)
+	suite.mockStore = new(mocks.ArchiveStore)
+	suite.mockLog = new(mocks.LogServiceInterface)
+	suite.mockAnchorStore = new(mocks.LogAnchorStore)
+	suite.retentionService = service.NewLogRetentionService(suite.mockLogRepo, suite.mockClientRepo, suite.mockStore, suite.mockAnchorStore, suite.mockLog, map[model.Operation]time.Duration{
+		model.OperationGet:    90 * 24 * time.Hour,
+		model.OperationUpdate: 7 * 365 * 24 * time.Hour,
+		model.OperationImport: 0,
+	}, 365*24*time.Hour)
+	suite.now = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	suite.archives = map[string][]byte{}
+}
+
+func (suite *LogRetentionServiceTestSuite) mockStorePut() {
+	suite.mockStore.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(func(ctx context.Context, name string, r io.Reader) error {
+		data, err := io.ReadAll(r)
+		suite.Require().NoError(err)
+		suite.archives[name] = data
+		return nil
+	})
+}
+
+// mockChainStart leaves the start of the chain as it is
+func (suite *LogRetentionServiceTestSuite) mockChainStart() {
+	suite.mockAnchorStore.On("LatestCheckpoint", mock.Anything).Return(nil, nil)
+	suite.mockLogRepo.On("ArchivedRunEnd", mock.Anything, int64(0)).Return(nil, nil)
+}
+
+// archivedLogs decompresses an archive and decodes its logs
+func (suite *LogRetentionServiceTestSuite) archivedLogs(data []byte) []model.Log {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	suite.Require().NoError(err)
+
+	var logs []model.Log
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var l model.Log
+		suite.Require().NoError(json.Unmarshal(scanner.Bytes(), &l))
+		logs = append(logs, l)
+	}
+	suite.Require().NoError(scanner.Err())
+	return logs
+}
+
+func (suite *LogRetentionServiceTestSuite) TestPurge() {
+	expired := chain(2)
+	expired[0].Operation, expired[0].ClientID = model.OperationGet, "65f1a2b3c4d5e6f708192a3b"
+	held := []string{"65f1a2b3c4d5e6f708192a3c"}
+
+	suite.mockClientRepo.On("GetHeldClientIDs", mock.Anything).Return(held, nil)
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.MatchedBy(func(f *model.ExpiredLogsFilter) bool {
+		return !f.OtherOperations && len(f.Operations) == 1 && f.Operations[0] == model.OperationGet
+	}), 1000).Return([]model.Log{*expired[0], *expired[1]}, nil)
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.Anything, 1000).Return([]model.Log{}, nil)
+	suite.mockStorePut()
+	suite.mockLogRepo.On("Archive", mock.Anything, []bson.ObjectID{expired[0].ID, expired[1].ID}, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationArchiveLogs && l.Actor == "retention" && strings.Contains(l.Details, "Archived 2 expired logs")
+	})).Return("log-id", nil)
+	suite.mockChainStart()
+
+	result, err := suite.retentionService.Purge(context.Background(), suite.now)
+
+	suite.NoError(err)
+	suite.Equal(2, result.Archived)
+	suite.Nil(result.Checkpoint)
+	suite.Require().Len(result.Archives, 1)
+	archive := result.Archives[0]
+	suite.True(strings.HasPrefix(archive.Name, "logs-20260301T000000Z-"))
+	suite.True(strings.HasSuffix(archive.Name, ".ndjson.gz"))
+
+	data := suite.archives[archive.Name]
+	sum := sha256.Sum256(data)
+	suite.Equal(hex.EncodeToString(sum[:]), archive.SHA256)
+	logs := suite.archivedLogs(data)
+	suite.Require().Len(logs, 2)
+	suite.Equal(expired[0].ClientID, logs[0].ClientID)
+	// the archived copy still hashes to the hash kept in the chain
+	suite.Equal(expired[1].Hash, logs[1].ChainHash())
+
+	suite.mockLogRepo.AssertCalled(suite.T(), "Archive", mock.Anything, mock.Anything, archive.Name)
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *LogRetentionServiceTestSuite) TestPurge_Filters() {
+	held := []string{"65f1a2b3c4d5e6f708192a3c"}
+	suite.mockClientRepo.On("GetHeldClientIDs", mock.Anything).Return(held, nil)
+	var filters []*model.ExpiredLogsFilter
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.Anything, 1000).Return(func(ctx context.Context, f *model.ExpiredLogsFilter, limit int) ([]model.Log, error) {
+		filters = append(filters, f)
+		return nil, nil
+	})
+	suite.mockChainStart()
+
+	result, err := suite.retentionService.Purge(context.Background(), suite.now)
+
+	suite.NoError(err)
+	suite.Equal(0, result.Archived)
+	suite.Empty(result.Archives)
+	// import has no retention, so it is neither purged on its own nor by the default
+	suite.Require().Len(filters, 3)
+	suite.Equal([]model.Operation{model.OperationUpdate}, filters[0].Operations)
+	suite.Equal(suite.now.AddDate(0, 0, -7*365), filters[0].Before)
+	suite.Equal([]model.Operation{model.OperationGet}, filters[1].Operations)
+	suite.Equal(suite.now.AddDate(0, 0, -90), filters[1].Before)
+	suite.True(filters[2].OtherOperations)
+	suite.ElementsMatch([]model.Operation{model.OperationGet, model.OperationUpdate, model.OperationImport}, filters[2].Operations)
+	suite.Equal(suite.now.AddDate(0, 0, -365), filters[2].Before)
+	for _, f := range filters {
+		suite.Equal(held, f.HeldClientIDs)
+	}
+	suite.mockStore.AssertNotCalled(suite.T(), "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *LogRetentionServiceTestSuite) TestPurge_Batches() {
+	batch := make([]model.Log, 1000)
+	for i := range batch {
+		batch[i] = model.Log{ID: bson.NewObjectID(), Operation: model.OperationGet}
+	}
+	suite.mockClientRepo.On("GetHeldClientIDs", mock.Anything).Return([]string{}, nil)
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.MatchedBy(func(f *model.ExpiredLogsFilter) bool {
+		return !f.OtherOperations && f.Operations[0] == model.OperationGet
+	}), 1000).Return(batch, nil).Once()
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.MatchedBy(func(f *model.ExpiredLogsFilter) bool {
+		return !f.OtherOperations && f.Operations[0] == model.OperationGet
+	}), 1000).Return(batch[:1], nil).Once()
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.Anything, 1000).Return(nil, nil)
+	suite.mockStorePut()
+	suite.mockLogRepo.On("Archive", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+	suite.mockChainStart()
+
+	result, err := suite.retentionService.Purge(context.Background(), suite.now)
+
+	suite.NoError(err)
+	suite.Equal(1001, result.Archived)
+	suite.Len(result.Archives, 2)
+	suite.Len(suite.archives, 2)
+}
+
+func (suite *LogRetentionServiceTestSuite) TestPurge_TruncatesChain() {
+	logs := chain(5)
+	suite.mockClientRepo.On("GetHeldClientIDs", mock.Anything).Return([]string{}, nil)
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.Anything, 1000).Return(nil, nil)
+	suite.mockAnchorStore.On("LatestCheckpoint", mock.Anything).Return(&model.LogCheckpoint{Seq: 1, Hash: logs[0].Hash}, nil)
+	suite.mockLogRepo.On("ArchivedRunEnd", mock.Anything, int64(1)).Return(logs[2], nil)
+	var calls []string
+	suite.mockAnchorStore.On("AppendCheckpoint", mock.Anything, mock.MatchedBy(func(c *model.LogCheckpoint) bool {
+		return c.Seq == 3 && c.Hash == logs[2].Hash
+	})).Run(func(args mock.Arguments) { calls = append(calls, "checkpoint") }).Return(nil)
+	suite.mockLogRepo.On("DeleteArchived", mock.Anything, int64(3)).Run(func(args mock.Arguments) { calls = append(calls, "delete") }).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationArchiveLogs && strings.Contains(l.Details, "up to seq 3")
+	})).Return("log-id", nil)
+
+	result, err := suite.retentionService.Purge(context.Background(), suite.now)
+
+	suite.NoError(err)
+	suite.Require().NotNil(result.Checkpoint)
+	suite.Equal(int64(3), result.Checkpoint.Seq)
+	// the checkpoint must outlive the logs it replaces
+	suite.Equal([]string{"checkpoint", "delete"}, calls)
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *LogRetentionServiceTestSuite) TestPurge_FinishesTruncation() {
+	suite.mockClientRepo.On("GetHeldClientIDs", mock.Anything).Return([]string{}, nil)
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.Anything, 1000).Return(nil, nil)
+	suite.mockAnchorStore.On("LatestCheckpoint", mock.Anything).Return(&model.LogCheckpoint{Seq: 3, Hash: "hash"}, nil)
+	suite.mockLogRepo.On("ArchivedRunEnd", mock.Anything, int64(3)).Return(nil, nil)
+	suite.mockLogRepo.On("DeleteArchived", mock.Anything, int64(3)).Return(nil)
+
+	result, err := suite.retentionService.Purge(context.Background(), suite.now)
+
+	suite.NoError(err)
+	suite.Nil(result.Checkpoint)
+	suite.mockLogRepo.AssertExpectations(suite.T())
+	suite.mockAnchorStore.AssertNotCalled(suite.T(), "AppendCheckpoint", mock.Anything, mock.Anything)
+}
+
+func (suite *LogRetentionServiceTestSuite) TestPurge_CheckpointError() {
+	logs := chain(2)
+	suite.mockClientRepo.On("GetHeldClientIDs", mock.Anything).Return([]string{}, nil)
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.Anything, 1000).Return(nil, nil)
+	suite.mockAnchorStore.On("LatestCheckpoint", mock.Anything).Return(nil, nil)
+	suite.mockLogRepo.On("ArchivedRunEnd", mock.Anything, int64(0)).Return(logs[0], nil)
+	suite.mockAnchorStore.On("AppendCheckpoint", mock.Anything, mock.Anything).Return(errorx.ErrDependencyFailed)
+
+	_, err := suite.retentionService.Purge(context.Background(), suite.now)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	// the logs stay until a checkpoint replaces them
+	suite.mockLogRepo.AssertNotCalled(suite.T(), "DeleteArchived", mock.Anything, mock.Anything)
+}
+
+func (suite *LogRetentionServiceTestSuite) TestPurge_StoreError() {
+	suite.mockClientRepo.On("GetHeldClientIDs", mock.Anything).Return([]string{}, nil)
+	suite.mockLogRepo.On("FindExpired", mock.Anything, mock.Anything, 1000).Return([]model.Log{{ID: bson.NewObjectID()}}, nil)
+	suite.mockStore.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(errorx.ErrDependencyFailed)
+
+	result, err := suite.retentionService.Purge(context.Background(), suite.now)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.Equal(0, result.Archived)
+	// nothing is purged unless its archive was stored
+	suite.mockLogRepo.AssertNotCalled(suite.T(), "Archive", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+}
+
+func (suite *LogRetentionServiceTestSuite) TestPurge_HoldsError() {
+	suite.mockClientRepo.On("GetHeldClientIDs", mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	_, err := suite.retentionService.Purge(context.Background(), suite.now)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.mockLogRepo.AssertNotCalled(suite.T(), "FindExpired", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogRetentionServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LogRetentionServiceTestSuite))
+}
+
+func TestLogPurger_Tick(t *testing.T) {
+	mockRetention := new(mocks.LogRetentionServiceInterface)
+	mockLeaseRepo := new(mocks.LeaseRepository)
+	purger := service.NewLogPurger(mockRetention, mockLeaseRepo, time.Hour)
+
+	mockLeaseRepo.On("Acquire", mock.Anything, "log-retention", mock.AnythingOfType("string"), 2*time.Hour).Return(false, nil).Once()
+	purger.Tick(context.Background())
+	mockRetention.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+
+	mockLeaseRepo.On("Acquire", mock.Anything, "log-retention", mock.AnythingOfType("string"), 2*time.Hour).Return(true, nil)
+	mockRetention.On("Purge", mock.Anything, mock.Anything).Return(&model.LogPurgeResult{Archived: 3}, nil)
+	purger.Tick(context.Background())
+	assert.True(t, mockRetention.AssertNumberOfCalls(t, "Purge", 1))
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/owjoel/client-factpack/apps/clients/config"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MatchService lets reviewers act on the candidates found by match jobs
+type MatchService struct {
+	jobRepository    repository.JobRepository
+	clientRepository repository.ClientRepository
+	jobService       JobServiceInterface
+	logService       LogServiceInterface
+	timelineService  TimelineServiceInterface
+	transactor       repository.Transactor
+}
+
+type MatchServiceInterface interface {
+	GetPendingMatches(ctx context.Context, query *model.GetPendingMatchesQuery) (*model.GetPendingMatchesResponse, error)
+	ReviewMatch(ctx context.Context, jobID string, candidateID string, req *model.ReviewMatchReq) (*model.MatchResult, error)
+	GetMatchStats(ctx context.Context, query *model.GetMatchStatsQuery) (*model.GetMatchStatsResponse, error)
+}
+
+func NewMatchService(jobRepository repository.JobRepository, clientRepository repository.ClientRepository, jobService JobServiceInterface, logService LogServiceInterface, timelineService TimelineServiceInterface, transactor repository.Transactor) *MatchService {
+	return &MatchService{
+		jobRepository:    jobRepository,
+		clientRepository: clientRepository,
+		jobService:       jobService,
+		logService:       logService,
+		timelineService:  timelineService,
+		transactor:       transactor,
+	}
+}
+
+// GetPendingMatches pages through match jobs awaiting review, each candidate alongside its current profile
+func (s *MatchService) GetPendingMatches(ctx context.Context, query *model.GetPendingMatchesQuery) (*model.GetPendingMatchesResponse, error) {
+	jobs, err := s.jobRepository.GetPendingMatches(ctx, query)
+	if err != nil {
+		return nil, wrapMatchErr(err, "error getting pending matches")
+	}
+	total, err := s.jobRepository.CountPendingMatches(ctx)
+	if err != nil {
+		return nil, wrapMatchErr(err, "error counting pending matches")
+	}
+
+	res := &model.GetPendingMatchesResponse{Total: total, Matches: []model.PendingMatch{}}
+	for _, job := range jobs {
+		match := model.PendingMatch{
+			JobID:            job.ID.Hex(),
+			ClientID:         job.ClientID,
+			FileName:         inputString(job.Input, "file_name"),
+			CreatedBy:        job.CreatedBy,
+			CreatedAt:        job.CreatedAt,
+			ExtractedProfile: job.ExtractedProfile,
+			Candidates:       []model.MatchCandidate{},
+		}
+		for _, result := range job.MatchResults {
+			candidate := model.MatchCandidate{MatchResult: result}
+			client, err := s.clientRepository.GetOne(ctx, result.ID.Hex())
+			switch {
+			case err == nil:
+				candidate.Data = client.Data
+			case errors.Is(err, errorx.ErrNotFound):
+				// the candidate was deleted since the job ran, it can still be rejected
+			default:
+				return nil, wrapMatchErr(err, "error getting candidate")
+			}
+			match.Candidates = append(match.Candidates, candidate)
+		}
+		res.Matches = append(res.Matches, match)
+	}
+
+	return res, nil
+}
+
+// ReviewMatch accepts or rejects one candidate of a completed match job. Accepting links the uploaded document to
+// the candidate and copies the requested extracted fields onto its profile, then queues a sync job to refresh the
+// profile in the vector index so later matches search what the reviewer accepted. Decisions are final.
+func (s *MatchService) ReviewMatch(ctx context.Context, jobID string, candidateID string, req *model.ReviewMatchReq) (*model.MatchResult, error) {
+	if req.Decision != model.MatchAccepted && req.Decision != model.MatchRejected {
+		return nil, fmt.Errorf("%w: decision must be '%s' or '%s'", errorx.ErrValidationFailed, model.MatchAccepted, model.MatchRejected)
+	}
+	if req.Decision == model.MatchRejected && len(req.Fields) > 0 {
+		return nil, fmt.Errorf("%w: fields can only be applied when accepting", errorx.ErrValidationFailed)
+	}
+	candidateObjID, err := bson.ObjectIDFromHex(candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid candidate ID", errorx.ErrInvalidInput)
+	}
+
+	job, err := s.jobRepository.GetOne(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrInvalidInput) || errors.Is(err, errorx.ErrDependencyFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting job", errorx.ErrInternal)
+	}
+	if job.Type != model.Match {
+		return nil, fmt.Errorf("%w: job %s is not a match job", errorx.ErrValidationFailed, jobID)
+	}
+	if job.Status != model.JobStatusCompleted {
+		return nil, fmt.Errorf("%w: match job is %s, only completed jobs can be reviewed", errorx.ErrConflict, job.Status)
+	}
+
+	var result *model.MatchResult
+	for i := range job.MatchResults {
+		if job.MatchResults[i].ID == candidateObjID {
+			result = &job.MatchResults[i]
+			break
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("%w: %s is not a candidate of job %s", errorx.ErrNotFound, candidateID, jobID)
+	}
+	if result.Review != nil {
+		return nil, fmt.Errorf("%w: candidate has already been %s", errorx.ErrConflict, result.Review.Decision)
+	}
+
+	fields, update, err := extractedFields(job.ExtractedProfile, req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.mergeChanges(ctx, candidateID, fields, update)
+	if err != nil {
+		return nil, err
+	}
+
+	username := GetUsername(ctx)
+	now := time.Now().UTC()
+	review := &model.MatchReview{
+		Decision:   req.Decision,
+		Fields:     fields,
+		Reviewer:   username,
+		ReviewedAt: now,
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.jobRepository.SetMatchReview(ctx, jobID, candidateObjID, review); err != nil {
+			return err
+		}
+		if review.Decision == model.MatchRejected {
+			return nil
+		}
+		doc := &model.ClientDocument{
+			JobID:    jobID,
+			FileName: inputString(job.Input, "file_name"),
+			LinkedBy: username,
+			LinkedAt: now,
+		}
+		if err := s.clientRepository.AddDocument(ctx, candidateID, doc); err != nil {
+			return err
+		}
+		if len(update) == 0 {
+			return nil
+		}
+		if err := s.clientRepository.Update(ctx, candidateID, update); err != nil {
+			return err
+		}
+		_, err := s.jobService.SubmitJob(ctx, &model.Job{
+			Type:       model.Sync,
+			ClientID:   candidateID,
+			CreatedBy:  username,
+			Deployment: config.PrefectSyncFlowID,
+			Input:      bson.M{"target_id": candidateID},
+			Attempt:    1,
+			Status:     model.JobStatusPending,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+			Logs:       []model.JobLog{{Message: fmt.Sprintf("Job [SYNC] created after match job %s was accepted", jobID), Timestamp: now}},
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrConflict) || errors.Is(err, errorx.ErrInvalidInput) || errors.Is(err, errorx.ErrDependencyFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error reviewing match", errorx.ErrInternal)
+	}
+	result.Review = review
+
+	if len(update) > 0 {
+		if client, err := s.clientRepository.GetOne(ctx, candidateID); err != nil {
+			log.Printf("error reloading client %s for timeline: %v", candidateID, err)
+		} else if err := s.timelineService.Capture(ctx, candidateID, client.Data, model.ChangeSourceMatch, username, now); err != nil {
+			log.Printf("error capturing timeline: %v", err) // don't return error since it's not critical
+		}
+	}
+
+	details := fmt.Sprintf("User %s %s client %s as a match for job %s (confidence %.2f)", username, review.Decision, candidateID, jobID, result.ConfidenceScore)
+	if len(fields) > 0 {
+		details += fmt.Sprintf(", applying %s", strings.Join(fields, ", "))
+	}
+	_, err = s.logService.CreateLog(ctx, &model.Log{
+		ClientID:  candidateID,
+		Actor:     username,
+		Operation: model.OperationMatch,
+		Details:   details,
+		Timestamp: now,
+		Changes:   changes,
+	})
+	if err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
+
+	return result, nil
+}
+
+// GetMatchStats reports how often reviewers confirm the matcher's candidates, overall and per reviewer
+func (s *MatchService) GetMatchStats(ctx context.Context, query *model.GetMatchStatsQuery) (*model.GetMatchStatsResponse, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, fmt.Errorf("%w: 'to' must not be before 'from'", errorx.ErrInvalidInput)
+	}
+
+	reviewers, err := s.jobRepository.GetMatchStats(ctx, query)
+	if err != nil {
+		return nil, wrapMatchErr(err, "error getting match stats")
+	}
+
+	res := &model.GetMatchStatsResponse{Reviewers: reviewers}
+	var acceptedTotal, rejectedTotal float64
+	for i := range res.Reviewers {
+		r := &res.Reviewers[i]
+		acceptedTotal += r.AcceptedScore * float64(r.Accepted)
+		rejectedTotal += r.RejectedScore * float64(r.Reviewed-r.Accepted)
+		r.Accuracy = acceptanceRate(r.Accepted, r.Reviewed)
+		r.AcceptedScore, r.RejectedScore = round2(r.AcceptedScore), round2(r.RejectedScore)
+		res.Reviewed += r.Reviewed
+		res.Accepted += r.Accepted
+	}
+	res.Accuracy = acceptanceRate(res.Accepted, res.Reviewed)
+	if res.Accepted > 0 {
+		res.AcceptedScore = round2(acceptedTotal / float64(res.Accepted))
+	}
+	if rejected := res.Reviewed - res.Accepted; rejected > 0 {
+		res.RejectedScore = round2(rejectedTotal / float64(rejected))
+	}
+	return res, nil
+}
+
+// mergeChanges pairs the fields an accepted match applies with the candidate's current values, for the audit log
+func (s *MatchService) mergeChanges(ctx context.Context, candidateID string, fields []string, update bson.D) ([]model.Change, error) {
+	if len(update) == 0 {
+		return nil, nil
+	}
+	client, err := s.clientRepository.GetOne(ctx, candidateID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) {
+			return nil, err
+		}
+		return nil, wrapMatchErr(err, "error getting candidate")
+	}
+
+	changes := make([]model.Change, len(fields))
+	for i, path := range fields {
+		old, _ := lookupPath(client.Data, path)
+		changes[i] = model.Change{Path: path, Old: old, New: update[i].Value, Source: model.ChangeSourceMatch}
+	}
+	return changes, nil
+}
+
+// extractedFields resolves the requested paths in the extracted profile into an update of the client's data
+func extractedFields(profile bson.M, paths []string) ([]string, bson.D, error) {
+	var fields []string
+	update := bson.D{}
+	seen := map[string]bool{}
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" || seen[path] {
+			continue
+		}
+		value, ok := lookupPath(profile, path)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: the extracted profile has no field %q", errorx.ErrValidationFailed, path)
+		}
+		seen[path] = true
+		fields = append(fields, path)
+		update = append(update, bson.E{Key: "data." + path, Value: value})
+	}
+	return fields, update, nil
+}
+
+func acceptanceRate(accepted, reviewed int) float64 {
+	if reviewed == 0 {
+		return 0
+	}
+	return round2(float64(accepted) / float64(reviewed))
+}
+
+func inputString(input bson.M, key string) string {
+	value, _ := input[key].(string)
+	return value
+}
+
+func wrapMatchErr(err error, msg string) error {
+	if errors.Is(err, errorx.ErrDependencyFailed) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", errorx.ErrInternal, msg)
+}
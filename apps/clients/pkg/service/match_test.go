@@ -0,0 +1,257 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MatchServiceTestSuite struct {
+	suite.Suite
+	mockJobRepo    *mocks.JobRepository
+	mockClientRepo *mocks.ClientRepository
+	mockJobService *mocks.JobServiceInterface
+	mockLog        *mocks.LogServiceInterface
+	mockTimeline   *mocks.TimelineServiceInterface
+	mockTx         *mocks.Transactor
+	matchService   *service.MatchService
+	jobID          string
+	candidateID    bson.ObjectID
+}
+
+func (suite *MatchServiceTestSuite) SetupTest() {
+	suite.mockJobRepo = new(mocks.JobRepository)
+	suite.mockClientRepo = new(mocks.ClientRepository)
+	suite.mockJobService = new(mocks.JobServiceInterface)
+	suite.mockLog = new(mocks.LogServiceInterface)
+	suite.mockTimeline = new(mocks.TimelineServiceInterface)
+	suite.mockTx = new(mocks.Transactor)
+	suite.mockTx.On("WithTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	suite.matchService = service.NewMatchService(suite.mockJobRepo, suite.mockClientRepo, suite.mockJobService, suite.mockLog, suite.mockTimeline, suite.mockTx)
+	suite.jobID = bson.NewObjectID().Hex()
+	suite.candidateID = bson.NewObjectID()
+}
+
+func (suite *MatchServiceTestSuite) matchJob() *model.Job {
+	jobID, _ := bson.ObjectIDFromHex(suite.jobID)
+	return &model.Job{
+		ID:       jobID,
+		Type:     model.Match,
+		ClientID: "target-id",
+		Status:   model.JobStatusCompleted,
+		Input:    bson.M{"file_name": "statement.pdf"},
+		ExtractedProfile: bson.M{
+			"profile": bson.D{{Key: "age", Value: 42}, {Key: "currentResidence", Value: "Singapore"}},
+		},
+		MatchResults: []model.MatchResult{{ID: suite.candidateID, ConfidenceScore: 0.87}},
+	}
+}
+
+func (suite *MatchServiceTestSuite) TestGetPendingMatches() {
+	query := &model.GetPendingMatchesQuery{Page: 1, PageSize: 10}
+	missing := bson.NewObjectID()
+	job := suite.matchJob()
+	job.MatchResults = append(job.MatchResults, model.MatchResult{ID: missing, ConfidenceScore: 0.4})
+	data := bson.D{{Key: "profile", Value: bson.D{{Key: "age", Value: 41}}}}
+
+	suite.mockJobRepo.On("GetPendingMatches", mock.Anything, query).Return([]model.Job{*job}, nil)
+	suite.mockJobRepo.On("CountPendingMatches", mock.Anything).Return(1, nil)
+	suite.mockClientRepo.On("GetOne", mock.Anything, suite.candidateID.Hex()).Return(&model.Client{Data: data}, nil)
+	suite.mockClientRepo.On("GetOne", mock.Anything, missing.Hex()).Return(nil, errorx.ErrNotFound)
+
+	res, err := suite.matchService.GetPendingMatches(context.Background(), query)
+
+	suite.NoError(err)
+	suite.Equal(1, res.Total)
+	suite.Require().Len(res.Matches, 1)
+	suite.Equal("statement.pdf", res.Matches[0].FileName)
+	suite.Equal(job.ExtractedProfile, res.Matches[0].ExtractedProfile)
+	suite.Require().Len(res.Matches[0].Candidates, 2)
+	suite.Equal(data, res.Matches[0].Candidates[0].Data)
+	suite.Nil(res.Matches[0].Candidates[1].Data)
+}
+
+func (suite *MatchServiceTestSuite) TestGetPendingMatches_DependencyFailed() {
+	suite.mockJobRepo.On("GetPendingMatches", mock.Anything, mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	_, err := suite.matchService.GetPendingMatches(context.Background(), &model.GetPendingMatchesQuery{})
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_Accept() {
+	candidate := suite.candidateID.Hex()
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(suite.matchJob(), nil)
+	suite.mockJobRepo.On("SetMatchReview", mock.Anything, suite.jobID, suite.candidateID, mock.MatchedBy(func(r *model.MatchReview) bool {
+		return r.Decision == model.MatchAccepted && r.Reviewer == "alice" && assert.ObjectsAreEqual([]string{"profile.age"}, r.Fields)
+	})).Return(nil)
+	suite.mockClientRepo.On("AddDocument", mock.Anything, candidate, mock.MatchedBy(func(d *model.ClientDocument) bool {
+		return d.JobID == suite.jobID && d.FileName == "statement.pdf" && d.LinkedBy == "alice"
+	})).Return(nil)
+	suite.mockClientRepo.On("Update", mock.Anything, candidate, bson.D{{Key: "data.profile.age", Value: 42}}).Return(nil)
+	suite.mockJobService.On("SubmitJob", mock.Anything, mock.MatchedBy(func(j *model.Job) bool {
+		return j.Type == model.Sync && j.ClientID == candidate && j.CreatedBy == "alice" && j.Input["target_id"] == candidate
+	})).Return("sync-job-id", nil)
+	suite.mockClientRepo.On("GetOne", mock.Anything, candidate).Return(&model.Client{Data: bson.D{{Key: "profile", Value: bson.D{{Key: "age", Value: 41}}}}}, nil)
+	suite.mockTimeline.On("Capture", mock.Anything, candidate, mock.Anything, model.ChangeSourceMatch, "alice", mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationMatch && l.ClientID == candidate &&
+			assert.ObjectsAreEqual([]model.Change{{Path: "profile.age", Old: 41, New: 42, Source: model.ChangeSourceMatch}}, l.Changes)
+	})).Return("log-id", nil)
+
+	ctx := context.WithValue(context.Background(), "username", "alice")
+	result, err := suite.matchService.ReviewMatch(ctx, suite.jobID, candidate, &model.ReviewMatchReq{
+		Decision: model.MatchAccepted,
+		Fields:   []string{"profile.age", "profile.age"},
+	})
+
+	suite.NoError(err)
+	suite.Require().NotNil(result.Review)
+	suite.Equal(model.MatchAccepted, result.Review.Decision)
+	suite.mockJobRepo.AssertExpectations(suite.T())
+	suite.mockClientRepo.AssertExpectations(suite.T())
+	suite.mockJobService.AssertExpectations(suite.T())
+	suite.mockTimeline.AssertExpectations(suite.T())
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_AcceptWithoutFields() {
+	candidate := suite.candidateID.Hex()
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(suite.matchJob(), nil)
+	suite.mockJobRepo.On("SetMatchReview", mock.Anything, suite.jobID, suite.candidateID, mock.Anything).Return(nil)
+	suite.mockClientRepo.On("AddDocument", mock.Anything, candidate, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+
+	_, err := suite.matchService.ReviewMatch(context.Background(), suite.jobID, candidate, &model.ReviewMatchReq{Decision: model.MatchAccepted})
+
+	suite.NoError(err)
+	// the profile is unchanged, so its vector is still current
+	suite.mockJobService.AssertNotCalled(suite.T(), "SubmitJob", mock.Anything, mock.Anything)
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_SyncError() {
+	candidate := suite.candidateID.Hex()
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(suite.matchJob(), nil)
+	suite.mockJobRepo.On("SetMatchReview", mock.Anything, suite.jobID, suite.candidateID, mock.Anything).Return(nil)
+	suite.mockClientRepo.On("AddDocument", mock.Anything, candidate, mock.Anything).Return(nil)
+	suite.mockClientRepo.On("GetOne", mock.Anything, candidate).Return(&model.Client{}, nil)
+	suite.mockClientRepo.On("Update", mock.Anything, candidate, mock.Anything).Return(nil)
+	suite.mockJobService.On("SubmitJob", mock.Anything, mock.Anything).Return("", errorx.ErrDependencyFailed)
+
+	_, err := suite.matchService.ReviewMatch(context.Background(), suite.jobID, candidate, &model.ReviewMatchReq{
+		Decision: model.MatchAccepted,
+		Fields:   []string{"profile.age"},
+	})
+
+	// the review is rolled back rather than leave the index stale
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_Reject() {
+	candidate := suite.candidateID.Hex()
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(suite.matchJob(), nil)
+	suite.mockJobRepo.On("SetMatchReview", mock.Anything, suite.jobID, suite.candidateID, mock.Anything).Return(nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationMatch
+	})).Return("log-id", nil)
+
+	result, err := suite.matchService.ReviewMatch(context.Background(), suite.jobID, candidate, &model.ReviewMatchReq{Decision: model.MatchRejected})
+
+	suite.NoError(err)
+	suite.Equal(model.MatchRejected, result.Review.Decision)
+	suite.mockClientRepo.AssertNotCalled(suite.T(), "AddDocument", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockClientRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_ValidationFailed() {
+	candidate := suite.candidateID.Hex()
+
+	_, err := suite.matchService.ReviewMatch(context.Background(), suite.jobID, candidate, &model.ReviewMatchReq{Decision: "maybe"})
+	suite.ErrorIs(err, errorx.ErrValidationFailed)
+
+	_, err = suite.matchService.ReviewMatch(context.Background(), suite.jobID, candidate, &model.ReviewMatchReq{Decision: model.MatchRejected, Fields: []string{"profile.age"}})
+	suite.ErrorIs(err, errorx.ErrValidationFailed)
+
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(suite.matchJob(), nil)
+	_, err = suite.matchService.ReviewMatch(context.Background(), suite.jobID, candidate, &model.ReviewMatchReq{Decision: model.MatchAccepted, Fields: []string{"profile.netWorth"}})
+	suite.ErrorIs(err, errorx.ErrValidationFailed)
+
+	suite.mockJobRepo.AssertNotCalled(suite.T(), "SetMatchReview", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_NotCompleted() {
+	job := suite.matchJob()
+	job.Status = model.JobStatusProcessing
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(job, nil)
+
+	_, err := suite.matchService.ReviewMatch(context.Background(), suite.jobID, suite.candidateID.Hex(), &model.ReviewMatchReq{Decision: model.MatchRejected})
+	suite.ErrorIs(err, errorx.ErrConflict)
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_AlreadyReviewed() {
+	job := suite.matchJob()
+	job.MatchResults[0].Review = &model.MatchReview{Decision: model.MatchRejected}
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(job, nil)
+
+	_, err := suite.matchService.ReviewMatch(context.Background(), suite.jobID, suite.candidateID.Hex(), &model.ReviewMatchReq{Decision: model.MatchAccepted})
+	suite.ErrorIs(err, errorx.ErrConflict)
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_UnknownCandidate() {
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(suite.matchJob(), nil)
+
+	_, err := suite.matchService.ReviewMatch(context.Background(), suite.jobID, bson.NewObjectID().Hex(), &model.ReviewMatchReq{Decision: model.MatchRejected})
+	suite.ErrorIs(err, errorx.ErrNotFound)
+}
+
+func (suite *MatchServiceTestSuite) TestReviewMatch_TransactionError() {
+	candidate := suite.candidateID.Hex()
+	suite.mockJobRepo.On("GetOne", mock.Anything, suite.jobID).Return(suite.matchJob(), nil)
+	suite.mockJobRepo.On("SetMatchReview", mock.Anything, suite.jobID, suite.candidateID, mock.Anything).Return(nil)
+	suite.mockClientRepo.On("AddDocument", mock.Anything, candidate, mock.Anything).Return(errorx.ErrNotFound)
+
+	_, err := suite.matchService.ReviewMatch(context.Background(), suite.jobID, candidate, &model.ReviewMatchReq{Decision: model.MatchAccepted})
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+}
+
+func (suite *MatchServiceTestSuite) TestGetMatchStats() {
+	query := &model.GetMatchStatsQuery{}
+	suite.mockJobRepo.On("GetMatchStats", mock.Anything, query).Return([]model.MatchStats{
+		{Reviewer: "alice", Reviewed: 4, Accepted: 3, AcceptedScore: 0.9, RejectedScore: 0.5},
+		{Reviewer: "bob", Reviewed: 2, Accepted: 1, AcceptedScore: 0.6, RejectedScore: 0.2},
+	}, nil)
+
+	res, err := suite.matchService.GetMatchStats(context.Background(), query)
+
+	suite.NoError(err)
+	suite.Equal(6, res.Reviewed)
+	suite.Equal(4, res.Accepted)
+	suite.Equal(0.67, res.Accuracy)
+	suite.Equal(0.75, res.Reviewers[0].Accuracy)
+	suite.Equal(0.5, res.Reviewers[1].Accuracy)
+	suite.Equal(0.83, res.AcceptedScore)
+	suite.Equal(0.35, res.RejectedScore)
+}
+
+func (suite *MatchServiceTestSuite) TestGetMatchStats_InvalidRange() {
+	now := time.Now()
+	_, err := suite.matchService.GetMatchStats(context.Background(), &model.GetMatchStatsQuery{From: now, To: now.Add(-time.Hour)})
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+}
+
+func TestMatchServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(MatchServiceTestSuite))
+}
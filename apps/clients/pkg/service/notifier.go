@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/streadway/amqp"
+)
+
+// notificationQueue is the queue the notification service consumes from
+const notificationQueue = "notifications"
+
+type NotifierInterface interface {
+	Publish(notification *model.Notification) error
+}
+
+type RabbitMQNotifier struct {
+	URL string
+}
+
+func NewRabbitMQNotifier(url string) *RabbitMQNotifier {
+	return &RabbitMQNotifier{URL: url}
+}
+
+// Publish sends a persistent message to the notifications queue
+func (n *RabbitMQNotifier) Publish(notification *model.Notification) error {
+	if n.URL == "" {
+		log.Printf("RabbitMQ URL not configured, dropping %s notification for job %s", notification.Status, notification.JobID)
+		return nil
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("error marshalling notification: %w", err)
+	}
+
+	conn, err := amqp.Dial(n.URL)
+	if err != nil {
+		return fmt.Errorf("error connecting to RabbitMQ: %w", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("error opening channel: %w", err)
+	}
+	defer ch.Close()
+
+	if _, err := ch.QueueDeclare(notificationQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("error declaring queue: %w", err)
+	}
+
+	err = ch.Publish("", notificationQueue, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("error publishing notification: %w", err)
+	}
+	return nil
+}
@@ -2,58 +2,211 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-)
-
-type PrefectFlowRunnerInterface interface {
-	Trigger(deploymentID string, params map[string]interface{}) error
-}
+	"strconv"
+	"strings"
+	"time"
 
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+)
 
+// PrefectFlowRunner is the WorkflowBackend that runs jobs as Prefect flow runs. Requests that fail with a network error, a 5xx or a 429 are retried
+// with exponential backoff, and once Breaker opens calls fail fast with ErrDependencyFailed.
 type PrefectFlowRunner struct {
 	APIURL string
 	APIKey string
 	Client *http.Client
+
+	Timeout   time.Duration // per attempt
+	Retries   int
+	RetryBase time.Duration
+	Breaker   *CircuitBreaker
 }
 
 func NewPrefectFlowRunner(apiURL string, apiKey string, client *http.Client) *PrefectFlowRunner {
 	return &PrefectFlowRunner{
-		APIURL: apiURL,
-		APIKey: apiKey,
-		Client: client,
+		APIURL:    apiURL,
+		APIKey:    apiKey,
+		Client:    client,
+		Timeout:   10 * time.Second,
+		Retries:   3,
+		RetryBase: 500 * time.Millisecond,
+		Breaker:   NewCircuitBreaker(5, 30*time.Second),
 	}
 }
 
-func (r *PrefectFlowRunner) Trigger(deploymentID string, params map[string]interface{}) error {
+// Trigger creates a flow run of the run's deployment and returns its id
+func (r *PrefectFlowRunner) Trigger(ctx context.Context, run WorkflowRun) (string, error) {
 	requestBody := map[string]interface{}{
-		"parameters": params,
+		"parameters": run.Params,
+	}
+	// the job id makes retries of the same trigger return the run that was already created
+	if jobID, ok := run.Params["job_id"].(string); ok && jobID != "" {
+		requestBody["idempotency_key"] = jobID
+	}
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling request body: %w", err)
+	}
+
+	body, status, err := r.do(ctx, "POST", r.APIURL+run.Deployment+"/create_flow_run", jsonData)
+	if err != nil {
+		return "", err
+	}
+
+	// the run was created either way, a missing id only means it can't be cancelled later
+	var flowRun struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &flowRun); err != nil {
+		log.Printf("Could not read flow run id from Prefect response: %v", err)
+	}
+
+	log.Printf("Triggered Prefect flow run %s. Status: %s", flowRun.ID, status)
+	return flowRun.ID, nil
+}
+
+// Cancel moves a flow run to the CANCELLING state, Prefect then stops its infrastructure
+func (r *PrefectFlowRunner) Cancel(ctx context.Context, flowRunID string) error {
+	requestBody := map[string]interface{}{
+		"state": map[string]interface{}{"type": "CANCELLING", "message": "Cancelled by user"},
 	}
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return fmt.Errorf("error marshalling request body: %w", err)
 	}
 
-	url := r.APIURL + deploymentID + "/create_flow_run"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	body, _, err := r.do(ctx, "POST", r.flowRunsURL()+flowRunID+"/set_state", jsonData)
 	if err != nil {
-		return fmt.Errorf("error creating HTTP request: %w", err)
+		return err
+	}
+
+	// Prefect answers 200 with status REJECT or ABORT when the run is already finished, so there's nothing to stop
+	var result struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &result); err == nil {
+		switch result.Status {
+		case "", "ACCEPT":
+		case "REJECT", "ABORT":
+			log.Printf("Prefect flow run %s already finished, nothing to cancel: %s", flowRunID, result.Status)
+			return nil
+		default:
+			return fmt.Errorf("prefect did not accept cancellation: %s", result.Status)
+		}
+	}
+
+	log.Printf("Cancelled Prefect flow run %s", flowRunID)
+	return nil
+}
+
+// State returns the type of the flow run's current state, e.g. RUNNING, COMPLETED or CRASHED
+func (r *PrefectFlowRunner) State(ctx context.Context, flowRunID string) (string, error) {
+	body, _, err := r.do(ctx, "GET", r.flowRunsURL()+flowRunID, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var flowRun struct {
+		StateType string `json:"state_type"`
+		State     struct {
+			Type string `json:"type"`
+		} `json:"state"`
+	}
+	if err := json.Unmarshal(body, &flowRun); err != nil {
+		return "", fmt.Errorf("error decoding flow run: %w", err)
+	}
+	if flowRun.State.Type != "" {
+		return flowRun.State.Type, nil
+	}
+	if flowRun.StateType == "" {
+		return "", fmt.Errorf("flow run %s has no state", flowRunID)
+	}
+	return flowRun.StateType, nil
+}
+
+// do sends the request, retrying failures Prefect may recover from, and returns the body of a 2xx response.
+// Errors caused by Prefect being unreachable or unhealthy wrap ErrDependencyFailed.
+func (r *PrefectFlowRunner) do(ctx context.Context, method string, url string, payload []byte) ([]byte, string, error) {
+	if !r.Breaker.Allow() {
+		return nil, "", fmt.Errorf("%w: prefect circuit breaker is open", errorx.ErrDependencyFailed)
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		body, status, retryAfter, err := r.send(ctx, method, url, payload)
+		if err == nil {
+			r.Breaker.Success()
+			return body, status, nil
+		}
+		if retryAfter < 0 {
+			// Prefect answered, so it is healthy even though it refused this request
+			r.Breaker.Success()
+			return nil, status, err
+		}
+
+		lastErr = err
+		if attempt >= r.Retries {
+			break
+		}
+		wait := max(r.RetryBase<<attempt, retryAfter)
+		log.Printf("Prefect request to %s failed, retrying in %s: %v", url, wait, err)
+		select {
+		case <-ctx.Done():
+			return nil, "", fmt.Errorf("%w: %v", errorx.ErrDependencyFailed, lastErr)
+		case <-time.After(wait):
+		}
+	}
+
+	r.Breaker.Failure()
+	return nil, "", fmt.Errorf("%w: %v", errorx.ErrDependencyFailed, lastErr)
+}
+
+// send makes a single attempt. retryAfter is -1 if the request must not be retried, otherwise the least
+// time to wait before retrying, which is only set when Prefect sends a Retry-After header.
+func (r *PrefectFlowRunner) send(ctx context.Context, method string, url string, payload []byte) (body []byte, status string, retryAfter time.Duration, err error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", -1, fmt.Errorf("error creating HTTP request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+r.APIKey)
-	req.Header.Set("Content-Type", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := r.Client.Do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return nil, "", 0, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 300 {
-		return fmt.Errorf("received non-2xx response: %s", resp.Status)
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.Status, 0, fmt.Errorf("error reading response: %w", err)
 	}
 
-	log.Printf("Triggered Prefect flow run. Status: %s", resp.Status)
-	return nil
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return nil, resp.Status, time.Duration(seconds) * time.Second, fmt.Errorf("received non-2xx response: %s", resp.Status)
+	case resp.StatusCode >= 300:
+		return nil, resp.Status, -1, fmt.Errorf("received non-2xx response: %s", resp.Status)
+	}
+	return body, resp.Status, 0, nil
+}
+
+// flowRunsURL derives the flow runs endpoint from APIURL, which points at the workspace deployments
+func (r *PrefectFlowRunner) flowRunsURL() string {
+	return strings.TrimSuffix(strings.TrimSuffix(r.APIURL, "/"), "/deployments") + "/flow_runs/"
 }
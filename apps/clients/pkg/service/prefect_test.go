@@ -1,12 +1,16 @@
 package service_test
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
 	"github.com/stretchr/testify/assert"
 )
@@ -36,7 +40,7 @@ func TestPrefectFlowRunner_Trigger_Success(t *testing.T) {
 		"client_id": "abc",
 	}
 
-	err := runner.Trigger("deployment-xyz", params)
+	_, err := runner.Trigger(context.Background(), service.WorkflowRun{Deployment: "deployment-xyz", Params: params})
 
 	assert.NoError(t, err)
 	assert.Equal(t, map[string]interface{}{
@@ -44,14 +48,17 @@ func TestPrefectFlowRunner_Trigger_Success(t *testing.T) {
 			"job_id":    "123",
 			"client_id": "abc",
 		},
+		"idempotency_key": "123",
 	}, receivedBody)
 }
 
 func TestPrefectFlowRunner_Trigger_HttpError(t *testing.T) {
 	runner := service.NewPrefectFlowRunner("http://invalid-host/", "key", &http.Client{})
+	runner.RetryBase = time.Millisecond
 
-	err := runner.Trigger("deployment-xyz", map[string]interface{}{"job_id": "fail"})
+	_, err := runner.Trigger(context.Background(), service.WorkflowRun{Deployment: "deployment-xyz", Params: map[string]interface{}{"job_id": "fail"}})
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, errorx.ErrDependencyFailed)
 	assert.Contains(t, err.Error(), "HTTP request failed")
 }
 
@@ -63,7 +70,209 @@ func TestPrefectFlowRunner_Trigger_BadStatus(t *testing.T) {
 
 	runner := service.NewPrefectFlowRunner(server.URL+"/", "key", server.Client())
 
-	err := runner.Trigger("deployment-xyz", map[string]interface{}{"job_id": "fail"})
+	_, err := runner.Trigger(context.Background(), service.WorkflowRun{Deployment: "deployment-xyz", Params: map[string]interface{}{"job_id": "fail"}})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "received non-2xx response")
 }
+
+func TestPrefectFlowRunner_Trigger_ReturnsFlowRunID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"flow-run-1","name":"quiet-fox"}`))
+	}))
+	defer server.Close()
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/deployments/", "key", server.Client())
+
+	flowRunID, err := runner.Trigger(context.Background(), service.WorkflowRun{Deployment: "deployment-xyz", Params: map[string]interface{}{"job_id": "123"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "flow-run-1", flowRunID)
+}
+
+func TestPrefectFlowRunner_Cancel_Success(t *testing.T) {
+	var receivedBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/flow_runs/flow-run-1/set_state", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		json.NewDecoder(r.Body).Decode(&receivedBody)
+		w.Write([]byte(`{"status":"ACCEPT"}`))
+	}))
+	defer server.Close()
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/api/deployments/", "key", server.Client())
+
+	err := runner.Cancel(context.Background(), "flow-run-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "CANCELLING", receivedBody["state"].(map[string]interface{})["type"])
+}
+
+func TestPrefectFlowRunner_Cancel_NotAccepted(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		// the run already finished, so the job can be cancelled locally
+		{"REJECT", false},
+		{"ABORT", false},
+		{"WAIT", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"status":"` + tt.status + `"}`))
+			}))
+			defer server.Close()
+
+			runner := service.NewPrefectFlowRunner(server.URL+"/deployments/", "key", server.Client())
+
+			err := runner.Cancel(context.Background(), "flow-run-1")
+			if tt.wantErr {
+				assert.ErrorContains(t, err, tt.status)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPrefectFlowRunner_State(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/flow_runs/flow-run-1", r.URL.Path)
+		w.Write([]byte(`{"id":"flow-run-1","state_type":"RUNNING","state":{"type":"CRASHED","name":"Crashed"}}`))
+	}))
+	defer server.Close()
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/api/deployments/", "key", server.Client())
+
+	state, err := runner.State(context.Background(), "flow-run-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "CRASHED", state)
+}
+
+func TestPrefectFlowRunner_State_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/api/deployments/", "key", server.Client())
+
+	_, err := runner.State(context.Background(), "flow-run-1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestPrefectFlowRunner_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":"flow-run-1"}`))
+	}))
+	defer server.Close()
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/", "key", server.Client())
+	runner.RetryBase = time.Millisecond
+
+	flowRunID, err := runner.Trigger(context.Background(), service.WorkflowRun{Deployment: "deployment-xyz", Params: map[string]interface{}{"job_id": "123"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "flow-run-1", flowRunID)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestPrefectFlowRunner_RetriesRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"state":{"type":"RUNNING"}}`))
+	}))
+	defer server.Close()
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/deployments/", "key", server.Client())
+	runner.RetryBase = time.Millisecond
+
+	state, err := runner.State(context.Background(), "flow-run-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "RUNNING", state)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestPrefectFlowRunner_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/", "key", server.Client())
+	runner.RetryBase = time.Millisecond
+
+	_, err := runner.Trigger(context.Background(), service.WorkflowRun{Deployment: "deployment-xyz", Params: map[string]interface{}{"job_id": "123"}})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, errorx.ErrDependencyFailed)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestPrefectFlowRunner_CircuitBreakerOpens(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/", "key", server.Client())
+	runner.Retries = 0
+	runner.Breaker = service.NewCircuitBreaker(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		_, err := runner.Trigger(context.Background(), service.WorkflowRun{Deployment: "deployment-xyz", Params: map[string]interface{}{"job_id": "123"}})
+		assert.ErrorIs(t, err, errorx.ErrDependencyFailed)
+	}
+
+	_, err := runner.Trigger(context.Background(), service.WorkflowRun{Deployment: "deployment-xyz", Params: map[string]interface{}{"job_id": "123"}})
+	assert.ErrorIs(t, err, errorx.ErrDependencyFailed)
+	assert.Contains(t, err.Error(), "circuit breaker is open")
+	assert.Equal(t, int32(2), calls.Load(), "an open breaker doesn't call Prefect")
+}
+
+func TestPrefectFlowRunner_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	runner := service.NewPrefectFlowRunner(server.URL+"/", "key", server.Client())
+	runner.Timeout = 10 * time.Millisecond
+	runner.Retries = 0
+
+	err := runner.Cancel(context.Background(), "flow-run-1")
+	assert.ErrorIs(t, err, errorx.ErrDependencyFailed)
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	breaker := service.NewCircuitBreaker(1, 10*time.Millisecond)
+	assert.True(t, breaker.Allow())
+
+	breaker.Failure()
+	assert.False(t, breaker.Allow())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, breaker.Allow(), "a trial call is let through after the cooldown")
+
+	breaker.Failure()
+	assert.False(t, breaker.Allow(), "a failed trial opens the breaker again")
+
+	breaker.Success()
+	assert.True(t, breaker.Allow())
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+// QuotaLimits caps how many scrape and match jobs are created. A limit of 0 is no limit.
+type QuotaLimits struct {
+	MaxActivePerUser int
+	MaxActiveGlobal  int
+	DailyJobs        int
+	// DailyJobsByGroup overrides DailyJobs for members of a group, the most generous of a user's groups applies
+	DailyJobsByGroup map[string]int
+	// RetryAfter is suggested to callers held back by a concurrency cap, since there is no telling when jobs finish
+	RetryAfter time.Duration
+}
+
+// QuotaExceededError is returned when creating a job would go over a quota, it wraps ErrTooManyRequests
+type QuotaExceededError struct {
+	Quota      string
+	Limit      int
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%v: %s quota of %d jobs reached", errorx.ErrTooManyRequests, e.Quota, e.Limit)
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return errorx.ErrTooManyRequests
+}
+
+// QuotaService enforces the job quotas and reports usage against them
+type QuotaService struct {
+	jobRepository repository.JobRepository
+	limits        QuotaLimits
+}
+
+type QuotaServiceInterface interface {
+	Check(ctx context.Context) error
+	CheckJobs(ctx context.Context, n int) error
+	GetUsage(ctx context.Context) (*model.UsageResponse, error)
+}
+
+func NewQuotaService(jobRepository repository.JobRepository, limits QuotaLimits) *QuotaService {
+	return &QuotaService{jobRepository: jobRepository, limits: limits}
+}
+
+// Check returns a QuotaExceededError if the caller may not create another job right now.
+// Usage is counted before the job is created, so concurrent requests can overshoot a limit slightly.
+func (s *QuotaService) Check(ctx context.Context) error {
+	return s.CheckJobs(ctx, 1)
+}
+
+// CheckJobs returns a QuotaExceededError if the caller may not create n more jobs right now, or ErrValidationFailed
+// if n alone is over a quota, since no amount of waiting would let them through
+func (s *QuotaService) CheckJobs(ctx context.Context, n int) error {
+	for _, quota := range []struct {
+		name  string
+		limit int
+	}{
+		{"global concurrent", s.limits.MaxActiveGlobal},
+		{"concurrent", s.limits.MaxActivePerUser},
+		{"daily", s.dailyLimit(ctx)},
+	} {
+		if quota.limit > 0 && n > quota.limit {
+			return fmt.Errorf("%w: %d jobs are more than the %s quota of %d jobs, start fewer at a time", errorx.ErrValidationFailed, n, quota.name, quota.limit)
+		}
+	}
+
+	usage, err := s.GetUsage(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case exhausted(usage.GlobalActive, n):
+		return &QuotaExceededError{Quota: "global concurrent", Limit: usage.GlobalActive.Limit, RetryAfter: s.limits.RetryAfter}
+	case exhausted(usage.Active, n):
+		return &QuotaExceededError{Quota: "concurrent", Limit: usage.Active.Limit, RetryAfter: s.limits.RetryAfter}
+	case exhausted(usage.Daily, n):
+		return &QuotaExceededError{Quota: "daily", Limit: usage.Daily.Limit, RetryAfter: time.Until(*usage.Daily.ResetsAt)}
+	}
+	return nil
+}
+
+// GetUsage counts the caller's active and today's jobs, and everyone's active jobs
+func (s *QuotaService) GetUsage(ctx context.Context) (*model.UsageResponse, error) {
+	username := GetUsername(ctx)
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	active, err := s.jobRepository.CountActive(ctx, username)
+	if err != nil {
+		return nil, wrapQuotaErr(err, "error counting active jobs")
+	}
+	daily, err := s.jobRepository.CountCreated(ctx, username, today)
+	if err != nil {
+		return nil, wrapQuotaErr(err, "error counting today's jobs")
+	}
+	globalActive, err := s.jobRepository.CountActive(ctx, "")
+	if err != nil {
+		return nil, wrapQuotaErr(err, "error counting active jobs")
+	}
+
+	return &model.UsageResponse{
+		Username:     username,
+		Active:       model.QuotaUsage{Used: active, Limit: s.limits.MaxActivePerUser},
+		Daily:        model.QuotaUsage{Used: daily, Limit: s.dailyLimit(ctx), ResetsAt: &tomorrow},
+		GlobalActive: model.QuotaUsage{Used: globalActive, Limit: s.limits.MaxActiveGlobal},
+	}, nil
+}
+
+func (s *QuotaService) dailyLimit(ctx context.Context) int {
+	limit, overridden := 0, false
+	groups, _ := ctx.Value("groups").([]string)
+	for _, group := range groups {
+		groupLimit, ok := s.limits.DailyJobsByGroup[group]
+		if !ok {
+			continue
+		}
+		// 0 is no limit, so it beats any other
+		if !overridden || groupLimit == 0 || (limit != 0 && groupLimit > limit) {
+			limit = groupLimit
+		}
+		overridden = true
+	}
+	if !overridden {
+		return s.limits.DailyJobs
+	}
+	return limit
+}
+
+// exhausted reports whether n more jobs would go over the limit
+func exhausted(usage model.QuotaUsage, n int) bool {
+	return usage.Limit > 0 && usage.Used+n > usage.Limit
+}
+
+func wrapQuotaErr(err error, msg string) error {
+	if errors.Is(err, errorx.ErrDependencyFailed) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", errorx.ErrInternal, msg)
+}
@@ -0,0 +1,154 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type QuotaServiceTestSuite struct {
+	suite.Suite
+	mockJobRepo *mocks.JobRepository
+	limits      service.QuotaLimits
+	ctx         context.Context
+}
+
+func (suite *QuotaServiceTestSuite) SetupTest() {
+	suite.mockJobRepo = new(mocks.JobRepository)
+	suite.limits = service.QuotaLimits{
+		MaxActivePerUser: 2,
+		MaxActiveGlobal:  10,
+		DailyJobs:        5,
+		DailyJobsByGroup: map[string]int{"analyst": 20, "admin": 0},
+		RetryAfter:       30 * time.Second,
+	}
+	suite.ctx = context.WithValue(context.Background(), "username", "alice")
+}
+
+func (suite *QuotaServiceTestSuite) usage(active, daily, global int) {
+	suite.mockJobRepo.On("CountActive", mock.Anything, "alice").Return(active, nil)
+	suite.mockJobRepo.On("CountCreated", mock.Anything, "alice", mock.MatchedBy(func(since time.Time) bool {
+		return !since.IsZero() && since.Hour() == 0
+	})).Return(daily, nil)
+	suite.mockJobRepo.On("CountActive", mock.Anything, "").Return(global, nil)
+}
+
+func (suite *QuotaServiceTestSuite) TestCheck_UnderQuota() {
+	suite.usage(1, 4, 9)
+
+	err := service.NewQuotaService(suite.mockJobRepo, suite.limits).Check(suite.ctx)
+
+	suite.NoError(err)
+}
+
+func (suite *QuotaServiceTestSuite) TestCheck_Exceeded() {
+	tests := []struct {
+		name                  string
+		active, daily, global int
+		quota                 string
+		retryAfter            time.Duration
+	}{
+		{"global concurrency", 0, 0, 10, "global concurrent", 30 * time.Second},
+		{"user concurrency", 2, 0, 3, "concurrent", 30 * time.Second},
+		{"daily", 0, 5, 3, "daily", 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			suite.usage(tt.active, tt.daily, tt.global)
+
+			err := service.NewQuotaService(suite.mockJobRepo, suite.limits).Check(suite.ctx)
+
+			suite.ErrorIs(err, errorx.ErrTooManyRequests)
+			var quota *service.QuotaExceededError
+			suite.Require().ErrorAs(err, &quota)
+			suite.Equal(tt.quota, quota.Quota)
+			suite.LessOrEqual(quota.RetryAfter, tt.retryAfter)
+			suite.Positive(quota.RetryAfter)
+		})
+	}
+}
+
+func (suite *QuotaServiceTestSuite) TestCheckJobs_CountsEveryJob() {
+	suite.usage(1, 2, 3)
+	quota := service.NewQuotaService(suite.mockJobRepo, suite.limits)
+
+	suite.NoError(quota.CheckJobs(suite.ctx, 1))
+
+	var exceeded *service.QuotaExceededError
+	suite.Require().ErrorAs(quota.CheckJobs(suite.ctx, 2), &exceeded)
+	suite.Equal("concurrent", exceeded.Quota)
+}
+
+func (suite *QuotaServiceTestSuite) TestCheckJobs_MoreThanQuota() {
+	quota := service.NewQuotaService(suite.mockJobRepo, suite.limits)
+
+	// more jobs than a quota allows can never fit, however long the caller waits
+	err := quota.CheckJobs(suite.ctx, suite.limits.MaxActivePerUser+1)
+
+	suite.ErrorIs(err, errorx.ErrValidationFailed)
+	var exceeded *service.QuotaExceededError
+	suite.False(errors.As(err, &exceeded))
+	suite.mockJobRepo.AssertNotCalled(suite.T(), "CountActive", mock.Anything, mock.Anything)
+}
+
+func (suite *QuotaServiceTestSuite) TestCheck_UnlimitedWhenZero() {
+	suite.usage(100, 100, 100)
+
+	err := service.NewQuotaService(suite.mockJobRepo, service.QuotaLimits{}).Check(suite.ctx)
+
+	suite.NoError(err)
+}
+
+func (suite *QuotaServiceTestSuite) TestGetUsage_GroupLimits() {
+	tests := []struct {
+		name   string
+		groups []string
+		limit  int
+	}{
+		{"no group", nil, 5},
+		{"unknown group", []string{"viewer"}, 5},
+		{"analyst", []string{"viewer", "analyst"}, 20},
+		{"admin is unlimited", []string{"analyst", "admin"}, 0},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			suite.usage(1, 3, 4)
+			ctx := context.WithValue(suite.ctx, "groups", tt.groups)
+
+			usage, err := service.NewQuotaService(suite.mockJobRepo, suite.limits).GetUsage(ctx)
+
+			suite.Require().NoError(err)
+			suite.Equal("alice", usage.Username)
+			suite.Equal(model.QuotaUsage{Used: 1, Limit: 2}, usage.Active)
+			suite.Equal(3, usage.Daily.Used)
+			suite.Equal(tt.limit, usage.Daily.Limit)
+			suite.Require().NotNil(usage.Daily.ResetsAt)
+			suite.True(usage.Daily.ResetsAt.After(time.Now()))
+			suite.Equal(model.QuotaUsage{Used: 4, Limit: 10}, usage.GlobalActive)
+		})
+	}
+}
+
+func (suite *QuotaServiceTestSuite) TestGetUsage_DependencyFailed() {
+	suite.mockJobRepo.On("CountActive", mock.Anything, "alice").Return(0, errorx.ErrDependencyFailed)
+
+	_, err := service.NewQuotaService(suite.mockJobRepo, suite.limits).GetUsage(suite.ctx)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func TestQuotaServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(QuotaServiceTestSuite))
+}
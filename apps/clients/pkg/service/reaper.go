@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"time"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+)
+
+// reaperLease is held by the replica currently allowed to reap stale jobs
+const reaperLease = "job-reaper"
+
+// Reaper periodically fails jobs that Prefect never picked up or that crashed without reporting back.
+// Only the replica holding the reaper lease does any work on a tick.
+type Reaper struct {
+	jobService      JobServiceInterface
+	leaseRepository repository.LeaseRepository
+	holder          string
+	interval        time.Duration
+	timeouts        map[model.JobType]time.Duration
+	checkPrefect    bool
+}
+
+func NewReaper(jobService JobServiceInterface, leaseRepository repository.LeaseRepository, interval time.Duration, timeouts map[model.JobType]time.Duration, checkPrefect bool) *Reaper {
+	return &Reaper{
+		jobService:      jobService,
+		leaseRepository: leaseRepository,
+		holder:          reaperHolder(),
+		interval:        interval,
+		timeouts:        timeouts,
+		checkPrefect:    checkPrefect,
+	}
+}
+
+// Run reaps stale jobs every interval until ctx is done, then releases the lease
+func (r *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			release, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := r.leaseRepository.Release(release, reaperLease, r.holder); err != nil {
+				log.Printf("error releasing %s lease: %v", reaperLease, err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			r.Tick(ctx)
+		}
+	}
+}
+
+// Tick reaps stale jobs once if this replica holds, or can take, the lease
+func (r *Reaper) Tick(ctx context.Context) {
+	// the lease outlives a tick, so the holder keeps it between ticks and another replica takes over if it stops
+	acquired, err := r.leaseRepository.Acquire(ctx, reaperLease, r.holder, 2*r.interval)
+	if err != nil {
+		log.Printf("error acquiring %s lease: %v", reaperLease, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	reaped, err := r.jobService.ReapStaleJobs(ctx, r.timeouts, r.checkPrefect)
+	if err != nil {
+		log.Printf("error reaping stale jobs: %v", err)
+	}
+	if reaped > 0 {
+		log.Printf("marked %d stale jobs as failed", reaped)
+	}
+}
+
+func reaperHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "clients"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReaperTestSuite struct {
+	suite.Suite
+	mockJobService *mocks.JobServiceInterface
+	mockLeaseRepo  *mocks.LeaseRepository
+	timeouts       map[model.JobType]time.Duration
+	reaper         *service.Reaper
+}
+
+func (suite *ReaperTestSuite) SetupTest() {
+	suite.mockJobService = new(mocks.JobServiceInterface)
+	suite.mockLeaseRepo = new(mocks.LeaseRepository)
+	suite.timeouts = map[model.JobType]time.Duration{model.Scrape: time.Hour}
+	suite.reaper = service.NewReaper(suite.mockJobService, suite.mockLeaseRepo, time.Minute, suite.timeouts, true)
+}
+
+func (suite *ReaperTestSuite) TestTick_LeaseAcquired() {
+	suite.mockLeaseRepo.On("Acquire", mock.Anything, "job-reaper", mock.AnythingOfType("string"), 2*time.Minute).Return(true, nil)
+	suite.mockJobService.On("ReapStaleJobs", mock.Anything, suite.timeouts, true).Return(2, nil)
+
+	suite.reaper.Tick(context.Background())
+
+	suite.mockJobService.AssertExpectations(suite.T())
+}
+
+func (suite *ReaperTestSuite) TestTick_LeaseHeldElsewhere() {
+	suite.mockLeaseRepo.On("Acquire", mock.Anything, "job-reaper", mock.Anything, mock.Anything).Return(false, nil)
+
+	suite.reaper.Tick(context.Background())
+
+	suite.mockJobService.AssertNotCalled(suite.T(), "ReapStaleJobs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReaperTestSuite) TestTick_LeaseError() {
+	suite.mockLeaseRepo.On("Acquire", mock.Anything, "job-reaper", mock.Anything, mock.Anything).Return(false, assert.AnError)
+
+	suite.reaper.Tick(context.Background())
+
+	suite.mockJobService.AssertNotCalled(suite.T(), "ReapStaleJobs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReaperTestSuite) TestRun_ReleasesLeaseOnShutdown() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var holder string
+	suite.mockLeaseRepo.On("Release", mock.Anything, "job-reaper", mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { holder = args.String(2) }).
+		Return(nil)
+
+	cancel()
+	suite.reaper.Run(ctx)
+
+	suite.NotEmpty(holder)
+	suite.mockLeaseRepo.AssertExpectations(suite.T())
+}
+
+func TestReaperTestSuite(t *testing.T) {
+	suite.Run(t, new(ReaperTestSuite))
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// pipelineActor is recorded as the actor for changes made by the scraping pipelines
+const pipelineActor = "pipeline"
+
+type TimelineService struct {
+	timelineRepository repository.TimelineRepository
+	clientRepository   repository.ClientRepository
+}
+
+type TimelineServiceInterface interface {
+	Capture(ctx context.Context, clientID string, data bson.D, source model.ChangeSource, actor string, at time.Time) error
+	CaptureScrape(ctx context.Context, clientID string, at time.Time) ([]model.Change, error)
+	GetTimeline(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) (*model.GetTimelineResponse, error)
+}
+
+func NewTimelineService(timelineRepository repository.TimelineRepository, clientRepository repository.ClientRepository) *TimelineService {
+	return &TimelineService{timelineRepository: timelineRepository, clientRepository: clientRepository}
+}
+
+// Capture records a new point for every tracked field whose value in data differs from the last recorded value
+func (s *TimelineService) Capture(ctx context.Context, clientID string, data bson.D, source model.ChangeSource, actor string, at time.Time) error {
+	_, err := s.capture(ctx, clientID, data, source, actor, at)
+	return err
+}
+
+// capture records the new points and returns the changes they make to the tracked fields
+func (s *TimelineService) capture(ctx context.Context, clientID string, data bson.D, source model.ChangeSource, actor string, at time.Time) ([]model.Change, error) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var changes []model.Change
+	for field, path := range model.TimelineFields {
+		value, ok := lookupPath(data, path)
+		if !ok || value == nil {
+			continue
+		}
+
+		latest, err := s.timelineRepository.GetLatest(ctx, clientID, field)
+		if err != nil {
+			return nil, err
+		}
+		if latest != nil && sameValue(latest.Value, value) {
+			continue
+		}
+
+		_, err = s.timelineRepository.Create(ctx, &model.TimelinePoint{
+			ClientID:  clientID,
+			Field:     field,
+			Value:     value,
+			Source:    source,
+			Actor:     actor,
+			Timestamp: at.UTC(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		change := model.Change{Path: path, New: value, Source: source}
+		if latest != nil {
+			change.Old = latest.Value
+		}
+		changes = append(changes, change)
+	}
+
+	// map iteration order is random, keep the changes stable
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// CaptureScrape records the tracked fields a scrape job wrote to the client's profile, at when the job completed.
+// It returns the changes to those fields for the caller to audit.
+func (s *TimelineService) CaptureScrape(ctx context.Context, clientID string, at time.Time) ([]model.Change, error) {
+	client, err := s.clientRepository.GetOne(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting client", errorx.ErrInternal)
+	}
+	return s.capture(ctx, clientID, client.Data, model.ChangeSourceScrape, pipelineActor, at)
+}
+
+// GetTimeline pages through the recorded history of a tracked field, oldest first
+func (s *TimelineService) GetTimeline(ctx context.Context, clientID string, field string, query *model.GetTimelineQuery) (*model.GetTimelineResponse, error) {
+	if _, ok := model.TimelineFields[field]; !ok {
+		return nil, fmt.Errorf("%w: untracked timeline field '%s'", errorx.ErrInvalidInput, field)
+	}
+
+	if _, err := s.clientRepository.GetOne(ctx, clientID); err != nil {
+		if errors.Is(err, errorx.ErrNotFound) || errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInvalidInput) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: error getting client", errorx.ErrInternal)
+	}
+
+	res := &model.GetTimelineResponse{ClientID: clientID, Field: field}
+	var err error
+	res.Points, err = s.timelineRepository.GetAll(ctx, clientID, field, query)
+	if err != nil {
+		return nil, wrapTimelineErr(err, "error getting timeline")
+	}
+	res.Page, res.PageSize = query.Page, query.PageSize
+
+	res.Total, err = s.timelineRepository.Count(ctx, clientID, field, query)
+	if err != nil {
+		return nil, wrapTimelineErr(err, "error counting timeline")
+	}
+
+	return res, nil
+}
+
+func wrapTimelineErr(err error, msg string) error {
+	if errors.Is(err, errorx.ErrDependencyFailed) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", errorx.ErrInternal, msg)
+}
+
+// lookupPath resolves a dot separated path inside a client document or an extracted profile
+func lookupPath(data any, path string) (any, bool) {
+	var current any = data
+	for _, key := range strings.Split(path, ".") {
+		switch doc := current.(type) {
+		case bson.D:
+			found := false
+			for _, elem := range doc {
+				if elem.Key == key {
+					current, found = elem.Value, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case bson.M:
+			value, ok := doc[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// sameValue compares values by their canonical JSON form, so key order and numeric types don't register as changes
+func sameValue(a, b any) bool {
+	ca, errA := canonicalJSON(a)
+	cb, errB := canonicalJSON(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return ca == cb
+}
+
+func canonicalJSON(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(generic)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
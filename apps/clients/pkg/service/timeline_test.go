@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type TimelineServiceTestSuite struct {
+	suite.Suite
+	mockRepo        *mocks.TimelineRepository
+	mockClientRepo  *mocks.ClientRepository
+	timelineService *service.TimelineService
+}
+
+func (suite *TimelineServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(mocks.TimelineRepository)
+	suite.mockClientRepo = new(mocks.ClientRepository)
+	suite.timelineService = service.NewTimelineService(suite.mockRepo, suite.mockClientRepo)
+}
+
+func profileData(netWorth int64) bson.D {
+	return bson.D{
+		{Key: "profile", Value: bson.D{
+			{Key: "names", Value: bson.A{"Jane Doe"}},
+			{Key: "netWorth", Value: bson.D{
+				{Key: "estimatedValue", Value: netWorth},
+				{Key: "currency", Value: "USD"},
+			}},
+		}},
+	}
+}
+
+func (suite *TimelineServiceTestSuite) TestCapture_NewValue() {
+	suite.mockRepo.On("GetLatest", mock.Anything, "client-id", "netWorth").Return(nil, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *model.TimelinePoint) bool {
+		return p.ClientID == "client-id" &&
+			p.Field == "netWorth" &&
+			p.Source == model.ChangeSourceManual &&
+			p.Actor == "test-user"
+	})).Return("point-id", nil)
+
+	err := suite.timelineService.Capture(context.Background(), "client-id", profileData(100), model.ChangeSourceManual, "test-user", time.Now())
+
+	suite.NoError(err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TimelineServiceTestSuite) TestCapture_UnchangedValueSkipped() {
+	// stored values come back with a different key order and numeric type
+	latest := &model.TimelinePoint{Value: bson.D{
+		{Key: "currency", Value: "USD"},
+		{Key: "estimatedValue", Value: float64(100)},
+	}}
+	suite.mockRepo.On("GetLatest", mock.Anything, "client-id", "netWorth").Return(latest, nil)
+
+	err := suite.timelineService.Capture(context.Background(), "client-id", profileData(100), model.ChangeSourceScrape, "pipeline", time.Now())
+
+	suite.NoError(err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TimelineServiceTestSuite) TestCaptureScrape_ReturnsChanges() {
+	latest := &model.TimelinePoint{Value: profileData(100)[0].Value.(bson.D)[1].Value}
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{Data: profileData(250)}, nil)
+	suite.mockRepo.On("GetLatest", mock.Anything, "client-id", "netWorth").Return(latest, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return("point-id", nil)
+
+	changes, err := suite.timelineService.CaptureScrape(context.Background(), "client-id", time.Now())
+
+	suite.NoError(err)
+	suite.Equal([]model.Change{{
+		Path:   "profile.netWorth",
+		Old:    latest.Value,
+		New:    bson.D{{Key: "estimatedValue", Value: int64(250)}, {Key: "currency", Value: "USD"}},
+		Source: model.ChangeSourceScrape,
+	}}, changes)
+}
+
+func (suite *TimelineServiceTestSuite) TestCapture_RepoError() {
+	suite.mockRepo.On("GetLatest", mock.Anything, "client-id", "netWorth").Return(nil, errorx.ErrDependencyFailed)
+
+	err := suite.timelineService.Capture(context.Background(), "client-id", profileData(100), model.ChangeSourceScrape, "pipeline", time.Now())
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *TimelineServiceTestSuite) TestCaptureScrape() {
+	completedAt := time.Now().Add(-time.Minute)
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{Data: profileData(250)}, nil)
+	suite.mockRepo.On("GetLatest", mock.Anything, "client-id", mock.Anything).Return(nil, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *model.TimelinePoint) bool {
+		return p.Source == model.ChangeSourceScrape && p.Actor == "pipeline" && p.Timestamp.Equal(completedAt.UTC())
+	})).Return("point-id", nil)
+
+	_, err := suite.timelineService.CaptureScrape(context.Background(), "client-id", completedAt)
+
+	suite.NoError(err)
+	suite.mockRepo.AssertNumberOfCalls(suite.T(), "Create", 1)
+}
+
+func (suite *TimelineServiceTestSuite) TestCaptureScrape_ClientNotFound() {
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(nil, errorx.ErrNotFound)
+
+	_, err := suite.timelineService.CaptureScrape(context.Background(), "client-id", time.Now())
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TimelineServiceTestSuite) TestGetTimeline_Success() {
+	query := &model.GetTimelineQuery{Page: 2, PageSize: 1}
+	expected := []model.TimelinePoint{{Field: "netWorth", Value: int64(100)}}
+
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+	suite.mockRepo.On("GetAll", mock.Anything, "client-id", "netWorth", query).Return(expected, nil)
+	suite.mockRepo.On("Count", mock.Anything, "client-id", "netWorth", query).Return(3, nil)
+
+	res, err := suite.timelineService.GetTimeline(context.Background(), "client-id", "netWorth", query)
+
+	suite.NoError(err)
+	suite.Equal(&model.GetTimelineResponse{ClientID: "client-id", Field: "netWorth", Total: 3, Page: 2, PageSize: 1, Points: expected}, res)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockClientRepo.AssertExpectations(suite.T())
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TimelineServiceTestSuite) TestGetTimeline_UntrackedField() {
+	points, err := suite.timelineService.GetTimeline(context.Background(), "client-id", "gender", &model.GetTimelineQuery{})
+
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+	suite.Nil(points)
+}
+
+func (suite *TimelineServiceTestSuite) TestGetTimeline_ClientNotFound() {
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(nil, errorx.ErrNotFound)
+
+	points, err := suite.timelineService.GetTimeline(context.Background(), "client-id", "netWorth", &model.GetTimelineQuery{})
+
+	suite.ErrorIs(err, errorx.ErrNotFound)
+	suite.Nil(points)
+}
+
+func (suite *TimelineServiceTestSuite) TestGetTimeline_RepoError() {
+	query := &model.GetTimelineQuery{}
+
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+	suite.mockRepo.On("GetAll", mock.Anything, "client-id", "netWorth", query).Return(nil, assert.AnError)
+
+	points, err := suite.timelineService.GetTimeline(context.Background(), "client-id", "netWorth", query)
+
+	suite.ErrorIs(err, errorx.ErrInternal)
+	suite.Nil(points)
+}
+
+func (suite *TimelineServiceTestSuite) TestGetTimeline_CountError() {
+	query := &model.GetTimelineQuery{}
+
+	suite.mockClientRepo.On("GetOne", mock.Anything, "client-id").Return(&model.Client{}, nil)
+	suite.mockRepo.On("GetAll", mock.Anything, "client-id", "netWorth", query).Return([]model.TimelinePoint{}, nil)
+	suite.mockRepo.On("Count", mock.Anything, "client-id", "netWorth", query).Return(0, errorx.ErrDependencyFailed)
+
+	res, err := suite.timelineService.GetTimeline(context.Background(), "client-id", "netWorth", query)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+	suite.Nil(res)
+}
+
+func TestTimelineServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TimelineServiceTestSuite))
+}
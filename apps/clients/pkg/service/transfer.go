@@ -0,0 +1,184 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxImportLineSize bounds a single NDJSON record, scraped profiles can be large
+const maxImportLineSize = 16 * 1024 * 1024
+
+type TransferService struct {
+	clientRepository repository.ClientRepository
+	logService       LogServiceInterface
+}
+
+type TransferServiceInterface interface {
+	ImportClients(ctx context.Context, r io.Reader, query *model.ImportClientsQuery) (*model.ImportResult, error)
+	ExportClients(ctx context.Context, w io.Writer) (int, error)
+}
+
+func NewTransferService(clientRepository repository.ClientRepository, logService LogServiceInterface) *TransferService {
+	return &TransferService{clientRepository: clientRepository, logService: logService}
+}
+
+// ImportClients reads NDJSON client documents (extended JSON, as written by ExportClients) and upserts them.
+// Invalid lines are reported in the result and do not stop the import.
+func (s *TransferService) ImportClients(ctx context.Context, r io.Reader, query *model.ImportClientsQuery) (*model.ImportResult, error) {
+	if query.Mode == "" {
+		query.Mode = model.ImportModeID
+	}
+	if query.Mode != model.ImportModeID && query.Mode != model.ImportModeName {
+		return nil, fmt.Errorf("%w: unknown import mode '%s'", errorx.ErrInvalidInput, query.Mode)
+	}
+
+	result := &model.ImportResult{DryRun: query.DryRun}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		result.Total++
+
+		client, err := decodeImportLine(raw, query.Mode)
+		if err != nil {
+			recordImportError(result, line, err)
+			continue
+		}
+		result.Valid++
+
+		if query.DryRun {
+			continue
+		}
+
+		var inserted bool
+		if query.Mode == model.ImportModeID {
+			inserted, err = s.clientRepository.UpsertByID(ctx, client)
+		} else {
+			inserted, err = s.clientRepository.UpsertByName(ctx, client)
+		}
+		if err != nil {
+			if errors.Is(err, errorx.ErrDependencyFailed) {
+				return result, err
+			}
+			recordImportError(result, line, err)
+			continue
+		}
+
+		if inserted {
+			result.Inserted++
+		} else {
+			result.Updated++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("%w: error reading import stream: %v", errorx.ErrInvalidInput, err)
+	}
+
+	if !query.DryRun {
+		username := GetUsername(ctx)
+		_, err := s.logService.CreateLog(ctx, &model.Log{
+			Actor:     username,
+			Operation: model.OperationImport,
+			Details:   fmt.Sprintf("User %s imported %d clients (%d inserted, %d updated, %d failed) by %s", username, result.Total, result.Inserted, result.Updated, result.Failed, query.Mode),
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			log.Printf("error creating log: %v", err) // don't return error since it's not critical
+		}
+	}
+
+	return result, nil
+}
+
+// ExportClients writes every client as one extended JSON document per line and returns the number written
+func (s *TransferService) ExportClients(ctx context.Context, w io.Writer) (int, error) {
+	count := 0
+	err := s.clientRepository.ForEach(ctx, func(c *model.Client) error {
+		// canonical, so numbers keep their BSON type and dates their precision through an import
+		line, err := bson.MarshalExtJSON(c, true, false)
+		if err != nil {
+			return fmt.Errorf("%w: error encoding client %s", errorx.ErrInternal, c.ID.Hex())
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("%w: error writing export stream", errorx.ErrInternal)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errorx.ErrDependencyFailed) || errors.Is(err, errorx.ErrInternal) {
+			return count, err
+		}
+		return count, fmt.Errorf("%w: error exporting clients", errorx.ErrInternal)
+	}
+
+	username := GetUsername(ctx)
+	_, err = s.logService.CreateLog(ctx, &model.Log{
+		Actor:     username,
+		Operation: model.OperationExport,
+		Details:   fmt.Sprintf("User %s exported %d clients", username, count),
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("error creating log: %v", err) // don't return error since it's not critical
+	}
+
+	return count, nil
+}
+
+func decodeImportLine(raw []byte, mode model.ImportMode) (*model.Client, error) {
+	var client model.Client
+	if err := bson.UnmarshalExtJSON(raw, false, &client); err != nil {
+		return nil, fmt.Errorf("%w: malformed client document: %v", errorx.ErrInvalidInput, err)
+	}
+
+	if mode == model.ImportModeID && client.ID.IsZero() {
+		return nil, fmt.Errorf("%w: missing _id", errorx.ErrValidationFailed)
+	}
+	if !hasName(client.Data) {
+		return nil, fmt.Errorf("%w: missing data.profile.names", errorx.ErrValidationFailed)
+	}
+
+	now := time.Now().UTC()
+	if client.Metadata.CreatedAt.IsZero() {
+		client.Metadata.CreatedAt = now
+	}
+	client.Metadata.UpdatedAt = now
+
+	return &client, nil
+}
+
+func recordImportError(result *model.ImportResult, line int, err error) {
+	result.Failed++
+	result.Errors = append(result.Errors, model.ImportError{Line: line, Message: err.Error()})
+}
+
+func hasName(data bson.D) bool {
+	names, ok := lookupPath(data, "profile.names")
+	if !ok {
+		return false
+	}
+	list, ok := names.(bson.A)
+	if !ok || len(list) == 0 {
+		return false
+	}
+	name, ok := list[0].(string)
+	return ok && name != ""
+}
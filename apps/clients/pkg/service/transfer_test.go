@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type TransferServiceTestSuite struct {
+	suite.Suite
+	mockRepo        *mocks.ClientRepository
+	mockLog         *mocks.LogServiceInterface
+	transferService *service.TransferService
+}
+
+func (suite *TransferServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(mocks.ClientRepository)
+	suite.mockLog = new(mocks.LogServiceInterface)
+	suite.transferService = service.NewTransferService(suite.mockRepo, suite.mockLog)
+}
+
+const importLines = `{"_id":{"$oid":"65f1a2b3c4d5e6f708192a3b"},"data":{"profile":{"names":["Jane Doe"]}},"metadata":{"scraped":true},"articles":[]}
+
+{"data":{"profile":{"names":["No Id"]}}}
+not json
+{"_id":{"$oid":"65f1a2b3c4d5e6f708192a3c"},"data":{"profile":{"names":["John Roe"]}}}
+`
+
+func (suite *TransferServiceTestSuite) TestImportClients_ByID() {
+	suite.mockRepo.On("UpsertByID", mock.Anything, mock.MatchedBy(func(c *model.Client) bool {
+		return c.ID.Hex() == "65f1a2b3c4d5e6f708192a3b"
+	})).Return(true, nil)
+	suite.mockRepo.On("UpsertByID", mock.Anything, mock.MatchedBy(func(c *model.Client) bool {
+		return c.ID.Hex() == "65f1a2b3c4d5e6f708192a3c"
+	})).Return(false, nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.MatchedBy(func(l *model.Log) bool {
+		return l.Operation == model.OperationImport
+	})).Return("log-id", nil)
+
+	result, err := suite.transferService.ImportClients(context.Background(), strings.NewReader(importLines), &model.ImportClientsQuery{})
+
+	suite.NoError(err)
+	suite.Equal(4, result.Total)
+	suite.Equal(1, result.Inserted)
+	suite.Equal(1, result.Updated)
+	suite.Equal(2, result.Failed)
+	suite.Equal(3, result.Errors[0].Line)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockLog.AssertExpectations(suite.T())
+}
+
+func (suite *TransferServiceTestSuite) TestImportClients_DryRun() {
+	result, err := suite.transferService.ImportClients(context.Background(), strings.NewReader(importLines), &model.ImportClientsQuery{Mode: model.ImportModeName, DryRun: true})
+
+	suite.NoError(err)
+	suite.True(result.DryRun)
+	suite.Equal(3, result.Valid)
+	suite.Equal(1, result.Failed)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpsertByName", mock.Anything, mock.Anything)
+	suite.mockLog.AssertNotCalled(suite.T(), "CreateLog", mock.Anything, mock.Anything)
+}
+
+func (suite *TransferServiceTestSuite) TestImportClients_AmbiguousName() {
+	suite.mockRepo.On("UpsertByName", mock.Anything, mock.MatchedBy(func(c *model.Client) bool {
+		return c.ID.Hex() == "65f1a2b3c4d5e6f708192a3b"
+	})).Return(false, errorx.ErrConflict)
+	suite.mockRepo.On("UpsertByName", mock.Anything, mock.Anything).Return(true, nil)
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+
+	result, err := suite.transferService.ImportClients(context.Background(), strings.NewReader(importLines), &model.ImportClientsQuery{Mode: model.ImportModeName})
+
+	// the ambiguous line is reported and the rest still imported
+	suite.NoError(err)
+	suite.Equal(2, result.Inserted)
+	suite.Equal(2, result.Failed)
+	suite.Equal(1, result.Errors[0].Line)
+	suite.Contains(result.Errors[0].Message, errorx.ErrConflict.Error())
+}
+
+func (suite *TransferServiceTestSuite) TestImportClients_InvalidMode() {
+	result, err := suite.transferService.ImportClients(context.Background(), strings.NewReader(importLines), &model.ImportClientsQuery{Mode: "email"})
+
+	suite.ErrorIs(err, errorx.ErrInvalidInput)
+	suite.Nil(result)
+}
+
+func (suite *TransferServiceTestSuite) TestImportClients_DependencyFailed() {
+	suite.mockRepo.On("UpsertByName", mock.Anything, mock.Anything).Return(false, errorx.ErrDependencyFailed)
+
+	_, err := suite.transferService.ImportClients(context.Background(), strings.NewReader(importLines), &model.ImportClientsQuery{Mode: model.ImportModeName})
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func (suite *TransferServiceTestSuite) TestExportClients() {
+	clients := []*model.Client{
+		{ID: bson.NewObjectID(), Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"Jane Doe"}}, {Key: "age", Value: int32(42)}}}}, Articles: []bson.ObjectID{bson.NewObjectID()}},
+		{ID: bson.NewObjectID(), Data: bson.D{{Key: "profile", Value: bson.D{{Key: "names", Value: bson.A{"John Roe"}}}}}},
+	}
+	suite.mockRepo.On("ForEach", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(*model.Client) error) error {
+		for _, c := range clients {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	suite.mockLog.On("CreateLog", mock.Anything, mock.Anything).Return("log-id", nil)
+
+	var buf bytes.Buffer
+	count, err := suite.transferService.ExportClients(context.Background(), &buf)
+
+	suite.NoError(err)
+	suite.Equal(2, count)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	suite.Len(lines, 2)
+	suite.Contains(lines[0], `"articles":[{"$oid"`)
+	// canonical, so types survive the round trip
+	suite.Contains(lines[0], `"age":{"$numberInt":"42"}`)
+	suite.Contains(lines[0], `{"$date":{"$numberLong":`)
+
+	// exported lines must import cleanly
+	suite.mockRepo.On("UpsertByID", mock.Anything, mock.Anything).Return(false, nil)
+	result, err := suite.transferService.ImportClients(context.Background(), &buf, &model.ImportClientsQuery{Mode: model.ImportModeID, DryRun: true})
+	suite.NoError(err)
+	suite.Equal(2, result.Valid)
+}
+
+func (suite *TransferServiceTestSuite) TestExportClients_RepoError() {
+	suite.mockRepo.On("ForEach", mock.Anything, mock.Anything).Return(errorx.ErrDependencyFailed)
+
+	var buf bytes.Buffer
+	_, err := suite.transferService.ExportClients(context.Background(), &buf)
+
+	suite.ErrorIs(err, errorx.ErrDependencyFailed)
+}
+
+func TestTransferServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TransferServiceTestSuite))
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+
+	"github.com/owjoel/client-factpack/apps/clients/config"
 )
 
 func GetUsername(ctx context.Context) string {
@@ -11,3 +13,14 @@ func GetUsername(ctx context.Context) string {
 	}
 	return username
 }
+
+// IsAdmin reports whether the caller belongs to the admin group
+func IsAdmin(ctx context.Context) bool {
+	groups, _ := ctx.Value("groups").([]string)
+	for _, g := range groups {
+		if g == config.AdminGroup {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+)
+
+// WorkflowBackend runs the workflow that does a job's work, e.g. a Prefect flow run of the job's deployment.
+// Runs report their progress back through JobService.HandleCallback.
+type WorkflowBackend interface {
+	Trigger(ctx context.Context, run WorkflowRun) (runID string, err error)
+	Cancel(ctx context.Context, runID string) error
+	// State returns the Prefect state type of the run, e.g. RUNNING, COMPLETED or CRASHED
+	State(ctx context.Context, runID string) (string, error)
+}
+
+// WorkflowRun is a request to run the workflow for a job
+type WorkflowRun struct {
+	JobType    model.JobType
+	Deployment string
+	Params     map[string]interface{}
+}
+
+// Workflow backends selectable with the WORKFLOW_BACKEND setting
+const (
+	WorkflowBackendPrefect = "prefect"
+	WorkflowBackendLocal   = "local"
+)
+
+// Prefect state types reported by the workflow backends
+const (
+	RunStateRunning   = "RUNNING"
+	RunStateCompleted = "COMPLETED"
+	RunStateFailed    = "FAILED"
+	RunStateCancelled = "CANCELLED"
+)
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+type ActivityHandler struct {
+	service service.ActivityServiceInterface
+}
+
+func NewActivityHandler(service service.ActivityServiceInterface) *ActivityHandler {
+	return &ActivityHandler{service: service}
+}
+
+// GetActivity retrieves everything that happened to a client in one feed
+//
+//	@Summary		Get Client Activity
+//	@Description	Audit logs, jobs, article additions, notes and field changes merged newest first
+//	@Tags			clients
+//	@Produce		json
+//	@Param			id			path		string		true	"Hex id used to identify client"
+//	@Param			type		query		[]string	false	"Activity types to include (log, job, article, note, change)"	collectionFormat(multi)
+//	@Param			from		query		string		false	"Start of time range (RFC3339)"
+//	@Param			to			query		string		false	"End of time range (RFC3339)"
+//	@Param			page		query		int			false	"Page number"
+//	@Param			pageSize	query		int			false	"Page size"
+//	@Success		200			{object}	handlers.Response{data=model.GetActivityResponse}
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		404			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/:id/activity [get]
+func (h *ActivityHandler) GetActivity(c *gin.Context) {
+	clientID := c.Param("id")
+	if clientID == "" {
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Missing id"})
+		return
+	}
+
+	query := &model.GetActivityQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	res, err := h.service.GetActivity(c.Request.Context(), clientID, query)
+	if err != nil {
+		log.Printf("Failed to retrieve activity (ID: %s): %v", clientID, err)
+		ErrorHandler(c, err, "Could not retrieve activity")
+		return
+	}
+
+	resp(c, http.StatusOK, res)
+}
+
+// AddNote attaches a note to a client
+//
+//	@Summary		Add Client Note
+//	@Description	Attach a free text note to a client, shown in the activity feed
+//	@Tags			clients
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Hex id used to identify client"
+//	@Param			note	body		model.CreateNoteReq	true	"Note text"
+//	@Success		201		{object}	handlers.Response{data=model.Note}
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		404		{object}	handlers.Response
+//	@Failure		422		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Failure		502		{object}	handlers.Response
+//	@Router			/:id/notes [post]
+func (h *ActivityHandler) AddNote(c *gin.Context) {
+	clientID := c.Param("id")
+
+	req := &model.CreateNoteReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Printf("Failed to bind request: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid request"})
+		return
+	}
+
+	note, err := h.service.AddNote(c.Request.Context(), clientID, req)
+	if err != nil {
+		log.Printf("Failed to add note (ID: %s): %v", clientID, err)
+		ErrorHandler(c, err, "Could not add note")
+		return
+	}
+
+	resp(c, http.StatusCreated, note)
+}
@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ActivityHandlerTestSuite struct {
+	suite.Suite
+	mockSvc *mocks.ActivityServiceInterface
+	handler *handlers.ActivityHandler
+	router  *gin.Engine
+}
+
+func (suite *ActivityHandlerTestSuite) SetupTest() {
+	suite.mockSvc = new(mocks.ActivityServiceInterface)
+	suite.handler = handlers.NewActivityHandler(suite.mockSvc)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.GET("/:id/activity", suite.handler.GetActivity)
+	suite.router.POST("/:id/notes", suite.handler.AddNote)
+}
+
+func (suite *ActivityHandlerTestSuite) TestGetActivity_Success() {
+	suite.mockSvc.On("GetActivity", mock.Anything, "abc", mock.MatchedBy(func(q *model.GetActivityQuery) bool {
+		return len(q.Types) == 2 && q.Types[0] == model.ActivityJob && q.Page == 2
+	})).Return(&model.GetActivityResponse{Total: 1, Items: []model.ActivityItem{{Type: model.ActivityJob, Action: "scrape"}}}, nil)
+
+	req, _ := http.NewRequest("GET", "/abc/activity?type=job&type=note&page=2", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"action":"scrape"`)
+}
+
+func (suite *ActivityHandlerTestSuite) TestGetActivity_InvalidType() {
+	suite.mockSvc.On("GetActivity", mock.Anything, "abc", mock.Anything).Return(nil, errorx.ErrInvalidInput)
+
+	req, _ := http.NewRequest("GET", "/abc/activity?type=email", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (suite *ActivityHandlerTestSuite) TestAddNote_Success() {
+	suite.mockSvc.On("AddNote", mock.Anything, "abc", &model.CreateNoteReq{Text: "called client"}).Return(&model.Note{ClientID: "abc", Text: "called client"}, nil)
+
+	req, _ := http.NewRequest("POST", "/abc/notes", bytes.NewBufferString(`{"text":"called client"}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusCreated, w.Code)
+}
+
+func (suite *ActivityHandlerTestSuite) TestAddNote_ClientNotFound() {
+	suite.mockSvc.On("AddNote", mock.Anything, "abc", mock.Anything).Return(nil, errorx.ErrNotFound)
+
+	req, _ := http.NewRequest("POST", "/abc/notes", bytes.NewBufferString(`{"text":"called client"}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestActivityHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ActivityHandlerTestSuite))
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -32,3 +33,243 @@ func (h *ArticleHandler) GetAllArticles(c *gin.Context) {
 
 	resp(c, http.StatusOK, model.GetArticlesRes{Articles: articles})
 }
+
+// GetClientArticles retrieves the articles linked to a client
+//
+//	@Summary		Get Client Articles
+//	@Description	Page through a client's articles, sorted by published date and filtered by source or keyword
+//	@Tags			articles
+//	@Produce		json
+//	@Param			id			path		string	true	"Hex id used to identify client"
+//	@Param			source		query		string	false	"Article source"
+//	@Param			keyword		query		string	false	"Keyword matched against title and summary"
+//	@Param			sort		query		string	false	"Published date order (asc, desc)"
+//	@Param			page		query		int		false	"Page number"
+//	@Param			pageSize	query		int		false	"Page size"
+//	@Success		200			{object}	handlers.Response{data=model.GetClientArticlesResponse}
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		404			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/:id/articles [get]
+func (h *ArticleHandler) GetClientArticles(c *gin.Context) {
+	clientID := c.Param("id")
+	if clientID == "" {
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Missing id"})
+		return
+	}
+
+	query := &model.GetClientArticlesQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	res, err := h.service.GetClientArticles(c.Request.Context(), clientID, query)
+	if err != nil {
+		log.Printf("Failed to retrieve articles for client (ID: %s): %v", clientID, err)
+		ErrorHandler(c, err, "Could not retrieve articles")
+		return
+	}
+
+	resp(c, http.StatusOK, res)
+}
+
+// GetClientSentiment aggregates the sentiment of a client's articles
+//
+//	@Summary		Get Client Sentiment
+//	@Description	Sentiment counts by label, average score, trend and daily breakdown over a rolling window
+//	@Tags			articles
+//	@Produce		json
+//	@Param			id		path		string	true	"Hex id used to identify client"
+//	@Param			days	query		int		false	"Window size in days (default 30)"
+//	@Success		200		{object}	handlers.Response{data=model.ClientSentimentResponse}
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		404		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Failure		502		{object}	handlers.Response
+//	@Router			/:id/sentiment [get]
+func (h *ArticleHandler) GetClientSentiment(c *gin.Context) {
+	clientID := c.Param("id")
+	if clientID == "" {
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Missing id"})
+		return
+	}
+
+	query := &model.GetSentimentQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	res, err := h.service.GetClientSentiment(c.Request.Context(), clientID, query)
+	if err != nil {
+		log.Printf("Failed to aggregate sentiment for client (ID: %s): %v", clientID, err)
+		ErrorHandler(c, err, "Could not retrieve sentiment")
+		return
+	}
+
+	resp(c, http.StatusOK, res)
+}
+
+// CreateArticle ingests an article and links it to clients
+//
+//	@Summary		Create Article
+//	@Description	Store an article, de-duplicated on its canonical URL, and link it to the given clients. The Prefect flows ingest through the signed /internal/articles instead.
+//	@Tags			articles
+//	@Accept			json
+//	@Produce		json
+//	@Param			article	body		model.CreateArticleReq	true	"Article to ingest"
+//	@Success		201		{object}	handlers.Response{data=model.CreateArticleRes}
+//	@Success		200		{object}	handlers.Response{data=model.CreateArticleRes}
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		404		{object}	handlers.Response
+//	@Failure		422		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Failure		502		{object}	handlers.Response
+//	@Router			/articles/ingest [post]
+func (h *ArticleHandler) CreateArticle(c *gin.Context) {
+	req := &model.CreateArticleReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Printf("Failed to bind request: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid request"})
+		return
+	}
+
+	res, err := h.service.CreateArticle(c.Request.Context(), req)
+	if err != nil {
+		log.Printf("Failed to ingest article (URL: %s): %v", req.URL, err)
+		ErrorHandler(c, err, "Could not ingest article")
+		return
+	}
+
+	// an existing article is only linked, nothing new is created
+	code := http.StatusCreated
+	if res.Duplicate {
+		code = http.StatusOK
+	}
+	resp(c, code, res)
+}
+
+// LinkArticle attaches an existing article to a client
+//
+//	@Summary		Link Article
+//	@Description	Manually link an article to a client
+//	@Tags			articles
+//	@Produce		json
+//	@Param			id			path		string	true	"Hex id used to identify client"
+//	@Param			articleId	path		string	true	"Hex id used to identify article"
+//	@Success		200			{object}	handlers.Response
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		404			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/:id/articles/:articleId [post]
+func (h *ArticleHandler) LinkArticle(c *gin.Context) {
+	clientID, articleID := c.Param("id"), c.Param("articleId")
+
+	if err := h.service.LinkArticle(c.Request.Context(), clientID, articleID); err != nil {
+		log.Printf("Failed to link article %s to client (ID: %s): %v", articleID, clientID, err)
+		ErrorHandler(c, err, "Could not link article")
+		return
+	}
+
+	resp(c, http.StatusOK, "Article linked")
+}
+
+// UnlinkArticle detaches an article from a client
+//
+//	@Summary		Unlink Article
+//	@Description	Remove an article from a client without recording feedback
+//	@Tags			articles
+//	@Produce		json
+//	@Param			id			path		string	true	"Hex id used to identify client"
+//	@Param			articleId	path		string	true	"Hex id used to identify article"
+//	@Success		200			{object}	handlers.Response
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		404			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/:id/articles/:articleId [delete]
+func (h *ArticleHandler) UnlinkArticle(c *gin.Context) {
+	clientID, articleID := c.Param("id"), c.Param("articleId")
+
+	if err := h.service.UnlinkArticle(c.Request.Context(), clientID, articleID); err != nil {
+		log.Printf("Failed to unlink article %s from client (ID: %s): %v", articleID, clientID, err)
+		ErrorHandler(c, err, "Could not unlink article")
+		return
+	}
+
+	resp(c, http.StatusOK, "Article unlinked")
+}
+
+// ReviewArticle records whether an article is relevant to a client
+//
+//	@Summary		Review Article
+//	@Description	Mark an article relevant or irrelevant to a client. Irrelevant articles need a reason and are unlinked.
+//	@Tags			articles
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string						true	"Hex id used to identify client"
+//	@Param			articleId	path		string						true	"Hex id used to identify article"
+//	@Param			feedback	body		model.ArticleFeedbackReq	true	"Relevance verdict"
+//	@Success		200			{object}	handlers.Response{data=model.ArticleFeedback}
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		404			{object}	handlers.Response
+//	@Failure		422			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/:id/articles/:articleId/feedback [put]
+func (h *ArticleHandler) ReviewArticle(c *gin.Context) {
+	clientID, articleID := c.Param("id"), c.Param("articleId")
+
+	req := &model.ArticleFeedbackReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Printf("Failed to bind request: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid request"})
+		return
+	}
+
+	feedback, err := h.service.ReviewArticle(c.Request.Context(), clientID, articleID, req)
+	if err != nil {
+		log.Printf("Failed to review article %s for client (ID: %s): %v", articleID, clientID, err)
+		ErrorHandler(c, err, "Could not record feedback")
+		return
+	}
+
+	resp(c, http.StatusOK, feedback)
+}
+
+// GetFeedbackStats reports false positive rates of reviewed articles
+//
+//	@Summary		Get Article Feedback Stats
+//	@Description	Reviewed and irrelevant article counts with false positive rates, overall and per source
+//	@Tags			articles
+//	@Produce		json
+//	@Param			source	query		string	false	"Article source"
+//	@Param			from	query		string	false	"Reviewed at or after (RFC3339)"
+//	@Param			to		query		string	false	"Reviewed at or before (RFC3339)"
+//	@Success		200		{object}	handlers.Response{data=model.GetFeedbackStatsResponse}
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Failure		502		{object}	handlers.Response
+//	@Router			/articles/feedback/stats [get]
+func (h *ArticleHandler) GetFeedbackStats(c *gin.Context) {
+	query := &model.GetFeedbackStatsQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	res, err := h.service.GetFeedbackStats(c.Request.Context(), query)
+	if err != nil {
+		log.Printf("Failed to get article feedback stats: %v", err)
+		ErrorHandler(c, err, "Could not retrieve feedback stats")
+		return
+	}
+
+	resp(c, http.StatusOK, res)
+}
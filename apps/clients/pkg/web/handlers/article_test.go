@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
@@ -32,6 +33,13 @@ func (suite *ArticleHandlerTestSuite) SetupTest() {
 	suite.router = gin.New()
 	
 	suite.router.POST("/articles", suite.handler.GetAllArticles)
+	suite.router.POST("/articles/ingest", suite.handler.CreateArticle)
+	suite.router.GET("/articles/feedback/stats", suite.handler.GetFeedbackStats)
+	suite.router.POST("/clients/:id/articles/:articleId", suite.handler.LinkArticle)
+	suite.router.DELETE("/clients/:id/articles/:articleId", suite.handler.UnlinkArticle)
+	suite.router.PUT("/clients/:id/articles/:articleId/feedback", suite.handler.ReviewArticle)
+	suite.router.GET("/clients/:id/articles", suite.handler.GetClientArticles)
+	suite.router.GET("/clients/:id/sentiment", suite.handler.GetClientSentiment)
 }
 
 func (suite *ArticleHandlerTestSuite) TestGetAllArticles_Success() {
@@ -66,6 +74,161 @@ func (suite *ArticleHandlerTestSuite) TestGetAllArticles_BindError() {
 	assert.Contains(suite.T(), w.Body.String(), "Invalid request body")
 }
 
+func (suite *ArticleHandlerTestSuite) TestGetClientArticles_Success() {
+	expectedQuery := &model.GetClientArticlesQuery{Source: "Reuters", Keyword: "merger", Sort: "asc", Page: 2, PageSize: 5}
+	suite.mockSvc.On("GetClientArticles", mock.Anything, "abc", expectedQuery).Return(&model.GetClientArticlesResponse{
+		Total:    6,
+		Page:     2,
+		PageSize: 5,
+		Sources:  map[string]int{"Reuters": 6},
+		Articles: []model.Article{{Title: "Merger news", Source: "Reuters"}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/clients/abc/articles?source=Reuters&keyword=merger&sort=asc&page=2&pageSize=5", nil)
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"total":6`)
+}
+
+func (suite *ArticleHandlerTestSuite) TestGetClientArticles_NotFound() {
+	suite.mockSvc.On("GetClientArticles", mock.Anything, "abc", mock.Anything).Return(nil, errorx.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/clients/abc/articles", nil)
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *ArticleHandlerTestSuite) TestGetClientSentiment_Success() {
+	suite.mockSvc.On("GetClientSentiment", mock.Anything, "abc", &model.GetSentimentQuery{Days: 7}).Return(&model.ClientSentimentResponse{
+		ClientID: "abc",
+		Days:     7,
+		Total:    3,
+		Counts:   map[string]int{"negative": 3},
+		Trend:    model.SentimentTrendStable,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/clients/abc/sentiment?days=7", nil)
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"negative":3`)
+}
+
+func (suite *ArticleHandlerTestSuite) TestGetClientSentiment_InvalidWindow() {
+	suite.mockSvc.On("GetClientSentiment", mock.Anything, "abc", mock.Anything).Return(nil, errorx.ErrInvalidInput)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/clients/abc/sentiment?days=1000", nil)
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *ArticleHandlerTestSuite) TestCreateArticle_Created() {
+	suite.mockSvc.On("CreateArticle", mock.Anything, mock.MatchedBy(func(r *model.CreateArticleReq) bool {
+		return r.URL == "https://example.com/a" && len(r.ClientIDs) == 1
+	})).Return(&model.CreateArticleRes{ID: "article-id", ClientIDs: []string{"client-id"}}, nil)
+
+	w := httptest.NewRecorder()
+	body := `{"source":"Reuters","title":"Story","url":"https://example.com/a","clientIds":["client-id"]}`
+	req, _ := http.NewRequest("POST", "/articles/ingest", bytes.NewBufferString(body))
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), "article-id")
+}
+
+func (suite *ArticleHandlerTestSuite) TestCreateArticle_Duplicate() {
+	suite.mockSvc.On("CreateArticle", mock.Anything, mock.Anything).Return(&model.CreateArticleRes{ID: "article-id", Duplicate: true}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/articles/ingest", bytes.NewBufferString(`{"url":"https://example.com/a"}`))
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+func (suite *ArticleHandlerTestSuite) TestCreateArticle_ValidationFailed() {
+	suite.mockSvc.On("CreateArticle", mock.Anything, mock.Anything).Return(nil, errorx.ErrValidationFailed)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/articles/ingest", bytes.NewBufferString(`{"url":"not a url"}`))
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (suite *ArticleHandlerTestSuite) TestCreateArticle_BindError() {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/articles/ingest", bytes.NewBufferString(`{"url":`))
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.mockSvc.AssertNotCalled(suite.T(), "CreateArticle", mock.Anything, mock.Anything)
+}
+
+func (suite *ArticleHandlerTestSuite) TestLinkArticle_Success() {
+	suite.mockSvc.On("LinkArticle", mock.Anything, "client-id", "article-id").Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/clients/client-id/articles/article-id", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+func (suite *ArticleHandlerTestSuite) TestUnlinkArticle_NotLinked() {
+	suite.mockSvc.On("UnlinkArticle", mock.Anything, "client-id", "article-id").Return(errorx.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/clients/client-id/articles/article-id", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+func (suite *ArticleHandlerTestSuite) TestReviewArticle_Success() {
+	suite.mockSvc.On("ReviewArticle", mock.Anything, "client-id", "article-id", mock.MatchedBy(func(r *model.ArticleFeedbackReq) bool {
+		return r.Relevant != nil && !*r.Relevant && r.Reason == "different person"
+	})).Return(&model.ArticleFeedback{ClientID: "client-id", ArticleID: "article-id", Reason: "different person"}, nil)
+
+	w := httptest.NewRecorder()
+	body := `{"relevant":false,"reason":"different person"}`
+	req, _ := http.NewRequest("PUT", "/clients/client-id/articles/article-id/feedback", bytes.NewBufferString(body))
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), "different person")
+}
+
+func (suite *ArticleHandlerTestSuite) TestReviewArticle_ValidationFailed() {
+	suite.mockSvc.On("ReviewArticle", mock.Anything, "client-id", "article-id", mock.Anything).Return(nil, errorx.ErrValidationFailed)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/clients/client-id/articles/article-id/feedback", bytes.NewBufferString(`{"relevant":false}`))
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (suite *ArticleHandlerTestSuite) TestGetFeedbackStats_Success() {
+	suite.mockSvc.On("GetFeedbackStats", mock.Anything, mock.MatchedBy(func(q *model.GetFeedbackStatsQuery) bool {
+		return q.Source == "Reuters"
+	})).Return(&model.GetFeedbackStatsResponse{FeedbackStats: model.FeedbackStats{Reviewed: 2, Irrelevant: 1, FalsePositiveRate: 0.5}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/articles/feedback/stats?source=Reuters", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"falsePositiveRate":0.5`)
+}
+
 func TestArticleHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(ArticleHandlerTestSuite))
 }
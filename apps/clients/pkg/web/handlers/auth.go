@@ -154,6 +154,7 @@ func Authenticate(getJWKS func(string, string) (*keyfunc.JWKS, error)) gin.Handl
 			}
 		}
 		c.Set("groups", userGroupsClaims)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), "groups", userGroupsClaims))
 
 		c.Next()
 		c.Set("accessToken", token)
@@ -161,6 +162,24 @@ func Authenticate(getJWKS func(string, string) (*keyfunc.JWKS, error)) gin.Handl
 	}
 }
 
+// RequireGroup is a middleware that only lets through users belonging to the given Cognito group.
+// It relies on the groups set by Authenticate, so it must be registered after it.
+func RequireGroup(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groups, _ := c.Get("groups")
+		userGroups, _ := groups.([]string)
+		for _, g := range userGroups {
+			if g == group {
+				c.Next()
+				return
+			}
+		}
+
+		resp(c, http.StatusForbidden, errorx.ErrForbidden.Error())
+		c.Abort()
+	}
+}
+
 // GetJWKS retrieves Cognito JSON Web Key Set (JWKS) for verifying JWTs.
 func GetJWKS(awsRegion string, cognitoUserPoolId string) (*keyfunc.JWKS, error) {
 	jwksURL := fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s/.well-known/jwks.json", awsRegion, cognitoUserPoolId)
@@ -365,3 +365,23 @@ func TestAuthenticate_MissingExpClaim(t *testing.T) {
 func base64url(data []byte) string {
 	return base64.RawURLEncoding.EncodeToString(data)
 }
+
+func TestRequireGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(groups []string) *gin.Engine {
+		r := gin.New()
+		r.Use(func(c *gin.Context) { c.Set("groups", groups) }, handlers.RequireGroup("admin"))
+		r.GET("/admin", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "success"})
+		})
+		return r
+	}
+
+	w := httptest.NewRecorder()
+	newRouter([]string{"advisor", "admin"}).ServeHTTP(w, httptest.NewRequest("GET", "/admin", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	newRouter([]string{"advisor"}).ServeHTTP(w, httptest.NewRequest("GET", "/admin", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
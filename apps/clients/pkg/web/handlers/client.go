@@ -75,6 +75,7 @@ func (h *ClientHandler) GetClient(c *gin.Context) {
 //	@Param			page	query		int		true	"Page number"
 //	@Param			pageSize	query		int		true	"Page size"
 //	@Param			sort	query		bool	false	"Sort by name"
+//	@Param			negativePressDays	query		int	false	"Only clients with negative press in the last N days"
 //	@Success		200	{object}	handlers.Response{data=[]model.Client}
 //	@Failure		400	{object}	handlers.Response
 //	@Failure		500	{object}	handlers.Response
@@ -207,6 +208,40 @@ func (h *ClientHandler) RescrapeClient(c *gin.Context) {
 	resp(c, http.StatusOK, model.StatusRes{Status: "Client rescraped"})
 }
 
+// RescrapeClients rescrapes several client profiles as one batch job
+//
+//	@Summary		Rescrape Clients
+//	@Description	Start a batch job with a rescrape job for each client. Track it with GET /jobs/:id and its children with GET /jobs/:id/children.
+//	@Tags			clients
+//	@Accept			json
+//	@Produce		json
+//	@Param			clients	body		model.RescrapeClientsReq	true	"Ids of the clients to rescrape, at most 100"
+//	@Success		201		{object}	handlers.Response{data=model.Job}
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		404		{object}	handlers.Response
+//	@Failure		422		{object}	handlers.Response
+//	@Failure		429		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Failure		502		{object}	handlers.Response
+//	@Router			/rescrape [post]
+func (h *ClientHandler) RescrapeClients(c *gin.Context) {
+	req := &model.RescrapeClientsReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Printf("Failed to bind request: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid request"})
+		return
+	}
+
+	batch, err := h.service.RescrapeClients(c.Request.Context(), req)
+	if err != nil {
+		log.Printf("Failed to rescrape clients: %v", err)
+		ErrorHandler(c, err, "Could not rescrape clients")
+		return
+	}
+
+	resp(c, http.StatusCreated, batch)
+}
+
 // MatchClient matches a client profile
 //
 //	@Summary		Match Client
@@ -274,3 +309,39 @@ func (h *ClientHandler) MatchClient(c *gin.Context) {
 
 	resp(c, http.StatusOK, model.JobIDRes{JobID: id})
 }
+
+// SetLegalHold places or lifts a legal hold on a client
+//
+//	@Summary		Set Legal Hold
+//	@Description	Place a legal hold on a client, which stops its logs from being purged however old they are, or lift it. A reason is required to place a hold.
+//	@Tags			admin
+//	@Accept			application/json
+//	@Produce		json
+//	@Param			id		path		string					true	"Client ID"
+//	@Param			hold	body		model.SetLegalHoldReq	true	"Hold, and the reason for placing it"
+//	@Success		200		{object}	handlers.Response
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		403		{object}	handlers.Response
+//	@Failure		404		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Router			/admin/clients/{id}/legal-hold [put]
+func (h *ClientHandler) SetLegalHold(c *gin.Context) {
+	req := &model.SetLegalHoldReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Printf("Failed to bind request: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid request"})
+		return
+	}
+
+	if err := h.service.SetLegalHold(c.Request.Context(), c.Param("id"), req); err != nil {
+		log.Printf("Failed to set legal hold: %v", err)
+		ErrorHandler(c, err, "Could not set legal hold")
+		return
+	}
+
+	status := "Legal hold lifted"
+	if *req.Hold {
+		status = "Legal hold placed"
+	}
+	resp(c, http.StatusOK, model.StatusRes{Status: status})
+}
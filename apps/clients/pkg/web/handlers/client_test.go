@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
@@ -38,6 +39,8 @@ func (suite *ClientHandlerTestSuite) SetupTest() {
 	suite.router.PUT("/:id", suite.handler.UpdateClient)
 	suite.router.POST("/:id/match", suite.handler.MatchClient)
 	suite.router.POST("/:id/scrape", suite.handler.RescrapeClient)
+	suite.router.POST("/rescrape", suite.handler.RescrapeClients)
+	suite.router.PUT("/:id/legal-hold", suite.handler.SetLegalHold)
 }
 
 func (suite *ClientHandlerTestSuite) TestHealthCheck() {
@@ -349,6 +352,79 @@ func (suite *ClientHandlerTestSuite) TestRescrapeClient_Success() {
 	assert.Contains(suite.T(), w.Body.String(), "Client rescraped")
 }
 
+func (suite *ClientHandlerTestSuite) TestRescrapeClients_Success() {
+	batchID := bson.NewObjectID()
+	suite.mockSvc.On("RescrapeClients", mock.Anything, &model.RescrapeClientsReq{ClientIDs: []string{"a", "b"}}).
+		Return(&model.Job{ID: batchID, Type: model.Batch, Status: model.JobStatusPending}, nil)
+
+	req, _ := http.NewRequest("POST", "/rescrape", bytes.NewBufferString(`{"clientIds":["a","b"]}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), batchID.Hex())
+}
+
+func (suite *ClientHandlerTestSuite) TestRescrapeClients_Errors() {
+	req, _ := http.NewRequest("POST", "/rescrape", bytes.NewBufferString(`{"clientIds":"a"}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	suite.mockSvc.On("RescrapeClients", mock.Anything, mock.Anything).Return(nil, errorx.ErrValidationFailed)
+	req, _ = http.NewRequest("POST", "/rescrape", bytes.NewBufferString(`{"clientIds":[]}`))
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+}
+
+func (suite *ClientHandlerTestSuite) TestSetLegalHold_Place() {
+	suite.mockSvc.On("SetLegalHold", mock.Anything, "abc", mock.MatchedBy(func(req *model.SetLegalHoldReq) bool {
+		return *req.Hold && req.Reason == "litigation"
+	})).Return(nil)
+
+	req, _ := http.NewRequest("PUT", "/abc/legal-hold", bytes.NewBufferString(`{"hold":true,"reason":"litigation"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "Legal hold placed")
+}
+
+func (suite *ClientHandlerTestSuite) TestSetLegalHold_Lift() {
+	suite.mockSvc.On("SetLegalHold", mock.Anything, "abc", mock.Anything).Return(nil)
+
+	req, _ := http.NewRequest("PUT", "/abc/legal-hold", bytes.NewBufferString(`{"hold":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "Legal hold lifted")
+}
+
+func (suite *ClientHandlerTestSuite) TestSetLegalHold_MissingHold() {
+	req, _ := http.NewRequest("PUT", "/abc/legal-hold", bytes.NewBufferString(`{"reason":"litigation"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	suite.mockSvc.AssertNotCalled(suite.T(), "SetLegalHold", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ClientHandlerTestSuite) TestSetLegalHold_NotFound() {
+	suite.mockSvc.On("SetLegalHold", mock.Anything, "abc", mock.Anything).Return(errorx.ErrNotFound)
+
+	req, _ := http.NewRequest("PUT", "/abc/legal-hold", bytes.NewBufferString(`{"hold":true,"reason":"litigation"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
 func TestClientHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(ClientHandlerTestSuite))
 }
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotentBodySize    = 32 << 20
+)
+
+// Idempotency is a middleware that makes a request safe to repeat when it carries an Idempotency-Key header.
+// The first response for a key is stored and replayed for repeats of the same request. Reusing the key for a
+// different body, or while the first request is still running, gets a 409. Server errors are not stored, so
+// the request can be retried with the same key. Requests without the header pass straight through.
+// It must run after Authenticate, keys are scoped to the user.
+func Idempotency(idempotencyService service.IdempotencyServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Could not read request body"})
+			c.Abort()
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			resp(c, http.StatusRequestEntityTooLarge, model.ErrorResponse{Message: "Request body too large"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		record, replay, err := idempotencyService.Begin(ctx, key, c.Request.Method, c.Request.URL.Path, requestHash(c.ContentType(), c.GetHeader("Content-Type"), body))
+		if err != nil {
+			log.Printf("Failed to begin idempotent request (key: %s): %v", key, err)
+			ErrorHandler(c, err, "Idempotency-Key is in use by another request")
+			c.Abort()
+			return
+		}
+		if replay {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// the client may have gone away by now, but the key must still be released or completed
+		ctx = context.WithoutCancel(ctx)
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			if err := idempotencyService.Release(ctx, record); err != nil {
+				log.Printf("error releasing idempotency key %s: %v", key, err)
+			}
+			return
+		}
+		if err := idempotencyService.Complete(ctx, record, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			log.Printf("error storing idempotent response for key %s: %v", key, err)
+		}
+	}
+}
+
+// requestHash fingerprints a request body. Multipart boundaries are random per request, so they are left out
+// and a resubmitted form hashes the same.
+func requestHash(mediaType string, contentType string, body []byte) string {
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if _, params, err := mime.ParseMediaType(contentType); err == nil && params["boundary"] != "" {
+			body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+		}
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,147 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotencyTestSuite struct {
+	suite.Suite
+	mockSvc *mocks.IdempotencyServiceInterface
+	router  *gin.Engine
+	status  int
+	calls   int
+}
+
+func (suite *IdempotencyTestSuite) SetupTest() {
+	suite.mockSvc = new(mocks.IdempotencyServiceInterface)
+	suite.status = http.StatusCreated
+	suite.calls = 0
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.POST("/clients/scrape", handlers.Idempotency(suite.mockSvc), func(c *gin.Context) {
+		suite.calls++
+		c.JSON(suite.status, gin.H{"jobId": "job-1"})
+	})
+}
+
+func (suite *IdempotencyTestSuite) post(key string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/clients/scrape", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(handlers.IdempotencyKeyHeader, key)
+	}
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *IdempotencyTestSuite) TestNoKey() {
+	w := suite.post("", `{"name":"Jane Doe"}`)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Equal(1, suite.calls)
+	suite.mockSvc.AssertNotCalled(suite.T(), "Begin", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *IdempotencyTestSuite) TestFirstRequestIsStored() {
+	record := &model.IdempotencyRecord{ID: "id"}
+	suite.mockSvc.On("Begin", mock.Anything, "key-1", "POST", "/clients/scrape", mock.Anything).Return(record, false, nil)
+	suite.mockSvc.On("Complete", mock.Anything, record, http.StatusCreated, "application/json; charset=utf-8", []byte(`{"jobId":"job-1"}`)).Return(nil)
+
+	w := suite.post("key-1", `{"name":"Jane Doe"}`)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Equal(1, suite.calls)
+	suite.mockSvc.AssertExpectations(suite.T())
+}
+
+func (suite *IdempotencyTestSuite) TestReplay() {
+	stored := &model.IdempotencyRecord{StatusCode: http.StatusCreated, ContentType: "application/json", Body: []byte(`{"jobId":"job-1"}`)}
+	suite.mockSvc.On("Begin", mock.Anything, "key-1", "POST", "/clients/scrape", mock.Anything).Return(stored, true, nil)
+
+	w := suite.post("key-1", `{"name":"Jane Doe"}`)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Equal(`{"jobId":"job-1"}`, w.Body.String())
+	suite.Equal("true", w.Header().Get(handlers.IdempotentReplayedHeader))
+	suite.Equal(0, suite.calls)
+}
+
+func (suite *IdempotencyTestSuite) TestConflict() {
+	suite.mockSvc.On("Begin", mock.Anything, "key-1", "POST", "/clients/scrape", mock.Anything).Return(nil, false, errorx.ErrConflict)
+
+	w := suite.post("key-1", `{"name":"John Doe"}`)
+
+	suite.Equal(http.StatusConflict, w.Code)
+	suite.Equal(0, suite.calls)
+}
+
+func (suite *IdempotencyTestSuite) TestServerErrorReleasesKey() {
+	suite.status = http.StatusBadGateway
+	record := &model.IdempotencyRecord{ID: "id"}
+	suite.mockSvc.On("Begin", mock.Anything, "key-1", "POST", "/clients/scrape", mock.Anything).Return(record, false, nil)
+	suite.mockSvc.On("Release", mock.Anything, record).Return(nil)
+
+	w := suite.post("key-1", `{"name":"Jane Doe"}`)
+
+	suite.Equal(http.StatusBadGateway, w.Code)
+	suite.mockSvc.AssertExpectations(suite.T())
+	suite.mockSvc.AssertNotCalled(suite.T(), "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *IdempotencyTestSuite) TestClientGoneStillCompletes() {
+	record := &model.IdempotencyRecord{ID: "id"}
+	live := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil })
+	suite.mockSvc.On("Begin", mock.Anything, "key-1", "POST", "/clients/scrape", mock.Anything).Return(record, false, nil)
+	suite.mockSvc.On("Complete", live, record, http.StatusCreated, mock.Anything, mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/clients/scrape", bytes.NewBufferString(`{"name":"Jane Doe"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IdempotencyKeyHeader, "key-1")
+	suite.router.ServeHTTP(w, req)
+
+	suite.mockSvc.AssertExpectations(suite.T())
+}
+
+func (suite *IdempotencyTestSuite) TestMultipartBoundaryIgnored() {
+	var hashes []string
+	suite.mockSvc.On("Begin", mock.Anything, "key-1", "POST", "/clients/scrape", mock.Anything).Run(func(args mock.Arguments) {
+		hashes = append(hashes, args.String(4))
+	}).Return(nil, false, errorx.ErrConflict)
+
+	for i := 0; i < 2; i++ {
+		body := &bytes.Buffer{}
+		form := multipart.NewWriter(body) // a new random boundary each time
+		_ = form.WriteField("text", "Jane Doe is a banker")
+		_ = form.Close()
+
+		req, _ := http.NewRequest("POST", "/clients/scrape", body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set(handlers.IdempotencyKeyHeader, "key-1")
+		suite.router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	suite.Require().Len(hashes, 2)
+	suite.Equal(hashes[0], hashes[1])
+}
+
+func TestIdempotencyTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyTestSuite))
+}
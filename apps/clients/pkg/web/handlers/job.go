@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"errors"
+	"log"
 	"net/http"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
@@ -39,19 +41,22 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 		return
 	}
 
-	resp(c, http.StatusOK, job)
+	chain, err := h.service.GetRetryChain(c.Request.Context(), job)
+	if err != nil {
+		log.Printf("Failed to get retry chain (job ID: %s): %v", jobID, err)
+		ErrorHandler(c, err, "Could not get job retry chain")
+		return
+	}
+
+	resp(c, http.StatusOK, model.GetJobResponse{Job: *job, RetryChain: chain})
 }
 
 func (h *JobHandler) GetAllJobs(c *gin.Context) {
 	query := &model.GetJobsQuery{}
 
 	if err := c.ShouldBindQuery(query); err != nil {
-		switch {
-		case errors.Is(err, errorx.ErrInvalidInput):
-			resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid request"})
-		default:
-			resp(c, http.StatusInternalServerError, model.ErrorResponse{Message: "Internal server error"})
-		}
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
 		return
 	}
 
@@ -70,3 +75,262 @@ func (h *JobHandler) GetAllJobs(c *gin.Context) {
 
 	resp(c, http.StatusOK, model.GetJobsResponse{Total: total, Jobs: jobs})
 }
+
+// GetJobStats reports on jobs created in a window
+//
+//	@Summary		Get Job Stats
+//	@Description	Counts by status and type, success rate, p50/p95 time to completion, the most common failure messages and jobs per day for jobs created in the window. The window defaults to the last 30 days and can be at most 366 days.
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			type	query		string	false	"Job type, scrape or match"
+//	@Param			from	query		string	false	"Created at or after (RFC3339)"
+//	@Param			to		query		string	false	"Created at or before (RFC3339), defaults to now"
+//	@Success		200		{object}	handlers.Response{data=model.JobStats}
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Failure		502		{object}	handlers.Response
+//	@Router			/jobs/stats [get]
+func (h *JobHandler) GetJobStats(c *gin.Context) {
+	query := &model.GetJobStatsQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	stats, err := h.service.GetStats(c.Request.Context(), query)
+	if err != nil {
+		log.Printf("Failed to get job stats: %v", err)
+		ErrorHandler(c, err, "Could not retrieve job stats")
+		return
+	}
+
+	resp(c, http.StatusOK, stats)
+}
+
+// CancelJob stops a pending or running job
+//
+//	@Summary		Cancel Job
+//	@Description	Cancel the job's Prefect flow run and mark it cancelled. Only the job's creator or an admin can cancel.
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			id	path		string	true	"Hex id used to identify job"
+//	@Success		200	{object}	handlers.Response{data=model.Job}
+//	@Failure		400	{object}	handlers.Response
+//	@Failure		403	{object}	handlers.Response
+//	@Failure		404	{object}	handlers.Response
+//	@Failure		409	{object}	handlers.Response
+//	@Failure		500	{object}	handlers.Response
+//	@Failure		502	{object}	handlers.Response
+//	@Router			/jobs/:id/cancel [post]
+func (h *JobHandler) CancelJob(c *gin.Context) {
+	jobID := c.Param("id")
+
+	job, err := h.service.CancelJob(c.Request.Context(), jobID)
+	if err != nil {
+		log.Printf("Failed to cancel job (ID: %s): %v", jobID, err)
+		ErrorHandler(c, err, "Could not cancel job")
+		return
+	}
+
+	resp(c, http.StatusOK, job)
+}
+
+// RetryJob starts a new attempt of a failed job
+//
+//	@Summary		Retry Job
+//	@Description	Create a new job linked to a failed job and re-trigger the same Prefect deployment with the recorded input
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			id	path		string	true	"Hex id used to identify job"
+//	@Success		201	{object}	handlers.Response{data=model.Job}
+//	@Failure		400	{object}	handlers.Response
+//	@Failure		404	{object}	handlers.Response
+//	@Failure		409	{object}	handlers.Response
+//	@Failure		422	{object}	handlers.Response
+//	@Failure		429	{object}	handlers.Response
+//	@Failure		500	{object}	handlers.Response
+//	@Failure		502	{object}	handlers.Response
+//	@Router			/jobs/:id/retry [post]
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	jobID := c.Param("id")
+
+	job, err := h.service.RetryJob(c.Request.Context(), jobID)
+	if err != nil {
+		log.Printf("Failed to retry job (ID: %s): %v", jobID, err)
+		ErrorHandler(c, err, "Could not retry job")
+		return
+	}
+
+	resp(c, http.StatusCreated, job)
+}
+
+// GetBatchChildren lists the jobs of a batch
+//
+//	@Summary		Get Batch Children
+//	@Description	Every job started by a batch, retries included, with the same filters and paging as listing jobs
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			id			path		string	true	"Hex id used to identify the batch"
+//	@Param			status		query		string	false	"Job status"
+//	@Param			page		query		int		false	"Page number, defaults to 1"
+//	@Param			pageSize	query		int		false	"Page size, defaults to 10"
+//	@Success		200			{object}	handlers.Response{data=model.GetJobsResponse}
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		404			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/jobs/:id/children [get]
+func (h *JobHandler) GetBatchChildren(c *gin.Context) {
+	batchID := c.Param("id")
+
+	query := &model.GetJobsQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	total, jobs, err := h.service.GetBatchChildren(c.Request.Context(), batchID, query)
+	if err != nil {
+		log.Printf("Failed to get children of batch (ID: %s): %v", batchID, err)
+		ErrorHandler(c, err, "Could not get batch children")
+		return
+	}
+
+	resp(c, http.StatusOK, model.GetJobsResponse{Total: total, Jobs: jobs})
+}
+
+// CancelBatch stops every pending or running job of a batch
+//
+//	@Summary		Cancel Batch
+//	@Description	Cancel each pending or processing child of the batch. Children that could not be cancelled are listed in errors. Only the batch's creator or an admin can cancel.
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			id	path		string	true	"Hex id used to identify the batch"
+//	@Success		200	{object}	handlers.Response{data=model.BatchActionResponse}
+//	@Failure		400	{object}	handlers.Response
+//	@Failure		403	{object}	handlers.Response
+//	@Failure		404	{object}	handlers.Response
+//	@Failure		409	{object}	handlers.Response
+//	@Failure		500	{object}	handlers.Response
+//	@Failure		502	{object}	handlers.Response
+//	@Router			/jobs/:id/children/cancel [post]
+func (h *JobHandler) CancelBatch(c *gin.Context) {
+	batchID := c.Param("id")
+
+	res, err := h.service.CancelBatch(c.Request.Context(), batchID)
+	if err != nil {
+		log.Printf("Failed to cancel batch (ID: %s): %v", batchID, err)
+		ErrorHandler(c, err, "Could not cancel batch")
+		return
+	}
+
+	resp(c, http.StatusOK, res)
+}
+
+// RetryBatch starts a new attempt of every failed job of a batch
+//
+//	@Summary		Retry Batch
+//	@Description	Retry each failed child of the batch. The new attempts belong to the batch, children that could not be retried are listed in errors.
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			id	path		string	true	"Hex id used to identify the batch"
+//	@Success		201	{object}	handlers.Response{data=model.BatchActionResponse}
+//	@Failure		400	{object}	handlers.Response
+//	@Failure		404	{object}	handlers.Response
+//	@Failure		409	{object}	handlers.Response
+//	@Failure		422	{object}	handlers.Response
+//	@Failure		429	{object}	handlers.Response
+//	@Failure		500	{object}	handlers.Response
+//	@Failure		502	{object}	handlers.Response
+//	@Router			/jobs/:id/children/retry [post]
+func (h *JobHandler) RetryBatch(c *gin.Context) {
+	batchID := c.Param("id")
+
+	res, err := h.service.RetryBatch(c.Request.Context(), batchID)
+	if err != nil {
+		log.Printf("Failed to retry batch (ID: %s): %v", batchID, err)
+		ErrorHandler(c, err, "Could not retry batch")
+		return
+	}
+
+	resp(c, http.StatusCreated, res)
+}
+
+// JobCallback records a status transition, progress, log lines or results reported by a Prefect flow
+//
+//	@Summary		Job Callback
+//	@Description	Called by the Prefect flows to report on a job. Requests must be HMAC signed with the shared callback secret.
+//	@Tags			jobs
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		string					true	"Hex id used to identify job"
+//	@Param			X-Signature			header		string					true	"sha256=<hex HMAC-SHA256 of timestamp.body>"
+//	@Param			X-Signature-Timestamp	header		string					true	"Unix time in seconds the request was signed at"
+//	@Param			request				body		model.JobCallbackReq	true	"Job update"
+//	@Success		200					{object}	handlers.Response{data=model.Job}
+//	@Failure		400					{object}	handlers.Response
+//	@Failure		401					{object}	handlers.Response
+//	@Failure		404					{object}	handlers.Response
+//	@Failure		409					{object}	handlers.Response
+//	@Failure		413					{object}	handlers.Response
+//	@Failure		422					{object}	handlers.Response
+//	@Failure		500					{object}	handlers.Response
+//	@Router			/internal/jobs/:id/callback [post]
+func (h *JobHandler) JobCallback(c *gin.Context) {
+	jobID := c.Param("id")
+
+	var req model.JobCallbackReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Failed to bind request: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid request"})
+		return
+	}
+
+	job, err := h.service.HandleCallback(c.Request.Context(), jobID, &req)
+	if err != nil {
+		log.Printf("Failed to apply callback to job (ID: %s): %v", jobID, err)
+		ErrorHandler(c, err, "Could not update job")
+		return
+	}
+
+	resp(c, http.StatusOK, job)
+}
+
+// StreamJobEvents streams a job's status changes and log entries as server-sent events
+//
+//	@Summary		Stream Job Events
+//	@Description	Stream the job's status changes and log entries until it finishes. Reconnecting clients resume from the Last-Event-ID header.
+//	@Tags			jobs
+//	@Produce		text/event-stream
+//	@Param			id				path		string	true	"Hex id used to identify job"
+//	@Param			Last-Event-ID	header		string	false	"ID of the last event received"
+//	@Success		200				{object}	model.JobEvent
+//	@Failure		400				{object}	handlers.Response
+//	@Failure		404				{object}	handlers.Response
+//	@Failure		500				{object}	handlers.Response
+//	@Router			/jobs/:id/events [get]
+func (h *JobHandler) StreamJobEvents(c *gin.Context) {
+	jobID := c.Param("id")
+
+	started := false
+	err := h.service.StreamJob(c.Request.Context(), jobID, c.GetHeader("Last-Event-ID"), func(event model.JobEvent) error {
+		if !started {
+			c.Header("Cache-Control", "no-cache")
+			c.Header("Connection", "keep-alive")
+			c.Header("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+			c.Status(http.StatusOK)
+			started = true
+		}
+		c.Render(-1, sse.Event{Id: event.ID, Event: string(event.Type), Data: event})
+		c.Writer.Flush()
+		return c.Request.Context().Err()
+	})
+	if err != nil {
+		log.Printf("Failed to stream job events (ID: %s): %v", jobID, err)
+		if !started {
+			ErrorHandler(c, err, "Could not stream job events")
+		}
+	}
+}
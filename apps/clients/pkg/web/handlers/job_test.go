@@ -3,6 +3,7 @@ package handlers_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,7 +32,15 @@ func (suite *JobHandlerTestSuite) SetupTest() {
 	gin.SetMode(gin.TestMode)
 	suite.router = gin.New()
 	suite.router.GET("/jobs", suite.handler.GetAllJobs)
+	suite.router.GET("/jobs/stats", suite.handler.GetJobStats)
 	suite.router.GET("/jobs/:id", suite.handler.GetJob)
+	suite.router.POST("/jobs/:id/cancel", suite.handler.CancelJob)
+	suite.router.POST("/jobs/:id/retry", suite.handler.RetryJob)
+	suite.router.POST("/internal/jobs/:id/callback", suite.handler.JobCallback)
+	suite.router.GET("/jobs/:id/events", suite.handler.StreamJobEvents)
+	suite.router.GET("/jobs/:id/children", suite.handler.GetBatchChildren)
+	suite.router.POST("/jobs/:id/children/cancel", suite.handler.CancelBatch)
+	suite.router.POST("/jobs/:id/children/retry", suite.handler.RetryBatch)
 }
 
 func (suite *JobHandlerTestSuite) TestGetJob_MissingID() {
@@ -55,12 +64,14 @@ func (suite *JobHandlerTestSuite) TestGetJob_Success() {
 		Logs:          []model.JobLog{},
 	}
 	suite.mockSvc.On("GetJob", mock.Anything, id.Hex()).Return(job, nil)
+	suite.mockSvc.On("GetRetryChain", mock.Anything, job).Return([]model.JobAttempt{{ID: id.Hex(), Attempt: 1, Status: model.JobStatusPending}}, nil)
 
 	req, _ := http.NewRequest("GET", "/jobs/"+id.Hex(), nil)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"retryChain":[{"id":"`+id.Hex()+`","attempt":1`)
 	suite.mockSvc.AssertExpectations(suite.T())
 }
 
@@ -103,12 +114,29 @@ func (suite *JobHandlerTestSuite) TestGetAllJobs_Success() {
 	suite.mockSvc.AssertExpectations(suite.T())
 }
 
-func (suite *JobHandlerTestSuite) TestGetAllJobs_BindError() {
-	req, _ := http.NewRequest("GET", "/jobs?page=abc", nil)
+func (suite *JobHandlerTestSuite) TestGetAllJobs_Filters() {
+	suite.mockSvc.On("GetAllJobs", mock.Anything, mock.MatchedBy(func(q *model.GetJobsQuery) bool {
+		return q.Type == model.Match && q.CreatedBy == "me" && q.SortBy == "createdAt" && q.Sort == "asc" &&
+			q.CreatedFrom.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC))
+	})).Return(0, []model.Job{}, nil)
+
+	req, _ := http.NewRequest("GET", "/jobs?type=match&createdBy=me&createdFrom=2025-04-01T00:00:00Z&sortBy=createdAt&sort=asc", nil)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
-	assert.Equal(suite.T(), http.StatusInternalServerError, w.Code)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockSvc.AssertExpectations(suite.T())
+}
+
+func (suite *JobHandlerTestSuite) TestGetAllJobs_BindError() {
+	for _, query := range []string{"page=abc", "createdFrom=yesterday"} {
+		req, _ := http.NewRequest("GET", "/jobs?"+query, nil)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, query)
+	}
+	suite.mockSvc.AssertNotCalled(suite.T(), "GetAllJobs", mock.Anything, mock.Anything)
 }
 
 func (suite *JobHandlerTestSuite) TestGetAllJobs_ServiceErrors() {
@@ -133,6 +161,271 @@ func (suite *JobHandlerTestSuite) TestGetAllJobs_ServiceErrors() {
 	}
 }
 
+func (suite *JobHandlerTestSuite) TestGetJobStats_Success() {
+	suite.mockSvc.On("GetStats", mock.Anything, mock.MatchedBy(func(q *model.GetJobStatsQuery) bool {
+		return q.Type == model.Scrape && q.From.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	})).Return(&model.JobStats{Total: 4, SuccessRate: 0.75}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/stats?type=scrape&from=2025-03-01T00:00:00Z", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"successRate":0.75`)
+}
+
+func (suite *JobHandlerTestSuite) TestGetJobStats_ErrorCases() {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/stats?from=yesterday", nil)
+	suite.router.ServeHTTP(w, req)
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	suite.mockSvc.On("GetStats", mock.Anything, mock.Anything).Return(nil, errorx.ErrInvalidInput).Once()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/jobs/stats?type=crawl", nil)
+	suite.router.ServeHTTP(w, req)
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	suite.mockSvc.On("GetStats", mock.Anything, mock.Anything).Return(nil, errorx.ErrDependencyFailed).Once()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/jobs/stats", nil)
+	suite.router.ServeHTTP(w, req)
+	suite.Equal(http.StatusBadGateway, w.Code)
+}
+
+func (suite *JobHandlerTestSuite) TestCancelJob_Success() {
+	suite.mockSvc.On("CancelJob", mock.Anything, "job-id").Return(&model.Job{Status: model.JobStatusCancelled}, nil)
+
+	req, _ := http.NewRequest("POST", "/jobs/job-id/cancel", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"status":"cancelled"`)
+}
+
+func (suite *JobHandlerTestSuite) TestCancelJob_ErrorCases() {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"forbidden", errorx.ErrForbidden, http.StatusForbidden},
+		{"finished", errorx.ErrConflict, http.StatusConflict},
+		{"prefect down", errorx.ErrDependencyFailed, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			suite.mockSvc.On("CancelJob", mock.Anything, "job-id").Return(nil, tt.err)
+
+			req, _ := http.NewRequest("POST", "/jobs/job-id/cancel", nil)
+			w := httptest.NewRecorder()
+			suite.router.ServeHTTP(w, req)
+
+			assert.Equal(suite.T(), tt.code, w.Code)
+		})
+	}
+}
+
+func (suite *JobHandlerTestSuite) TestRetryJob_Success() {
+	suite.mockSvc.On("RetryJob", mock.Anything, "job-id").Return(&model.Job{ParentID: "job-id", Attempt: 2, Status: model.JobStatusPending}, nil)
+
+	req, _ := http.NewRequest("POST", "/jobs/job-id/retry", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"parentId":"job-id"`)
+	assert.Contains(suite.T(), w.Body.String(), `"attempt":2`)
+}
+
+func (suite *JobHandlerTestSuite) TestRetryJob_ErrorCases() {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", errorx.ErrNotFound, http.StatusNotFound},
+		{"not failed", errorx.ErrConflict, http.StatusConflict},
+		{"no input", errorx.ErrValidationFailed, http.StatusUnprocessableEntity},
+		{"prefect down", errorx.ErrDependencyFailed, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			suite.mockSvc.On("RetryJob", mock.Anything, "job-id").Return(nil, tt.err)
+
+			req, _ := http.NewRequest("POST", "/jobs/job-id/retry", nil)
+			w := httptest.NewRecorder()
+			suite.router.ServeHTTP(w, req)
+
+			assert.Equal(suite.T(), tt.code, w.Code)
+		})
+	}
+}
+
+func (suite *JobHandlerTestSuite) TestJobCallback_Success() {
+	suite.mockSvc.On("HandleCallback", mock.Anything, "job-id", mock.MatchedBy(func(req *model.JobCallbackReq) bool {
+		return req.Status == model.JobStatusCompleted && req.Message == "done" && *req.Progress == 100
+	})).Return(&model.Job{Status: model.JobStatusCompleted, Progress: 100}, nil)
+
+	req, _ := http.NewRequest("POST", "/internal/jobs/job-id/callback", strings.NewReader(`{"status":"completed","progress":100,"message":"done"}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"status":"completed"`)
+}
+
+func (suite *JobHandlerTestSuite) TestJobCallback_BindError() {
+	req, _ := http.NewRequest("POST", "/internal/jobs/job-id/callback", strings.NewReader(`{"status":`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	suite.mockSvc.AssertNotCalled(suite.T(), "HandleCallback", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *JobHandlerTestSuite) TestJobCallback_ErrorCases() {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", errorx.ErrNotFound, http.StatusNotFound},
+		{"invalid transition", errorx.ErrConflict, http.StatusConflict},
+		{"invalid update", errorx.ErrValidationFailed, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			suite.mockSvc.On("HandleCallback", mock.Anything, "job-id", mock.Anything).Return(nil, tt.err)
+
+			req, _ := http.NewRequest("POST", "/internal/jobs/job-id/callback", strings.NewReader(`{"status":"processing"}`))
+			w := httptest.NewRecorder()
+			suite.router.ServeHTTP(w, req)
+
+			assert.Equal(suite.T(), tt.code, w.Code)
+		})
+	}
+}
+
+func (suite *JobHandlerTestSuite) TestStreamJobEvents_Success() {
+	suite.mockSvc.On("StreamJob", mock.Anything, "job-id", "1-processing", mock.Anything).
+		Run(func(args mock.Arguments) {
+			send := args.Get(3).(func(model.JobEvent) error)
+			_ = send(model.JobEvent{ID: "2-processing", Type: model.JobEventLog, Log: &model.JobLog{Message: "scraping"}})
+			_ = send(model.JobEvent{ID: "2-completed", Type: model.JobEventStatus, Status: model.JobStatusCompleted})
+		}).
+		Return(nil)
+
+	req, _ := http.NewRequest("GET", "/jobs/job-id/events", nil)
+	req.Header.Set("Last-Event-ID", "1-processing")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Header().Get("Content-Type"), "text/event-stream")
+	body := w.Body.String()
+	assert.Contains(suite.T(), body, "id:2-processing\nevent:log\ndata:{\"log\":{\"message\":\"scraping\"")
+	assert.Contains(suite.T(), body, "id:2-completed\nevent:status\ndata:{\"status\":\"completed\"}\n\n")
+}
+
+func (suite *JobHandlerTestSuite) TestStreamJobEvents_ErrorBeforeStreaming() {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", errorx.ErrNotFound, http.StatusNotFound},
+		{"bad last event id", errorx.ErrInvalidInput, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			suite.mockSvc.On("StreamJob", mock.Anything, "job-id", "", mock.Anything).Return(tt.err)
+
+			req, _ := http.NewRequest("GET", "/jobs/job-id/events", nil)
+			w := httptest.NewRecorder()
+			suite.router.ServeHTTP(w, req)
+
+			assert.Equal(suite.T(), tt.code, w.Code)
+		})
+	}
+}
+
+func (suite *JobHandlerTestSuite) TestGetBatchChildren_Success() {
+	suite.mockSvc.On("GetBatchChildren", mock.Anything, "batch-id", mock.MatchedBy(func(q *model.GetJobsQuery) bool {
+		return q.Status == model.JobStatusFailed && q.Page == 2
+	})).Return(3, []model.Job{{ClientID: "client-1", BatchID: "batch-id"}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/batch-id/children?status=failed&page=2", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"total":3`)
+	suite.Contains(w.Body.String(), `"batchId":"batch-id"`)
+}
+
+func (suite *JobHandlerTestSuite) TestGetBatchChildren_NotABatch() {
+	suite.mockSvc.On("GetBatchChildren", mock.Anything, "job-id", mock.Anything).Return(0, nil, errorx.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/job-id/children", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+func (suite *JobHandlerTestSuite) TestCancelBatch() {
+	suite.mockSvc.On("CancelBatch", mock.Anything, "batch-id").Return(&model.BatchActionResponse{
+		Batch:  &model.Job{Type: model.Batch, Status: model.JobStatusCancelled},
+		Jobs:   []model.Job{{Status: model.JobStatusCancelled}},
+		Errors: []model.BatchChildError{{JobID: "job-2", Message: "job is already completed"}},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs/batch-id/children/cancel", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"jobId":"job-2"`)
+
+	suite.mockSvc.On("CancelBatch", mock.Anything, "batch-id").Return(nil, errorx.ErrForbidden).Once()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/jobs/batch-id/children/cancel", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusForbidden, w.Code)
+}
+
+func (suite *JobHandlerTestSuite) TestRetryBatch() {
+	suite.mockSvc.On("RetryBatch", mock.Anything, "batch-id").Return(&model.BatchActionResponse{
+		Batch: &model.Job{Type: model.Batch, Status: model.JobStatusPending},
+		Jobs:  []model.Job{{Status: model.JobStatusPending, Attempt: 2}},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs/batch-id/children/retry", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"attempt":2`)
+
+	suite.mockSvc.On("RetryBatch", mock.Anything, "batch-id").Return(nil, errorx.ErrConflict).Once()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/jobs/batch-id/children/retry", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
 func TestJobHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(JobHandlerTestSuite))
 }
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+type LogChainHandler struct {
+	service service.LogChainServiceInterface
+}
+
+func NewLogChainHandler(service service.LogChainServiceInterface) *LogChainHandler {
+	return &LogChainHandler{service: service}
+}
+
+// VerifyLogChain walks the hash chain of the audit log
+//
+//	@Summary		Verify Log Chain
+//	@Description	Check every chained log's hash and link to the log before it, and that the chain agrees with its latest anchor. A broken chain is reported with valid false and the first break found.
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	handlers.Response{data=model.ChainVerification}
+//	@Failure		403	{object}	handlers.Response
+//	@Failure		500	{object}	handlers.Response
+//	@Failure		502	{object}	handlers.Response
+//	@Router			/admin/logs/verify [get]
+func (h *LogChainHandler) VerifyLogChain(c *gin.Context) {
+	result, err := h.service.VerifyChain(c.Request.Context())
+	if err != nil {
+		log.Printf("Failed to verify log chain: %v", err)
+		ErrorHandler(c, err, "Could not verify log chain")
+		return
+	}
+
+	resp(c, http.StatusOK, result)
+}
@@ -0,0 +1,62 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogChainHandlerTestSuite struct {
+	suite.Suite
+	mockSvc *mocks.LogChainServiceInterface
+	handler *handlers.LogChainHandler
+	router  *gin.Engine
+}
+
+func (suite *LogChainHandlerTestSuite) SetupTest() {
+	suite.mockSvc = new(mocks.LogChainServiceInterface)
+	suite.handler = handlers.NewLogChainHandler(suite.mockSvc)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.GET("/admin/logs/verify", suite.handler.VerifyLogChain)
+}
+
+func (suite *LogChainHandlerTestSuite) TestVerifyLogChain_Broken() {
+	suite.mockSvc.On("VerifyChain", mock.Anything).Return(&model.ChainVerification{
+		Checked: 41,
+		HeadSeq: 41,
+		Break:   &model.ChainBreak{Seq: 42, LogID: "abc", Reason: model.ChainBreakHash},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/logs/verify", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"valid":false`)
+	assert.Contains(suite.T(), w.Body.String(), `"reason":"hash mismatch"`)
+}
+
+func (suite *LogChainHandlerTestSuite) TestVerifyLogChain_Error() {
+	suite.mockSvc.On("VerifyChain", mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	req, _ := http.NewRequest("GET", "/admin/logs/verify", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadGateway, w.Code)
+}
+
+func TestLogChainHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(LogChainHandlerTestSuite))
+}
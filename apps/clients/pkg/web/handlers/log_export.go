@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+// ExportIDHeader carries the ID of an export's manifest, which can be fetched once the export has finished
+const ExportIDHeader = "X-Export-Id"
+
+var exportContentTypes = map[model.ExportFormat]string{
+	model.ExportFormatCSV:    "text/csv",
+	model.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+type LogExportHandler struct {
+	service service.LogExportServiceInterface
+}
+
+func NewLogExportHandler(service service.LogExportServiceInterface) *LogExportHandler {
+	return &LogExportHandler{service: service}
+}
+
+// ExportLogs streams the logs matching the filters as CSV or NDJSON
+//
+//	@Summary		Export Logs
+//	@Description	Stream every log matching the filters, oldest first. The X-Export-Id header names the signed manifest, holding the row count and SHA-256 of the file, which is stored once the export completes. The export is audited when it starts and again when it completes or is aborted.
+//	@Tags			logs
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Param			format		query	string	false	"Export format (csv, ndjson), defaults to csv"
+//	@Param			clientId	query	string	false	"Client ID"
+//	@Param			operation	query	string	false	"Operation"
+//	@Param			actor		query	string	false	"Username of the actor"
+//	@Param			path		query	string	false	"Changed path, matching the path and any path under it"
+//	@Param			from		query	string	false	"Logged at or after (RFC3339)"
+//	@Param			to			query	string	false	"Logged at or before (RFC3339)"
+//	@Success		200
+//	@Failure		400	{object}	handlers.Response
+//	@Failure		500	{object}	handlers.Response
+//	@Failure		502	{object}	handlers.Response
+//	@Router			/logs/export [get]
+func (h *LogExportHandler) ExportLogs(c *gin.Context) {
+	query := &model.ExportLogsQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	export, err := h.service.StartExport(c.Request.Context(), query)
+	if err != nil {
+		log.Printf("Failed to start log export: %v", err)
+		ErrorHandler(c, err, "Could not export logs")
+		return
+	}
+
+	c.Header("Content-Type", exportContentTypes[export.Format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="logs-%s.%s"`, export.ID.Hex(), export.Format))
+	c.Header(ExportIDHeader, export.ID.Hex())
+	c.Status(http.StatusOK)
+
+	// headers are already sent once streaming starts, so failures can only be logged and leave no manifest
+	if err := h.service.WriteExport(c.Request.Context(), export, c.Writer); err != nil {
+		log.Printf("Log export %s aborted: %v", export.ID.Hex(), err)
+	}
+}
+
+// GetLogExport returns the manifest of a finished export
+//
+//	@Summary		Get Log Export
+//	@Description	Get the signed manifest of a log export, with the filters used, the row count and the SHA-256 of the file
+//	@Tags			logs
+//	@Produce		json
+//	@Param			id	path		string	true	"Export ID"
+//	@Success		200	{object}	handlers.Response{data=model.LogExport}
+//	@Failure		400	{object}	handlers.Response
+//	@Failure		404	{object}	handlers.Response
+//	@Failure		500	{object}	handlers.Response
+//	@Router			/logs/exports/{id} [get]
+func (h *LogExportHandler) GetLogExport(c *gin.Context) {
+	export, err := h.service.GetExport(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Printf("Failed to get log export: %v", err)
+		ErrorHandler(c, err, "Could not get log export")
+		return
+	}
+
+	resp(c, http.StatusOK, export)
+}
@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type LogExportHandlerTestSuite struct {
+	suite.Suite
+	mockSvc *mocks.LogExportServiceInterface
+	handler *handlers.LogExportHandler
+	router  *gin.Engine
+}
+
+func (suite *LogExportHandlerTestSuite) SetupTest() {
+	suite.mockSvc = new(mocks.LogExportServiceInterface)
+	suite.handler = handlers.NewLogExportHandler(suite.mockSvc)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.GET("/logs/export", suite.handler.ExportLogs)
+	suite.router.GET("/logs/exports/:id", suite.handler.GetLogExport)
+}
+
+func (suite *LogExportHandlerTestSuite) TestExportLogs_Streams() {
+	export := &model.LogExport{ID: bson.NewObjectID(), Format: model.ExportFormatNDJSON}
+	suite.mockSvc.On("StartExport", mock.Anything, mock.MatchedBy(func(q *model.ExportLogsQuery) bool {
+		return q.Format == model.ExportFormatNDJSON && q.Actor == "alice"
+	})).Return(export, nil)
+	suite.mockSvc.On("WriteExport", mock.Anything, export, mock.Anything).Return(func(ctx context.Context, export *model.LogExport, w io.Writer) error {
+		_, _ = w.Write([]byte("{\"actor\":\"alice\"}\n"))
+		return nil
+	})
+
+	req, _ := http.NewRequest("GET", "/logs/export?format=ndjson&actor=alice", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), export.ID.Hex(), w.Header().Get(handlers.ExportIDHeader))
+	assert.Contains(suite.T(), w.Header().Get("Content-Disposition"), ".ndjson")
+	assert.Equal(suite.T(), "{\"actor\":\"alice\"}\n", w.Body.String())
+}
+
+func (suite *LogExportHandlerTestSuite) TestExportLogs_InvalidFilters() {
+	suite.mockSvc.On("StartExport", mock.Anything, mock.Anything).Return(nil, errorx.ErrInvalidInput)
+
+	req, _ := http.NewRequest("GET", "/logs/export?format=xml", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	suite.mockSvc.AssertNotCalled(suite.T(), "WriteExport", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *LogExportHandlerTestSuite) TestExportLogs_InvalidQuery() {
+	req, _ := http.NewRequest("GET", "/logs/export?from=yesterday", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	suite.mockSvc.AssertNotCalled(suite.T(), "StartExport", mock.Anything, mock.Anything)
+}
+
+func (suite *LogExportHandlerTestSuite) TestGetLogExport() {
+	export := &model.LogExport{ID: bson.NewObjectID(), Format: model.ExportFormatCSV, Rows: 2, SHA256: "abc", Signature: "def"}
+	suite.mockSvc.On("GetExport", mock.Anything, export.ID.Hex()).Return(export, nil)
+
+	req, _ := http.NewRequest("GET", "/logs/exports/"+export.ID.Hex(), nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"rows":2`)
+	assert.Contains(suite.T(), w.Body.String(), `"signature":"def"`)
+}
+
+func (suite *LogExportHandlerTestSuite) TestGetLogExport_NotFound() {
+	suite.mockSvc.On("GetExport", mock.Anything, "missing").Return(nil, errorx.ErrNotFound)
+
+	req, _ := http.NewRequest("GET", "/logs/exports/missing", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func TestLogExportHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(LogExportHandlerTestSuite))
+}
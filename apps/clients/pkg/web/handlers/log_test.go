@@ -58,6 +58,27 @@ func (suite *LogHandlerTestSuite) TestGetLogs_Success() {
 	suite.mockSvc.AssertExpectations(suite.T())
 }
 
+func (suite *LogHandlerTestSuite) TestGetLogs_ChangedPath() {
+	expectedLogs := []model.Log{{
+		ClientID:  "123",
+		Actor:     "test-actor",
+		Operation: model.OperationUpdate,
+		Timestamp: time.Now(),
+		Changes:   []model.Change{{Path: "profile.netWorth", Old: 100, New: 250, Source: model.ChangeSourceManual}},
+	}}
+
+	suite.mockSvc.On("GetLogs", mock.Anything, mock.MatchedBy(func(q *model.GetLogsQuery) bool {
+		return q.Path == "profile.netWorth"
+	})).Return(1, expectedLogs, nil)
+
+	req, _ := http.NewRequest("GET", "/logs?path=profile.netWorth", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"changes":[{"path":"profile.netWorth","old":100,"new":250,"source":"manual"}]`)
+}
+
 func (suite *LogHandlerTestSuite) TestGetLogs_InvalidQuery() {
 	req, _ := http.NewRequest("GET", "/logs?page=abc", nil)
 	w := httptest.NewRecorder()
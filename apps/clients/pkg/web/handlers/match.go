@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+type MatchHandler struct {
+	service service.MatchServiceInterface
+}
+
+func NewMatchHandler(service service.MatchServiceInterface) *MatchHandler {
+	return &MatchHandler{service: service}
+}
+
+// GetPendingMatches lists match jobs with candidates awaiting review
+//
+//	@Summary		Get Pending Matches
+//	@Description	Completed match jobs with unreviewed candidates, oldest first. Each candidate's current profile is shown next to the profile extracted from the uploaded document.
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			page		query		int	false	"Page number, defaults to 1"
+//	@Param			pageSize	query		int	false	"Page size, defaults to 10"
+//	@Success		200			{object}	handlers.Response{data=model.GetPendingMatchesResponse}
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/jobs/matches [get]
+func (h *MatchHandler) GetPendingMatches(c *gin.Context) {
+	query := &model.GetPendingMatchesQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	res, err := h.service.GetPendingMatches(c.Request.Context(), query)
+	if err != nil {
+		log.Printf("Failed to get pending matches: %v", err)
+		ErrorHandler(c, err, "Could not retrieve pending matches")
+		return
+	}
+
+	resp(c, http.StatusOK, res)
+}
+
+// ReviewMatch accepts or rejects a match candidate
+//
+//	@Summary		Review Match
+//	@Description	Accept or reject one candidate of a completed match job. Accepting links the uploaded document to the candidate and copies the listed extracted fields onto its profile. Decisions are final.
+//	@Tags			jobs
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"Hex id used to identify job"
+//	@Param			candidateId	path		string					true	"Hex id of the candidate client"
+//	@Param			review		body		model.ReviewMatchReq	true	"Decision and fields to apply"
+//	@Success		200			{object}	handlers.Response{data=model.MatchResult}
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		404			{object}	handlers.Response
+//	@Failure		409			{object}	handlers.Response
+//	@Failure		422			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/jobs/:id/matches/:candidateId [post]
+func (h *MatchHandler) ReviewMatch(c *gin.Context) {
+	jobID, candidateID := c.Param("id"), c.Param("candidateId")
+
+	req := &model.ReviewMatchReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Printf("Failed to bind request: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid request"})
+		return
+	}
+
+	result, err := h.service.ReviewMatch(c.Request.Context(), jobID, candidateID, req)
+	if err != nil {
+		log.Printf("Failed to review candidate %s of match job (ID: %s): %v", candidateID, jobID, err)
+		ErrorHandler(c, err, "Could not review match")
+		return
+	}
+
+	resp(c, http.StatusOK, result)
+}
+
+// GetMatchStats reports how often reviewers accept match candidates
+//
+//	@Summary		Get Match Review Stats
+//	@Description	Reviewed and accepted candidate counts with accuracy and mean confidence scores, overall and per reviewer
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			reviewer	query		string	false	"Reviewer username"
+//	@Param			from		query		string	false	"Reviewed at or after (RFC3339)"
+//	@Param			to			query		string	false	"Reviewed at or before (RFC3339)"
+//	@Success		200			{object}	handlers.Response{data=model.GetMatchStatsResponse}
+//	@Failure		400			{object}	handlers.Response
+//	@Failure		500			{object}	handlers.Response
+//	@Failure		502			{object}	handlers.Response
+//	@Router			/jobs/matches/stats [get]
+func (h *MatchHandler) GetMatchStats(c *gin.Context) {
+	query := &model.GetMatchStatsQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	res, err := h.service.GetMatchStats(c.Request.Context(), query)
+	if err != nil {
+		log.Printf("Failed to get match stats: %v", err)
+		ErrorHandler(c, err, "Could not retrieve match stats")
+		return
+	}
+
+	resp(c, http.StatusOK, res)
+}
@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MatchHandlerTestSuite struct {
+	suite.Suite
+	mockSvc *mocks.MatchServiceInterface
+	handler *handlers.MatchHandler
+	router  *gin.Engine
+}
+
+func (suite *MatchHandlerTestSuite) SetupTest() {
+	suite.mockSvc = new(mocks.MatchServiceInterface)
+	suite.handler = handlers.NewMatchHandler(suite.mockSvc)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+
+	// registered next to the job lookup, as in the router
+	suite.router.GET("/jobs/:id", func(c *gin.Context) { c.Status(http.StatusTeapot) })
+	suite.router.GET("/jobs/matches", suite.handler.GetPendingMatches)
+	suite.router.GET("/jobs/matches/stats", suite.handler.GetMatchStats)
+	suite.router.POST("/jobs/:id/matches/:candidateId", suite.handler.ReviewMatch)
+}
+
+func (suite *MatchHandlerTestSuite) TestGetPendingMatches_Success() {
+	suite.mockSvc.On("GetPendingMatches", mock.Anything, mock.MatchedBy(func(q *model.GetPendingMatchesQuery) bool {
+		return q.Page == 2 && q.PageSize == 5
+	})).Return(&model.GetPendingMatchesResponse{Total: 6, Matches: []model.PendingMatch{{JobID: "job-id", FileName: "statement.pdf"}}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/matches?page=2&pageSize=5", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), "statement.pdf")
+}
+
+func (suite *MatchHandlerTestSuite) TestGetPendingMatches_InvalidQuery() {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/matches?page=first", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.mockSvc.AssertNotCalled(suite.T(), "GetPendingMatches", mock.Anything, mock.Anything)
+}
+
+func (suite *MatchHandlerTestSuite) TestReviewMatch_Success() {
+	suite.mockSvc.On("ReviewMatch", mock.Anything, "job-id", "candidate-id", mock.MatchedBy(func(r *model.ReviewMatchReq) bool {
+		return r.Decision == model.MatchAccepted && len(r.Fields) == 1 && r.Fields[0] == "profile.age"
+	})).Return(&model.MatchResult{ConfidenceScore: 0.87, Review: &model.MatchReview{Decision: model.MatchAccepted}}, nil)
+
+	w := httptest.NewRecorder()
+	body := `{"decision":"accepted","fields":["profile.age"]}`
+	req, _ := http.NewRequest("POST", "/jobs/job-id/matches/candidate-id", bytes.NewBufferString(body))
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"decision":"accepted"`)
+}
+
+func (suite *MatchHandlerTestSuite) TestReviewMatch_Errors() {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"unknown field", errorx.ErrValidationFailed, http.StatusUnprocessableEntity},
+		{"already reviewed", errorx.ErrConflict, http.StatusConflict},
+		{"unknown candidate", errorx.ErrNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.SetupTest()
+			suite.mockSvc.On("ReviewMatch", mock.Anything, "job-id", "candidate-id", mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/jobs/job-id/matches/candidate-id", bytes.NewBufferString(`{"decision":"accepted"}`))
+			suite.router.ServeHTTP(w, req)
+
+			suite.Equal(tt.code, w.Code)
+		})
+	}
+}
+
+func (suite *MatchHandlerTestSuite) TestReviewMatch_InvalidBody() {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs/job-id/matches/candidate-id", bytes.NewBufferString(`{"decision":`))
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (suite *MatchHandlerTestSuite) TestGetMatchStats_Success() {
+	suite.mockSvc.On("GetMatchStats", mock.Anything, mock.MatchedBy(func(q *model.GetMatchStatsQuery) bool {
+		return q.Reviewer == "alice"
+	})).Return(&model.GetMatchStatsResponse{MatchStats: model.MatchStats{Reviewed: 4, Accepted: 3, Accuracy: 0.75}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/matches/stats?reviewer=alice", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"accuracy":0.75`)
+}
+
+func TestMatchHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(MatchHandlerTestSuite))
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+type QuotaHandler struct {
+	service service.QuotaServiceInterface
+}
+
+func NewQuotaHandler(service service.QuotaServiceInterface) *QuotaHandler {
+	return &QuotaHandler{service: service}
+}
+
+// GetUsage reports the caller's usage against the job quotas
+//
+//	@Summary		Get My Usage
+//	@Description	Active and daily job counts of the current user and active jobs overall, each with its limit. A limit of 0 means none.
+//	@Tags			jobs
+//	@Produce		json
+//	@Success		200	{object}	handlers.Response{data=model.UsageResponse}
+//	@Failure		500	{object}	handlers.Response
+//	@Failure		502	{object}	handlers.Response
+//	@Router			/jobs/usage [get]
+func (h *QuotaHandler) GetUsage(c *gin.Context) {
+	usage, err := h.service.GetUsage(c.Request.Context())
+	if err != nil {
+		log.Printf("Failed to get usage: %v", err)
+		ErrorHandler(c, err, "Could not retrieve usage")
+		return
+	}
+
+	resp(c, http.StatusOK, usage)
+}
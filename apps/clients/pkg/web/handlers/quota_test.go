@@ -0,0 +1,74 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type QuotaHandlerTestSuite struct {
+	suite.Suite
+	mockSvc *mocks.QuotaServiceInterface
+	router  *gin.Engine
+}
+
+func (suite *QuotaHandlerTestSuite) SetupTest() {
+	suite.mockSvc = new(mocks.QuotaServiceInterface)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.GET("/jobs/usage", handlers.NewQuotaHandler(suite.mockSvc).GetUsage)
+}
+
+func (suite *QuotaHandlerTestSuite) TestGetUsage_Success() {
+	suite.mockSvc.On("GetUsage", mock.Anything).Return(&model.UsageResponse{
+		Username: "alice",
+		Active:   model.QuotaUsage{Used: 1, Limit: 5},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/usage", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"active":{"used":1,"limit":5}`)
+}
+
+func (suite *QuotaHandlerTestSuite) TestGetUsage_DependencyFailed() {
+	suite.mockSvc.On("GetUsage", mock.Anything).Return(nil, errorx.ErrDependencyFailed)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/usage", nil)
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadGateway, w.Code)
+}
+
+func TestQuotaHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(QuotaHandlerTestSuite))
+}
+
+func TestErrorHandler_QuotaExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handlers.ErrorHandler(c, &service.QuotaExceededError{Quota: "daily", Limit: 50, RetryAfter: 90*time.Second + time.Millisecond}, "Could not create client")
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "91" {
+		t.Fatalf("expected Retry-After 91, got %q", got)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+)
+
+const (
+	SignatureHeader = "X-Signature"
+	TimestampHeader = "X-Signature-Timestamp"
+
+	// PipelineActor is the username signed requests act as, since only the Prefect flows hold the secret
+	PipelineActor = "pipeline"
+
+	// signatureTolerance bounds how old a signed request may be, so captured requests can't be replayed later
+	signatureTolerance = 5 * time.Minute
+	// maxSignedBodySize fits a scraped profile with its extracted fields, which end up in a Mongo document capped at 16MB
+	maxSignedBodySize = 16 << 20
+)
+
+// VerifySignature is a middleware that authenticates service-to-service requests signed with a shared secret.
+// The X-Signature header must be "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>",
+// where timestamp is the unix time in seconds sent in X-Signature-Timestamp. Verified requests act as PipelineActor.
+func VerifySignature(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			log.Printf("Rejecting signed request to %s: no signing secret configured", c.FullPath())
+			resp(c, http.StatusUnauthorized, errorx.ErrUnauthorized.Error())
+			c.Abort()
+			return
+		}
+
+		timestamp := c.GetHeader(TimestampHeader)
+		sent, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || math.Abs(time.Since(time.Unix(sent, 0)).Seconds()) > signatureTolerance.Seconds() {
+			resp(c, http.StatusUnauthorized, errorx.ErrUnauthorized.Error())
+			c.Abort()
+			return
+		}
+
+		signature, ok := strings.CutPrefix(c.GetHeader(SignatureHeader), "sha256=")
+		if !ok {
+			resp(c, http.StatusUnauthorized, errorx.ErrUnauthorized.Error())
+			c.Abort()
+			return
+		}
+		expected, err := hex.DecodeString(signature)
+		if err != nil {
+			resp(c, http.StatusUnauthorized, errorx.ErrUnauthorized.Error())
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodySize+1))
+		if err != nil {
+			resp(c, http.StatusBadRequest, errorx.ErrBadRequest.Error())
+			c.Abort()
+			return
+		}
+		// a truncated body would only fail the signature check, hiding why
+		if len(body) > maxSignedBodySize {
+			log.Printf("Rejecting signed request to %s: body over %d bytes", c.FullPath(), maxSignedBodySize)
+			resp(c, http.StatusRequestEntityTooLarge, model.ErrorResponse{Message: "Request body too large"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !hmac.Equal(expected, Sign(secret, timestamp, body)) {
+			resp(c, http.StatusUnauthorized, errorx.ErrUnauthorized.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set("username", PipelineActor)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), "username", PipelineActor))
+		c.Next()
+	}
+}
+
+// Sign computes the HMAC-SHA256 that VerifySignature expects for a request body sent at timestamp
+func Sign(secret string, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
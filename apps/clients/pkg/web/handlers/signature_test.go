@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "callback-secret"
+
+func signedRouter(secret string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/callback", handlers.VerifySignature(secret), func(c *gin.Context) {
+		var body map[string]any
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		body["actor"] = c.Request.Context().Value("username")
+		c.JSON(http.StatusOK, body)
+	})
+	return router
+}
+
+func signedRequest(secret string, sentAt time.Time, body string) *http.Request {
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	req, _ := http.NewRequest("POST", "/callback", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.TimestampHeader, timestamp)
+	req.Header.Set(handlers.SignatureHeader, "sha256="+hex.EncodeToString(handlers.Sign(secret, timestamp, []byte(body))))
+	return req
+}
+
+func TestVerifySignature_Valid(t *testing.T) {
+	w := httptest.NewRecorder()
+	signedRouter(testSecret).ServeHTTP(w, signedRequest(testSecret, time.Now(), `{"status":"processing"}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"processing","actor":"pipeline"}`, w.Body.String(), "body must still be readable by the handler")
+}
+
+func TestVerifySignature_TooLarge(t *testing.T) {
+	// a scraped profile well over the old 1MB limit is accepted
+	large := `{"scrapeResult":"` + strings.Repeat("a", 4<<20) + `"}`
+	w := httptest.NewRecorder()
+	signedRouter(testSecret).ServeHTTP(w, signedRequest(testSecret, time.Now(), large))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	tooLarge := `{"scrapeResult":"` + strings.Repeat("a", 16<<20) + `"}`
+	w = httptest.NewRecorder()
+	signedRouter(testSecret).ServeHTTP(w, signedRequest(testSecret, time.Now(), tooLarge))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestVerifySignature_Rejected(t *testing.T) {
+	tampered := signedRequest(testSecret, time.Now(), `{"status":"processing"}`)
+	tampered.Body = signedRequest(testSecret, time.Now(), `{"status":"completed"}`).Body
+
+	unsigned, _ := http.NewRequest("POST", "/callback", bytes.NewBufferString(`{}`))
+
+	malformed := signedRequest(testSecret, time.Now(), `{}`)
+	malformed.Header.Set(handlers.SignatureHeader, "sha256=not-hex")
+
+	tests := []struct {
+		name   string
+		secret string
+		req    *http.Request
+	}{
+		{"wrong secret", testSecret, signedRequest("other-secret", time.Now(), `{}`)},
+		{"tampered body", testSecret, tampered},
+		{"expired", testSecret, signedRequest(testSecret, time.Now().Add(-10*time.Minute), `{}`)},
+		{"unsigned", testSecret, unsigned},
+		{"malformed signature", testSecret, malformed},
+		{"no secret configured", "", signedRequest("", time.Now(), `{}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			signedRouter(tt.secret).ServeHTTP(w, tt.req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+type TimelineHandler struct {
+	service service.TimelineServiceInterface
+}
+
+func NewTimelineHandler(service service.TimelineServiceInterface) *TimelineHandler {
+	return &TimelineHandler{service: service}
+}
+
+// GetTimeline retrieves the recorded history of a tracked client field
+//
+//	@Summary		Get Client Field Timeline
+//	@Description	Retrieve the time series of a tracked field (netWorth, residence, occupations) with source attribution
+//	@Tags			clients
+//	@Produce		json
+//	@Param			id		path		string	true	"Hex id used to identify client"
+//	@Param			field	path		string	true	"Tracked field name"
+//	@Param			source		query		string	false	"Filter by source (manual, scrape, match)"
+//	@Param			from		query		string	false	"Start of time range (RFC3339)"
+//	@Param			to			query		string	false	"End of time range (RFC3339)"
+//	@Param			page		query		int		false	"Page number, defaults to 1"
+//	@Param			pageSize	query		int		false	"Points per page, defaults to 100"
+//	@Success		200		{object}	handlers.Response{data=model.GetTimelineResponse}
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		404		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Failure		502		{object}	handlers.Response
+//	@Router			/:id/timeline/:field [get]
+func (h *TimelineHandler) GetTimeline(c *gin.Context) {
+	clientID := c.Param("id")
+	field := c.Param("field")
+	if clientID == "" || field == "" {
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Missing id or field"})
+		return
+	}
+
+	query := &model.GetTimelineQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	timeline, err := h.service.GetTimeline(c.Request.Context(), clientID, field, query)
+	if err != nil {
+		log.Printf("Failed to retrieve timeline (ID: %s, field: %s): %v", clientID, field, err)
+		ErrorHandler(c, err, "Could not retrieve timeline")
+		return
+	}
+
+	resp(c, http.StatusOK, timeline)
+}
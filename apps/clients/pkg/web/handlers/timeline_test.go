@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TimelineHandlerTestSuite struct {
+	suite.Suite
+	mockSvc *mocks.TimelineServiceInterface
+	handler *handlers.TimelineHandler
+	router  *gin.Engine
+}
+
+func (suite *TimelineHandlerTestSuite) SetupTest() {
+	suite.mockSvc = new(mocks.TimelineServiceInterface)
+	suite.handler = handlers.NewTimelineHandler(suite.mockSvc)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.GET("/:id/timeline/:field", suite.handler.GetTimeline)
+}
+
+func (suite *TimelineHandlerTestSuite) TestGetTimeline_Success() {
+	timeline := &model.GetTimelineResponse{
+		ClientID: "abc",
+		Field:    "netWorth",
+		Total:    1,
+		Page:     2,
+		PageSize: 50,
+		Points:   []model.TimelinePoint{{Field: "netWorth", Value: 100, Source: model.ChangeSourceScrape}},
+	}
+	suite.mockSvc.On("GetTimeline", mock.Anything, "abc", "netWorth", mock.MatchedBy(func(q *model.GetTimelineQuery) bool {
+		return q.Source == model.ChangeSourceScrape && q.Page == 2 && q.PageSize == 50
+	})).Return(timeline, nil)
+
+	req, _ := http.NewRequest("GET", "/abc/timeline/netWorth?source=scrape&page=2&pageSize=50", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"source":"scrape"`)
+	assert.Contains(suite.T(), w.Body.String(), `"total":1`)
+}
+
+func (suite *TimelineHandlerTestSuite) TestGetTimeline_InvalidQuery() {
+	req, _ := http.NewRequest("GET", "/abc/timeline/netWorth?from=not-a-date", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *TimelineHandlerTestSuite) TestGetTimeline_UntrackedField() {
+	suite.mockSvc.On("GetTimeline", mock.Anything, "abc", "gender", mock.Anything).Return(nil, errorx.ErrInvalidInput)
+
+	req, _ := http.NewRequest("GET", "/abc/timeline/gender", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *TimelineHandlerTestSuite) TestGetTimeline_NotFound() {
+	suite.mockSvc.On("GetTimeline", mock.Anything, "abc", "netWorth", mock.Anything).Return(nil, errorx.ErrNotFound)
+
+	req, _ := http.NewRequest("GET", "/abc/timeline/netWorth", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func TestTimelineHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TimelineHandlerTestSuite))
+}
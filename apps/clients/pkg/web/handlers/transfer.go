@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
+)
+
+type TransferHandler struct {
+	service service.TransferServiceInterface
+}
+
+func NewTransferHandler(service service.TransferServiceInterface) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+// ImportClients bulk imports NDJSON client documents
+//
+//	@Summary		Import Clients
+//	@Description	Stream-import NDJSON client documents, upserting by id or by primary name
+//	@Tags			admin
+//	@Accept			application/x-ndjson
+//	@Produce		json
+//	@Param			mode	query		string	false	"Upsert mode (id, name)"
+//	@Param			dryRun	query		bool	false	"Validate without writing"
+//	@Success		200		{object}	handlers.Response{data=model.ImportResult}
+//	@Failure		400		{object}	handlers.Response
+//	@Failure		403		{object}	handlers.Response
+//	@Failure		500		{object}	handlers.Response
+//	@Failure		502		{object}	handlers.Response
+//	@Router			/admin/clients/import [post]
+func (h *TransferHandler) ImportClients(c *gin.Context) {
+	query := &model.ImportClientsQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		log.Printf("Failed to bind query: %v", err)
+		resp(c, http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters"})
+		return
+	}
+
+	result, err := h.service.ImportClients(c.Request.Context(), c.Request.Body, query)
+	if err != nil {
+		log.Printf("Failed to import clients: %v", err)
+		ErrorHandler(c, err, "Could not import clients")
+		return
+	}
+
+	resp(c, http.StatusOK, result)
+}
+
+// ExportClients streams every client as NDJSON
+//
+//	@Summary		Export Clients
+//	@Description	Stream the full client collection, including metadata and article links, as NDJSON
+//	@Tags			admin
+//	@Produce		application/x-ndjson
+//	@Success		200
+//	@Failure		403	{object}	handlers.Response
+//	@Router			/admin/clients/export [get]
+func (h *TransferHandler) ExportClients(c *gin.Context) {
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="clients.ndjson"`)
+	c.Status(http.StatusOK)
+
+	// headers are already sent once streaming starts, so failures can only be logged
+	count, err := h.service.ExportClients(c.Request.Context(), c.Writer)
+	if err != nil {
+		log.Printf("Client export aborted after %d records: %v", count, err)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/mocks"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransferHandlerTestSuite struct {
+	suite.Suite
+	mockSvc *mocks.TransferServiceInterface
+	handler *handlers.TransferHandler
+	router  *gin.Engine
+}
+
+func (suite *TransferHandlerTestSuite) SetupTest() {
+	suite.mockSvc = new(mocks.TransferServiceInterface)
+	suite.handler = handlers.NewTransferHandler(suite.mockSvc)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.POST("/admin/clients/import", suite.handler.ImportClients)
+	suite.router.GET("/admin/clients/export", suite.handler.ExportClients)
+}
+
+func (suite *TransferHandlerTestSuite) TestImportClients_Success() {
+	suite.mockSvc.On("ImportClients", mock.Anything, mock.Anything, &model.ImportClientsQuery{Mode: model.ImportModeName, DryRun: true}).
+		Return(&model.ImportResult{DryRun: true, Total: 1, Valid: 1}, nil)
+
+	req, _ := http.NewRequest("POST", "/admin/clients/import?mode=name&dryRun=true", strings.NewReader(`{"data":{}}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"valid":1`)
+}
+
+func (suite *TransferHandlerTestSuite) TestImportClients_InvalidMode() {
+	suite.mockSvc.On("ImportClients", mock.Anything, mock.Anything, mock.Anything).Return(nil, errorx.ErrInvalidInput)
+
+	req, _ := http.NewRequest("POST", "/admin/clients/import?mode=email", strings.NewReader(""))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *TransferHandlerTestSuite) TestExportClients_Streams() {
+	suite.mockSvc.On("ExportClients", mock.Anything, mock.Anything).Return(func(ctx context.Context, w io.Writer) (int, error) {
+		_, _ = w.Write([]byte("{\"_id\":1}\n"))
+		return 1, nil
+	})
+
+	req, _ := http.NewRequest("GET", "/admin/clients/export", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), "{\"_id\":1}\n", w.Body.String())
+}
+
+func TestTransferHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TransferHandlerTestSuite))
+}
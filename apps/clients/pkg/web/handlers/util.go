@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/owjoel/client-factpack/apps/clients/config"
 	errorx "github.com/owjoel/client-factpack/apps/clients/pkg/api/errors"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
 )
 
 type Response struct {
@@ -44,6 +47,13 @@ func ErrorHandler(c *gin.Context, err error, message string) {
 		resp(c, http.StatusNotFound, model.ErrorResponse{Message: "Not found: " + message})
 	case errors.Is(err, errorx.ErrConflict):
 		resp(c, http.StatusConflict, model.ErrorResponse{Message: "Conflict: " + message})
+	case errors.Is(err, errorx.ErrTooManyRequests):
+		var quota *service.QuotaExceededError
+		if errors.As(err, &quota) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(quota.RetryAfter.Seconds()))))
+			message += ", " + quota.Quota + " job quota reached"
+		}
+		resp(c, http.StatusTooManyRequests, model.ErrorResponse{Message: "Too many requests: " + message})
 	case errors.Is(err, errorx.ErrInternal):
 		resp(c, http.StatusInternalServerError, model.ErrorResponse{Message: "Internal server error: " + message})
 	case errors.Is(err, errorx.ErrDependencyFailed):
@@ -14,6 +14,7 @@ import (
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"github.com/owjoel/client-factpack/apps/clients/config"
+	"github.com/owjoel/client-factpack/apps/clients/pkg/api/model"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/repository"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/service"
 	"github.com/owjoel/client-factpack/apps/clients/pkg/web/handlers"
@@ -23,6 +24,10 @@ import (
 
 type Router struct {
 	*gin.Engine
+	reaper     *service.Reaper
+	dispatcher *service.OutboxDispatcher
+	anchorer   *service.LogAnchorer
+	purger     *service.LogPurger
 }
 
 func NewRouter() *Router {
@@ -33,9 +38,9 @@ func NewRouter() *Router {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:4173"}, // Allow frontend origin
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", handlers.IdempotencyKeyHeader},
 		AllowCredentials: true,
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", handlers.IdempotentReplayedHeader, "Retry-After", handlers.ExportIDHeader},
 	}))
 
 	pprof.Register(router)
@@ -45,59 +50,168 @@ func NewRouter() *Router {
 	logRepository := repository.NewMongoLogRepository(mongoDb)
 	logService := service.NewLogService(logRepository)
 	logHandler := handlers.NewLogHandler(logService)
+	logExportRepository := repository.NewMongoLogExportRepository(mongoDb)
+	logExportService := service.NewLogExportService(logRepository, logExportRepository, logService, config.AuditExportSecret)
+	logExportHandler := handlers.NewLogExportHandler(logExportService)
+	anchorStore := repository.InitLogAnchorStore()
+	archiveStore := repository.NewFileArchiveStore(config.AuditArchiveDir)
+	logChainService := service.NewLogChainService(logRepository, anchorStore, archiveStore)
+	logChainHandler := handlers.NewLogChainHandler(logChainService)
+	anchorer := service.NewLogAnchorer(logChainService, config.AuditAnchorInterval)
+
+	clientRepository := repository.NewMongoClientRepository(mongoDb)
+
+	idempotencyRepository := repository.NewMongoIdempotencyRepository(mongoDb)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepository, config.IdempotencyKeyTTL)
+	idempotent := handlers.Idempotency(idempotencyService)
+
+	var workflow service.WorkflowBackend = service.NewPrefectFlowRunner(config.PrefectAPIURL, config.PrefectAPIKey, &http.Client{})
+	var localWorkflow *service.LocalWorkflowBackend
+	if config.WorkflowBackend == service.WorkflowBackendLocal {
+		log.Printf("Running jobs with the local workflow backend")
+		localWorkflow = service.NewLocalWorkflowBackend()
+		localWorkflow.Register(model.Scrape, service.StubScrapeHandler(clientRepository))
+		localWorkflow.Register(model.Match, service.StubMatchHandler())
+		localWorkflow.Register(model.Sync, service.StubSyncHandler())
+		workflow = localWorkflow
+	}
+
+	notifier := service.NewRabbitMQNotifier(config.RabbitMQURL)
+
+	transactor := repository.NewMongoTransactor(mongoDb)
 
 	jobRepository := repository.NewMongoJobRepository(mongoDb)
-	jobService := service.NewJobService(jobRepository)
-	jobHandler := handlers.NewJobHandler(jobService)
+	outboxRepository := repository.NewMongoOutboxRepository(mongoDb)
+	quotaService := service.NewQuotaService(jobRepository, service.QuotaLimits{
+		MaxActivePerUser: config.QuotaMaxActivePerUser,
+		MaxActiveGlobal:  config.QuotaMaxActiveGlobal,
+		DailyJobs:        config.QuotaDailyJobs,
+		DailyJobsByGroup: config.QuotaDailyJobsByGroup,
+		RetryAfter:       config.QuotaRetryAfter,
+	})
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
 
-	prefectFlowRunner := service.NewPrefectFlowRunner(config.PrefectAPIURL, config.PrefectAPIKey, &http.Client{})
+	timelineRepository := repository.NewMongoTimelineRepository(mongoDb)
+	timelineService := service.NewTimelineService(timelineRepository, clientRepository)
+	timelineHandler := handlers.NewTimelineHandler(timelineService)
 
-	clientRepository := repository.NewMongoClientRepository(mongoDb)
-	clientService := service.NewClientService(clientRepository, jobService, logService, prefectFlowRunner)
-	clientHandler := handlers.NewClientHandler(clientService)
+	jobService := service.NewJobService(jobRepository, outboxRepository, workflow, logService, notifier, transactor, quotaService, timelineService)
+	if localWorkflow != nil {
+		localWorkflow.SetCallbackHandler(jobService)
+	}
+	jobHandler := handlers.NewJobHandler(jobService)
+
+	leaseRepository := repository.NewMongoLeaseRepository(mongoDb)
+	reaper := service.NewReaper(jobService, leaseRepository, config.JobReaperInterval, map[model.JobType]time.Duration{
+		model.Scrape: config.JobTimeoutScrape,
+		model.Match:  config.JobTimeoutMatch,
+		model.Sync:   config.JobTimeoutSync,
+	}, config.JobReaperCheckPrefect)
+	dispatcher := service.NewOutboxDispatcher(jobService, config.OutboxDispatchInterval, config.OutboxMaxAttempts)
 
 	articleRepository := repository.NewMongoArticleRepository(mongoDb)
-	articleService := service.NewArticleService(articleRepository)
+	feedbackRepository := repository.NewMongoFeedbackRepository(mongoDb)
+	articleService := service.NewArticleService(articleRepository, clientRepository, feedbackRepository, logService)
 	articleHandler := handlers.NewArticleHandler(articleService)
 
+	activityRepository := repository.NewMongoActivityRepository(mongoDb)
+	activityService := service.NewActivityService(activityRepository, clientRepository)
+	activityHandler := handlers.NewActivityHandler(activityService)
+
+	matchService := service.NewMatchService(jobRepository, clientRepository, jobService, logService, timelineService, transactor)
+	matchHandler := handlers.NewMatchHandler(matchService)
+
+	clientService := service.NewClientService(clientRepository, jobService, logService, timelineService, transactor, quotaService)
+	clientHandler := handlers.NewClientHandler(clientService)
+
+	retention := map[model.Operation]time.Duration{}
+	for op, days := range config.AuditRetentionDays {
+		retention[model.Operation(op)] = time.Duration(days) * 24 * time.Hour
+	}
+	retentionService := service.NewLogRetentionService(logRepository, clientRepository, archiveStore, anchorStore, logService, retention, time.Duration(config.AuditRetentionDefaultDays)*24*time.Hour)
+	purger := service.NewLogPurger(retentionService, leaseRepository, config.AuditRetentionInterval)
+
+	transferService := service.NewTransferService(clientRepository, logService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+
 	v1API := router.Group("/api/v1/clients")
 	v1Logs := router.Group("/api/v1/logs")
 	v1Jobs := router.Group("/api/v1/jobs")
 	v1Articles := router.Group("/api/v1/articles")
+	v1Admin := router.Group("/api/v1/admin")
+	v1Internal := router.Group("/api/v1/internal")
 	v1API.GET("/health", clientHandler.HealthCheck)
 
 	// enable auth
 	v1API.Use(handlers.Authenticate(handlers.GetJWKS))
 	v1Logs.Use(handlers.Authenticate(handlers.GetJWKS))
 	v1Jobs.Use(handlers.Authenticate(handlers.GetJWKS))
+	v1Admin.Use(handlers.Authenticate(handlers.GetJWKS), handlers.RequireGroup(config.AdminGroup))
+	v1Internal.Use(handlers.VerifySignature(config.JobCallbackSecret)) // called by the Prefect flows, not users
 
 	// Use RPC styling rather than REST
 	// startregion Clients
 	v1API.GET("/:id", clientHandler.GetClient)
 	v1API.GET("/", clientHandler.GetAllClients)
 	v1API.PUT("/:id", clientHandler.UpdateClient)
-	v1API.POST("/scrape", clientHandler.CreateClientByName)
-	v1API.POST("/:id/scrape", clientHandler.RescrapeClient)
-	v1API.POST("/:id/match", clientHandler.MatchClient)
+	v1API.POST("/scrape", idempotent, clientHandler.CreateClientByName)
+	v1API.POST("/:id/scrape", idempotent, clientHandler.RescrapeClient)
+	v1API.POST("/rescrape", idempotent, clientHandler.RescrapeClients)
+	v1API.POST("/:id/match", idempotent, clientHandler.MatchClient)
+	v1API.GET("/:id/timeline/:field", timelineHandler.GetTimeline)
+	v1API.GET("/:id/articles", articleHandler.GetClientArticles)
+	v1API.GET("/:id/sentiment", articleHandler.GetClientSentiment)
+	v1API.POST("/:id/articles/:articleId", articleHandler.LinkArticle)
+	v1API.DELETE("/:id/articles/:articleId", articleHandler.UnlinkArticle)
+	v1API.PUT("/:id/articles/:articleId/feedback", articleHandler.ReviewArticle)
+	v1API.GET("/:id/activity", activityHandler.GetActivity)
+	v1API.POST("/:id/notes", activityHandler.AddNote)
 	// endregion Clients
 
 	// startregion Jobs
 	v1Jobs.GET("/:id", jobHandler.GetJob)
 	v1Jobs.GET("/", jobHandler.GetAllJobs)
+	v1Jobs.POST("/:id/cancel", jobHandler.CancelJob)
+	v1Jobs.POST("/:id/retry", jobHandler.RetryJob)
+	v1Jobs.GET("/:id/events", jobHandler.StreamJobEvents)
+	v1Jobs.GET("/:id/children", jobHandler.GetBatchChildren)
+	v1Jobs.POST("/:id/children/cancel", jobHandler.CancelBatch)
+	v1Jobs.POST("/:id/children/retry", jobHandler.RetryBatch)
+	v1Jobs.GET("/stats", jobHandler.GetJobStats)
+	v1Jobs.GET("/usage", quotaHandler.GetUsage)
+	v1Jobs.GET("/matches", matchHandler.GetPendingMatches)
+	v1Jobs.GET("/matches/stats", matchHandler.GetMatchStats)
+	v1Jobs.POST("/:id/matches/:candidateId", matchHandler.ReviewMatch)
 	// endregion Jobs
 
+	// startregion Internal
+	v1Internal.POST("/jobs/:id/callback", jobHandler.JobCallback)
+	v1Internal.POST("/articles", articleHandler.CreateArticle)
+	// endregion Internal
+
 	// startregion Logs
 	v1Logs.GET("/", logHandler.GetLogs)
 	v1Logs.GET("/:id", logHandler.GetLog)
+	v1Logs.GET("/export", logExportHandler.ExportLogs)
+	v1Logs.GET("/exports/:id", logExportHandler.GetLogExport)
 	// endregion Logs
 
 	// startregion Articles
 	v1Articles.POST("/", articleHandler.GetAllArticles)
+	v1Articles.POST("/ingest", handlers.Authenticate(handlers.GetJWKS), articleHandler.CreateArticle)
+	v1Articles.GET("/feedback/stats", handlers.Authenticate(handlers.GetJWKS), articleHandler.GetFeedbackStats)
 	// endregion Articles
 
+	// startregion Admin
+	v1Admin.POST("/clients/import", transferHandler.ImportClients)
+	v1Admin.GET("/clients/export", transferHandler.ExportClients)
+	v1Admin.GET("/logs/verify", logChainHandler.VerifyLogChain)
+	v1Admin.PUT("/clients/:id/legal-hold", clientHandler.SetLegalHold)
+	// endregion Admin
+
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-	return &Router{router}
+	return &Router{Engine: router, reaper: reaper, dispatcher: dispatcher, anchorer: anchorer, purger: purger}
 }
 
 func (r *Router) Run() {
@@ -107,6 +221,13 @@ func (r *Router) Run() {
 		Handler: r.Engine,
 	}
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go r.reaper.Run(workerCtx)
+	go r.dispatcher.Run(workerCtx)
+	go r.anchorer.Run(workerCtx)
+	go r.purger.Run(workerCtx)
+
 	go func() {
 		log.Printf("started on port: %v\n", port)
 		err := srv.ListenAndServe()
@@ -120,6 +241,7 @@ func (r *Router) Run() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -35,7 +35,7 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Job status filter (completed, pending, failed, processing)",
+                        "description": "Job status filter (completed, pending, failed, processing, cancelled, partial)",
                         "name": "status",
                         "in": "query"
                     },
@@ -13,9 +13,13 @@ const (
 	JobStatusPending    JobStatus = "pending"
 	JobStatusFailed     JobStatus = "failed"
 	JobStatusProcessing JobStatus = "processing"
+	JobStatusCancelled  JobStatus = "cancelled"
+	JobStatusPartial    JobStatus = "partial"
 
 	JobTypeScrape JobType = "scrape"
 	JobTypeMatch  JobType = "match"
+	JobTypeBatch  JobType = "batch"
+	JobTypeSync   JobType = "sync"
 
 	PriorityHigh   Priority = "high"
 	PriorityMedium Priority = "medium"
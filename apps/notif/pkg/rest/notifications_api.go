@@ -18,7 +18,7 @@ func InitNotificationAPI(store *storage.NotificationStorage) {
 // @Description  Returns job notifications for a given username, optionally filtered by status
 // @Tags         notifications
 // @Param        username  query  string  true  "Username"
-// @Param        status    query  string  false "Job status filter (completed, pending, failed, processing)"
+// @Param        status    query  string  false "Job status filter (completed, pending, failed, processing, cancelled, partial)"
 // @Param        page      query  int     false "Page number"
 // @Param        pageSize  query  int     false "Number of items per page"
 // @Produce      json
@@ -1,26 +1,11 @@
 import json
 from prefect import flow
 from tasks.scrape_task import generate_openai_response, parse_openai_response
-from tasks.qdrant_task import search_profiles_by_json, update_qdrant_client_profile
+from tasks.qdrant_task import search_profiles_by_json
 from tasks.dedupe_task import dedupe_against_mongo
-from tasks.mongo_task import (
-    update_job_status,
-    update_job_match_results,
-    get_client_names,
-    add_job_log,
-    get_client_profile,
-    update_mongo_client_profile,
-)
+from tasks.mongo_task import get_client_names
+from tasks.job_task import update_job_status, update_job_match_results, add_job_log
 from tasks.pdf_task import decode_file, extract_text
-from tasks.notification_task import (
-    publish_notification,
-    JobStatus,
-    JobType,
-    Priority,
-    Notification,
-    NotificationType,
-)
-from tasks.merge_task import merge_profiles, review_with_openai
 from bson import ObjectId
 
 
@@ -33,7 +18,7 @@ def match_client_flow(
     1. Decode and extract text
     2. Generate and parse LLM response
     3. Search for profile matches
-    4. Update job results and status
+    4. Update job results and status, a reviewer accepts or rejects the match
     """
 
     DEDUPE_WEIGHT = 0.6
@@ -87,54 +72,20 @@ def match_client_flow(
                         "confidenceScore": weighted_avg,
                     }
                 ],
+                profile_json,
             )
 
-            # merge profile
-            existing_profile = get_client_profile(dedupe_match["matched_id"])
-            merged_profile = merge_profiles(profile_json, existing_profile)
-            cleaned_profile = review_with_openai(
-                profile_json, existing_profile, merged_profile
-            )
-            update_mongo_client_profile(dedupe_match["matched_id"], cleaned_profile)
-            update_qdrant_client_profile(dedupe_match["matched_id"], cleaned_profile)
-
         else:
-            update_job_match_results(job_id, [])
+            update_job_match_results(job_id, [], profile_json)
 
         if job_id:
             update_job_status(job_id, "completed", "Client matching job completed")
 
-        notification = Notification(
-            notificationType=NotificationType.JOB,
-            username=username,
-            jobId=job_id,
-            status=JobStatus.COMPLETED,
-            type=JobType.MATCH,
-            clientId=target_id,
-            clientName=names,
-            priority=Priority.LOW,
-        )
-
-        print("[MATCHING] Sending notification...")
-        publish_notification(notification)
-        add_job_log(job_id, "Notification sent, job completed")
-
         print("[MATCHING] Job completed, exiting...")
 
     except Exception as e:
         error_msg = f"Error while processing: {str(e)}"
         print(error_msg)
 
-        notification = Notification(
-            notificationType=NotificationType.JOB,
-            username=username,
-            jobId=job_id,
-            status=JobStatus.FAILED,
-            type=JobType.MATCH,
-            clientId=target_id,
-            clientName=names,
-            priority=Priority.MEDIUM,
-        )
-
-        print("[MATCHING] Job failed, sending notification...")
-        publish_notification(notification)
+        if job_id:
+            update_job_status(job_id, "failed", error_msg)
@@ -8,15 +8,7 @@ from tasks.scrape_task import (
     save_files,
     update_client_profile,
 )
-from tasks.mongo_task import update_job_status, add_job_log
-from tasks.notification_task import (
-    publish_notification,
-    JobStatus,
-    JobType,
-    Priority,
-    Notification,
-    NotificationType,
-)
+from tasks.job_task import update_job_status, add_job_log
 
 
 @flow(name="scrape-client", log_prints=True)
@@ -60,35 +52,8 @@ def scrape_client_flow(job_id: str, target: str, client_id: str, username: str):
                 job_id, "completed", f"Client scraping job completed for {target}"
             )
 
-        notification = Notification(
-            notificationType=NotificationType.JOB,
-            username=username,
-            jobId=job_id,
-            status=JobStatus.COMPLETED,
-            type=JobType.SCRAPE,
-            clientId=client_id,
-            clientName=[target_clean],
-            priority=Priority.LOW,
-        )
-
-        print("Job Completed. Sending notification...")
-        publish_notification(notification)
-
     except Exception as e:
         error_msg = f"Error while processing {target}: {str(e)}"
         print(error_msg)
         if job_id:
             update_job_status(job_id, "failed", error_msg)
-        notification = Notification(
-            notificationType=NotificationType.JOB,
-            username=username,
-            jobId=job_id,
-            status=JobStatus.FAILED,
-            type=JobType.SCRAPE,
-            clientId=client_id,
-            clientName=[target_clean],
-            priority=Priority.LOW,
-        )
-
-        print("Job Failed: Sending notification...")
-        publish_notification(notification)
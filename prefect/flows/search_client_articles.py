@@ -14,10 +14,8 @@ from tasks.sentiment_task import analyze_sentiment, getPriority
 from tasks.qdrant_task import search_profiles_by_json
 from tasks.dedupe_task import dedupe_against_mongo
 from model.client_article import ClientArticle
-from utils.mongo_utils import (
-    put_article,
-    update_client_article,
-)
+from utils.mongo_utils import is_article_excluded, get_client_names
+from utils.clients_api import ingest_article
 
 
 @flow(task_runner=ThreadPoolTaskRunner(max_workers=3), log_prints=True)
@@ -35,6 +33,7 @@ def search_client(c: str):
         title: str = article.get("title", "Unknown Title")
         url: str = article.get("url", "Unknown URL")
         source: str = article["source"].get("name", "Unknown Source")
+        published_at = article.get("publishedAt")
         article_text: str = scrape_article.submit(url).result()
         if article_text.startswith("Error"):
             continue
@@ -43,7 +42,12 @@ def search_client(c: str):
         summary = summarize_text.submit(article_text).result()
         sentiment = analyze_sentiment.submit(summary).result()
         obj = ClientArticle(
-            source=source, title=title, url=url, summary=summary, sentiment=sentiment
+            source=source,
+            title=title,
+            url=url,
+            summary=summary,
+            sentiment=sentiment,
+            publishedAt=published_at,
         )
 
         # qdrant to match client
@@ -60,10 +64,13 @@ def search_client(c: str):
         ).result()
         if not client_id:
             continue
+        if is_article_excluded(client_id["matched_id"], obj.url):
+            logger.info(f"Skipping article marked irrelevant for client: {obj.url}")
+            continue
 
-        # upload article, update client, and send to queue
-        article_id = put_article(obj)
-        names = update_client_article(client_id["matched_id"], article_id)
+        # ingest article linked to the client, and send to queue
+        ingest_article(obj.model_dump(mode="json"), [client_id["matched_id"]])
+        names = get_client_names(client_id["matched_id"])
         message = {
             "notificationType": "client",
             "title": obj.title,
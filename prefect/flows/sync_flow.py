@@ -0,0 +1,30 @@
+from prefect import flow
+from tasks.mongo_task import get_client_profile
+from tasks.qdrant_task import update_qdrant_client_profile
+from tasks.job_task import update_job_status, add_job_log
+
+
+# re-embeds a client's profile after a reviewer accepted match fields onto it, so later matches search the new profile
+@flow(name="sync-client", log_prints=True)
+def sync_client_flow(job_id: str, target_id: str):
+    try:
+        if job_id:
+            update_job_status(job_id, "processing", "Client sync job started")
+
+        profile = get_client_profile(target_id)
+        if not profile:
+            raise ValueError(f"Client {target_id} has no profile to sync")
+
+        update_qdrant_client_profile(target_id, profile)
+        print(f"[SYNC] Updated vector for client {target_id}")
+        add_job_log(job_id, f"Updated vector for client {target_id}")
+
+        if job_id:
+            update_job_status(job_id, "completed", "Client sync job completed")
+
+    except Exception as e:
+        error_msg = f"Error while syncing: {str(e)}"
+        print(error_msg)
+
+        if job_id:
+            update_job_status(job_id, "failed", error_msg)
@@ -1,3 +1,6 @@
+from datetime import datetime
+from typing import Optional
+
 from pydantic import BaseModel
 
 
@@ -12,3 +15,4 @@ class ClientArticle(BaseModel):
     url: str
     summary: str
     sentiment: Sentiment
+    publishedAt: Optional[datetime] = None
@@ -0,0 +1,61 @@
+from prefect import task
+
+from utils.clients_api import post_signed
+
+
+class JobClosedError(RuntimeError):
+    """The job has already finished or been cancelled, so the flow should stop reporting on it."""
+
+
+def _post_callback(job_id: str, payload: dict) -> dict:
+    response = post_signed(f"/jobs/{job_id}/callback", payload)
+
+    if response.status_code == 404:
+        raise ValueError(f"Job with ID {job_id} not found")
+    if response.status_code == 409:
+        raise JobClosedError(response.json().get("data", {}).get("message", "job is closed"))
+    response.raise_for_status()
+    return response.json().get("data", {})
+
+
+@task
+def update_job_status(job_id: str, status: str, log_message: str = None):
+    payload = {"status": status}
+    if log_message:
+        payload["message"] = log_message
+    if status == "completed":
+        payload["progress"] = 100
+
+    try:
+        _post_callback(job_id, payload)
+    except JobClosedError as e:
+        # a cancelled job stays cancelled even if the flow run was already finishing
+        print(f"Not updating job {job_id}: {e}")
+
+
+@task
+def add_job_log(job_id: str, log_message: str, progress: int = None):
+    payload = {"message": log_message}
+    if progress is not None:
+        payload["progress"] = progress
+    _post_callback(job_id, payload)
+
+
+@task
+def update_job_match_results(
+    job_id: str, match_results: list, extracted_profile: dict = None
+):
+    results = [
+        {"id": str(m["_id"]), "confidenceScore": m["confidenceScore"]}
+        for m in match_results
+    ]
+    payload = {"matchResults": results}
+    if extracted_profile is not None:
+        # reviewers pick fields from it when accepting a candidate
+        payload["extractedProfile"] = extracted_profile
+    _post_callback(job_id, payload)
+
+
+@task
+def update_job_scrape_result(job_id: str, scrape_result: str):
+    _post_callback(job_id, {"scrapeResult": str(scrape_result)})
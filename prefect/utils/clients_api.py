@@ -0,0 +1,39 @@
+import hashlib
+import hmac
+import json
+import os
+import time
+
+import requests
+from dotenv import load_dotenv
+
+load_dotenv()
+CLIENTS_API_URL = os.getenv("CLIENTS_API_URL", "http://localhost:8081")
+JOB_CALLBACK_SECRET = os.getenv("JOB_CALLBACK_SECRET", "")
+
+
+def post_signed(path: str, payload: dict, timeout: int = 10) -> requests.Response:
+    """POSTs payload to the clients service's internal API, signed with the shared callback secret."""
+    body = json.dumps(payload, separators=(",", ":")).encode()
+    timestamp = str(int(time.time()))
+    signature = hmac.new(
+        JOB_CALLBACK_SECRET.encode(), f"{timestamp}.".encode() + body, hashlib.sha256
+    ).hexdigest()
+
+    return requests.post(
+        f"{CLIENTS_API_URL}/api/v1/internal{path}",
+        data=body,
+        headers={
+            "Content-Type": "application/json",
+            "X-Signature": f"sha256={signature}",
+            "X-Signature-Timestamp": timestamp,
+        },
+        timeout=timeout,
+    )
+
+
+def ingest_article(article: dict, client_ids: list[str]) -> dict:
+    """Ingests an article through the clients service, which de-duplicates it on its canonical URL and links it to the clients."""
+    response = post_signed("/articles", {**article, "clientIds": client_ids})
+    response.raise_for_status()
+    return response.json().get("data", {})
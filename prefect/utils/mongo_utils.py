@@ -3,8 +3,8 @@ import logging
 
 from pymongo import MongoClient
 from bson import ObjectId
-from model.client_article import ClientArticle
 from dotenv import load_dotenv
+from utils.url_utils import canonicalize_url
 
 load_dotenv()
 
@@ -12,37 +12,48 @@ mongo_client = MongoClient(os.getenv("MONGO_URI"))
 db_name = "client-factpack"
 db = mongo_client[db_name]
 
-article_collection = "articles"
 clients_collection = "clients"
+feedback_collection = "articleFeedback"
 
 
-def put_article(article: ClientArticle):
-    articles = db[article_collection]
-    result = articles.insert_one(article.model_dump())
-    id = result.inserted_id
-    return id
-
-
-def update_client_article(client_id: str, article_id: ObjectId):
+def get_client_names(client_id: str) -> list[str]:
     try:
         _id = ObjectId(client_id)
     except Exception:
         logging.error("Invalid clientId: Unable to convert to ObjectId", exc_info=True)
         return []
 
-    clients = db[clients_collection]
-    result = clients.find_one_and_update(
-        {"_id": _id},
-        {"$addToSet": {"articles": article_id}},
-        projection={"data.profile.names": 1},
+    result = db[clients_collection].find_one(
+        {"_id": _id}, projection={"data.profile.names": 1}
     )
-
     if not result:
         logging.warning(f"Client with ID {_id} not found")
+        return []
 
     return result["data"]["profile"]["names"]
 
 
+def is_article_excluded(client_id: str, url: str) -> bool:
+    """True if a reviewer marked the article at url, or any other link to it, irrelevant for the client."""
+    canonical = canonicalize_url(url)
+    feedback = db[feedback_collection]
+    return (
+        feedback.find_one(
+            {
+                "clientId": client_id,
+                "relevant": False,
+                "$or": [
+                    {"canonicalUrl": canonical},
+                    # feedback stored before canonical URLs were recorded
+                    {"canonicalUrl": {"$exists": False}, "url": canonical},
+                ],
+            },
+            projection={"_id": 1},
+        )
+        is not None
+    )
+
+
 def fetch_mongo_records_by_ids(ids: list):
     mongo_uri = os.getenv("MONGO_URI")
     db_name = os.getenv("DB_NAME")
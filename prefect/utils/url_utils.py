@@ -0,0 +1,34 @@
+from urllib.parse import parse_qsl, urlencode, urlsplit, urlunsplit
+
+# query parameters that vary per share link but not per article
+TRACKING_PARAMS = {"fbclid", "gclid", "mc_cid", "mc_eid", "igshid"}
+
+
+def canonicalize_url(raw: str) -> str:
+    """Normalises an article URL the same way the clients service does, so links to the same article compare equal.
+
+    The scheme and host are lowercased, "www.", default ports, fragments, tracking parameters and trailing slashes
+    are dropped, and the remaining query parameters are sorted. URLs that can't be parsed are returned unchanged.
+    """
+    try:
+        parts = urlsplit(raw.strip())
+        port = parts.port
+    except ValueError:
+        return raw
+
+    scheme = parts.scheme.lower()
+    if scheme not in ("http", "https") or not parts.hostname:
+        return raw
+
+    host = parts.hostname.lower().removeprefix("www.")
+    if port is not None and not (scheme == "http" and port == 80) and not (scheme == "https" and port == 443):
+        host = f"{host}:{port}"
+
+    query = [
+        (key, value)
+        for key, value in parse_qsl(parts.query, keep_blank_values=True)
+        if not key.lower().startswith("utm_") and key.lower() not in TRACKING_PARAMS
+    ]
+    query.sort(key=lambda kv: kv[0])
+
+    return urlunsplit((scheme, host, parts.path.rstrip("/"), urlencode(query), ""))